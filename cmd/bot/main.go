@@ -11,21 +11,36 @@ import (
 	"time"
 
 	"github.com/tian841224/stock-bot/config"
+	"github.com/tian841224/stock-bot/internal/api/discordbot"
+	"github.com/tian841224/stock-bot/internal/api/imagestore"
 	"github.com/tian841224/stock-bot/internal/api/linebot"
+	"github.com/tian841224/stock-bot/internal/api/slackbot"
 	"github.com/tian841224/stock-bot/internal/api/tgbot"
 	"github.com/tian841224/stock-bot/internal/db"
 	cnyesInfra "github.com/tian841224/stock-bot/internal/infrastructure/cnyes"
+	discordInfra "github.com/tian841224/stock-bot/internal/infrastructure/discordbot"
 	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade"
 	fugleInfra "github.com/tian841224/stock-bot/internal/infrastructure/fugle"
+	imagestoreInfra "github.com/tian841224/stock-bot/internal/infrastructure/imagestore"
 	"github.com/tian841224/stock-bot/internal/infrastructure/imgbb"
 	linebotInfra "github.com/tian841224/stock-bot/internal/infrastructure/linebot"
+	slackInfra "github.com/tian841224/stock-bot/internal/infrastructure/slackbot"
 	tgbotInfra "github.com/tian841224/stock-bot/internal/infrastructure/tgbot"
 	twseInfra "github.com/tian841224/stock-bot/internal/infrastructure/twse"
 	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	discordService "github.com/tian841224/stock-bot/internal/service/bot/discord"
 	lineService "github.com/tian841224/stock-bot/internal/service/bot/line"
+	slackService "github.com/tian841224/stock-bot/internal/service/bot/slack"
 	tgService "github.com/tian841224/stock-bot/internal/service/bot/tg"
+	"github.com/tian841224/stock-bot/internal/service/exchange_rate_alert"
+	"github.com/tian841224/stock-bot/internal/service/job_run"
+	"github.com/tian841224/stock-bot/internal/service/stock_news"
+	"github.com/tian841224/stock-bot/internal/service/symbol_search"
+	"github.com/tian841224/stock-bot/internal/service/trading_calendar"
 	twstockService "github.com/tian841224/stock-bot/internal/service/twstock"
 	"github.com/tian841224/stock-bot/internal/service/user"
+	"github.com/tian841224/stock-bot/internal/service/user_preference"
 	"github.com/tian841224/stock-bot/internal/service/user_subscription"
 	"github.com/tian841224/stock-bot/pkg/logger"
 
@@ -35,21 +50,30 @@ import (
 
 // 初始化結果結構
 type InitResult struct {
-	cfg                  *config.Config
-	log                  logger.Logger
-	userRepo             repository.UserRepository
-	symbolsRepo          repository.SymbolRepository
-	userSubscriptionRepo repository.UserSubscriptionRepository
-	fugleAPI             *fugleInfra.FugleAPI
-	finmindClient        *finmindtrade.FinmindTradeAPI
-	twseAPI              *twseInfra.TwseAPI
-	cnyesAPI             *cnyesInfra.CnyesAPI
-	imgbbClient          *imgbb.ImgBBClient
-	userService          user.UserService
-	stockService         twstockService.StockService
-	lineBotClient        *linebotInfra.LineBotClient
-	tgBotClient          *tgbotInfra.TgBotClient
-	err                  error
+	cfg                   *config.Config
+	log                   logger.Logger
+	userRepo              repository.UserRepository
+	symbolsRepo           repository.SymbolRepository
+	userSubscriptionRepo  repository.UserSubscriptionRepository
+	exchangeRateAlertRepo repository.ExchangeRateAlertRepository
+	userPreferenceRepo    repository.UserPreferenceRepository
+	newsSeenRepo          repository.NewsSeenRepository
+	newsAlertRepo         repository.NewsAlertRepository
+	callbackPayloadRepo   repository.CallbackPayloadRepository
+	jobRunRepo            repository.JobRunRepository
+	fugleAPI              *fugleInfra.FugleAPI
+	finmindClient         *finmindtrade.FinmindTradeAPI
+	twseAPI               *twseInfra.TwseAPI
+	cnyesAPI              *cnyesInfra.CnyesAPI
+	imageStore            *imagestoreInfra.ImageStore
+	imgbbClient           *imgbb.ImgBBClient
+	userService           user.UserService
+	stockService          twstockService.StockService
+	lineBotClient         *linebotInfra.LineBotClient
+	tgBotClient           *tgbotInfra.TgBotClient
+	discordBotClient      *discordInfra.DiscordBotClient
+	slackBotClient        *slackInfra.SlackBotClient
+	err                   error
 }
 
 func main() {
@@ -85,33 +109,85 @@ func main() {
 
 	// 建立使用者訂閱服務
 	userSubscriptionService := user_subscription.NewUserSubscriptionService(initResult.userSubscriptionRepo)
-	// 建立 LINE Bot 服務層
-	lineSvc := lineService.NewLineService(initResult.stockService, userSubscriptionService, initResult.log)
-	lineCommandHandler := lineService.NewLineCommandHandler(
-		initResult.lineBotClient,
-		lineSvc,
-		initResult.userService,
+	// 建立使用者偏好設定服務
+	userPreferenceService := user_preference.NewUserPreferenceService(initResult.userPreferenceRepo)
+	// 建立交易日曆服務
+	tradingCalendarService := trading_calendar.NewTradingCalendarService(initResult.finmindClient, initResult.twseAPI, initResult.log)
+	// 建立匯率警示服務
+	exchangeRateAlertService := exchange_rate_alert.NewExchangeRateAlertService(initResult.exchangeRateAlertRepo, initResult.stockService, initResult.log)
+	// 建立股票新聞服務
+	stockNewsService := stock_news.NewStockNewsService(initResult.newsSeenRepo, initResult.newsAlertRepo, initResult.stockService, initResult.log)
+	// 建立排程執行服務，供管理員查詢排程紀錄及手動執行
+	jobRunService := job_run.NewJobRunService(initResult.jobRunRepo, initResult.log)
+	// 建立股票搜尋服務
+	symbolSearchService := symbol_search.NewSymbolSearchService(initResult.symbolsRepo, initResult.log)
+	// 建立共用指令註冊表
+	commandRegistry := command.NewCommandRegistry(
+		initResult.stockService,
 		userSubscriptionService,
-		initResult.imgbbClient,
+		userPreferenceService,
+		exchangeRateAlertService,
+		stockNewsService,
+		jobRunService,
+		symbolSearchService,
+		tradingCalendarService,
 		initResult.log,
 	)
-	service := lineService.NewBotService(initResult.lineBotClient, lineCommandHandler, initResult.userService, initResult.log)
+	// LINE 圖片及 Telegram 行內查詢的圖表需公開網址，優先使用自架圖片服務，未設定任何圖片服務時僅回傳文字
+	var imageUploader command.ImageUploader
+	switch {
+	case initResult.imageStore != nil:
+		imageUploader = initResult.imageStore
+		imageHandler := imagestore.NewImageHandler(initResult.imageStore, initResult.log)
+		imagestore.RegisterRoutes(router, imageHandler, imagestoreInfra.RoutePath(*initResult.cfg))
+	case initResult.imgbbClient != nil:
+		imageUploader = initResult.imgbbClient
+	}
+
+	// 建立 LINE Bot 服務層
+	lineRenderer := lineService.NewLineRenderer(initResult.lineBotClient, imageUploader, initResult.log)
+	service := lineService.NewBotService(initResult.lineBotClient, commandRegistry, lineRenderer, initResult.userService, initResult.log)
 	handler := linebot.NewLineBotHandler(service, initResult.lineBotClient, initResult.log)
 	linebot.RegisterRoutes(router, handler, initResult.cfg.LINE_BOT_WEBHOOK_PATH)
 
 	// 建立 Telegram Bot 服務層
-	tgSvc := tgService.NewTgService(initResult.stockService, userSubscriptionService, initResult.log)
-	tgCommandHandler := tgService.NewTgCommandHandler(
-		initResult.tgBotClient,
-		tgSvc,
-		initResult.userService,
-		userSubscriptionService,
-		initResult.log,
-	)
-	tgServiceHandler := tgService.NewTgServiceHandler(tgCommandHandler, initResult.userService, initResult.log)
+	// 超過 Telegram 長度限制的按鈕指令存放於資料庫，排程推播的按鈕亦由此解碼
+	// Bot 及排程器以資料庫共用 Telegram 發送限流，合計不超過 Telegram 的發送限制
+	initResult.tgBotClient.SetLimitStore(repository.NewRateLimitRepository(db.GetDB()))
+	tgCallbackCodec := tgService.NewCallbackCodec(initResult.callbackPayloadRepo, initResult.log)
+	tgRenderer := tgService.NewTgRenderer(initResult.tgBotClient, tgCallbackCodec, initResult.log)
+	tgInlineHandler := tgService.NewTgInlineQueryHandler(initResult.tgBotClient, commandRegistry, symbolSearchService, imageUploader, tradingCalendarService, initResult.log)
+	tgServiceHandler := tgService.NewTgServiceHandler(initResult.tgBotClient, commandRegistry, tgRenderer, tgInlineHandler, initResult.userService, initResult.cfg.TELEGRAM_ADMIN_CHAT_ID, initResult.log)
 	tgHandler := tgbot.NewTgHandler(initResult.cfg, tgServiceHandler, initResult.log)
 	tgbot.RegisterRoutes(router, tgHandler, initResult.cfg.TELEGRAM_BOT_WEBHOOK_PATH)
 
+	// 建立 Discord Bot 服務層（條件性）
+	if initResult.discordBotClient != nil {
+		discordRenderer := discordService.NewDiscordRenderer(initResult.discordBotClient)
+		discordServiceHandler := discordService.NewDiscordServiceHandler(initResult.discordBotClient, commandRegistry, discordRenderer, initResult.userService, initResult.log)
+		discordHandler := discordbot.NewDiscordHandler(initResult.discordBotClient, discordServiceHandler, initResult.log)
+		discordbot.RegisterRoutes(router, discordHandler, pathOrDefault(initResult.cfg.DISCORD_BOT_WEBHOOK_PATH, "/discord/interactions"))
+
+		// 註冊斜線指令不影響伺服器啟動，失敗時僅記錄
+		go func() {
+			if err := discordServiceHandler.RegisterCommands(); err != nil {
+				initResult.log.Error("註冊 Discord 斜線指令失敗", zap.Error(err))
+			}
+		}()
+	}
+
+	// 建立 Slack Bot 服務層（條件性）
+	if initResult.slackBotClient != nil {
+		slackRenderer := slackService.NewSlackRenderer(initResult.slackBotClient, initResult.log)
+		slackServiceHandler := slackService.NewSlackServiceHandler(initResult.slackBotClient, commandRegistry, slackRenderer, initResult.userService, initResult.log)
+		slackHandler := slackbot.NewSlackHandler(initResult.slackBotClient, slackServiceHandler, initResult.log)
+		slackbot.RegisterRoutes(router, slackHandler,
+			pathOrDefault(initResult.cfg.SLACK_COMMANDS_PATH, "/slack/commands"),
+			pathOrDefault(initResult.cfg.SLACK_EVENTS_PATH, "/slack/events"),
+			pathOrDefault(initResult.cfg.SLACK_INTERACTIONS_PATH, "/slack/interactions"),
+		)
+	}
+
 	// 從環境變數讀取埠號，預設 8080
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -172,6 +248,14 @@ func main() {
 	}
 }
 
+// pathOrDefault 未設定路由路徑時使用預設值
+func pathOrDefault(path, defaultPath string) string {
+	if path == "" {
+		return defaultPath
+	}
+	return path
+}
+
 // 非同步初始化函數
 func asyncInit(log logger.Logger) (*InitResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -195,7 +279,7 @@ func asyncInit(log logger.Logger) (*InitResult, error) {
 	log.Info("資料庫初始化成功")
 
 	// 並行初始化 Repository
-	wg.Add(3)
+	wg.Add(9)
 	go func() {
 		defer wg.Done()
 		result.userRepo = repository.NewUserRepository(db.GetDB())
@@ -214,6 +298,42 @@ func asyncInit(log logger.Logger) (*InitResult, error) {
 		log.Info("UserSubscriptionRepository 初始化完成")
 	}()
 
+	go func() {
+		defer wg.Done()
+		result.exchangeRateAlertRepo = repository.NewExchangeRateAlertRepository(db.GetDB())
+		log.Info("ExchangeRateAlertRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.userPreferenceRepo = repository.NewUserPreferenceRepository(db.GetDB())
+		log.Info("UserPreferenceRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.newsSeenRepo = repository.NewNewsSeenRepository(db.GetDB())
+		log.Info("NewsSeenRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.newsAlertRepo = repository.NewNewsAlertRepository(db.GetDB())
+		log.Info("NewsAlertRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.jobRunRepo = repository.NewJobRunRepository(db.GetDB())
+		log.Info("JobRunRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.callbackPayloadRepo = repository.NewCallbackPayloadRepository(db.GetDB())
+		log.Info("CallbackPayloadRepository 初始化完成")
+	}()
+
 	// 並行初始化外部 API 客戶端
 	wg.Add(4)
 	go func() {
@@ -240,16 +360,45 @@ func asyncInit(log logger.Logger) (*InitResult, error) {
 		log.Info("CnyesAPI 初始化完成")
 	}()
 
-	// 初始化 ImgBB 客戶端（條件性）
+	// 初始化圖片上傳服務（條件性）
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		result.imageStore, result.imgbbClient = newImageBackend(*cfg, log)
+	}()
+
+	// 初始化 Discord Bot 客戶端（條件性）
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if cfg.IMGBB_API_KEY != "" {
-			result.imgbbClient = imgbb.NewImgBBClient(cfg.IMGBB_API_KEY)
-			log.Info("ImgBB 客戶端初始化成功")
-		} else {
-			log.Warn("IMGBB_API_KEY 未設定，圖片上傳功能將不可用")
+		if cfg.DISCORD_APPLICATION_ID == "" {
+			log.Warn("DISCORD_APPLICATION_ID 未設定，Discord Bot 功能將不可用")
+			return
 		}
+		botClient, err := discordInfra.NewBot(*cfg, log)
+		if err != nil {
+			result.err = fmt.Errorf("初始化 Discord Bot 失敗: %v", err)
+			return
+		}
+		result.discordBotClient = botClient
+		log.Info("Discord Bot 客戶端初始化完成")
+	}()
+
+	// 初始化 Slack Bot 客戶端（條件性）
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if cfg.SLACK_BOT_TOKEN == "" {
+			log.Warn("SLACK_BOT_TOKEN 未設定，Slack Bot 功能將不可用")
+			return
+		}
+		botClient, err := slackInfra.NewBot(*cfg, log)
+		if err != nil {
+			result.err = fmt.Errorf("初始化 Slack Bot 失敗: %v", err)
+			return
+		}
+		result.slackBotClient = botClient
+		log.Info("Slack Bot 客戶端初始化完成")
 	}()
 
 	// 並行初始化 Bot 客戶端
@@ -309,3 +458,22 @@ func asyncInit(log logger.Logger) (*InitResult, error) {
 	log.Info("所有初始化完成")
 	return result, nil
 }
+
+// newImageBackend 依 IMAGE_STORE_BACKEND 建立圖片服務，預設使用自架圖片服務，無法建立時改用 ImgBB
+func newImageBackend(cfg config.Config, log logger.Logger) (*imagestoreInfra.ImageStore, *imgbb.ImgBBClient) {
+	if cfg.IMAGE_STORE_BACKEND != "imgbb" {
+		store, err := imagestoreInfra.NewImageStore(cfg, log)
+		if err == nil {
+			log.Info("自架圖片服務初始化成功")
+			return store, nil
+		}
+		log.Warn("自架圖片服務初始化失敗，改用 ImgBB", zap.Error(err))
+	}
+
+	if cfg.IMGBB_API_KEY == "" {
+		log.Warn("IMGBB_API_KEY 未設定，圖片上傳功能將不可用")
+		return nil, nil
+	}
+	log.Info("ImgBB 客戶端初始化成功")
+	return nil, imgbb.NewImgBBClient(cfg.IMGBB_API_KEY)
+}
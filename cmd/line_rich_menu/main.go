@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tian841224/stock-bot/config"
+	"github.com/tian841224/stock-bot/internal/infrastructure/linebot"
+	lineService "github.com/tian841224/stock-bot/internal/service/bot/line"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// 建立 LINE 預設圖文選單，重複執行會以新選單取代同名的舊選單
+// 使用方式：go run ./cmd/line_rich_menu [-image menu.png]
+func main() {
+	imagePath := flag.String("image", "", "自訂選單圖片路徑（2500x1686 PNG/JPEG，1MB 以內），未指定時自動產生")
+	flag.Parse()
+
+	// 初始化日誌
+	log, err := logger.NewLogger()
+	if err != nil {
+		panic(fmt.Sprintf("初始化日誌失敗: %v", err))
+	}
+	defer log.Sync()
+
+	// 載入設定
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Panic("載入設定失敗", zap.Error(err))
+	}
+
+	lineBot, err := linebot.NewBot(*cfg, log)
+	if err != nil {
+		log.Panic("初始化 LINE Bot 失敗", zap.Error(err))
+	}
+
+	// 未指定圖片時產生與選單格子對應的圖片
+	if *imagePath == "" {
+		image, err := lineService.RenderRichMenuImage()
+		if err != nil {
+			log.Panic("產生選單圖片失敗", zap.Error(err))
+		}
+
+		file, err := os.CreateTemp("", "rich_menu_*.png")
+		if err != nil {
+			log.Panic("建立暫存檔失敗", zap.Error(err))
+		}
+		defer os.Remove(file.Name())
+
+		if _, err := file.Write(image); err != nil {
+			file.Close()
+			log.Panic("寫入暫存檔失敗", zap.Error(err))
+		}
+		file.Close()
+		*imagePath = file.Name()
+	}
+
+	richMenuID, err := lineBot.ProvisionRichMenu(lineService.BuildRichMenu(), *imagePath)
+	if err != nil {
+		log.Panic("建立圖文選單失敗", zap.Error(err))
+	}
+	log.Info("圖文選單建立完成", zap.String("rich_menu_id", richMenuID))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/tian841224/stock-bot/config"
+	"github.com/tian841224/stock-bot/internal/service/job_run"
+	"github.com/tian841224/stock-bot/internal/service/notification"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// catchUpGrace 解析補執行的時間範圍，0 表示不補執行，未設定或格式錯誤時使用預設值
+func catchUpGrace(value string, log logger.Logger) time.Duration {
+	if value == "" {
+		return job_run.DefaultCatchUpGrace
+	}
+	grace, err := time.ParseDuration(value)
+	if err != nil || grace < 0 {
+		log.Warn("SCHEDULER_CATCHUP_GRACE 格式錯誤，使用預設值", zap.String("value", value), zap.Duration("default", job_run.DefaultCatchUpGrace))
+		return job_run.DefaultCatchUpGrace
+	}
+	return grace
+}
+
+// schedulerJobs 排程器的所有排程工作
+func schedulerJobs(cfg *config.Config, schedulerJobService notification.SchedulerJobService, subscriptionScheduler notification.SubscriptionScheduler, jobRegistry job_run.JobRegistry) []*job_run.Job {
+	// 匯率警示排程（預設每天 16:30，周一至周五，牌告匯率收盤後）
+	fxCronSpec := cfg.SCHEDULER_FX_SPEC
+	if fxCronSpec == "" {
+		fxCronSpec = "0 30 16 * * 1-5"
+	}
+	// 新聞關鍵字警示排程（預設每 10 分鐘），新聞不限交易日
+	newsCronSpec := cfg.SCHEDULER_NEWS_SPEC
+	if newsCronSpec == "" {
+		newsCronSpec = "0 */10 * * * *"
+	}
+
+	jobs := []*job_run.Job{
+		{
+			// 每分鐘同步訂閱排程，使用者變更推播時間後不需重啟
+			Name:  "subscription_sync",
+			Spec:  "0 * * * * *",
+			Local: true,
+			Run: func(context.Context) (int, error) {
+				subscriptionScheduler.Sync()
+				return 0, nil
+			},
+		},
+		{
+			// 檢查 Bot 指令建立的手動執行請求
+			Name:  "job_trigger",
+			Spec:  "*/15 * * * * *",
+			Local: true,
+			Run: func(context.Context) (int, error) {
+				jobRegistry.RunTriggered()
+				return 0, nil
+			},
+		},
+		{
+			// 休市日銀行牌告匯率不更新，略過檢查
+			Name:           job_run.JobExchangeRate,
+			Spec:           fxCronSpec,
+			Timeout:        5 * time.Minute,
+			TradingDayOnly: true,
+			Run:            schedulerJobService.NotificationExchangeRateAlerts,
+		},
+		{
+			Name:    job_run.JobNewsAlert,
+			Spec:    newsCronSpec,
+			Timeout: 5 * time.Minute,
+			Run:     schedulerJobService.NotificationNewsAlerts,
+		},
+	}
+
+	// 訂閱項目只供手動執行，推播給該項目的所有訂閱者，依推播時間的排程由 subscriptionScheduler 註冊
+	for _, item := range slices.Sorted(maps.Keys(job_run.SubscriptionJobs)) {
+		jobs = append(jobs, &job_run.Job{
+			Name:        job_run.SubscriptionJobs[item],
+			Timeout:     10 * time.Minute,
+			Concurrency: 1,
+			Run: func(ctx context.Context) (int, error) {
+				return schedulerJobService.NotifyItemSubscribers(ctx, item)
+			},
+		})
+	}
+	return jobs
+}
@@ -12,15 +12,31 @@ import (
 	"github.com/robfig/cron/v3"
 	"github.com/tian841224/stock-bot/config"
 	"github.com/tian841224/stock-bot/internal/db"
+	"github.com/tian841224/stock-bot/internal/db/models"
 	cnyesInfra "github.com/tian841224/stock-bot/internal/infrastructure/cnyes"
+	discordInfra "github.com/tian841224/stock-bot/internal/infrastructure/discordbot"
 	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade"
 	fugleInfra "github.com/tian841224/stock-bot/internal/infrastructure/fugle"
+	imagestoreInfra "github.com/tian841224/stock-bot/internal/infrastructure/imagestore"
+	"github.com/tian841224/stock-bot/internal/infrastructure/imgbb"
+	lineInfra "github.com/tian841224/stock-bot/internal/infrastructure/linebot"
+	slackInfra "github.com/tian841224/stock-bot/internal/infrastructure/slackbot"
 	tgbotInfra "github.com/tian841224/stock-bot/internal/infrastructure/tgbot"
 	twseInfra "github.com/tian841224/stock-bot/internal/infrastructure/twse"
 	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	discordService "github.com/tian841224/stock-bot/internal/service/bot/discord"
+	lineService "github.com/tian841224/stock-bot/internal/service/bot/line"
+	slackService "github.com/tian841224/stock-bot/internal/service/bot/slack"
 	tgService "github.com/tian841224/stock-bot/internal/service/bot/tg"
+	"github.com/tian841224/stock-bot/internal/service/exchange_rate_alert"
+	"github.com/tian841224/stock-bot/internal/service/job_run"
 	"github.com/tian841224/stock-bot/internal/service/notification"
+	"github.com/tian841224/stock-bot/internal/service/stock_news"
+	"github.com/tian841224/stock-bot/internal/service/symbol_search"
+	"github.com/tian841224/stock-bot/internal/service/trading_calendar"
 	twstockService "github.com/tian841224/stock-bot/internal/service/twstock"
+	"github.com/tian841224/stock-bot/internal/service/user_preference"
 	"github.com/tian841224/stock-bot/internal/service/user_subscription"
 	"github.com/tian841224/stock-bot/pkg/logger"
 
@@ -36,12 +52,27 @@ type InitResult struct {
 	subscriptionRepo       repository.SubscriptionRepository
 	userSubscriptionRepo   repository.UserSubscriptionRepository
 	subscriptionSymbolRepo repository.SubscriptionSymbolRepository
+	exchangeRateAlertRepo  repository.ExchangeRateAlertRepository
+	featureRepo            repository.FeatureRepository
+	eventRepo              repository.NotificationEventRepository
+	deliveryRepo           repository.NotificationDeliveryRepository
+	imageRepo              repository.NotificationImageRepository
+	userPreferenceRepo     repository.UserPreferenceRepository
+	newsSeenRepo           repository.NewsSeenRepository
+	newsAlertRepo          repository.NewsAlertRepository
+	callbackPayloadRepo    repository.CallbackPayloadRepository
+	jobRunRepo             repository.JobRunRepository
 	fugleAPI               *fugleInfra.FugleAPI
 	finmindClient          *finmindtrade.FinmindTradeAPI
 	twseAPI                *twseInfra.TwseAPI
 	cnyesAPI               *cnyesInfra.CnyesAPI
 	stockService           twstockService.StockService
 	tgBotClient            *tgbotInfra.TgBotClient
+	lineBotClient          *lineInfra.LineBotClient
+	imageStore             *imagestoreInfra.ImageStore
+	imgbbClient            *imgbb.ImgBBClient
+	discordBotClient       *discordInfra.DiscordBotClient
+	slackBotClient         *slackInfra.SlackBotClient
 	err                    error
 }
 
@@ -61,10 +92,65 @@ func main() {
 
 	// 建立使用者訂閱服務
 	userSubscriptionService := user_subscription.NewUserSubscriptionService(initResult.userSubscriptionRepo)
-	// 建立 Telegram Bot 服務層
-	tgSvc := tgService.NewTgService(initResult.stockService, userSubscriptionService, initResult.log)
+	// 建立使用者偏好設定服務
+	userPreferenceService := user_preference.NewUserPreferenceService(initResult.userPreferenceRepo)
+	// 建立交易日曆服務
+	tradingCalendarService := trading_calendar.NewTradingCalendarService(initResult.finmindClient, initResult.twseAPI, initResult.log)
+	// 建立匯率警示服務
+	exchangeRateAlertService := exchange_rate_alert.NewExchangeRateAlertService(initResult.exchangeRateAlertRepo, initResult.stockService, initResult.log)
+	// 建立股票新聞服務
+	stockNewsService := stock_news.NewStockNewsService(initResult.newsSeenRepo, initResult.newsAlertRepo, initResult.stockService, initResult.log)
+	// 建立排程執行服務，多個排程器同時運作時以執行紀錄確保同一排程只執行一次
+	jobRunService := job_run.NewJobRunService(initResult.jobRunRepo, initResult.log)
+	// 建立股票搜尋服務
+	symbolSearchService := symbol_search.NewSymbolSearchService(initResult.symbolsRepo, initResult.log)
+	// 建立共用指令註冊表，推播內容與 Bot 指令一致
+	commandRegistry := command.NewCommandRegistry(
+		initResult.stockService,
+		userSubscriptionService,
+		userPreferenceService,
+		exchangeRateAlertService,
+		stockNewsService,
+		jobRunService,
+		symbolSearchService,
+		tradingCalendarService,
+		initResult.log,
+	)
+	// 依使用者平台推播，未設定的平台不推播
+	// Telegram 群組與個人共用同一個 Bot，AccountID 皆為 chat ID
+	// Bot 及排程器以資料庫共用 Telegram 發送限流，合計不超過 Telegram 的發送限制
+	initResult.tgBotClient.SetLimitStore(repository.NewRateLimitRepository(db.GetDB()))
+	tgCallbackCodec := tgService.NewCallbackCodec(initResult.callbackPayloadRepo, initResult.log)
+	tgNotifier := notification.NewTgNotifier(tgService.NewTgRenderer(initResult.tgBotClient, tgCallbackCodec, initResult.log))
+	notifiers := notification.Notifiers{
+		models.UserTypeTelegram:      tgNotifier,
+		models.UserTypeTelegramGroup: tgNotifier,
+	}
+	if initResult.lineBotClient != nil {
+		// LINE 圖片需公開網址，未設定圖片上傳服務時僅推播文字
+		var imageUploader command.ImageUploader
+		switch {
+		case initResult.imageStore != nil:
+			imageUploader = initResult.imageStore
+		case initResult.imgbbClient != nil:
+			imageUploader = initResult.imgbbClient
+		}
+		lineRenderer := lineService.NewLineRenderer(initResult.lineBotClient, imageUploader, initResult.log)
+		notifiers[models.UserTypeLine] = notification.NewLineNotifier(lineRenderer)
+	}
+	if initResult.discordBotClient != nil {
+		notifiers[models.UserTypeDiscord] = notification.NewDiscordNotifier(discordService.NewDiscordRenderer(initResult.discordBotClient))
+	}
+	if initResult.slackBotClient != nil {
+		notifiers[models.UserTypeSlack] = notification.NewSlackNotifier(slackService.NewSlackRenderer(initResult.slackBotClient, initResult.log))
+	}
+	// 建立通知佇列，通知寫入資料庫後由背景工作發送並記錄結果，勿擾時段內的通知延後發送
+	outbox := notification.NewOutbox(notifiers, initResult.userRepo, initResult.featureRepo, initResult.eventRepo, initResult.deliveryRepo, initResult.imageRepo, userPreferenceService, initResult.log)
+	outboxCtx, stopOutbox := context.WithCancel(context.Background())
+	defer stopOutbox()
+	outbox.Start(outboxCtx)
 	// 建立排程通知服務
-	schedulerJobService := notification.NewSchedulerJobService(tgSvc, initResult.tgBotClient, initResult.userRepo, initResult.subscriptionRepo, initResult.subscriptionSymbolRepo, initResult.log)
+	schedulerJobService := notification.NewSchedulerJobService(commandRegistry, outbox, userPreferenceService, initResult.subscriptionRepo, initResult.subscriptionSymbolRepo, exchangeRateAlertService, stockNewsService, initResult.log)
 
 	// 從設定檔載入時區（預設 Asia/Taipei）
 	timezone := initResult.cfg.SCHEDULER_TIMEZONE
@@ -84,37 +170,40 @@ func main() {
 		cron.WithSeconds(), // 如果需要秒級，可開啟；否則移除這行
 	)
 
-	// 從設定檔載入排程規格（預設每天 15 點，周一至周五）
+	// 從設定檔載入預設排程規格（預設每天 15 點，周一至周五）
 	cronSpec := initResult.cfg.SCHEDULER_STOCK_SPEC
 	if cronSpec == "" {
 		cronSpec = "0 0 15 * * 1-5"
 	}
-	_, err = c.AddFunc(cronSpec, func() {
-		go func() {
-			schedulerJobService.NotificationStockPrice()
-		}()
-		go func() {
-			schedulerJobService.NotificationStockNews()
-		}()
-		go func() {
-			schedulerJobService.NotificationDailyMarketInfo()
-		}()
-		go func() {
-			schedulerJobService.NotificationTopVolumeItems()
-		}()
-	})
-	if err != nil {
-		initResult.log.Panic("註冊排程失敗", zap.Error(err))
+	// 排程工作註冊表，訂閱排程亦註冊於此供手動執行
+	jobRegistry := job_run.NewJobRegistry(c, jobRunService, tradingCalendarService, initResult.log)
+
+	// 依各訂閱的推播時間及時區註冊排程，未自訂時使用上述預設排程
+	subscriptionScheduler := notification.NewSubscriptionScheduler(initResult.subscriptionRepo, schedulerJobService, jobRegistry, cronSpec, timezone, initResult.log)
+	subscriptionScheduler.Sync()
+
+	// 註冊排程工作，各排程的規格、逾時及並行數於 schedulerJobs 定義
+	for _, job := range schedulerJobs(initResult.cfg, schedulerJobService, subscriptionScheduler, jobRegistry) {
+		if err := jobRegistry.Register(job); err != nil {
+			initResult.log.Panic("註冊排程失敗", zap.Error(err))
+		}
 	}
 
 	c.Start()
 	initResult.log.Info("排程器啟動完成")
 
+	// 補執行排程器停止期間錯過的排程，已執行過的排程由執行紀錄略過
+	grace := catchUpGrace(initResult.cfg.SCHEDULER_CATCHUP_GRACE, initResult.log)
+	go jobRegistry.CatchUp(grace)
+
 	// 等待終止訊號
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	c.Stop()
+	stopOutbox()
+
 	dbErr := db.Close()
 	if dbErr != nil {
 		initResult.log.Error("資料庫關閉失敗", zap.Error(dbErr))
@@ -147,7 +236,7 @@ func asyncInit(log logger.Logger) (*InitResult, error) {
 	log.Info("資料庫初始化成功")
 
 	// 並行初始化 Repository
-	wg.Add(5)
+	wg.Add(6)
 	go func() {
 		defer wg.Done()
 		result.symbolsRepo = repository.NewSymbolRepository(db.GetDB())
@@ -178,6 +267,67 @@ func asyncInit(log logger.Logger) (*InitResult, error) {
 		log.Info("SubscriptionRepository 初始化完成")
 	}()
 
+	go func() {
+		defer wg.Done()
+		result.exchangeRateAlertRepo = repository.NewExchangeRateAlertRepository(db.GetDB())
+		log.Info("ExchangeRateAlertRepository 初始化完成")
+	}()
+
+	wg.Add(9)
+	go func() {
+		defer wg.Done()
+		result.featureRepo = repository.NewFeatureRepository(db.GetDB())
+		log.Info("FeatureRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.eventRepo = repository.NewNotificationEventRepository(db.GetDB())
+		log.Info("NotificationEventRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.deliveryRepo = repository.NewNotificationDeliveryRepository(db.GetDB())
+		log.Info("NotificationDeliveryRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.imageRepo = repository.NewNotificationImageRepository(db.GetDB())
+		log.Info("NotificationImageRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.userPreferenceRepo = repository.NewUserPreferenceRepository(db.GetDB())
+		log.Info("UserPreferenceRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.newsSeenRepo = repository.NewNewsSeenRepository(db.GetDB())
+		log.Info("NewsSeenRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.newsAlertRepo = repository.NewNewsAlertRepository(db.GetDB())
+		log.Info("NewsAlertRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.jobRunRepo = repository.NewJobRunRepository(db.GetDB())
+		log.Info("JobRunRepository 初始化完成")
+	}()
+
+	go func() {
+		defer wg.Done()
+		result.callbackPayloadRepo = repository.NewCallbackPayloadRepository(db.GetDB())
+		log.Info("CallbackPayloadRepository 初始化完成")
+	}()
+
 	// 並行初始化外部 API 客戶端
 	wg.Add(4)
 	go func() {
@@ -217,6 +367,59 @@ func asyncInit(log logger.Logger) (*InitResult, error) {
 		log.Info("Telegram Bot 客戶端初始化完成")
 	}()
 
+	// 初始化 LINE Bot 客戶端（條件性）
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if cfg.CHANNEL_ACCESS_TOKEN == "" {
+			log.Warn("CHANNEL_ACCESS_TOKEN 未設定，不推播 LINE 通知")
+			return
+		}
+		botClient, err := lineInfra.NewBot(*cfg, log)
+		if err != nil {
+			result.err = fmt.Errorf("初始化 LINE Bot 失敗: %v", err)
+			return
+		}
+		result.lineBotClient = botClient
+		log.Info("LINE Bot 客戶端初始化完成")
+
+		result.imageStore, result.imgbbClient = newImageBackend(*cfg, log)
+	}()
+
+	// 初始化 Discord Bot 客戶端（條件性）
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if cfg.DISCORD_APPLICATION_ID == "" {
+			log.Warn("DISCORD_APPLICATION_ID 未設定，不推播 Discord 通知")
+			return
+		}
+		botClient, err := discordInfra.NewBot(*cfg, log)
+		if err != nil {
+			result.err = fmt.Errorf("初始化 Discord Bot 失敗: %v", err)
+			return
+		}
+		result.discordBotClient = botClient
+		log.Info("Discord Bot 客戶端初始化完成")
+	}()
+
+	// 初始化 Slack Bot 客戶端（條件性）
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if cfg.SLACK_BOT_TOKEN == "" {
+			log.Warn("SLACK_BOT_TOKEN 未設定，不推播 Slack 通知")
+			return
+		}
+		botClient, err := slackInfra.NewBot(*cfg, log)
+		if err != nil {
+			result.err = fmt.Errorf("初始化 Slack Bot 失敗: %v", err)
+			return
+		}
+		result.slackBotClient = botClient
+		log.Info("Slack Bot 客戶端初始化完成")
+	}()
+
 	// 等待所有並行初始化完成
 	done := make(chan struct{})
 	go func() {
@@ -249,3 +452,22 @@ func asyncInit(log logger.Logger) (*InitResult, error) {
 	log.Info("所有初始化完成")
 	return result, nil
 }
+
+// newImageBackend 建立 LINE 通知使用的圖片服務
+// 圖片由 Bot 服務提供下載，自架圖片服務需與 Bot 共用 IMAGE_STORE_DIR 及 IMAGE_SIGNING_SECRET，否則改用 ImgBB
+func newImageBackend(cfg config.Config, log logger.Logger) (*imagestoreInfra.ImageStore, *imgbb.ImgBBClient) {
+	if cfg.IMAGE_STORE_BACKEND != "imgbb" && cfg.IMAGE_STORE_DIR != "" && cfg.IMAGE_SIGNING_SECRET != "" {
+		store, err := imagestoreInfra.NewImageStore(cfg, log)
+		if err == nil {
+			log.Info("自架圖片服務初始化成功")
+			return store, nil
+		}
+		log.Warn("自架圖片服務初始化失敗，改用 ImgBB", zap.Error(err))
+	}
+
+	if cfg.IMGBB_API_KEY == "" {
+		log.Warn("未設定共用圖片目錄及 IMGBB_API_KEY，LINE 通知將不含圖片")
+		return nil, nil
+	}
+	return nil, imgbb.NewImgBBClient(cfg.IMGBB_API_KEY)
+}
@@ -8,6 +8,9 @@ type Config struct {
 	TELEGRAM_ADMIN_CHAT_ID      string `mapstructure:"TELEGRAM_ADMIN_CHAT_ID"`
 	DB_NAME                     string `mapstructure:"DB_NAME"`
 	SCHEDULER_STOCK_SPEC        string `mapstructure:"SCHEDULER_STOCK_SPEC"`
+	SCHEDULER_FX_SPEC           string `mapstructure:"SCHEDULER_FX_SPEC"`
+	SCHEDULER_NEWS_SPEC         string `mapstructure:"SCHEDULER_NEWS_SPEC"`
+	SCHEDULER_CATCHUP_GRACE     string `mapstructure:"SCHEDULER_CATCHUP_GRACE"`
 	CHANNEL_ACCESS_TOKEN        string `mapstructure:"CHANNEL_ACCESS_TOKEN"`
 	CHANNEL_SECRET              string `mapstructure:"CHANNEL_SECRET"`
 	SCHEDULER_TIMEZONE          string `mapstructure:"SCHEDULER_TIMEZONE"`
@@ -19,6 +22,22 @@ type Config struct {
 	FINMIND_TOKEN               string `mapstructure:"FINMIND_TOKEN"`
 	FUGLE_API_KEY               string `mapstructure:"FUGLE_API_KEY"`
 	IMGBB_API_KEY               string `mapstructure:"IMGBB_API_KEY"`
+	DISCORD_APPLICATION_ID      string `mapstructure:"DISCORD_APPLICATION_ID"`
+	DISCORD_PUBLIC_KEY          string `mapstructure:"DISCORD_PUBLIC_KEY"`
+	DISCORD_BOT_TOKEN           string `mapstructure:"DISCORD_BOT_TOKEN"`
+	DISCORD_BOT_WEBHOOK_PATH    string `mapstructure:"DISCORD_BOT_WEBHOOK_PATH"`
+	DISCORD_GUILD_ID            string `mapstructure:"DISCORD_GUILD_ID"`
+	SLACK_BOT_TOKEN             string `mapstructure:"SLACK_BOT_TOKEN"`
+	SLACK_SIGNING_SECRET        string `mapstructure:"SLACK_SIGNING_SECRET"`
+	SLACK_COMMANDS_PATH         string `mapstructure:"SLACK_COMMANDS_PATH"`
+	SLACK_EVENTS_PATH           string `mapstructure:"SLACK_EVENTS_PATH"`
+	SLACK_INTERACTIONS_PATH     string `mapstructure:"SLACK_INTERACTIONS_PATH"`
+	IMAGE_STORE_BACKEND         string `mapstructure:"IMAGE_STORE_BACKEND"`
+	IMAGE_PUBLIC_BASE_URL       string `mapstructure:"IMAGE_PUBLIC_BASE_URL"`
+	IMAGE_SIGNING_SECRET        string `mapstructure:"IMAGE_SIGNING_SECRET"`
+	IMAGE_STORE_DIR             string `mapstructure:"IMAGE_STORE_DIR"`
+	IMAGE_ROUTE_PATH            string `mapstructure:"IMAGE_ROUTE_PATH"`
+	IMAGE_STORE_MAX_MB          int    `mapstructure:"IMAGE_STORE_MAX_MB"`
 	DB_PORT                     int    `mapstructure:"DB_PORT"`
 	DB_LOG_MODE                 bool   `mapstructure:"DB_LOG"`
 }
@@ -6,6 +6,7 @@ require (
 	github.com/flopp/go-findfont v0.1.0
 	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
+	github.com/mozillazg/go-pinyin v0.21.0
 	github.com/spf13/viper v1.20.1
 	golang.org/x/image v0.15.0
 	gorm.io/gorm v1.30.3
@@ -0,0 +1,74 @@
+package discordbot
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	discordInfra "github.com/tian841224/stock-bot/internal/infrastructure/discordbot"
+	"github.com/tian841224/stock-bot/internal/infrastructure/discordbot/dto"
+	discordService "github.com/tian841224/stock-bot/internal/service/bot/discord"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type DiscordHandler struct {
+	botClient             *discordInfra.DiscordBotClient
+	discordServiceHandler discordService.DiscordServiceHandler
+	logger                logger.Logger
+}
+
+func NewDiscordHandler(botClient *discordInfra.DiscordBotClient, discordServiceHandler discordService.DiscordServiceHandler, log logger.Logger) *DiscordHandler {
+	return &DiscordHandler{botClient: botClient, discordServiceHandler: discordServiceHandler, logger: log}
+}
+
+// Webhook 驗證 Ed25519 簽章並回應互動事件
+func (h *DiscordHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	// Discord 要求驗證失敗時回應 401，否則會拒絕設定 Interactions Endpoint
+	signature := c.GetHeader("X-Signature-Ed25519")
+	timestamp := c.GetHeader("X-Signature-Timestamp")
+	if signature == "" || timestamp == "" || !h.botClient.VerifySignature(signature, timestamp, body) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	var interaction dto.Interaction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	switch interaction.Type {
+	case dto.InteractionTypePing:
+		c.JSON(http.StatusOK, dto.InteractionResponse{Type: dto.InteractionResponsePong})
+		return
+	case dto.InteractionTypeApplicationCommand:
+		// 查詢可能超過 Discord 的 3 秒限制，先回應「思考中」再編輯原始回應
+		c.JSON(http.StatusOK, dto.InteractionResponse{Type: dto.InteractionResponseDeferredChannelMessage})
+	case dto.InteractionTypeMessageComponent:
+		c.JSON(http.StatusOK, dto.InteractionResponse{Type: dto.InteractionResponseDeferredUpdateMessage})
+	default:
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	go func(i dto.Interaction) {
+		defer func() {
+			if r := recover(); r != nil {
+				h.logger.Error("處理 Discord 互動發生 panic", zap.Any("recover", r))
+			}
+		}()
+
+		if err := h.discordServiceHandler.HandleInteraction(&i); err != nil {
+			h.logger.Error("處理 Discord 互動失敗", zap.Error(err))
+		}
+	}(interaction)
+}
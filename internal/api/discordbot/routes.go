@@ -0,0 +1,8 @@
+package discordbot
+
+import "github.com/gin-gonic/gin"
+
+// RegisterRoutes 註冊 Discord Interactions Endpoint 的路由
+func RegisterRoutes(r *gin.Engine, handler *DiscordHandler, path string) {
+	r.POST(path, handler.Webhook)
+}
@@ -0,0 +1,48 @@
+package imagestore
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	imagestoreInfra "github.com/tian841224/stock-bot/internal/infrastructure/imagestore"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ImageHandler 提供簽章圖片網址的下載
+type ImageHandler struct {
+	store  *imagestoreInfra.ImageStore
+	logger logger.Logger
+}
+
+func NewImageHandler(store *imagestoreInfra.ImageStore, log logger.Logger) *ImageHandler {
+	return &ImageHandler{store: store, logger: log}
+}
+
+// Get 驗證簽章後回傳圖片，快取時間不超過網址到期時間
+func (h *ImageHandler) Get(c *gin.Context) {
+	image, err := h.store.Get(c.Param("id"), c.Query("signature"))
+	switch {
+	case errors.Is(err, imagestoreInfra.ErrInvalidSignature):
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	case errors.Is(err, imagestoreInfra.ErrExpired):
+		c.AbortWithStatus(http.StatusGone)
+		return
+	case errors.Is(err, imagestoreInfra.ErrNotFound):
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	case err != nil:
+		h.logger.Error("讀取圖片失敗", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	maxAge := int(time.Until(image.ExpiresAt).Seconds())
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", max(maxAge, 0)))
+	c.Data(http.StatusOK, image.ContentType, image.Data)
+}
@@ -0,0 +1,9 @@
+package imagestore
+
+import "github.com/gin-gonic/gin"
+
+// RegisterRoutes 註冊圖片下載路由，LINE 取得圖片時會使用 GET 及 HEAD
+func RegisterRoutes(r *gin.Engine, handler *ImageHandler, path string) {
+	r.GET(path+"/:id", handler.Get)
+	r.HEAD(path+"/:id", handler.Get)
+}
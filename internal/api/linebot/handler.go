@@ -36,17 +36,15 @@ func (h *LineBotHandler) Webhook(c *gin.Context) {
 	}
 
 	for _, event := range events {
-		if event.Type == linebot.EventTypeMessage {
-			switch message := event.Message.(type) {
-			case *linebot.TextMessage:
-				if err := h.service.HandleTextMessage(event, message); err != nil {
-					h.logger.Error("Failed to handle text message", zap.Error(err))
-					// 即使處理失敗，也要回覆 LINE 平台，避免重複發送
-					// 使用 ReplyMessage 回覆錯誤訊息給使用者
-					if replyErr := h.botClient.ReplyMessage(event.ReplyToken, "處理訊息時發生錯誤，請稍後再試"); replyErr != nil {
-						h.logger.Error("Failed to send error reply", zap.Error(replyErr))
-					}
-				}
+		if err := h.handleEvent(event); err != nil {
+			h.logger.Error("Failed to handle event", zap.String("type", string(event.Type)), zap.Error(err))
+			// 即使處理失敗，也要回覆 LINE 平台，避免重複發送
+			// 使用 ReplyMessage 回覆錯誤訊息給使用者，封鎖事件沒有 reply token
+			if event.ReplyToken == "" {
+				continue
+			}
+			if replyErr := h.botClient.ReplyMessage(event.ReplyToken, "處理訊息時發生錯誤，請稍後再試"); replyErr != nil {
+				h.logger.Error("Failed to send error reply", zap.Error(replyErr))
 			}
 		}
 	}
@@ -54,3 +52,22 @@ func (h *LineBotHandler) Webhook(c *gin.Context) {
 	// 確保回傳 200 狀態碼，告訴 LINE 平台事件已成功處理
 	c.JSON(200, gin.H{"status": "success"})
 }
+
+// handleEvent 依事件類型分派處理
+func (h *LineBotHandler) handleEvent(event *linebot.Event) error {
+	switch event.Type {
+	case linebot.EventTypeMessage:
+		if message, ok := event.Message.(*linebot.TextMessage); ok {
+			return h.service.HandleTextMessage(event, message)
+		}
+	case linebot.EventTypePostback:
+		return h.service.HandlePostback(event)
+	case linebot.EventTypeFollow:
+		return h.service.HandleFollow(event)
+	case linebot.EventTypeUnfollow:
+		return h.service.HandleUnfollow(event)
+	case linebot.EventTypeJoin:
+		return h.service.HandleJoin(event)
+	}
+	return nil
+}
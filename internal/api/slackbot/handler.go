@@ -0,0 +1,154 @@
+package slackbot
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	slackInfra "github.com/tian841224/stock-bot/internal/infrastructure/slackbot"
+	"github.com/tian841224/stock-bot/internal/infrastructure/slackbot/dto"
+	slackService "github.com/tian841224/stock-bot/internal/service/bot/slack"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SlackHandler struct {
+	botClient           *slackInfra.SlackBotClient
+	slackServiceHandler slackService.SlackServiceHandler
+	logger              logger.Logger
+}
+
+func NewSlackHandler(botClient *slackInfra.SlackBotClient, slackServiceHandler slackService.SlackServiceHandler, log logger.Logger) *SlackHandler {
+	return &SlackHandler{botClient: botClient, slackServiceHandler: slackServiceHandler, logger: log}
+}
+
+// Commands 處理斜線指令，先回應 200 再於背景執行，避免超過 Slack 的 3 秒限制
+func (h *SlackHandler) Commands(c *gin.Context) {
+	body, ok := h.verifiedBody(c)
+	if !ok {
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	slashCommand := dto.SlashCommand{
+		Command:     form.Get("command"),
+		Text:        form.Get("text"),
+		TeamID:      form.Get("team_id"),
+		ChannelID:   form.Get("channel_id"),
+		UserID:      form.Get("user_id"),
+		ResponseURL: form.Get("response_url"),
+	}
+
+	// in_channel 讓頻道成員看到觸發的指令
+	c.JSON(http.StatusOK, gin.H{"response_type": "in_channel"})
+
+	h.handleAsync("斜線指令", func() error {
+		return h.slackServiceHandler.HandleSlashCommand(&slashCommand)
+	})
+}
+
+// Events 處理 Events API 請求
+func (h *SlackHandler) Events(c *gin.Context) {
+	body, ok := h.verifiedBody(c)
+	if !ok {
+		return
+	}
+
+	var envelope dto.EventEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	// 設定 Request URL 時的驗證
+	if envelope.Type == dto.EnvelopeTypeURLVerification {
+		c.JSON(http.StatusOK, gin.H{"challenge": envelope.Challenge})
+		return
+	}
+
+	c.Status(http.StatusOK)
+
+	// Slack 未在 3 秒內收到回應時會重送，已於背景處理的事件不再重複執行
+	if c.GetHeader("X-Slack-Retry-Num") != "" {
+		return
+	}
+	if envelope.Type != dto.EnvelopeTypeEventCallback || envelope.Event == nil {
+		return
+	}
+
+	event := *envelope.Event
+	h.handleAsync("事件", func() error {
+		return h.slackServiceHandler.HandleEvent(&event)
+	})
+}
+
+// Interactions 處理 Block Kit 按鈕互動
+func (h *SlackHandler) Interactions(c *gin.Context) {
+	body, ok := h.verifiedBody(c)
+	if !ok {
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	var payload dto.InteractionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	c.Status(http.StatusOK)
+
+	if payload.Type != dto.InteractionTypeBlockActions {
+		return
+	}
+
+	h.handleAsync("按鈕互動", func() error {
+		return h.slackServiceHandler.HandleBlockActions(&payload)
+	})
+}
+
+// verifiedBody 讀取 body 並驗證 X-Slack-Signature，驗證失敗時回應 401
+func (h *SlackHandler) verifiedBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return nil, false
+	}
+
+	signature := c.GetHeader("X-Slack-Signature")
+	timestamp := c.GetHeader("X-Slack-Request-Timestamp")
+	if signature == "" || timestamp == "" || !h.botClient.VerifySignature(signature, timestamp, body, time.Now()) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return nil, false
+	}
+	return body, true
+}
+
+// handleAsync 於背景處理請求
+func (h *SlackHandler) handleAsync(kind string, handle func() error) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				h.logger.Error("處理 Slack "+kind+"發生 panic", zap.Any("recover", r))
+			}
+		}()
+
+		if err := handle(); err != nil {
+			h.logger.Error("處理 Slack "+kind+"失敗", zap.Error(err))
+		}
+	}()
+}
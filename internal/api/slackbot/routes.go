@@ -0,0 +1,10 @@
+package slackbot
+
+import "github.com/gin-gonic/gin"
+
+// RegisterRoutes 註冊 Slack 斜線指令、Events API 及互動的路由
+func RegisterRoutes(r *gin.Engine, handler *SlackHandler, commandsPath, eventsPath, interactionsPath string) {
+	r.POST(commandsPath, handler.Commands)
+	r.POST(eventsPath, handler.Events)
+	r.POST(interactionsPath, handler.Interactions)
+}
@@ -0,0 +1,18 @@
+package models
+
+// 按鈕回呼資料模型，指令超過 Telegram callback_data 長度限制時以短代碼存放
+type CallbackPayload struct {
+	Model
+	// 代碼，寫入 callback_data
+	Token string `gorm:"column:token;type:varchar(32);not null;uniqueIndex" json:"token"`
+	// 按下按鈕後執行的指令
+	Command string `gorm:"column:command;type:text;not null" json:"command"`
+}
+
+func (CallbackPayload) TableName() string {
+	return "callback_payloads"
+}
+
+func init() {
+	RegisterModel(&CallbackPayload{})
+}
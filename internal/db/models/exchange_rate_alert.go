@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// 匯率警示模型
+type ExchangeRateAlert struct {
+	Model
+	// 使用者ID
+	UserID uint `gorm:"column:user_id;type:bigint;index" json:"user_id"`
+	// 幣別 (USD, JPY...)
+	Currency string `gorm:"column:currency;type:varchar(10);not null;index" json:"currency"`
+	// 匯率種類
+	RateType ExchangeRateType `gorm:"column:rate_type;type:varchar(20);not null" json:"rate_type"`
+	// 觸發條件
+	Condition ExchangeRateAlertCondition `gorm:"column:condition;type:varchar(10);not null" json:"condition"`
+	// 門檻值
+	Threshold float64 `gorm:"column:threshold;type:numeric(18,6);not null" json:"threshold"`
+	// 狀態（觸發後停用）
+	Status bool `gorm:"column:status;type:boolean;index" json:"status"`
+	// 觸發時間
+	TriggeredAt *time.Time `gorm:"column:triggered_at;type:timestamptz" json:"triggered_at"`
+	// 關聯資料表
+	User *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+// 匯率種類
+type ExchangeRateType string
+
+const (
+	ExchangeRateTypeCashBuy  ExchangeRateType = "cash_buy"
+	ExchangeRateTypeCashSell ExchangeRateType = "cash_sell"
+	ExchangeRateTypeSpotBuy  ExchangeRateType = "spot_buy"
+	ExchangeRateTypeSpotSell ExchangeRateType = "spot_sell"
+)
+
+// GetName 回傳匯率種類的中文名稱
+func (t ExchangeRateType) GetName() string {
+	switch t {
+	case ExchangeRateTypeCashBuy:
+		return "現金買入"
+	case ExchangeRateTypeCashSell:
+		return "現金賣出"
+	case ExchangeRateTypeSpotBuy:
+		return "即期買入"
+	case ExchangeRateTypeSpotSell:
+		return "即期賣出"
+	default:
+		return string(t)
+	}
+}
+
+// 觸發條件
+type ExchangeRateAlertCondition string
+
+const (
+	ExchangeRateAlertConditionBelow ExchangeRateAlertCondition = "below"
+	ExchangeRateAlertConditionAbove ExchangeRateAlertCondition = "above"
+)
+
+// GetName 回傳觸發條件的中文名稱
+func (c ExchangeRateAlertCondition) GetName() string {
+	switch c {
+	case ExchangeRateAlertConditionBelow:
+		return "低於"
+	case ExchangeRateAlertConditionAbove:
+		return "高於"
+	default:
+		return string(c)
+	}
+}
+
+func (ExchangeRateAlert) TableName() string {
+	return "exchange_rate_alerts"
+}
+
+func init() {
+	RegisterModel(&ExchangeRateAlert{})
+}
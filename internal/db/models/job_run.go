@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// 排程執行紀錄模型，同一排程時間只允許一筆紀錄，多個排程器同時執行時僅一個取得執行權
+type JobRun struct {
+	Model
+	// 排程名稱
+	JobName string `gorm:"column:job_name;type:varchar(128);not null;uniqueIndex:idx_job_runs_name_scheduled" json:"job_name"`
+	// 排程觸發時間，取至分鐘，各排程器相同
+	ScheduledAt time.Time `gorm:"column:scheduled_at;type:timestamptz;not null;uniqueIndex:idx_job_runs_name_scheduled;index" json:"scheduled_at"`
+	// 執行狀態
+	Status JobRunStatus `gorm:"column:status;type:varchar(20);index" json:"status"`
+	// 執行的排程器，主機名稱及程序ID
+	Instance string `gorm:"column:instance;type:varchar(128)" json:"instance"`
+	// 開始時間
+	StartedAt time.Time `gorm:"column:started_at;type:timestamptz" json:"started_at"`
+	// 執行權到期時間，排程器中斷時執行中的紀錄於到期後可由其他排程器接手
+	LeaseUntil *time.Time `gorm:"column:lease_until;type:timestamptz" json:"lease_until"`
+	// 結束時間
+	FinishedAt *time.Time `gorm:"column:finished_at;type:timestamptz" json:"finished_at"`
+	// 處理數量，例如通知的使用者數
+	Count int `gorm:"column:count;type:int;default:0" json:"count"`
+	// 錯誤訊息
+	Error string `gorm:"column:error;type:text" json:"error"`
+	// 手動執行的使用者，排程執行時為空白
+	TriggeredBy string `gorm:"column:triggered_by;type:varchar(128)" json:"triggered_by"`
+}
+
+// 排程執行狀態
+type JobRunStatus string
+
+const (
+	JobRunStatusPending JobRunStatus = "pending" // 手動執行，等待排程器取得
+	JobRunStatusRunning JobRunStatus = "running"
+	JobRunStatusSuccess JobRunStatus = "success"
+	JobRunStatusFailed  JobRunStatus = "failed"
+)
+
+// Interrupted 執行中但執行權已到期，表示排程器於執行期間中斷
+func (r *JobRun) Interrupted(now time.Time) bool {
+	return r.Status == JobRunStatusRunning && r.LeaseUntil != nil && r.LeaseUntil.Before(now)
+}
+
+func (JobRun) TableName() string {
+	return "job_runs"
+}
+
+func init() {
+	RegisterModel(&JobRun{})
+}
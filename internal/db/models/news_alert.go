@@ -0,0 +1,20 @@
+package models
+
+// 新聞關鍵字警示模型，新聞標題包含所有關鍵字時通知
+type NewsAlert struct {
+	Model
+	// 使用者ID
+	UserID uint `gorm:"column:user_id;type:bigint;index" json:"user_id"`
+	// 關鍵字，以空白分隔
+	Keywords string `gorm:"column:keywords;type:varchar(255);not null" json:"keywords"`
+	// 關聯資料表
+	User *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (NewsAlert) TableName() string {
+	return "news_alerts"
+}
+
+func init() {
+	RegisterModel(&NewsAlert{})
+}
@@ -0,0 +1,27 @@
+package models
+
+// NewsScopeKeyword 關鍵字警示使用的推播範圍，同一則新聞符合多個關鍵字時只推播一次
+const NewsScopeKeyword = "*"
+
+// 已推播新聞模型，避免重複推播同一則新聞
+type NewsSeen struct {
+	Model
+	// 使用者ID
+	UserID uint `gorm:"column:user_id;type:bigint;not null;uniqueIndex:idx_news_seen_user_scope_link" json:"user_id"`
+	// 推播範圍，股票代號或 NewsScopeKeyword
+	Scope string `gorm:"column:scope;type:varchar(20);not null;uniqueIndex:idx_news_seen_user_scope_link" json:"scope"`
+	// 新聞連結
+	Link string `gorm:"column:link;type:varchar(1024);not null;uniqueIndex:idx_news_seen_user_scope_link" json:"link"`
+	// 新聞標題
+	Title string `gorm:"column:title;type:varchar(512)" json:"title"`
+	// 關聯資料表
+	User *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+func (NewsSeen) TableName() string {
+	return "news_seen"
+}
+
+func init() {
+	RegisterModel(&NewsSeen{})
+}
@@ -7,10 +7,16 @@ type NotificationDelivery struct {
 	Model
 	// 事件ID
 	EventID uint `gorm:"column:event_id;type:bigint;index" json:"event_id"`
-	// 管道ID
+	// 管道ID，即使用者平台類型
 	ChannelID uint `gorm:"column:channel_id;type:bigint;index" json:"channel_id"`
 	// 投遞狀態
-	Status NotificationDeliveryStatus `gorm:"column:status;type:varchar(255)" json:"status"`
+	Status NotificationDeliveryStatus `gorm:"column:status;type:varchar(255);index" json:"status"`
+	// 已嘗試發送次數
+	Attempts int `gorm:"column:attempts;type:int;default:0" json:"attempts"`
+	// 已送出的訊息則數，通知分為多則發送時重試由此繼續，避免重複發送
+	SentParts int `gorm:"column:sent_parts;type:int;not null;default:0" json:"sent_parts"`
+	// 下次發送時間，失敗後依退避時間延後
+	NextAttemptAt time.Time `gorm:"column:next_attempt_at;type:timestamptz;index" json:"next_attempt_at"`
 	// 回應
 	Response string `gorm:"column:response;type:jsonb" json:"response"`
 	// 發送時間
@@ -23,9 +29,11 @@ type NotificationDelivery struct {
 type NotificationDeliveryStatus string
 
 const (
-	NotificationDeliveryStatusQueued NotificationDeliveryStatus = "queued"
-	NotificationDeliveryStatusSent   NotificationDeliveryStatus = "sent"
-	NotificationDeliveryStatusFailed NotificationDeliveryStatus = "failed"
+	NotificationDeliveryStatusQueued  NotificationDeliveryStatus = "queued"
+	NotificationDeliveryStatusSending NotificationDeliveryStatus = "sending" // 已由發送工作取得，避免重複發送
+	NotificationDeliveryStatusSent    NotificationDeliveryStatus = "sent"
+	NotificationDeliveryStatusFailed  NotificationDeliveryStatus = "failed"
+	NotificationDeliveryStatusUnknown NotificationDeliveryStatus = "unknown" // 發送工作中斷，無法確認是否已送達，不自動重送
 )
 
 func (NotificationDelivery) TableName() string {
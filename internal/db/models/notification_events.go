@@ -7,8 +7,8 @@ type NotificationEvent struct {
 	Model
 	// 使用者ID
 	UserID uint `gorm:"column:user_id;type:bigint;index" json:"user_id"`
-	// 功能ID
-	FeatureID uint `gorm:"column:feature_id;type:bigint;index" json:"feature_id"`
+	// 功能ID，匯率警示等非訂閱項目的通知為空
+	FeatureID *uint `gorm:"column:feature_id;type:bigint;index" json:"feature_id"`
 	// 訂閱ID
 	SubscriptionID *uint `gorm:"column:subscription_id;type:bigint;index" json:"subscription_id"`
 	// 股票ID
@@ -22,6 +22,8 @@ type NotificationEvent struct {
 	Feature      *Feature      `gorm:"foreignKey:FeatureID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"-"`
 	Subscription *Subscription `gorm:"foreignKey:SubscriptionID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
 	Symbol       *Symbol       `gorm:"foreignKey:SymbolID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+	// 投遞紀錄，與事件於同一交易中建立
+	Deliveries []*NotificationDelivery `gorm:"foreignKey:EventID" json:"-"`
 }
 
 func (NotificationEvent) TableName() string {
@@ -0,0 +1,20 @@
+package models
+
+// 通知圖片模型，同一張圖片推播給多位使用者時只存放一份，通知事件以雜湊值參照
+type NotificationImage struct {
+	Model
+	// 圖片內容的 SHA-256 雜湊值
+	Hash string `gorm:"column:hash;type:varchar(64);not null;uniqueIndex" json:"hash"`
+	// 檔名，例如 chart.png
+	Name string `gorm:"column:name;type:varchar(255)" json:"name"`
+	// 圖片內容
+	Data []byte `gorm:"column:data;type:bytea;not null" json:"-"`
+}
+
+func (NotificationImage) TableName() string {
+	return "notification_images"
+}
+
+func init() {
+	RegisterModel(&NotificationImage{})
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// 發送限流令牌桶模型，Bot 及排程器等多個程序共用，合計不超過平台的發送限制
+type RateLimitBucket struct {
+	// 令牌桶名稱，例如 telegram:chat:123
+	Key string `gorm:"column:key;type:varchar(64);primaryKey" json:"key"`
+	// 剩餘令牌數
+	Tokens float64 `gorm:"column:tokens;type:double precision;not null" json:"tokens"`
+	// 最後補充令牌的時間
+	RefilledAt time.Time `gorm:"column:refilled_at;type:timestamptz;not null;index" json:"refilled_at"`
+	// 收到 429 時暫停發送至此時間
+	PausedUntil time.Time `gorm:"column:paused_until;type:timestamptz;not null" json:"paused_until"`
+}
+
+// Wait 取得一個令牌所需等待的時間，rate 為每秒補充的令牌數，burst 為令牌上限
+func (b *RateLimitBucket) Wait(rate, burst float64, now time.Time) time.Duration {
+	tokens := b.Tokens
+	if now.After(b.RefilledAt) {
+		tokens = min(burst, tokens+now.Sub(b.RefilledAt).Seconds()*rate)
+	}
+	var delay time.Duration
+	if tokens < 1 {
+		delay = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	if paused := b.PausedUntil.Sub(now); paused > delay {
+		delay = paused
+	}
+	return delay
+}
+
+func (RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
+
+func init() {
+	RegisterModel(&RateLimitBucket{})
+}
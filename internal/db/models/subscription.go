@@ -9,8 +9,10 @@ type Subscription struct {
 	FeatureID uint `gorm:"column:feature_id;type:bigint;index:idx_subscriptions_user_feature,priority:2" json:"feature_id"`
 	// 狀態
 	Status bool `gorm:"column:status;type:boolean" json:"status"`
-	// 排程
+	// 排程，空白時使用預設排程
 	ScheduleCron string `gorm:"column:schedule_cron;type:varchar(255)" json:"schedule_cron"`
+	// 排程時區，空白時使用預設時區
+	Timezone string `gorm:"column:timezone;type:varchar(64)" json:"timezone"`
 	// 關聯資料表
 	User    *User    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
 	Feature *Feature `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;" json:"-"`
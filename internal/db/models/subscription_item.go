@@ -9,6 +9,7 @@ const (
 	SubscriptionItemStockNews       SubscriptionItem = 2
 	SubscriptionItemDailyMarketInfo SubscriptionItem = 3
 	SubscriptionItemTopVolumeItems  SubscriptionItem = 4
+	SubscriptionItemTreasuryYield   SubscriptionItem = 5
 )
 
 // SubscriptionItemMap mapping table for subscription items
@@ -18,6 +19,7 @@ var SubscriptionItemMap = map[string]SubscriptionItem{
 	"2": SubscriptionItemStockNews,
 	"3": SubscriptionItemDailyMarketInfo,
 	"4": SubscriptionItemTopVolumeItems,
+	"5": SubscriptionItemTreasuryYield,
 }
 
 // GetName returns the name of the subscription item
@@ -33,6 +35,8 @@ func (s SubscriptionItem) GetName() string {
 		return "每日大盤資訊"
 	case SubscriptionItemTopVolumeItems:
 		return "交易量前20名"
+	case SubscriptionItemTreasuryYield:
+		return "美債殖利率"
 	default:
 		return "Default"
 	}
@@ -9,6 +9,8 @@ type Symbol struct {
 	Name string `gorm:"column:name;type:varchar(255)" json:"name"`
 	// 市場
 	Market string `gorm:"column:market;type:varchar(255);not null;index:idx_symbol_market,priority:2" json:"market"`
+	// 產業類別
+	Industry string `gorm:"column:industry;type:varchar(255);index" json:"industry"`
 }
 
 func (Symbol) TableName() string {
@@ -5,8 +5,8 @@ package models
 type User struct {
 	Model
 	AccountID string `gorm:"column:account_id;type:varchar(255);uniqueIndex;not null" json:"account_id"`
-	// 使用者類型 TG or LINE
-	UserType UserType `gorm:"column:user_type;type:SMALLINT;not null;check:user_type IN (1,2)" json:"user_type"`
+	// 使用者類型 TG、LINE、Discord、Slack（以頻道為單位）or TG 群組
+	UserType UserType `gorm:"column:user_type;type:SMALLINT;not null;check:user_type IN (1,2,3,4,5)" json:"user_type"`
 	Status   bool     `gorm:"column:status;type:boolean" json:"status"`
 }
 
@@ -15,6 +15,10 @@ type UserType int
 const (
 	UserTypeTelegram UserType = 1
 	UserTypeLine     UserType = 2
+	UserTypeDiscord  UserType = 3
+	UserTypeSlack    UserType = 4
+	// UserTypeTelegramGroup Telegram 群組，AccountID 為群組 chat ID，訂閱屬於整個群組
+	UserTypeTelegramGroup UserType = 5
 )
 
 func (u *User) GetUserType() UserType {
@@ -0,0 +1,76 @@
+package models
+
+// 使用者偏好設定模型，未建立時使用預設值
+type UserPreference struct {
+	Model
+	// 使用者ID
+	UserID uint `gorm:"column:user_id;type:bigint;uniqueIndex;not null" json:"user_id"`
+	// 勿擾時段開始及結束時間（HH:MM），皆空白表示未設定，結束早於開始時表示跨午夜
+	QuietStart string `gorm:"column:quiet_start;type:varchar(5)" json:"quiet_start"`
+	QuietEnd   string `gorm:"column:quiet_end;type:varchar(5)" json:"quiet_end"`
+	// 勿擾時段時區，空白時使用台北時間
+	Timezone string `gorm:"column:timezone;type:varchar(64)" json:"timezone"`
+	// 偏好語言
+	Language Language `gorm:"column:language;type:varchar(16)" json:"language"`
+	// K線圖主題
+	ChartTheme ChartTheme `gorm:"column:chart_theme;type:varchar(16)" json:"chart_theme"`
+	// 預設K線週期
+	KLineTimeframe KLineTimeframe `gorm:"column:kline_timeframe;type:varchar(8)" json:"kline_timeframe"`
+	// 摘要模式，開啟時排程推播合併為單一訊息
+	DigestMode DigestMode `gorm:"column:digest_mode;type:SMALLINT;not null;default:0" json:"digest_mode"`
+	// 關聯資料表
+	User *User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+// Language 偏好語言
+type Language string
+
+const (
+	LanguageZhTW Language = "zh-TW"
+	LanguageEn   Language = "en"
+)
+
+// ChartTheme 圖表主題
+type ChartTheme string
+
+const (
+	ChartThemeLight ChartTheme = "light"
+	ChartThemeDark  ChartTheme = "dark"
+)
+
+// KLineTimeframe K線週期
+type KLineTimeframe string
+
+const (
+	KLineTimeframeDay   KLineTimeframe = "D"
+	KLineTimeframeWeek  KLineTimeframe = "W"
+	KLineTimeframeMonth KLineTimeframe = "M"
+)
+
+// DigestMode 排程推播方式
+type DigestMode int
+
+const (
+	DigestModeOff   DigestMode = 0 // 各訂閱項目分別推播
+	DigestModeText  DigestMode = 1 // 合併為單一訊息
+	DigestModeChart DigestMode = 2 // 合併為單一訊息並附上自選股圖表拼貼
+)
+
+// DefaultUserPreference 預設偏好設定
+func DefaultUserPreference(userID uint) *UserPreference {
+	return &UserPreference{
+		UserID:         userID,
+		Language:       LanguageZhTW,
+		ChartTheme:     ChartThemeLight,
+		KLineTimeframe: KLineTimeframeDay,
+		DigestMode:     DigestModeOff,
+	}
+}
+
+func (UserPreference) TableName() string {
+	return "user_preferences"
+}
+
+func init() {
+	RegisterModel(&UserPreference{})
+}
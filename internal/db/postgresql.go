@@ -101,9 +101,50 @@ func (d *postgresDatabase) createOrUpdateTable() error {
 		return fmt.Errorf("資料庫遷移失敗: %w", err)
 	}
 
+	if err := d.updateCheckConstraints(); err != nil {
+		return fmt.Errorf("更新資料表限制失敗: %w", err)
+	}
+
+	if err := d.migrateTelegramGroups(); err != nil {
+		return fmt.Errorf("轉換 Telegram 群組失敗: %w", err)
+	}
+
 	return nil
 }
 
+// migrateTelegramGroups 舊版將群組視為 Telegram 使用者，群組 chat ID 為負數，轉為群組類型
+func (d *postgresDatabase) migrateTelegramGroups() error {
+	return d.db.Model(&models.User{}).
+		Where("user_type = ? AND account_id LIKE ?", models.UserTypeTelegram, "-%").
+		Update("user_type", models.UserTypeTelegramGroup).Error
+}
+
+// updateCheckConstraints 重建會隨程式擴充的 check constraint
+// AutoMigrate 只會建立不存在的 constraint，既有的條件（例如新增使用者類型）不會更新
+func (d *postgresDatabase) updateCheckConstraints() error {
+	constraints := []struct {
+		model any
+		name  string
+	}{
+		{&models.User{}, "chk_users_user_type"},
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		for _, c := range constraints {
+			if migrator.HasConstraint(c.model, c.name) {
+				if err := migrator.DropConstraint(c.model, c.name); err != nil {
+					return err
+				}
+			}
+			if err := migrator.CreateConstraint(c.model, c.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (d *postgresDatabase) createDatabaseIfNotExists(cfg *config.Config) error {
 	sqlDB, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=postgres sslmode=disable", cfg.DB_HOST, cfg.DB_PORT, cfg.DB_USER, cfg.DB_PASSWORD))
 	if err != nil {
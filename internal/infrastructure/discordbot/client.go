@@ -0,0 +1,214 @@
+// Package discordbot 提供 Discord Bot 客戶端實作（Interactions Endpoint + REST API）
+package discordbot
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/tian841224/stock-bot/config"
+	"github.com/tian841224/stock-bot/internal/infrastructure/discordbot/dto"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DiscordBaseURL = "https://discord.com/api/v10"
+)
+
+// DiscordBotClient Discord Bot 客戶端
+type DiscordBotClient struct {
+	applicationID string
+	guildID       string
+	botToken      string
+	publicKey     ed25519.PublicKey
+	client        *http.Client
+	logger        logger.Logger
+}
+
+// NewBot 初始化 Discord Bot
+func NewBot(cfg config.Config, log logger.Logger) (*DiscordBotClient, error) {
+	if cfg.DISCORD_APPLICATION_ID == "" || cfg.DISCORD_BOT_TOKEN == "" {
+		return nil, fmt.Errorf("未設定 DISCORD_APPLICATION_ID 或 DISCORD_BOT_TOKEN")
+	}
+
+	publicKey, err := hex.DecodeString(cfg.DISCORD_PUBLIC_KEY)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("DISCORD_PUBLIC_KEY 格式錯誤")
+	}
+
+	return &DiscordBotClient{
+		applicationID: cfg.DISCORD_APPLICATION_ID,
+		guildID:       cfg.DISCORD_GUILD_ID,
+		botToken:      cfg.DISCORD_BOT_TOKEN,
+		publicKey:     ed25519.PublicKey(publicKey),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		logger: log,
+	}, nil
+}
+
+// VerifySignature 驗證 Interactions Endpoint 請求的 Ed25519 簽章
+// 簽章內容為 X-Signature-Timestamp 加上原始 request body
+func (c *DiscordBotClient) VerifySignature(signature, timestamp string, body []byte) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+
+	message := make([]byte, 0, len(timestamp)+len(body))
+	message = append(message, timestamp...)
+	message = append(message, body...)
+	return ed25519.Verify(c.publicKey, message, sig)
+}
+
+// RegisterCommands 以覆寫方式註冊斜線指令，有設定 DISCORD_GUILD_ID 時只註冊於該伺服器（立即生效）
+func (c *DiscordBotClient) RegisterCommands(commands []dto.ApplicationCommand) error {
+	path := fmt.Sprintf("/applications/%s/commands", c.applicationID)
+	if c.guildID != "" {
+		path = fmt.Sprintf("/applications/%s/guilds/%s/commands", c.applicationID, c.guildID)
+	}
+
+	body, err := json.Marshal(commands)
+	if err != nil {
+		return fmt.Errorf("序列化斜線指令失敗: %w", err)
+	}
+
+	if err := c.doRequest(http.MethodPut, path, "application/json", body, true, nil); err != nil {
+		c.logger.Error("註冊斜線指令失敗", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// EditOriginalResponse 編輯互動的原始回應，用於延遲回應後送出實際內容或更新按鈕所在的訊息
+func (c *DiscordBotClient) EditOriginalResponse(interactionToken string, message *dto.Message, files []dto.File) error {
+	path := fmt.Sprintf("/webhooks/%s/%s/messages/@original", c.applicationID, interactionToken)
+	err := c.sendMessage(http.MethodPatch, path, false, message, files)
+	if err != nil {
+		c.logger.Error("編輯互動回應失敗", zap.Error(err))
+	}
+	return err
+}
+
+// CreateFollowupMessage 對互動追加發送新訊息
+func (c *DiscordBotClient) CreateFollowupMessage(interactionToken string, message *dto.Message, files []dto.File) error {
+	path := fmt.Sprintf("/webhooks/%s/%s", c.applicationID, interactionToken)
+	err := c.sendMessage(http.MethodPost, path, false, message, files)
+	if err != nil {
+		c.logger.Error("發送追加訊息失敗", zap.Error(err))
+	}
+	return err
+}
+
+// SendDirectMessage 私訊使用者，供排程推播使用
+func (c *DiscordBotClient) SendDirectMessage(userID string, message *dto.Message, files []dto.File) error {
+	channel, err := c.createDMChannel(userID)
+	if err != nil {
+		c.logger.Error("建立私訊頻道失敗", zap.String("user_id", userID), zap.Error(err))
+		return err
+	}
+	return c.SendChannelMessage(channel.ID, message, files)
+}
+
+// SendChannelMessage 發送訊息至頻道
+func (c *DiscordBotClient) SendChannelMessage(channelID string, message *dto.Message, files []dto.File) error {
+	path := fmt.Sprintf("/channels/%s/messages", channelID)
+	err := c.sendMessage(http.MethodPost, path, true, message, files)
+	if err != nil {
+		c.logger.Error("發送頻道訊息失敗", zap.String("channel_id", channelID), zap.Error(err))
+	}
+	return err
+}
+
+// createDMChannel 建立（或取得既有的）私訊頻道
+func (c *DiscordBotClient) createDMChannel(userID string) (*dto.Channel, error) {
+	body, err := json.Marshal(map[string]string{"recipient_id": userID})
+	if err != nil {
+		return nil, fmt.Errorf("序列化請求失敗: %w", err)
+	}
+
+	var channel dto.Channel
+	if err := c.doRequest(http.MethodPost, "/users/@me/channels", "application/json", body, true, &channel); err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// sendMessage 發送訊息，有附件時以 multipart/form-data 上傳
+func (c *DiscordBotClient) sendMessage(method, path string, withAuth bool, message *dto.Message, files []dto.File) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("序列化訊息失敗: %w", err)
+	}
+
+	if len(files) == 0 {
+		return c.doRequest(method, path, "application/json", payload, withAuth, nil)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	if err := writer.WriteField("payload_json", string(payload)); err != nil {
+		return fmt.Errorf("寫入訊息內容失敗: %w", err)
+	}
+
+	for i, file := range files {
+		fileWriter, err := writer.CreateFormFile(fmt.Sprintf("files[%d]", i), file.Name)
+		if err != nil {
+			return fmt.Errorf("建立檔案欄位失敗: %w", err)
+		}
+		if _, err := fileWriter.Write(file.Data); err != nil {
+			return fmt.Errorf("寫入檔案內容失敗: %w", err)
+		}
+	}
+
+	writer.Close()
+
+	return c.doRequest(method, path, writer.FormDataContentType(), buf.Bytes(), withAuth, nil)
+}
+
+// doRequest 發送請求，result 不為 nil 時解析回應
+// Interaction webhook 以 token 驗證，不需帶 Bot 授權標頭
+func (c *DiscordBotClient) doRequest(method, path, contentType string, body []byte, withAuth bool, result any) error {
+	req, err := http.NewRequest(method, DiscordBaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("建立請求失敗: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	if withAuth {
+		req.Header.Set("Authorization", "Bot "+c.botToken)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("發送請求失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("讀取回應失敗: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Discord API 回應錯誤，狀態碼: %d，內容: %s", resp.StatusCode, string(respBody))
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("解析 JSON 回應失敗: %w", err)
+		}
+	}
+
+	return nil
+}
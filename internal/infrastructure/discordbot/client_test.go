@@ -0,0 +1,33 @@
+package discordbot
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	client := &DiscordBotClient{publicKey: publicKey}
+
+	timestamp := "1700000000"
+	body := []byte(`{"type":1}`)
+	signature := hex.EncodeToString(ed25519.Sign(privateKey, append([]byte(timestamp), body...)))
+
+	if !client.VerifySignature(signature, timestamp, body) {
+		t.Error("VerifySignature() = false, want true for valid signature")
+	}
+	if client.VerifySignature(signature, "1700000001", body) {
+		t.Error("VerifySignature() = true, want false for tampered timestamp")
+	}
+	if client.VerifySignature(signature, timestamp, []byte(`{"type":2}`)) {
+		t.Error("VerifySignature() = true, want false for tampered body")
+	}
+	if client.VerifySignature("not-hex", timestamp, body) {
+		t.Error("VerifySignature() = true, want false for malformed signature")
+	}
+}
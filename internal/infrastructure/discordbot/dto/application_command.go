@@ -0,0 +1,29 @@
+package dto
+
+// ApplicationCommandOptionType 斜線指令參數型別
+type ApplicationCommandOptionType int
+
+const (
+	ApplicationCommandOptionString  ApplicationCommandOptionType = 3
+	ApplicationCommandOptionInteger ApplicationCommandOptionType = 4
+)
+
+// ApplicationCommandTypeChatInput 斜線指令
+const ApplicationCommandTypeChatInput = 1
+
+// ApplicationCommand 斜線指令定義
+type ApplicationCommand struct {
+	Name        string                     `json:"name"`
+	Type        int                        `json:"type"`
+	Description string                     `json:"description"`
+	Options     []ApplicationCommandOption `json:"options,omitempty"`
+}
+
+// ApplicationCommandOption 斜線指令參數定義
+type ApplicationCommandOption struct {
+	Type        ApplicationCommandOptionType `json:"type"`
+	Name        string                       `json:"name"`
+	Description string                       `json:"description"`
+	Required    bool                         `json:"required,omitempty"`
+	MinValue    *int                         `json:"min_value,omitempty"`
+}
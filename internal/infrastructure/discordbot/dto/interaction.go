@@ -0,0 +1,86 @@
+// Package dto 定義 Discord API 的資料結構
+package dto
+
+// InteractionType 互動類型
+type InteractionType int
+
+const (
+	InteractionTypePing               InteractionType = 1
+	InteractionTypeApplicationCommand InteractionType = 2
+	InteractionTypeMessageComponent   InteractionType = 3
+)
+
+// InteractionResponseType 互動回應類型
+type InteractionResponseType int
+
+const (
+	// InteractionResponsePong 回應 Discord 的 PING 驗證
+	InteractionResponsePong InteractionResponseType = 1
+	// InteractionResponseDeferredChannelMessage 先回應「思考中」，之後再編輯原始回應
+	InteractionResponseDeferredChannelMessage InteractionResponseType = 5
+	// InteractionResponseDeferredUpdateMessage 按鈕互動先確認收到，之後再更新原訊息
+	InteractionResponseDeferredUpdateMessage InteractionResponseType = 6
+)
+
+// Interaction Discord 互動事件（Interactions Endpoint 收到的請求）
+type Interaction struct {
+	ID            string           `json:"id"`
+	ApplicationID string           `json:"application_id"`
+	Type          InteractionType  `json:"type"`
+	Data          *InteractionData `json:"data,omitempty"`
+	GuildID       string           `json:"guild_id,omitempty"`
+	ChannelID     string           `json:"channel_id,omitempty"`
+	Member        *Member          `json:"member,omitempty"`
+	User          *User            `json:"user,omitempty"`
+	Token         string           `json:"token"`
+	Message       *Message         `json:"message,omitempty"`
+}
+
+// GetUser 取得觸發互動的使用者，伺服器內為 member.user，私訊為 user
+func (i *Interaction) GetUser() *User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// InteractionData 斜線指令或按鈕的資料
+type InteractionData struct {
+	ID            string              `json:"id,omitempty"`
+	Name          string              `json:"name,omitempty"`
+	Type          int                 `json:"type,omitempty"`
+	Options       []InteractionOption `json:"options,omitempty"`
+	CustomID      string              `json:"custom_id,omitempty"`
+	ComponentType ComponentType       `json:"component_type,omitempty"`
+}
+
+// InteractionOption 斜線指令的參數值
+type InteractionOption struct {
+	Name  string `json:"name"`
+	Type  int    `json:"type"`
+	Value any    `json:"value,omitempty"`
+}
+
+// InteractionResponse 回應互動事件
+type InteractionResponse struct {
+	Type InteractionResponseType `json:"type"`
+	Data *Message                `json:"data,omitempty"`
+}
+
+// Member 伺服器成員
+type Member struct {
+	User *User  `json:"user,omitempty"`
+	Nick string `json:"nick,omitempty"`
+}
+
+// User Discord 使用者
+type User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// Channel 頻道
+type Channel struct {
+	ID   string `json:"id"`
+	Type int    `json:"type"`
+}
@@ -0,0 +1,70 @@
+package dto
+
+// ComponentType 訊息元件類型
+type ComponentType int
+
+const (
+	ComponentTypeActionRow ComponentType = 1
+	ComponentTypeButton    ComponentType = 2
+)
+
+// ButtonStyle 按鈕樣式
+type ButtonStyle int
+
+const (
+	ButtonStylePrimary   ButtonStyle = 1
+	ButtonStyleSecondary ButtonStyle = 2
+	ButtonStyleLink      ButtonStyle = 5
+)
+
+// Message 發送或編輯的訊息內容
+// Embeds、Components、Attachments 不加 omitempty，編輯訊息時傳入空陣列才能清除原有內容
+type Message struct {
+	Content     string       `json:"content,omitempty"`
+	Embeds      []Embed      `json:"embeds"`
+	Components  []Component  `json:"components"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Embed 嵌入內容
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Image       *EmbedImage  `json:"image,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+}
+
+// EmbedImage 嵌入圖片，附件圖片使用 attachment://檔名
+type EmbedImage struct {
+	URL string `json:"url"`
+}
+
+// EmbedFooter 嵌入頁尾
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// Component 訊息元件，ActionRow 內含 Button
+type Component struct {
+	Type       ComponentType `json:"type"`
+	Style      ButtonStyle   `json:"style,omitempty"`
+	Label      string        `json:"label,omitempty"`
+	CustomID   string        `json:"custom_id,omitempty"`
+	URL        string        `json:"url,omitempty"`
+	Disabled   bool          `json:"disabled,omitempty"`
+	Components []Component   `json:"components,omitempty"`
+}
+
+// Attachment 附件描述，ID 對應 multipart 的 files[ID]
+type Attachment struct {
+	ID       int    `json:"id"`
+	Filename string `json:"filename"`
+}
+
+// File 要上傳的附件檔案
+type File struct {
+	Name string
+	Data []byte
+}
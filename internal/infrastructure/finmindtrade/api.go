@@ -25,6 +25,7 @@ type FinmindTradeAPIInterface interface {
 	GetTaiwanStockSplitPrice(requestDto dto.FinmindtradeRequestDto) (dto.TaiwanStockSplitPriceResponseDto, error)
 	GetUSStockInfo() (dto.USStockInfoResponseDto, error)
 	GetUSStockPrice(requestDto dto.FinmindtradeRequestDto) (dto.USStockPriceResponseDto, error)
+	GetUSGovernmentBondsYield(requestDto dto.FinmindtradeRequestDto) (dto.USGovernmentBondsYieldResponseDto, error)
 	GetTodayInfo() (dto.TodayInfoResponseDto, error)
 	GetTaiwanStockAnalysis(requestDto dto.FinmindtradeRequestDto) (dto.TaiwanStockAnalysisResponseDto, error)
 	GetTaiwanStockAnalysisPlot(requestDto dto.FinmindtradeRequestDto) (dto.TaiwanStockAnalysisPlotResponseDto, error)
@@ -124,6 +125,12 @@ func (f *FinmindTradeAPI) GetUSStockPrice(requestDto dto.FinmindtradeRequestDto)
 	return doRequest[dto.USStockPriceResponseDto](f, requestDto)
 }
 
+// GetUSGovernmentBondsYield 美國公債殖利率（data_id 例如 United States 10-Year）
+func (f *FinmindTradeAPI) GetUSGovernmentBondsYield(requestDto dto.FinmindtradeRequestDto) (response dto.USGovernmentBondsYieldResponseDto, err error) {
+	requestDto.DataSet = "GovernmentBondsYield"
+	return doRequest[dto.USGovernmentBondsYieldResponseDto](f, requestDto)
+}
+
 // GetTodayInfo 大盤資訊(法人/資券/美股大盤)
 func (f *FinmindTradeAPI) GetTodayInfo() (response dto.TodayInfoResponseDto, err error) {
 	baseURL := "https://api.web.finmindtrade.com/v2/today_info"
@@ -0,0 +1,347 @@
+// Package imagestore 提供自架圖片存放服務，以簽章網址公開圖表供 LINE 等平台讀取
+package imagestore
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/config"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultRoutePath  = "/images"
+	defaultMaxMB      = 64
+	defaultExpiration = 7 * 24 * time.Hour // 未指定保存時間時的預設值
+	diskSweepInterval = time.Minute        // 磁碟清理間隔
+)
+
+var (
+	ErrNotFound         = errors.New("圖片不存在")
+	ErrExpired          = errors.New("圖片網址已過期")
+	ErrInvalidSignature = errors.New("圖片網址簽章錯誤")
+)
+
+// imageIDPattern 圖片 ID 格式為「到期時間-隨機碼.副檔名」，同時避免讀取磁碟時路徑穿越
+var imageIDPattern = regexp.MustCompile(`^(\d+)-[0-9a-f]{32}\.(png|jpg|gif|webp)$`)
+
+// Image 存放的圖片
+type Image struct {
+	Data        []byte
+	ContentType string
+	ExpiresAt   time.Time
+}
+
+// ImageStore 以 LRU 保存圖片於記憶體，設定目錄時同時寫入磁碟
+// 磁碟目錄可由多個行程共用（例如排程器產生、Bot 提供下載），需使用相同的簽章金鑰
+type ImageStore struct {
+	baseURL  string
+	secret   []byte
+	dir      string
+	maxBytes int64
+	logger   logger.Logger
+	now      func() time.Time
+
+	mu        sync.Mutex
+	items     map[string]*list.Element
+	order     *list.List // 最近使用的在前
+	size      int64
+	lastSweep time.Time
+}
+
+// entry LRU 項目
+type entry struct {
+	id    string
+	image *Image
+}
+
+// NewImageStore 建立圖片存放服務
+// 公開網址未設定時沿用 Telegram webhook 網域；簽章金鑰未設定時每次啟動隨機產生，重啟後舊網址失效
+func NewImageStore(cfg config.Config, log logger.Logger) (*ImageStore, error) {
+	baseURL := cfg.IMAGE_PUBLIC_BASE_URL
+	if baseURL == "" {
+		baseURL = cfg.TELEGRAM_BOT_WEBHOOK_DOMAIN
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("未設定 IMAGE_PUBLIC_BASE_URL")
+	}
+
+	secret := []byte(cfg.IMAGE_SIGNING_SECRET)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("產生簽章金鑰失敗: %w", err)
+		}
+		log.Warn("IMAGE_SIGNING_SECRET 未設定，使用隨機金鑰，重啟後舊圖片網址將失效")
+	}
+
+	if cfg.IMAGE_STORE_DIR != "" {
+		if err := os.MkdirAll(cfg.IMAGE_STORE_DIR, 0o755); err != nil {
+			return nil, fmt.Errorf("建立圖片目錄失敗: %w", err)
+		}
+	}
+
+	maxMB := cfg.IMAGE_STORE_MAX_MB
+	if maxMB <= 0 {
+		maxMB = defaultMaxMB
+	}
+
+	return &ImageStore{
+		baseURL:  strings.TrimRight(baseURL, "/") + RoutePath(cfg),
+		secret:   secret,
+		dir:      cfg.IMAGE_STORE_DIR,
+		maxBytes: int64(maxMB) << 20,
+		logger:   log,
+		now:      time.Now,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}, nil
+}
+
+// RoutePath 圖片下載路由路徑
+func RoutePath(cfg config.Config) string {
+	if cfg.IMAGE_ROUTE_PATH == "" {
+		return DefaultRoutePath
+	}
+	return "/" + strings.Trim(cfg.IMAGE_ROUTE_PATH, "/")
+}
+
+// UploadImage 保存圖片並回傳簽章網址，expiration 為保存秒數，0 表示使用預設值
+func (s *ImageStore) UploadImage(data []byte, filename string, expiration int) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("圖片內容為空")
+	}
+	if int64(len(data)) > s.maxBytes {
+		return "", fmt.Errorf("圖片大小超過上限")
+	}
+
+	ttl := defaultExpiration
+	if expiration > 0 {
+		ttl = time.Duration(expiration) * time.Second
+	}
+	expiresAt := s.now().Add(ttl)
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("產生圖片 ID 失敗: %w", err)
+	}
+
+	contentType := http.DetectContentType(data)
+	id := fmt.Sprintf("%d-%s.%s", expiresAt.Unix(), hex.EncodeToString(random), extension(contentType, filename))
+
+	image := &Image{Data: data, ContentType: contentType, ExpiresAt: expiresAt}
+	if s.dir != "" {
+		if err := os.WriteFile(filepath.Join(s.dir, id), data, 0o644); err != nil {
+			return "", fmt.Errorf("寫入圖片失敗: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.put(id, image)
+	s.mu.Unlock()
+
+	if s.dir != "" {
+		s.sweepDisk()
+	}
+
+	return s.baseURL + "/" + id + "?signature=" + url.QueryEscape(s.sign(id)), nil
+}
+
+// Get 驗證簽章及到期時間後取得圖片，記憶體中沒有時改從磁碟讀取
+func (s *ImageStore) Get(id, signature string) (*Image, error) {
+	matches := imageIDPattern.FindStringSubmatch(id)
+	if matches == nil {
+		return nil, ErrNotFound
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(id))) {
+		return nil, ErrInvalidSignature
+	}
+
+	expiresUnix, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	expiresAt := time.Unix(expiresUnix, 0)
+	if !s.now().Before(expiresAt) {
+		s.mu.Lock()
+		s.remove(id)
+		s.mu.Unlock()
+		return nil, ErrExpired
+	}
+
+	s.mu.Lock()
+	if element, ok := s.items[id]; ok {
+		s.order.MoveToFront(element)
+		image := element.Value.(*entry).image
+		s.mu.Unlock()
+		return image, nil
+	}
+	s.mu.Unlock()
+
+	if s.dir == "" {
+		return nil, ErrNotFound
+	}
+
+	path := filepath.Join(s.dir, id)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("讀取圖片失敗: %w", err)
+	}
+
+	// 更新修改時間作為磁碟 LRU 的使用時間
+	now := s.now()
+	_ = os.Chtimes(path, now, now)
+
+	image := &Image{Data: data, ContentType: http.DetectContentType(data), ExpiresAt: expiresAt}
+	s.mu.Lock()
+	s.put(id, image)
+	s.mu.Unlock()
+	return image, nil
+}
+
+// sign 以 HMAC-SHA256 簽署圖片 ID
+func (s *ImageStore) sign(id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// put 加入記憶體 LRU，超過容量時移除最久未使用及已過期的圖片，呼叫前需持有鎖
+func (s *ImageStore) put(id string, image *Image) {
+	if element, ok := s.items[id]; ok {
+		s.order.MoveToFront(element)
+		return
+	}
+	s.items[id] = s.order.PushFront(&entry{id: id, image: image})
+	s.size += int64(len(image.Data))
+
+	now := s.now()
+	for element := s.order.Back(); element != nil && s.size > s.maxBytes; {
+		prev := element.Prev()
+		s.removeElement(element)
+		element = prev
+	}
+	for element := s.order.Back(); element != nil; {
+		prev := element.Prev()
+		if !now.Before(element.Value.(*entry).image.ExpiresAt) {
+			s.removeElement(element)
+		}
+		element = prev
+	}
+}
+
+// remove 自記憶體移除圖片，呼叫前需持有鎖
+func (s *ImageStore) remove(id string) {
+	if element, ok := s.items[id]; ok {
+		s.removeElement(element)
+	}
+}
+
+func (s *ImageStore) removeElement(element *list.Element) {
+	e := s.order.Remove(element).(*entry)
+	delete(s.items, e.id)
+	s.size -= int64(len(e.image.Data))
+}
+
+// sweepDisk 刪除磁碟中已過期的圖片，超過容量時依修改時間刪除最舊的圖片
+func (s *ImageStore) sweepDisk() {
+	s.mu.Lock()
+	now := s.now()
+	if now.Sub(s.lastSweep) < diskSweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		s.logger.Warn("讀取圖片目錄失敗", zap.Error(err))
+		return
+	}
+
+	type diskFile struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+	var files []diskFile
+	var total int64
+	for _, dirEntry := range entries {
+		matches := imageIDPattern.FindStringSubmatch(dirEntry.Name())
+		if matches == nil {
+			continue
+		}
+		expiresUnix, _ := strconv.ParseInt(matches[1], 10, 64)
+		if !now.Before(time.Unix(expiresUnix, 0)) {
+			s.removeFile(dirEntry.Name())
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, diskFile{name: dirEntry.Name(), size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	if total <= s.maxBytes {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, file := range files {
+		if total <= s.maxBytes {
+			break
+		}
+		s.removeFile(file.name)
+		total -= file.size
+	}
+}
+
+// removeFile 刪除磁碟圖片
+func (s *ImageStore) removeFile(name string) {
+	if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.logger.Warn("刪除圖片失敗", zap.String("name", name), zap.Error(err))
+	}
+}
+
+// extension 依內容類型決定副檔名，無法判斷時沿用檔名
+func extension(contentType, filename string) string {
+	switch contentType {
+	case "image/png":
+		return "png"
+	case "image/jpeg":
+		return "jpg"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	}
+	switch ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), ".")); ext {
+	case "jpeg":
+		return "jpg"
+	case "png", "jpg", "gif", "webp":
+		return ext
+	}
+	return "png"
+}
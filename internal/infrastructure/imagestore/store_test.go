@@ -0,0 +1,124 @@
+package imagestore
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tian841224/stock-bot/config"
+)
+
+// pngData 以 PNG 檔頭開頭的測試資料
+func pngData(size int) []byte {
+	data := make([]byte, size)
+	copy(data, "\x89PNG\r\n\x1a\n")
+	return data
+}
+
+func newTestStore(t *testing.T, dir string) (*ImageStore, *time.Time) {
+	t.Helper()
+	cfg := config.Config{
+		IMAGE_PUBLIC_BASE_URL: "https://bot.example.com/",
+		IMAGE_SIGNING_SECRET:  "secret",
+		IMAGE_STORE_DIR:       dir,
+		IMAGE_STORE_MAX_MB:    1,
+	}
+	store, err := NewImageStore(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewImageStore() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+// parseImageURL 取得網址中的圖片 ID 及簽章
+func parseImageURL(t *testing.T, rawURL string) (string, string) {
+	t.Helper()
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if !strings.HasPrefix(rawURL, "https://bot.example.com/images/") {
+		t.Fatalf("UploadImage() url = %s, want prefix https://bot.example.com/images/", rawURL)
+	}
+	return path.Base(parsed.Path), parsed.Query().Get("signature")
+}
+
+func TestUploadImageSignedURL(t *testing.T) {
+	store, now := newTestStore(t, "")
+
+	imageURL, err := store.UploadImage(pngData(64), "chart.png", 60)
+	if err != nil {
+		t.Fatalf("UploadImage() error = %v", err)
+	}
+	id, signature := parseImageURL(t, imageURL)
+
+	image, err := store.Get(id, signature)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if image.ContentType != "image/png" {
+		t.Errorf("ContentType = %s, want image/png", image.ContentType)
+	}
+
+	if _, err := store.Get(id, strings.Repeat("0", len(signature))); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Get() with tampered signature error = %v, want ErrInvalidSignature", err)
+	}
+	if _, err := store.Get("../"+id, signature); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() with invalid id error = %v, want ErrNotFound", err)
+	}
+
+	*now = now.Add(61 * time.Second)
+	if _, err := store.Get(id, signature); !errors.Is(err, ErrExpired) {
+		t.Errorf("Get() after expiration error = %v, want ErrExpired", err)
+	}
+}
+
+func TestUploadImageEvictsLeastRecentlyUsed(t *testing.T) {
+	store, _ := newTestStore(t, "")
+
+	firstURL, _ := store.UploadImage(pngData(400<<10), "a.png", 0)
+	secondURL, _ := store.UploadImage(pngData(400<<10), "b.png", 0)
+
+	// 讀取第一張使其成為最近使用，第三張加入時應移除第二張
+	firstID, firstSignature := parseImageURL(t, firstURL)
+	if _, err := store.Get(firstID, firstSignature); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := store.UploadImage(pngData(400<<10), "c.png", 0); err != nil {
+		t.Fatalf("UploadImage() error = %v", err)
+	}
+
+	if _, err := store.Get(firstID, firstSignature); err != nil {
+		t.Errorf("Get() recently used image error = %v, want nil", err)
+	}
+	secondID, secondSignature := parseImageURL(t, secondURL)
+	if _, err := store.Get(secondID, secondSignature); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() evicted image error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetFallsBackToDisk(t *testing.T) {
+	dir := t.TempDir()
+	writer, _ := newTestStore(t, dir)
+	reader, _ := newTestStore(t, dir)
+
+	imageURL, err := writer.UploadImage(pngData(64), "chart.png", 0)
+	if err != nil {
+		t.Fatalf("UploadImage() error = %v", err)
+	}
+	id, signature := parseImageURL(t, imageURL)
+
+	// 另一個行程以相同金鑰及目錄讀取
+	image, err := reader.Get(id, signature)
+	if err != nil {
+		t.Fatalf("Get() from disk error = %v", err)
+	}
+	if len(image.Data) != 64 {
+		t.Errorf("len(Data) = %d, want 64", len(image.Data))
+	}
+}
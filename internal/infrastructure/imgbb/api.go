@@ -114,6 +114,19 @@ func (c *ImgBBClient) UploadFromFile(file io.Reader, filename string, options *U
 	return c.sendRequest(req)
 }
 
+// UploadImage 上傳圖片位元組並回傳公開網址
+func (c *ImgBBClient) UploadImage(data []byte, filename string, expiration int) (string, error) {
+	options := &UploadOptions{Expiration: expiration}
+	resp, err := c.UploadFromFile(bytes.NewReader(data), filename, options)
+	if err != nil {
+		return "", err
+	}
+	if resp.Data.URL == "" {
+		return "", fmt.Errorf("ImgBB 未回傳圖片網址")
+	}
+	return resp.Data.URL, nil
+}
+
 // UploadFromURL 從 URL 上傳圖片
 func (c *ImgBBClient) UploadFromURL(imageURL string, options *UploadOptions) (*dto.ImgBBUploadResponse, error) {
 	// 建立表單資料
@@ -1,10 +1,9 @@
 package linebot
 
 import (
-	"bytes"
+	"fmt"
 
 	"github.com/tian841224/stock-bot/config"
-	"github.com/tian841224/stock-bot/internal/infrastructure/imgbb"
 	"github.com/tian841224/stock-bot/pkg/logger"
 
 	"github.com/line/line-bot-sdk-go/linebot"
@@ -34,6 +33,24 @@ func (b *LineBotClient) ReplyMessage(replyToken, text string) error {
 	return err
 }
 
+// ReplyMessages 一次回覆多則訊息，LINE 限制最多 5 則
+func (b *LineBotClient) ReplyMessages(replyToken string, messages ...linebot.SendingMessage) error {
+	_, err := b.Client.ReplyMessage(replyToken, messages...).Do()
+	if err != nil {
+		b.logger.Error("發送訊息失敗", zap.Error(err))
+	}
+	return err
+}
+
+// PushMessages 主動推播多則訊息，LINE 限制最多 5 則，推播會計入每月訊息額度
+func (b *LineBotClient) PushMessages(to string, messages ...linebot.SendingMessage) error {
+	_, err := b.Client.PushMessage(to, messages...).Do()
+	if err != nil {
+		b.logger.Error("推播訊息失敗", zap.String("to", to), zap.Error(err))
+	}
+	return err
+}
+
 // ReplyMessageWithButtons 回覆帶有按鈕的訊息
 func (b *LineBotClient) ReplyMessageWithButtons(replyToken, text string, buttons []linebot.TemplateAction) error {
 	if len(buttons) == 0 {
@@ -61,27 +78,40 @@ func (b *LineBotClient) ReplyImage(replyToken, imageURL string) error {
 	return err
 }
 
-// ReplyPhoto 上傳圖片並回覆（需要 ImgBB 客戶端）
-func (b *LineBotClient) ReplyPhoto(replyToken string, data []byte, caption string, imgbbClient *imgbb.ImgBBClient) error {
-	// 如果沒有 ImgBB 客戶端，只發送文字訊息
-	if imgbbClient == nil {
-		b.logger.Warn("ImgBB 客戶端未設定，只發送文字訊息")
-		return b.ReplyMessage(replyToken, caption)
+// ProvisionRichMenu 建立圖文選單、上傳圖片並設為預設選單，成功後刪除同名的舊選單
+func (b *LineBotClient) ProvisionRichMenu(richMenu linebot.RichMenu, imagePath string) (string, error) {
+	existing, err := b.Client.GetRichMenuList().Do()
+	if err != nil {
+		return "", fmt.Errorf("取得圖文選單列表失敗: %w", err)
 	}
 
-	// 上傳圖片到 ImgBB
-	options := &imgbb.UploadOptions{
-		Name: "stock_chart",
+	created, err := b.Client.CreateRichMenu(richMenu).Do()
+	if err != nil {
+		return "", fmt.Errorf("建立圖文選單失敗: %w", err)
 	}
 
-	reader := bytes.NewReader(data)
-	resp, err := imgbbClient.UploadFromFile(reader, "chart.png", options)
-	if err != nil {
-		b.logger.Error("上傳圖片到 ImgBB 失敗", zap.Error(err))
-		// 如果上傳失敗，只發送文字訊息
-		return b.ReplyMessage(replyToken, caption)
+	if _, err := b.Client.UploadRichMenuImage(created.RichMenuID, imagePath).Do(); err != nil {
+		b.deleteRichMenu(created.RichMenuID)
+		return "", fmt.Errorf("上傳圖文選單圖片失敗: %w", err)
+	}
+
+	if _, err := b.Client.SetDefaultRichMenu(created.RichMenuID).Do(); err != nil {
+		b.deleteRichMenu(created.RichMenuID)
+		return "", fmt.Errorf("設定預設圖文選單失敗: %w", err)
 	}
 
-	// 發送圖片
-	return b.ReplyImage(replyToken, resp.Data.URL)
+	// 新選單生效後再移除舊選單，避免使用者短暫看不到選單
+	for _, menu := range existing {
+		if menu.Name == richMenu.Name {
+			b.deleteRichMenu(menu.RichMenuID)
+		}
+	}
+	return created.RichMenuID, nil
+}
+
+// deleteRichMenu 刪除圖文選單，失敗時僅記錄
+func (b *LineBotClient) deleteRichMenu(richMenuID string) {
+	if _, err := b.Client.DeleteRichMenu(richMenuID).Do(); err != nil {
+		b.logger.Warn("刪除圖文選單失敗", zap.String("rich_menu_id", richMenuID), zap.Error(err))
+	}
 }
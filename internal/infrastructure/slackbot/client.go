@@ -0,0 +1,225 @@
+// Package slackbot 提供 Slack Bot 客戶端實作（Web API + 請求簽章驗證）
+package slackbot
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/tian841224/stock-bot/config"
+	"github.com/tian841224/stock-bot/internal/infrastructure/slackbot/dto"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	SlackBaseURL = "https://slack.com/api"
+	// 請求時間戳記與現在相差超過此值視為重送攻擊
+	signatureMaxAge = 5 * time.Minute
+)
+
+// SlackBotClient Slack Bot 客戶端
+type SlackBotClient struct {
+	botToken      string
+	signingSecret string
+	client        *http.Client
+	logger        logger.Logger
+}
+
+// NewBot 初始化 Slack Bot
+func NewBot(cfg config.Config, log logger.Logger) (*SlackBotClient, error) {
+	if cfg.SLACK_BOT_TOKEN == "" || cfg.SLACK_SIGNING_SECRET == "" {
+		return nil, fmt.Errorf("未設定 SLACK_BOT_TOKEN 或 SLACK_SIGNING_SECRET")
+	}
+
+	return &SlackBotClient{
+		botToken:      cfg.SLACK_BOT_TOKEN,
+		signingSecret: cfg.SLACK_SIGNING_SECRET,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		logger: log,
+	}, nil
+}
+
+// VerifySignature 驗證 X-Slack-Signature
+// 簽章為 v0= 加上以 signing secret 對 v0:{timestamp}:{body} 計算的 HMAC-SHA256
+func (c *SlackBotClient) VerifySignature(signature, timestamp string, body []byte, now time.Time) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > signatureMaxAge || age < -signatureMaxAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(c.signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// PostMessage 發送 Block Kit 訊息至頻道，text 為通知及不支援 Block Kit 時顯示的文字
+func (c *SlackBotClient) PostMessage(channelID, text string, blocks []dto.Block) error {
+	request := dto.PostMessageRequest{Channel: channelID, Text: text, Blocks: blocks}
+	if err := c.callJSON("chat.postMessage", request, nil); err != nil {
+		c.logger.Error("發送訊息失敗", zap.String("channel_id", channelID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateMessage 更新既有訊息
+func (c *SlackBotClient) UpdateMessage(channelID, ts, text string, blocks []dto.Block) error {
+	request := dto.PostMessageRequest{Channel: channelID, TS: ts, Text: text, Blocks: blocks}
+	if err := c.callJSON("chat.update", request, nil); err != nil {
+		c.logger.Error("更新訊息失敗", zap.String("channel_id", channelID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UploadFile 上傳檔案並分享至頻道
+// 依 Slack 建議流程：取得上傳網址 → 上傳內容 → 完成上傳並分享
+func (c *SlackBotClient) UploadFile(channelID, filename, title string, data []byte) error {
+	var uploadURL dto.GetUploadURLResponse
+	params := url.Values{}
+	params.Set("filename", filename)
+	params.Set("length", strconv.Itoa(len(data)))
+	if err := c.callForm("files.getUploadURLExternal", params, &uploadURL); err != nil {
+		c.logger.Error("取得檔案上傳網址失敗", zap.Error(err))
+		return err
+	}
+
+	if err := c.uploadContent(uploadURL.UploadURL, data); err != nil {
+		c.logger.Error("上傳檔案內容失敗", zap.Error(err))
+		return err
+	}
+
+	files, err := json.Marshal([]map[string]string{{"id": uploadURL.FileID, "title": title}})
+	if err != nil {
+		return fmt.Errorf("序列化檔案資訊失敗: %w", err)
+	}
+	params = url.Values{}
+	params.Set("files", string(files))
+	params.Set("channel_id", channelID)
+	if err := c.callForm("files.completeUploadExternal", params, nil); err != nil {
+		c.logger.Error("完成檔案上傳失敗", zap.String("channel_id", channelID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RespondURL 透過斜線指令或互動的 response_url 回覆，機器人不在頻道內時仍可回覆
+func (c *SlackBotClient) RespondURL(responseURL string, message dto.ResponseURLMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("序列化訊息失敗: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("建立請求失敗: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if _, err := c.do(req); err != nil {
+		c.logger.Error("透過 response_url 回覆失敗", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// uploadContent 將檔案內容上傳至 files.getUploadURLExternal 取得的網址
+func (c *SlackBotClient) uploadContent(uploadURL string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("建立請求失敗: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	_, err = c.do(req)
+	return err
+}
+
+// callJSON 以 JSON 呼叫 Web API
+func (c *SlackBotClient) callJSON(method string, payload any, result any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化請求失敗: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, SlackBaseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("建立請求失敗: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	return c.callAPI(req, result)
+}
+
+// callForm 以表單呼叫 Web API
+func (c *SlackBotClient) callForm(method string, params url.Values, result any) error {
+	req, err := http.NewRequest(http.MethodPost, SlackBaseURL+"/"+method, bytes.NewBufferString(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("建立請求失敗: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return c.callAPI(req, result)
+}
+
+// callAPI 發送 Web API 請求，Slack 以 HTTP 200 搭配 ok=false 表示錯誤
+func (c *SlackBotClient) callAPI(req *http.Request, result any) error {
+	req.Header.Set("Authorization", "Bearer "+c.botToken)
+
+	respBody, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	var apiResp dto.APIResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return fmt.Errorf("解析 JSON 回應失敗: %w", err)
+	}
+	if !apiResp.OK {
+		return fmt.Errorf("Slack API 回應錯誤: %s", apiResp.Error)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("解析 JSON 回應失敗: %w", err)
+		}
+	}
+	return nil
+}
+
+// do 發送請求並讀取回應內容
+func (c *SlackBotClient) do(req *http.Request) ([]byte, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("發送請求失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("讀取回應失敗: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("Slack 回應錯誤，狀態碼: %d，內容: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
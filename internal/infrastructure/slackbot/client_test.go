@@ -0,0 +1,35 @@
+package slackbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	client := &SlackBotClient{signingSecret: "secret"}
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte("command=%2Fstock&text=d+2330")
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	signature := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !client.VerifySignature(signature, timestamp, body, now) {
+		t.Error("VerifySignature() = false, want true for valid signature")
+	}
+	if client.VerifySignature(signature, timestamp, []byte("command=%2Fstock&text=k+2330"), now) {
+		t.Error("VerifySignature() = true, want false for tampered body")
+	}
+	if client.VerifySignature(signature, timestamp, body, now.Add(6*time.Minute)) {
+		t.Error("VerifySignature() = true, want false for stale timestamp")
+	}
+	if client.VerifySignature(signature, "not-a-number", body, now) {
+		t.Error("VerifySignature() = true, want false for malformed timestamp")
+	}
+}
@@ -0,0 +1,70 @@
+package dto
+
+// Block Kit 區塊類型
+const (
+	BlockTypeHeader  = "header"
+	BlockTypeSection = "section"
+	BlockTypeDivider = "divider"
+	BlockTypeActions = "actions"
+)
+
+// Block Kit 文字類型
+const (
+	TextTypePlain    = "plain_text"
+	TextTypeMarkdown = "mrkdwn"
+)
+
+// ElementTypeButton 按鈕元件
+const ElementTypeButton = "button"
+
+// Block Block Kit 區塊
+type Block struct {
+	Type     string         `json:"type"`
+	Text     *TextObject    `json:"text,omitempty"`
+	Fields   []TextObject   `json:"fields,omitempty"`
+	Elements []BlockElement `json:"elements,omitempty"`
+}
+
+// TextObject 文字物件
+type TextObject struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// BlockElement 互動元件
+type BlockElement struct {
+	Type     string      `json:"type"`
+	Text     *TextObject `json:"text,omitempty"`
+	ActionID string      `json:"action_id,omitempty"`
+	Value    string      `json:"value,omitempty"`
+	URL      string      `json:"url,omitempty"`
+}
+
+// PostMessageRequest chat.postMessage / chat.update 請求
+type PostMessageRequest struct {
+	Channel string  `json:"channel"`
+	TS      string  `json:"ts,omitempty"`
+	Text    string  `json:"text"`
+	Blocks  []Block `json:"blocks,omitempty"`
+}
+
+// ResponseURLMessage 透過 response_url 回覆的訊息
+type ResponseURLMessage struct {
+	ResponseType    string `json:"response_type,omitempty"`
+	Text            string `json:"text"`
+	ReplaceOriginal bool   `json:"replace_original,omitempty"`
+}
+
+// APIResponse Web API 共用回應
+type APIResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// GetUploadURLResponse files.getUploadURLExternal 回應
+type GetUploadURLResponse struct {
+	APIResponse
+	UploadURL string `json:"upload_url"`
+	FileID    string `json:"file_id"`
+}
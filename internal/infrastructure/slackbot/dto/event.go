@@ -0,0 +1,76 @@
+// Package dto 定義 Slack API 的資料結構
+package dto
+
+// Events API 外層事件類型
+const (
+	EnvelopeTypeURLVerification = "url_verification"
+	EnvelopeTypeEventCallback   = "event_callback"
+)
+
+// Events API 事件類型
+const (
+	EventTypeAppMention = "app_mention"
+	EventTypeMessage    = "message"
+)
+
+// InteractionTypeBlockActions 按鈕等 Block Kit 互動
+const InteractionTypeBlockActions = "block_actions"
+
+// SlashCommand 斜線指令請求（application/x-www-form-urlencoded）
+type SlashCommand struct {
+	Command     string
+	Text        string
+	TeamID      string
+	ChannelID   string
+	UserID      string
+	ResponseURL string
+}
+
+// EventEnvelope Events API 請求
+type EventEnvelope struct {
+	Type      string `json:"type"`
+	Token     string `json:"token"`
+	Challenge string `json:"challenge"`
+	TeamID    string `json:"team_id"`
+	EventID   string `json:"event_id"`
+	Event     *Event `json:"event"`
+}
+
+// Event Events API 事件內容
+type Event struct {
+	Type        string `json:"type"`
+	Subtype     string `json:"subtype"`
+	User        string `json:"user"`
+	BotID       string `json:"bot_id"`
+	Text        string `json:"text"`
+	Channel     string `json:"channel"`
+	ChannelType string `json:"channel_type"`
+	TS          string `json:"ts"`
+}
+
+// InteractionPayload Block Kit 互動請求，以 payload 表單欄位傳送的 JSON
+type InteractionPayload struct {
+	Type        string        `json:"type"`
+	User        IDRef         `json:"user"`
+	Channel     IDRef         `json:"channel"`
+	Message     *MessageRef   `json:"message"`
+	Actions     []BlockAction `json:"actions"`
+	ResponseURL string        `json:"response_url"`
+}
+
+// IDRef 只含 ID 的參照
+type IDRef struct {
+	ID string `json:"id"`
+}
+
+// MessageRef 互動所在的訊息
+type MessageRef struct {
+	TS string `json:"ts"`
+}
+
+// BlockAction 被點擊的元件
+type BlockAction struct {
+	ActionID string `json:"action_id"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+}
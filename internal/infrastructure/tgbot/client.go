@@ -10,8 +10,9 @@ import (
 )
 
 type TgBotClient struct {
-	Client *tgbotapi.BotAPI
-	logger logger.Logger
+	Client  *tgbotapi.BotAPI
+	logger  logger.Logger
+	limiter *sendLimiter
 }
 
 // NewBot 初始化 Telegram Bot 並設定 webhook
@@ -41,14 +42,20 @@ func NewBot(cfg config.Config, log logger.Logger) (*TgBotClient, error) {
 			return nil, err
 		}
 	}
-	return &TgBotClient{Client: client, logger: log}, nil
+	return &TgBotClient{Client: client, logger: log, limiter: newSendLimiter(log)}, nil
+}
+
+// SetLimitStore 改以共用儲存限流，需於開始發送前設定
+// Bot 及排程器皆使用同一個 Bot 發送，未共用時各程序分別限流，合計可能超過 Telegram 的發送限制
+func (c *TgBotClient) SetLimitStore(store LimitStore) {
+	c.limiter.store = store
 }
 
 // SendMessage 發送訊息
 func (c *TgBotClient) SendMessage(chatID int64, text string) error {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
-	_, err := c.Client.Send(msg)
+	_, err := c.send(chatID, msg)
 	if err != nil {
 		c.logger.Error("發送訊息失敗", zap.Error(err))
 	}
@@ -62,7 +69,7 @@ func (c *TgBotClient) SendMessageWithKeyboard(chatID int64, text string, keyboar
 	if keyboard != nil {
 		msg.ReplyMarkup = keyboard
 	}
-	_, err := c.Client.Send(msg)
+	_, err := c.send(chatID, msg)
 	if err != nil {
 		c.logger.Error("發送帶有鍵盤的訊息失敗", zap.Error(err))
 	}
@@ -73,7 +80,7 @@ func (c *TgBotClient) SendMessageWithKeyboard(chatID int64, text string, keyboar
 func (c *TgBotClient) SendMessageHTML(chatID int64, text string) error {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = tgbotapi.ModeHTML
-	_, err := c.Client.Send(msg)
+	_, err := c.send(chatID, msg)
 	if err != nil {
 		c.logger.Error("發送 HTML 訊息失敗", zap.Error(err))
 	}
@@ -88,9 +95,82 @@ func (c *TgBotClient) SendPhoto(chatID int64, data []byte, caption string) error
 	})
 	photo.Caption = caption
 	photo.ParseMode = tgbotapi.ModeHTML
-	_, err := c.Client.Send(photo)
+	_, err := c.send(chatID, photo)
 	if err != nil {
 		c.logger.Error("發送圖片失敗", zap.Error(err))
 	}
 	return err
 }
+
+// SendPhotoWithKeyboard 發送帶有鍵盤的圖片
+func (c *TgBotClient) SendPhotoWithKeyboard(chatID int64, data []byte, caption string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{
+		Name:  "chart.png",
+		Bytes: data,
+	})
+	photo.Caption = caption
+	photo.ParseMode = tgbotapi.ModeHTML
+	if keyboard != nil {
+		photo.ReplyMarkup = keyboard
+	}
+	_, err := c.send(chatID, photo)
+	if err != nil {
+		c.logger.Error("發送帶有鍵盤的圖片失敗", zap.Error(err))
+	}
+	return err
+}
+
+// EditMessageWithKeyboard 編輯既有訊息內容與鍵盤
+func (c *TgBotClient) EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	msg := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	if keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+	_, err := c.send(chatID, msg)
+	if err != nil {
+		c.logger.Error("編輯訊息失敗", zap.Error(err))
+	}
+	return err
+}
+
+// AnswerCallbackQuery 回應按鈕回呼，text 為空時僅結束按鈕的載入狀態
+func (c *TgBotClient) AnswerCallbackQuery(callbackID string, text string) error {
+	_, err := c.Client.Request(tgbotapi.NewCallback(callbackID, text))
+	if err != nil {
+		c.logger.Error("回應按鈕回呼失敗", zap.Error(err))
+	}
+	return err
+}
+
+// AnswerInlineQuery 回應行內查詢結果
+func (c *TgBotClient) AnswerInlineQuery(queryID string, results []interface{}, cacheTime int) error {
+	config := tgbotapi.InlineConfig{
+		InlineQueryID: queryID,
+		Results:       results,
+		CacheTime:     cacheTime,
+		IsPersonal:    false,
+	}
+	_, err := c.Client.Request(config)
+	if err != nil {
+		c.logger.Error("回應行內查詢失敗", zap.Error(err))
+	}
+	return err
+}
+
+// Username 機器人的使用者名稱，用於辨識群組中的 /指令@機器人
+func (c *TgBotClient) Username() string {
+	return c.Client.Self.UserName
+}
+
+// IsChatAdmin 判斷使用者是否為群組擁有者或管理員
+func (c *TgBotClient) IsChatAdmin(chatID, userID int64) (bool, error) {
+	member, err := c.Client.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
+	if err != nil {
+		c.logger.Error("取得群組成員失敗", zap.Int64("chat_id", chatID), zap.Int64("user_id", userID), zap.Error(err))
+		return false, err
+	}
+	return member.IsCreator() || member.IsAdministrator(), nil
+}
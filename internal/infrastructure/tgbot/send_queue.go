@@ -0,0 +1,241 @@
+package tgbot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Telegram 發送限制：全域每秒 30 則，同一聊天室每秒 1 則，群組每分鐘 20 則
+const (
+	globalRatePerSecond = 30
+	chatRatePerSecond   = 1
+	groupRatePerSecond  = 20.0 / 60
+	maxRetryAfter       = time.Minute     // 超過此等待時間時不在佇列中等待，交由呼叫端重試
+	maxRateLimitRetries = 3               // 收到 429 後最多重試次數
+	chatBucketIdleTTL   = 5 * time.Minute // 閒置令牌桶的保留時間
+)
+
+// ErrChatBlocked 使用者封鎖機器人、帳號已停用或機器人已被移出群組（403）
+var ErrChatBlocked = errors.New("無法發送訊息給此聊天室")
+
+// LimitStore 令牌桶的儲存，預設存於程序內
+// Bot 及排程器等多個程序同時發送時改用共用儲存，合計不超過 Telegram 的發送限制
+type LimitStore interface {
+	// Take 取用令牌桶的一個令牌並回傳 0，令牌不足或暫停中時不取用並回傳需等待的時間
+	Take(key string, rate, burst float64, now time.Time) (time.Duration, error)
+	// Pause 暫停令牌桶至 until，收到 429 時使用
+	Pause(key string, until time.Time) error
+	// Prune 移除 before 前即未使用的令牌桶
+	Prune(before time.Time) error
+}
+
+// 令牌桶名稱，共用儲存中與其他平台區隔
+const globalBucketKey = "telegram:global"
+
+// chatBucketKey 聊天室的令牌桶名稱
+func chatBucketKey(chatID int64) string {
+	return fmt.Sprintf("telegram:chat:%d", chatID)
+}
+
+// chatRate 聊天室每秒的發送數，群組（chat ID 為負數）使用較低的速率
+func chatRate(chatID int64) float64 {
+	if chatID < 0 {
+		return groupRatePerSecond
+	}
+	return chatRatePerSecond
+}
+
+// tokenBucket 令牌桶，rate 為每秒補充的令牌數
+type tokenBucket struct {
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // 收到 429 時暫停至 retry_after 之後
+}
+
+// refill 依經過時間補充令牌
+func (b *tokenBucket) refill(rate, burst float64, now time.Time) {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+}
+
+// wait 取得一個令牌所需等待的時間
+func (b *tokenBucket) wait(rate, burst float64, now time.Time) time.Duration {
+	b.refill(rate, burst, now)
+	var delay time.Duration
+	if b.tokens < 1 {
+		delay = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	if paused := b.pausedUntil.Sub(now); paused > delay {
+		delay = paused
+	}
+	return delay
+}
+
+// memoryStore 程序內的令牌桶，只限制單一程序的發送
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{buckets: make(map[string]*tokenBucket)}
+}
+
+func (m *memoryStore) Take(key string, rate, burst float64, now time.Time) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bucket := m.bucket(key, burst, now)
+	if delay := bucket.wait(rate, burst, now); delay > 0 {
+		return delay, nil
+	}
+	bucket.tokens--
+	return 0, nil
+}
+
+func (m *memoryStore) Pause(key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// 尚未發送過的聊天室於暫停結束後可立即發送一則
+	bucket := m.bucket(key, 1, until)
+	if until.After(bucket.pausedUntil) {
+		bucket.pausedUntil = until
+	}
+	return nil
+}
+
+func (m *memoryStore) Prune(before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, bucket := range m.buckets {
+		if bucket.last.Before(before) && bucket.pausedUntil.Before(before) {
+			delete(m.buckets, key)
+		}
+	}
+	return nil
+}
+
+// bucket 取得令牌桶，不存在時建立已補滿的令牌桶，呼叫前需持有鎖
+func (m *memoryStore) bucket(key string, burst float64, now time.Time) *tokenBucket {
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		m.buckets[key] = bucket
+	}
+	return bucket
+}
+
+// sendLimiter 依全域及聊天室限流，呼叫端依序排隊等待發送
+type sendLimiter struct {
+	store     LimitStore
+	local     *memoryStore // 共用儲存無法使用時改以程序內限流
+	logger    logger.Logger
+	now       func() time.Time
+	sleep     func(time.Duration)
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func newSendLimiter(log logger.Logger) *sendLimiter {
+	local := newMemoryStore()
+	return &sendLimiter{
+		store:  local,
+		local:  local,
+		logger: log,
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// Wait 依序等待聊天室及全域的令牌後取用
+func (l *sendLimiter) Wait(chatID int64) {
+	l.take(chatBucketKey(chatID), chatRate(chatID), 1)
+	l.take(globalBucketKey, globalRatePerSecond, globalRatePerSecond)
+	l.prune()
+}
+
+// take 等待至令牌桶有令牌後取用
+func (l *sendLimiter) take(key string, rate, burst float64) {
+	for {
+		now := l.now()
+		delay, err := l.store.Take(key, rate, burst, now)
+		if err != nil {
+			// 共用儲存無法使用時仍需發送，改以程序內限流
+			l.logger.Warn("取得共用限流狀態失敗，改以程序內限流", zap.String("key", key), zap.Error(err))
+			delay, _ = l.local.Take(key, rate, burst, now)
+		}
+		if delay <= 0 {
+			return
+		}
+		l.sleep(delay)
+	}
+}
+
+// Pause 收到 429 時暫停聊天室發送
+func (l *sendLimiter) Pause(chatID int64, duration time.Duration) {
+	key := chatBucketKey(chatID)
+	until := l.now().Add(duration)
+	if err := l.store.Pause(key, until); err != nil {
+		l.logger.Warn("更新共用限流狀態失敗", zap.String("key", key), zap.Error(err))
+		_ = l.local.Pause(key, until)
+	}
+}
+
+// prune 定期移除閒置的聊天室令牌桶
+func (l *sendLimiter) prune() {
+	l.mu.Lock()
+	now := l.now()
+	if now.Sub(l.lastPrune) < chatBucketIdleTTL {
+		l.mu.Unlock()
+		return
+	}
+	l.lastPrune = now
+	l.mu.Unlock()
+
+	before := now.Add(-chatBucketIdleTTL)
+	if err := l.store.Prune(before); err != nil {
+		l.logger.Warn("清除閒置限流狀態失敗", zap.Error(err))
+	}
+	_ = l.local.Prune(before)
+}
+
+// send 經限流後發送，收到 429 時依 retry_after 等待後重試，403 時回傳 ErrChatBlocked
+func (c *TgBotClient) send(chatID int64, chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	for attempt := 0; ; attempt++ {
+		c.limiter.Wait(chatID)
+		message, err := c.Client.Send(chattable)
+		if err == nil {
+			return message, nil
+		}
+
+		var apiErr *tgbotapi.Error
+		if !errors.As(err, &apiErr) {
+			return message, err
+		}
+		switch apiErr.Code {
+		case http.StatusForbidden:
+			return message, fmt.Errorf("%w: %s", ErrChatBlocked, apiErr.Message)
+		case http.StatusTooManyRequests:
+			retryAfter := time.Duration(apiErr.RetryAfter) * time.Second
+			c.limiter.Pause(chatID, retryAfter)
+			if attempt >= maxRateLimitRetries || retryAfter > maxRetryAfter {
+				return message, err
+			}
+			c.logger.Warn("Telegram 發送頻率過高，等待後重試", zap.Int64("chat_id", chatID), zap.Duration("retry_after", retryAfter))
+		default:
+			return message, err
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tgbot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...zap.Field)  {}
+func (nopLogger) Error(string, ...zap.Field) {}
+func (nopLogger) Warn(string, ...zap.Field)  {}
+func (nopLogger) Debug(string, ...zap.Field) {}
+func (nopLogger) Panic(string, ...zap.Field) {}
+func (nopLogger) Fatal(string, ...zap.Field) {}
+func (nopLogger) Sync() error                { return nil }
+
+// testClock 假時鐘，sleep 直接推進時間並記錄等待總時間
+type testClock struct {
+	now    time.Time
+	waited time.Duration
+}
+
+// attach 以假時鐘取代限流器的時間
+func (c *testClock) attach(l *sendLimiter) *sendLimiter {
+	l.now = func() time.Time { return c.now }
+	l.sleep = func(d time.Duration) {
+		c.now = c.now.Add(d)
+		c.waited += d
+	}
+	return l
+}
+
+// newTestLimiter 以假時鐘建立限流器
+func newTestLimiter() (*sendLimiter, *time.Duration) {
+	clock := &testClock{now: time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)}
+	return clock.attach(newSendLimiter(nopLogger{})), &clock.waited
+}
+
+func TestSendLimiterPerChat(t *testing.T) {
+	l, waited := newTestLimiter()
+
+	l.Wait(1)
+	l.Wait(2)
+	if *waited != 0 {
+		t.Fatalf("different chats should not wait, waited %v", *waited)
+	}
+
+	l.Wait(1)
+	if *waited != time.Second {
+		t.Errorf("same chat waited %v, want 1s", *waited)
+	}
+
+	// 群組每分鐘 20 則
+	l.Wait(-100)
+	before := *waited
+	l.Wait(-100)
+	if got := *waited - before; got != 3*time.Second {
+		t.Errorf("group chat waited %v, want 3s", got)
+	}
+}
+
+func TestSendLimiterGlobal(t *testing.T) {
+	l, waited := newTestLimiter()
+
+	for chatID := int64(1); chatID <= globalRatePerSecond; chatID++ {
+		l.Wait(chatID)
+	}
+	if *waited != 0 {
+		t.Fatalf("burst within global limit should not wait, waited %v", *waited)
+	}
+
+	l.Wait(globalRatePerSecond + 1)
+	if want := time.Second / globalRatePerSecond; *waited < want-time.Millisecond || *waited > want+time.Millisecond {
+		t.Errorf("global limit waited %v, want about %v", *waited, want)
+	}
+}
+
+func TestSendLimiterPause(t *testing.T) {
+	l, waited := newTestLimiter()
+
+	l.Pause(1, 5*time.Second)
+	l.Wait(1)
+	if *waited != 5*time.Second {
+		t.Errorf("paused chat waited %v, want 5s", *waited)
+	}
+}
+
+func TestSendLimiterSharedStore(t *testing.T) {
+	// Bot 及排程器共用令牌桶，合計不超過全域限制
+	clock := &testClock{now: time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)}
+	store := newMemoryStore()
+	bot := clock.attach(newSendLimiter(nopLogger{}))
+	scheduler := clock.attach(newSendLimiter(nopLogger{}))
+	bot.store, scheduler.store = store, store
+
+	for chatID := int64(1); chatID <= globalRatePerSecond; chatID++ {
+		if chatID%2 == 0 {
+			bot.Wait(chatID)
+		} else {
+			scheduler.Wait(chatID)
+		}
+	}
+	if clock.waited != 0 {
+		t.Fatalf("burst within global limit should not wait, waited %v", clock.waited)
+	}
+	scheduler.Wait(globalRatePerSecond + 1)
+	if clock.waited == 0 {
+		t.Error("sends from both processes should share the global limit")
+	}
+
+	// 同一聊天室亦共用
+	before := clock.waited
+	bot.Wait(1)
+	if clock.waited == before {
+		t.Error("same chat from another process should wait for the shared chat limit")
+	}
+}
+
+// failingStore 無法連線的共用儲存
+type failingStore struct{}
+
+func (failingStore) Take(string, float64, float64, time.Time) (time.Duration, error) {
+	return 0, errors.New("connection refused")
+}
+func (failingStore) Pause(string, time.Time) error { return errors.New("connection refused") }
+func (failingStore) Prune(time.Time) error         { return errors.New("connection refused") }
+
+func TestSendLimiterFallsBackToLocal(t *testing.T) {
+	l, waited := newTestLimiter()
+	l.store = failingStore{}
+
+	l.Wait(1)
+	l.Wait(1)
+	if *waited != time.Second {
+		t.Errorf("same chat waited %v, want 1s from the local limiter", *waited)
+	}
+}
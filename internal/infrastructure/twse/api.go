@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -104,6 +105,38 @@ func (t *TwseAPI) GetDailyMarketInfo() (dto.DailyMarketInfoResponseDto, error) {
 	return response, nil
 }
 
+// GetHolidaySchedule 取得指定年度的市場開休市日期，年度以西元年傳入
+func (t *TwseAPI) GetHolidaySchedule(year int) (dto.HolidayScheduleResponseDto, error) {
+	u, err := url.Parse(t.baseURL + "/holidaySchedule/holidaySchedule")
+	if err != nil {
+		return dto.HolidayScheduleResponseDto{}, err
+	}
+	q := u.Query()
+	// 證交所以民國年查詢
+	q.Set("queryYear", strconv.Itoa(year-1911))
+	q.Set("response", "json")
+	u.RawQuery = q.Encode()
+
+	req, err := t.getRequest(u.String())
+	if err != nil {
+		return dto.HolidayScheduleResponseDto{}, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return dto.HolidayScheduleResponseDto{}, fmt.Errorf("無法連接到外部 API: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return dto.HolidayScheduleResponseDto{}, fmt.Errorf("外部 API 回應錯誤，狀態碼: %d", resp.StatusCode)
+	}
+
+	var response dto.HolidayScheduleResponseDto
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return dto.HolidayScheduleResponseDto{}, fmt.Errorf("無法解析回應 JSON: %v", err)
+	}
+	return response, nil
+}
+
 // 設定Request參數
 func (f *TwseAPI) getRequest(url string) (*http.Request, error) {
 	req, err := http.NewRequest("GET", url, nil)
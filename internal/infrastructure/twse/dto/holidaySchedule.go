@@ -0,0 +1,9 @@
+package dto
+
+// 市場開休市日期（證交所公告當年度休市日，含尚未到來的日期）
+type HolidayScheduleResponseDto struct {
+	Stat   string     `json:"stat"`
+	Title  string     `json:"title"`
+	Fields []string   `json:"fields"` // 日期、名稱、說明
+	Data   [][]string `json:"data"`
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"gorm.io/gorm"
+)
+
+type CallbackPayloadRepository interface {
+	Create(payload *models.CallbackPayload) error
+	GetByToken(token string) (*models.CallbackPayload, error)
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+type callbackPayloadRepository struct {
+	db *gorm.DB
+}
+
+func NewCallbackPayloadRepository(db *gorm.DB) CallbackPayloadRepository {
+	return &callbackPayloadRepository{db: db}
+}
+
+// Create 建立按鈕回呼資料
+func (r *callbackPayloadRepository) Create(payload *models.CallbackPayload) error {
+	return r.db.Create(payload).Error
+}
+
+// GetByToken 依代碼取得按鈕回呼資料
+func (r *callbackPayloadRepository) GetByToken(token string) (*models.CallbackPayload, error) {
+	var payload models.CallbackPayload
+	err := r.db.Where("token = ?", token).First(&payload).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// DeleteBefore 刪除指定時間前建立的按鈕回呼資料
+func (r *callbackPayloadRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.CallbackPayload{})
+	return result.RowsAffected, result.Error
+}
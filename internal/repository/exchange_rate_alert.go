@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"gorm.io/gorm"
+)
+
+type ExchangeRateAlertRepository interface {
+	Create(alert *models.ExchangeRateAlert) error
+	GetByID(id uint) (*models.ExchangeRateAlert, error)
+	GetByUserID(userID uint) ([]*models.ExchangeRateAlert, error)
+	GetActiveAlerts() ([]*models.ExchangeRateAlert, error)
+	MarkTriggered(id uint, triggeredAt time.Time) error
+	Delete(id uint) error
+}
+
+type exchangeRateAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateAlertRepository(db *gorm.DB) ExchangeRateAlertRepository {
+	return &exchangeRateAlertRepository{db: db}
+}
+
+// Create 建立新匯率警示
+func (r *exchangeRateAlertRepository) Create(alert *models.ExchangeRateAlert) error {
+	return r.db.Create(alert).Error
+}
+
+// GetByID 根據 ID 取得匯率警示
+func (r *exchangeRateAlertRepository) GetByID(id uint) (*models.ExchangeRateAlert, error) {
+	var alert models.ExchangeRateAlert
+	err := r.db.First(&alert, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// GetByUserID 根據使用者 ID 取得啟用中的匯率警示
+func (r *exchangeRateAlertRepository) GetByUserID(userID uint) ([]*models.ExchangeRateAlert, error) {
+	var alerts []*models.ExchangeRateAlert
+	err := r.db.Where("user_id = ? AND status = ?", userID, true).Order("id").Find(&alerts).Error
+	return alerts, err
+}
+
+// GetActiveAlerts 取得所有啟用中的匯率警示
+func (r *exchangeRateAlertRepository) GetActiveAlerts() ([]*models.ExchangeRateAlert, error) {
+	var alerts []*models.ExchangeRateAlert
+	err := r.db.Where("status = ?", true).Find(&alerts).Error
+	return alerts, err
+}
+
+// MarkTriggered 標記警示已觸發並停用
+func (r *exchangeRateAlertRepository) MarkTriggered(id uint, triggeredAt time.Time) error {
+	return r.db.Model(&models.ExchangeRateAlert{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       false,
+		"triggered_at": triggeredAt,
+	}).Error
+}
+
+// Delete 刪除匯率警示
+func (r *exchangeRateAlertRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ExchangeRateAlert{}, id).Error
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRunRepository interface {
+	TryStart(run *models.JobRun) (bool, error)
+	RenewLease(run *models.JobRun) (bool, error)
+	Finish(run *models.JobRun) error
+	Create(run *models.JobRun) error
+	GetPending(now time.Time) ([]*models.JobRun, error)
+	Claim(run *models.JobRun) (bool, error)
+	GetLatestPerJob() ([]*models.JobRun, error)
+	GetByJobName(name string, limit int) ([]*models.JobRun, error)
+}
+
+type jobRunRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRunRepository(db *gorm.DB) JobRunRepository {
+	return &jobRunRepository{db: db}
+}
+
+// TryStart 建立執行紀錄取得執行權，相同排程名稱及時間已有紀錄時回傳 false
+// 已有的紀錄仍為執行中但執行權已於 run.StartedAt 前到期時，表示原排程器已中斷，改由此次執行接手
+func (r *jobRunRepository) TryStart(run *models.JobRun) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	result = r.db.Model(&models.JobRun{}).
+		Where("job_name = ? AND scheduled_at = ? AND status = ? AND lease_until < ?",
+			run.JobName, run.ScheduledAt, models.JobRunStatusRunning, run.StartedAt).
+		Updates(map[string]any{
+			"instance":    run.Instance,
+			"started_at":  run.StartedAt,
+			"lease_until": run.LeaseUntil,
+			"count":       0,
+			"error":       "",
+		})
+	if result.Error != nil || result.RowsAffected != 1 {
+		return false, result.Error
+	}
+	err := r.db.Model(&models.JobRun{}).
+		Where("job_name = ? AND scheduled_at = ?", run.JobName, run.ScheduledAt).
+		Select("id").Scan(&run.ID).Error
+	return err == nil, err
+}
+
+// RenewLease 延長執行中紀錄的執行權期限，紀錄已由其他排程器接手或已結束時回傳 false
+func (r *jobRunRepository) RenewLease(run *models.JobRun) (bool, error) {
+	result := r.db.Model(&models.JobRun{}).
+		Where("id = ? AND instance = ? AND status = ?", run.ID, run.Instance, models.JobRunStatusRunning).
+		Update("lease_until", run.LeaseUntil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Finish 更新執行結果，紀錄已由其他排程器接手時不更新，避免覆寫接手後的執行結果
+func (r *jobRunRepository) Finish(run *models.JobRun) error {
+	return r.db.Model(&models.JobRun{}).
+		Where("id = ? AND instance = ?", run.ID, run.Instance).
+		Updates(map[string]any{
+			"status":      run.Status,
+			"finished_at": run.FinishedAt,
+			"count":       run.Count,
+			"error":       run.Error,
+		}).Error
+}
+
+// Create 建立執行紀錄
+func (r *jobRunRepository) Create(run *models.JobRun) error {
+	return r.db.Create(run).Error
+}
+
+// GetPending 取得等待執行及執行權已到期的手動執行紀錄，依建立順序排列
+func (r *jobRunRepository) GetPending(now time.Time) ([]*models.JobRun, error) {
+	var runs []*models.JobRun
+	err := r.db.Where("status = ? OR (status = ? AND triggered_by <> '' AND lease_until < ?)",
+		models.JobRunStatusPending, models.JobRunStatusRunning, now).
+		Order("id").Find(&runs).Error
+	return runs, err
+}
+
+// Claim 將等待執行或執行權已到期的紀錄改為執行中，其他排程器已取得時回傳 false，避免重複執行
+func (r *jobRunRepository) Claim(run *models.JobRun) (bool, error) {
+	result := r.db.Model(&models.JobRun{}).
+		Where("id = ? AND (status = ? OR (status = ? AND lease_until < ?))",
+			run.ID, models.JobRunStatusPending, models.JobRunStatusRunning, run.StartedAt).
+		Updates(map[string]any{
+			"status":      models.JobRunStatusRunning,
+			"instance":    run.Instance,
+			"started_at":  run.StartedAt,
+			"lease_until": run.LeaseUntil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetLatestPerJob 取得各排程最近一次的執行紀錄
+func (r *jobRunRepository) GetLatestPerJob() ([]*models.JobRun, error) {
+	var runs []*models.JobRun
+	err := r.db.Raw(`SELECT DISTINCT ON (job_name) * FROM job_runs ORDER BY job_name, scheduled_at DESC, id DESC`).
+		Scan(&runs).Error
+	return runs, err
+}
+
+// GetByJobName 取得指定排程最近的執行紀錄
+func (r *jobRunRepository) GetByJobName(name string, limit int) ([]*models.JobRun, error) {
+	var runs []*models.JobRun
+	err := r.db.Where("job_name = ?", name).
+		Order("scheduled_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Find(&runs).Error
+	return runs, err
+}
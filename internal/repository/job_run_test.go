@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+)
+
+func TestJobRunTryStartTakesOverExpiredLease(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewJobRunRepository(db)
+
+	now := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	leaseUntil := now.Add(11 * time.Minute)
+	run := &models.JobRun{
+		JobName:     "price",
+		ScheduledAt: now,
+		Status:      models.JobRunStatusRunning,
+		StartedAt:   now,
+		LeaseUntil:  &leaseUntil,
+	}
+	// DryRun 不會寫入資料，建立紀錄視為衝突，改為嘗試接手
+	if _, err := repo.TryStart(run); err != nil {
+		t.Fatalf("TryStart() error = %v", err)
+	}
+
+	if len(recorder.statements) < 2 {
+		t.Fatalf("statements = %v, want insert followed by takeover", recorder.statements)
+	}
+	assertSQLAt(t, recorder.statements[0], `ON CONFLICT DO NOTHING`)
+	assertSQLAt(t, recorder.statements[1],
+		`"lease_until"='2025-03-03 09:11:00'`,
+		`WHERE job_name = 'price' AND scheduled_at = '2025-03-03 09:00:00' AND status = 'running' AND lease_until < '2025-03-03 09:00:00'`,
+	)
+}
+
+func TestJobRunClaimExpiredLease(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewJobRunRepository(db)
+
+	now := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	if _, err := repo.Claim(&models.JobRun{Model: models.Model{ID: 3}, StartedAt: now}); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	assertSQL(t, recorder, `WHERE id = 3 AND (status = 'pending' OR (status = 'running' AND lease_until < '2025-03-03 09:00:00'))`)
+}
+
+func TestJobRunGetPendingIncludesInterruptedTriggers(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewJobRunRepository(db)
+
+	if _, err := repo.GetPending(time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("GetPending() error = %v", err)
+	}
+	// 排程執行的紀錄不列入，由補執行接手或於 /jobs 手動重試
+	assertSQL(t, recorder, `status = 'pending' OR (status = 'running' AND triggered_by <> '' AND lease_until < '2025-03-03 09:00:00')`)
+}
+
+func TestJobRunRenewLeaseOnlyOwnRun(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewJobRunRepository(db)
+
+	leaseUntil := time.Date(2025, 3, 3, 9, 11, 0, 0, time.UTC)
+	run := &models.JobRun{Model: models.Model{ID: 3}, Instance: "host:1", LeaseUntil: &leaseUntil}
+	if _, err := repo.RenewLease(run); err != nil {
+		t.Fatalf("RenewLease() error = %v", err)
+	}
+	assertSQL(t, recorder, `"lease_until"='2025-03-03 09:11:00'`, `WHERE id = 3 AND instance = 'host:1' AND status = 'running'`)
+
+	// 已由其他排程器接手的紀錄不覆寫執行結果
+	if err := repo.Finish(run); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	assertSQL(t, recorder, `WHERE id = 3 AND instance = 'host:1'`)
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"gorm.io/gorm"
+)
+
+type NewsAlertRepository interface {
+	Create(alert *models.NewsAlert) error
+	GetByID(id uint) (*models.NewsAlert, error)
+	GetByUserID(userID uint) ([]*models.NewsAlert, error)
+	GetAll() ([]*models.NewsAlert, error)
+	Delete(id uint) error
+}
+
+type newsAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewNewsAlertRepository(db *gorm.DB) NewsAlertRepository {
+	return &newsAlertRepository{db: db}
+}
+
+// Create 建立新聞關鍵字警示
+func (r *newsAlertRepository) Create(alert *models.NewsAlert) error {
+	return r.db.Create(alert).Error
+}
+
+// GetByID 根據 ID 取得新聞關鍵字警示
+func (r *newsAlertRepository) GetByID(id uint) (*models.NewsAlert, error) {
+	var alert models.NewsAlert
+	err := r.db.First(&alert, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// GetByUserID 根據使用者 ID 取得新聞關鍵字警示
+func (r *newsAlertRepository) GetByUserID(userID uint) ([]*models.NewsAlert, error) {
+	var alerts []*models.NewsAlert
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&alerts).Error
+	return alerts, err
+}
+
+// GetAll 取得所有新聞關鍵字警示
+func (r *newsAlertRepository) GetAll() ([]*models.NewsAlert, error) {
+	var alerts []*models.NewsAlert
+	err := r.db.Order("id").Find(&alerts).Error
+	return alerts, err
+}
+
+// Delete 刪除新聞關鍵字警示
+func (r *newsAlertRepository) Delete(id uint) error {
+	return r.db.Delete(&models.NewsAlert{}, id).Error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NewsSeenRepository interface {
+	GetSeenLinks(scope string, userIDs []uint, links []string) (map[uint]map[string]bool, error)
+	BatchCreate(seen []*models.NewsSeen) error
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+type newsSeenRepository struct {
+	db *gorm.DB
+}
+
+func NewNewsSeenRepository(db *gorm.DB) NewsSeenRepository {
+	return &newsSeenRepository{db: db}
+}
+
+// GetSeenLinks 取得指定使用者在推播範圍內已推播過的新聞連結
+func (r *newsSeenRepository) GetSeenLinks(scope string, userIDs []uint, links []string) (map[uint]map[string]bool, error) {
+	seen := make(map[uint]map[string]bool)
+	if len(userIDs) == 0 || len(links) == 0 {
+		return seen, nil
+	}
+
+	var rows []*models.NewsSeen
+	err := r.db.Select("user_id", "link").
+		Where("scope = ? AND user_id IN ? AND link IN ?", scope, userIDs, links).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if seen[row.UserID] == nil {
+			seen[row.UserID] = make(map[string]bool)
+		}
+		seen[row.UserID][row.Link] = true
+	}
+	return seen, nil
+}
+
+// BatchCreate 批次建立已推播紀錄，已存在的紀錄略過
+func (r *newsSeenRepository) BatchCreate(seen []*models.NewsSeen) error {
+	if len(seen) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(seen, 100).Error
+}
+
+// DeleteBefore 刪除指定時間前的已推播紀錄
+func (r *newsSeenRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.NewsSeen{})
+	return result.RowsAffected, result.Error
+}
@@ -19,8 +19,13 @@ type NotificationDeliveryRepository interface {
 	Delete(id uint) error
 	List(offset, limit int) ([]*models.NotificationDelivery, error)
 	GetByDateRange(startDate, endDate time.Time) ([]*models.NotificationDelivery, error)
-	GetFailedDeliveries() ([]*models.NotificationDelivery, error)
+	GetFailedDeliveries(maxAttempts int, before time.Time, limit int) ([]*models.NotificationDelivery, error)
+	GetQueuedDeliveries(before time.Time, limit int) ([]*models.NotificationDelivery, error)
 	BatchCreate(deliveries []*models.NotificationDelivery) error
+	Claim(id uint, status models.NotificationDeliveryStatus) (bool, error)
+	UpdateResult(delivery *models.NotificationDelivery) error
+	MarkStaleSending(before time.Time, response string) (int64, error)
+	Defer(id uint, status models.NotificationDeliveryStatus, until time.Time) error
 }
 
 type notificationDeliveryRepository struct {
@@ -96,10 +101,21 @@ func (r *notificationDeliveryRepository) GetByDateRange(startDate, endDate time.
 	return deliveries, err
 }
 
-// GetFailedDeliveries 取得失敗的投遞紀錄
-func (r *notificationDeliveryRepository) GetFailedDeliveries() ([]*models.NotificationDelivery, error) {
+// GetFailedDeliveries 取得可重試的失敗投遞紀錄（嘗試次數未達上限且已到重試時間）
+func (r *notificationDeliveryRepository) GetFailedDeliveries(maxAttempts int, before time.Time, limit int) ([]*models.NotificationDelivery, error) {
 	var deliveries []*models.NotificationDelivery
-	err := r.db.Where("status = ?", models.NotificationDeliveryStatusFailed).Find(&deliveries).Error
+	err := r.db.Preload("Event").
+		Where("status = ? AND attempts < ? AND next_attempt_at <= ?", models.NotificationDeliveryStatusFailed, maxAttempts, before).
+		Order("id").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetQueuedDeliveries 取得已到發送時間的待發送投遞紀錄
+func (r *notificationDeliveryRepository) GetQueuedDeliveries(before time.Time, limit int) ([]*models.NotificationDelivery, error) {
+	var deliveries []*models.NotificationDelivery
+	err := r.db.Preload("Event").
+		Where("status = ? AND next_attempt_at <= ?", models.NotificationDeliveryStatusQueued, before).
+		Order("id").Limit(limit).Find(&deliveries).Error
 	return deliveries, err
 }
 
@@ -107,3 +123,53 @@ func (r *notificationDeliveryRepository) GetFailedDeliveries() ([]*models.Notifi
 func (r *notificationDeliveryRepository) BatchCreate(deliveries []*models.NotificationDelivery) error {
 	return r.db.CreateInBatches(deliveries, 100).Error
 }
+
+// Claim 將投遞紀錄由指定狀態改為發送中並增加嘗試次數，狀態已被其他發送工作變更時回傳 false
+func (r *notificationDeliveryRepository) Claim(id uint, status models.NotificationDeliveryStatus) (bool, error) {
+	result := r.db.Model(&models.NotificationDelivery{}).
+		Where("id = ? AND status = ?", id, status).
+		Updates(map[string]any{
+			"status":     models.NotificationDeliveryStatusSending,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// UpdateResult 更新發送結果
+func (r *notificationDeliveryRepository) UpdateResult(delivery *models.NotificationDelivery) error {
+	return r.db.Model(&models.NotificationDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]any{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"sent_parts":      delivery.SentParts,
+			"response":        delivery.Response,
+			"sent_at":         delivery.SentAt,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// MarkStaleSending 將發送中逾時（發送工作中斷）的投遞紀錄標記為無法確認
+// 中斷時可能已送達，為避免重複發送不自動重送，由管理者確認後處理
+func (r *notificationDeliveryRepository) MarkStaleSending(before time.Time, response string) (int64, error) {
+	result := r.db.Model(&models.NotificationDelivery{}).
+		Where("status = ? AND updated_at < ?", models.NotificationDeliveryStatusSending, before).
+		Updates(map[string]any{
+			"status":     models.NotificationDeliveryStatusUnknown,
+			"response":   response,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// Defer 延後投遞紀錄的發送時間，狀態已被其他發送工作變更時不更新
+func (r *notificationDeliveryRepository) Defer(id uint, status models.NotificationDeliveryStatus, until time.Time) error {
+	return r.db.Model(&models.NotificationDelivery{}).
+		Where("id = ? AND status = ?", id, status).
+		Updates(map[string]any{
+			"next_attempt_at": until,
+			"updated_at":      time.Now(),
+		}).Error
+}
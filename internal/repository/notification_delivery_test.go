@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+)
+
+func TestNotificationDeliveryClaim(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewNotificationDeliveryRepository(db)
+
+	if _, err := repo.Claim(7, models.NotificationDeliveryStatusFailed); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	// 僅在狀態未被其他發送工作變更時取得
+	assertSQL(t, recorder,
+		`"status"='sending'`,
+		`"attempts"=attempts + 1`,
+		`WHERE id = 7 AND status = 'failed'`,
+	)
+}
+
+func TestNotificationDeliveryMarkStaleSending(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewNotificationDeliveryRepository(db)
+
+	before := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	if _, err := repo.MarkStaleSending(before, `{}`); err != nil {
+		t.Fatalf("MarkStaleSending() error = %v", err)
+	}
+	// 中斷時可能已送達，標記為無法確認而不重新排入佇列
+	assertSQL(t, recorder,
+		`"status"='unknown'`,
+		`WHERE status = 'sending' AND updated_at < '2025-03-03 09:00:00'`,
+	)
+}
+
+func TestNotificationImageSaveRefreshesExisting(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewNotificationImageRepository(db)
+
+	if err := repo.Save(&models.NotificationImage{Hash: "abc", Name: "chart.png", Data: []byte("png")}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	assertSQL(t, recorder, `ON CONFLICT ("hash") DO UPDATE SET "updated_at"="excluded"."updated_at"`)
+}
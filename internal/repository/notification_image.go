@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationImageRepository interface {
+	Save(image *models.NotificationImage) error
+	GetByHash(hash string) (*models.NotificationImage, error)
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+type notificationImageRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationImageRepository(db *gorm.DB) NotificationImageRepository {
+	return &notificationImageRepository{db: db}
+}
+
+// Save 建立通知圖片，相同雜湊值已存在時只更新時間，避免仍被參照的圖片遭清除
+func (r *notificationImageRepository) Save(image *models.NotificationImage) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+	}).Create(image).Error
+}
+
+// GetByHash 依雜湊值取得通知圖片
+func (r *notificationImageRepository) GetByHash(hash string) (*models.NotificationImage, error) {
+	var image models.NotificationImage
+	err := r.db.Where("hash = ?", hash).First(&image).Error
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// DeleteBefore 刪除指定時間前最後使用的通知圖片
+func (r *notificationImageRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("updated_at < ?", before).Delete(&models.NotificationImage{})
+	return result.RowsAffected, result.Error
+}
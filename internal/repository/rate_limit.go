@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"gorm.io/gorm"
+)
+
+// minRetryDelay 令牌已由其他程序取用時重新嘗試的等待時間
+const minRetryDelay = 10 * time.Millisecond
+
+type RateLimitRepository interface {
+	Take(key string, rate, burst float64, now time.Time) (time.Duration, error)
+	Pause(key string, until time.Time) error
+	Prune(before time.Time) error
+}
+
+type rateLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewRateLimitRepository(db *gorm.DB) RateLimitRepository {
+	return &rateLimitRepository{db: db}
+}
+
+// refilledTokens 補充經過時間的令牌後的令牌數，不超過上限
+const refilledTokens = `LEAST(CAST(@burst AS double precision),
+	rate_limit_buckets.tokens + GREATEST(EXTRACT(EPOCH FROM CAST(@now AS timestamptz) - rate_limit_buckets.refilled_at), 0) * CAST(@rate AS double precision))`
+
+// Take 以單一語句補充並取用令牌，多個程序同時取用時由資料列鎖確保不超過限制
+// 令牌不足或暫停中時不取用，依目前狀態回傳需等待的時間
+func (r *rateLimitRepository) Take(key string, rate, burst float64, now time.Time) (time.Duration, error) {
+	args := map[string]any{"key": key, "rate": rate, "burst": burst, "now": now}
+	result := r.db.Exec(`INSERT INTO rate_limit_buckets (key, tokens, refilled_at, paused_until)
+VALUES (@key, CAST(@burst AS double precision) - 1, @now, @now)
+ON CONFLICT (key) DO UPDATE SET
+	tokens = `+refilledTokens+` - 1,
+	refilled_at = GREATEST(CAST(@now AS timestamptz), rate_limit_buckets.refilled_at)
+WHERE rate_limit_buckets.paused_until <= @now AND `+refilledTokens+` >= 1`, args)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return 0, nil
+	}
+
+	var bucket models.RateLimitBucket
+	if err := r.db.Where("key = ?", key).Take(&bucket).Error; err != nil {
+		return 0, err
+	}
+	return max(bucket.Wait(rate, burst, now), minRetryDelay), nil
+}
+
+// Pause 暫停令牌桶至 until，已暫停至更晚的時間時不變更
+func (r *rateLimitRepository) Pause(key string, until time.Time) error {
+	return r.db.Exec(`INSERT INTO rate_limit_buckets (key, tokens, refilled_at, paused_until)
+VALUES (?, 1, ?, ?)
+ON CONFLICT (key) DO UPDATE SET paused_until = GREATEST(rate_limit_buckets.paused_until, excluded.paused_until)`,
+		key, until, until).Error
+}
+
+// Prune 刪除 before 前即未使用且未暫停的令牌桶
+func (r *rateLimitRepository) Prune(before time.Time) error {
+	return r.db.Where("refilled_at < ? AND paused_until < ?", before, before).Delete(&models.RateLimitBucket{}).Error
+}
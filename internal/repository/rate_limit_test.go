@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitTakeIsSingleConditionalUpsert(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewRateLimitRepository(db)
+
+	now := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	if _, err := repo.Take("telegram:chat:1", 1, 1, now); err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	if len(recorder.statements) == 0 {
+		t.Fatal("no SQL recorded")
+	}
+	// 補充及取用於同一語句，令牌不足或暫停中時不更新
+	assertSQLAt(t, recorder.statements[0],
+		`VALUES ('telegram:chat:1', CAST(1 AS double precision) - 1`,
+		`ON CONFLICT (key) DO UPDATE SET`,
+		`WHERE rate_limit_buckets.paused_until <= '2025-03-03 09:00:00'`,
+		`>= 1`,
+	)
+}
+
+func TestRateLimitPauseKeepsLatest(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewRateLimitRepository(db)
+
+	if err := repo.Pause("telegram:chat:1", time.Date(2025, 3, 3, 9, 0, 5, 0, time.UTC)); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	assertSQL(t, recorder, `paused_until = GREATEST(rate_limit_buckets.paused_until, excluded.paused_until)`)
+}
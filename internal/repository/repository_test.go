@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// sqlRecorder 記錄產生的 SQL，DryRun 模式下不連線資料庫
+type sqlRecorder struct {
+	gormLogger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(gormLogger.LogLevel) gormLogger.Interface { return r }
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// newDryRunDB 建立只產生 SQL 的資料庫連線，用於檢查查詢條件
+func newDryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{Interface: gormLogger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost user=test dbname=test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db, recorder
+}
+
+// assertSQL 檢查最後一個 SQL 包含所有片段
+func assertSQL(t *testing.T, recorder *sqlRecorder, fragments ...string) {
+	t.Helper()
+	if len(recorder.statements) == 0 {
+		t.Fatal("no SQL recorded")
+	}
+	assertSQLAt(t, recorder.statements[len(recorder.statements)-1], fragments...)
+}
+
+// assertSQLAt 檢查 SQL 包含所有片段
+func assertSQLAt(t *testing.T, sql string, fragments ...string) {
+	t.Helper()
+	for _, fragment := range fragments {
+		if !strings.Contains(sql, fragment) {
+			t.Errorf("SQL %q does not contain %q", sql, fragment)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"fmt"
+
 	"github.com/tian841224/stock-bot/internal/db/models"
 
 	"gorm.io/gorm"
@@ -19,6 +21,7 @@ type SubscriptionRepository interface {
 	List(offset, limit int) ([]*models.Subscription, error)
 	GetActiveSubscriptions() ([]*models.Subscription, error)
 	GetBySchedule(scheduleCron string) ([]*models.Subscription, error)
+	GetActiveByItem(item models.SubscriptionItem) ([]*models.Subscription, error)
 }
 
 type subscriptionRepository struct {
@@ -110,3 +113,12 @@ func (r *subscriptionRepository) GetBySchedule(scheduleCron string) ([]*models.S
 	err := r.db.Preload("User").Preload("Feature").Where("schedule_cron = ?", scheduleCron).Find(&subscriptions).Error
 	return subscriptions, err
 }
+
+// GetActiveByItem 取得訂閱項目的所有啟用訂閱
+func (r *subscriptionRepository) GetActiveByItem(item models.SubscriptionItem) ([]*models.Subscription, error) {
+	var subscriptions []*models.Subscription
+	err := r.db.Joins("JOIN features ON features.id = subscriptions.feature_id").
+		Where("features.code = ? AND subscriptions.status = ?", fmt.Sprintf("%d", int(item)), true).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
@@ -106,6 +106,7 @@ func (r *symbolRepository) BatchUpsert(symbols []*models.Symbol) (successCount,
 			if r.shouldUpdate(existingSymbol, symbol) {
 				existingSymbol.Name = symbol.Name
 				existingSymbol.Market = symbol.Market
+				existingSymbol.Industry = symbol.Industry
 				if err := tx.Save(existingSymbol).Error; err != nil {
 					errorCount++
 					continue
@@ -168,5 +169,5 @@ func (r *symbolRepository) getBySymbolAndMarketTx(tx *gorm.DB, symbol, market st
 
 // shouldUpdate 判斷是否需要更新股票資料
 func (r *symbolRepository) shouldUpdate(existing, new *models.Symbol) bool {
-	return existing.Name != new.Name || existing.Market != new.Market
+	return existing.Name != new.Name || existing.Market != new.Market || existing.Industry != new.Industry
 }
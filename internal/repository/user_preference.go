@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"gorm.io/gorm"
+)
+
+type UserPreferenceRepository interface {
+	GetByUserID(userID uint) (*models.UserPreference, error)
+	Save(preference *models.UserPreference) error
+}
+
+type userPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewUserPreferenceRepository(db *gorm.DB) UserPreferenceRepository {
+	return &userPreferenceRepository{db: db}
+}
+
+// GetByUserID 根據使用者 ID 取得偏好設定
+func (r *userPreferenceRepository) GetByUserID(userID uint) (*models.UserPreference, error) {
+	var preference models.UserPreference
+	err := r.db.Where("user_id = ?", userID).First(&preference).Error
+	if err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// Save 建立或更新偏好設定
+func (r *userPreferenceRepository) Save(preference *models.UserPreference) error {
+	return r.db.Save(preference).Error
+}
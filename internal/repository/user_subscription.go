@@ -15,6 +15,7 @@ type UserSubscriptionRepository interface {
 	AddUserSubscriptionItem(userID uint, item models.SubscriptionItem) error
 	UpdateUserSubscriptionItem(userID uint, item models.SubscriptionItem, status bool) error
 	GetUserSubscriptionList(userID uint) ([]*models.Subscription, error)
+	UpdateUserSubscriptionSchedule(userID uint, item models.SubscriptionItem, scheduleCron, timezone string) error
 	// 訂閱股票相關
 	AddUserSubscriptionStock(userID uint, stockSymbol string) (bool, error)
 	DeleteUserSubscriptionStock(userID uint, stockSymbol string) (bool, error)
@@ -103,6 +104,20 @@ func (r *userSubscriptionRepository) UpdateUserSubscriptionItem(userID uint, ite
 		Update("status", status).Error
 }
 
+// UpdateUserSubscriptionSchedule 更新使用者訂閱項目的排程及時區
+func (r *userSubscriptionRepository) UpdateUserSubscriptionSchedule(userID uint, item models.SubscriptionItem, scheduleCron, timezone string) error {
+	// 先取得 feature
+	var feature models.Feature
+	itemCode := fmt.Sprintf("%d", int(item))
+	if err := r.db.Where("code = ?", itemCode).First(&feature).Error; err != nil {
+		return err
+	}
+
+	return r.db.Model(&models.Subscription{}).
+		Where("user_id = ? AND feature_id = ?", userID, feature.ID).
+		Updates(map[string]any{"schedule_cron": scheduleCron, "timezone": timezone}).Error
+}
+
 // GetUserSubscriptionList 取得使用者訂閱項目列表
 func (r *userSubscriptionRepository) GetUserSubscriptionList(userID uint) ([]*models.Subscription, error) {
 	var subscriptions []*models.Subscription
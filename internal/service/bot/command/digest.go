@@ -0,0 +1,319 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	"github.com/tian841224/stock-bot/pkg/imageutil"
+
+	"go.uber.org/zap"
+)
+
+const (
+	digestNewsPerSymbol = 2   // 每檔股票顯示的新聞數
+	digestTopVolume     = 5   // 顯示的交易量排行筆數
+	digestChartSymbols  = 4   // 圖表拼貼最多股票數
+	digestChartWidth    = 600 // 拼貼圖每格寬度
+	digestChartHeight   = 400 // 拼貼圖每格高度
+)
+
+// digestModes 摘要模式參數
+var digestModes = map[string]models.DigestMode{
+	"on":    models.DigestModeText,
+	"chart": models.DigestModeChart,
+	"off":   models.DigestModeOff,
+	"開啟":    models.DigestModeText,
+	"圖表":    models.DigestModeChart,
+	"關閉":    models.DigestModeOff,
+}
+
+// registerDigestCommands 註冊摘要模式指令
+func (r *commandRegistry) registerDigestCommands() {
+	r.Register(&Command{
+		Name:        "digest",
+		Category:    CategorySubscription,
+		Description: "摘要模式，排程推播合併為一則訊息 (on:開啟 chart:開啟並附圖表 off:分別推播)",
+		Example:     "/digest chart",
+		ExampleNote: "合併推播並附上自選股K線拼貼圖",
+		Args:        []ArgSpec{{Key: "mode", Name: "模式", Type: ArgString}},
+		Handler:     r.digest,
+	})
+}
+
+// digest 處理 /digest 命令 - 未指定模式時顯示目前設定
+func (r *commandRegistry) digest(ctx *Context, args Args) (*Response, error) {
+	input := strings.ToLower(args.String("mode"))
+	if input == "" {
+		preference, err := r.userPreferenceService.GetUserPreference(ctx.UserID)
+		if err != nil {
+			r.logger.Error("取得摘要模式失敗", zap.Error(err))
+			return nil, fmt.Errorf("操作失敗，請稍後再試")
+		}
+		return &Response{
+			Title:  "📋 摘要模式",
+			Blocks: []Block{{Text: "目前設定：" + digestModeName(preference.DigestMode)}},
+			Buttons: [][]Button{{
+				CommandButton("開啟", "/digest on"),
+				CommandButton("附圖表", "/digest chart"),
+				CommandButton("分別推播", "/digest off"),
+			}},
+		}, nil
+	}
+
+	// 變更設定才需要群組管理員權限
+	if err := ctx.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	mode, ok := digestModes[input]
+	if !ok {
+		return nil, fmt.Errorf("無效的模式: %s，請輸入 on、chart 或 off", input)
+	}
+	if err := r.updatePreference(ctx.UserID, func(preference *models.UserPreference) { preference.DigestMode = mode }); err != nil {
+		r.logger.Error("更新摘要模式失敗", zap.Error(err))
+		return nil, fmt.Errorf("操作失敗，請稍後再試")
+	}
+	return NewTextResponse("已設定推播方式：" + digestModeName(mode)), nil
+}
+
+// digestModeName 摘要模式說明
+func digestModeName(mode models.DigestMode) string {
+	switch mode {
+	case models.DigestModeText:
+		return "合併為一則摘要"
+	case models.DigestModeChart:
+		return "合併為一則摘要並附圖表"
+	default:
+		return "各項目分別推播"
+	}
+}
+
+// DigestBuilder 將使用者的訂閱項目合併為單一摘要訊息
+// 同一批次推播中相同資料只查詢一次，不可同時於多個 goroutine 使用
+type DigestBuilder struct {
+	registry *commandRegistry
+	date     string
+	sections map[string]*Block // 以項目及股票代號為鍵的區塊內容，nil 表示查詢失敗
+	charts   map[string][]byte
+}
+
+// NewDigestBuilder 建立摘要產生器
+func (r *commandRegistry) NewDigestBuilder() *DigestBuilder {
+	return &DigestBuilder{
+		registry: r,
+		date:     DefaultQuoteDate(r.tradingCalendar),
+		sections: make(map[string]*Block),
+		charts:   make(map[string][]byte),
+	}
+}
+
+// Build 依訂閱項目產生摘要，symbols 為使用者訂閱的股票，沒有任何內容時回傳 nil
+// news 為各股票尚未推播給此使用者的新聞，回傳實際列入摘要的新聞，由呼叫端於推播後記錄為已推播
+// 圖表摘要模式依使用者偏好的K線週期及圖表主題附上自選股圖表
+func (b *DigestBuilder) Build(items []models.SubscriptionItem, symbols []string, news map[string][]dto.TaiwanNewsResponseData, preference *models.UserPreference) (*Response, map[string][]dto.TaiwanNewsResponseData) {
+	items = append([]models.SubscriptionItem(nil), items...)
+	sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+
+	response := &Response{Title: "📋 每日摘要 " + strings.ReplaceAll(b.date, "-", "/")}
+	var rendered map[string][]dto.TaiwanNewsResponseData
+	for _, item := range items {
+		var block *Block
+		switch item {
+		case models.SubscriptionItemStockInfo:
+			block = b.symbolsBlock("📈 自選股收盤", symbols, b.priceFields)
+		case models.SubscriptionItemStockNews:
+			block, rendered = newsBlock(symbols, news)
+		case models.SubscriptionItemDailyMarketInfo:
+			block = b.cached("market", b.marketBlock)
+		case models.SubscriptionItemTopVolumeItems:
+			block = b.cached("top", b.topVolumeBlock)
+		case models.SubscriptionItemTreasuryYield:
+			block = b.cached("yield", b.yieldBlock)
+		}
+		if block != nil {
+			response.Blocks = append(response.Blocks, *block)
+		}
+	}
+	if len(response.Blocks) == 0 {
+		return nil, nil
+	}
+
+	if preference.DigestMode == models.DigestModeChart {
+		response.Image = b.chartCollage(symbols, preference)
+	}
+	response.Buttons = [][]Button{{CommandButton("改為分別推播", "/digest off")}}
+	return response, rendered
+}
+
+// cached 取得區塊內容，同一批次只查詢一次
+func (b *DigestBuilder) cached(key string, load func() *Block) *Block {
+	if block, ok := b.sections[key]; ok {
+		return block
+	}
+	block := load()
+	b.sections[key] = block
+	return block
+}
+
+// symbolsBlock 合併每檔股票的欄位為單一區塊
+func (b *DigestBuilder) symbolsBlock(heading string, symbols []string, fields func(symbol string) *Block) *Block {
+	block := &Block{Heading: heading}
+	for _, symbol := range symbols {
+		if section := b.cached(heading+":"+symbol, func() *Block { return fields(symbol) }); section != nil {
+			block.Fields = append(block.Fields, section.Fields...)
+		}
+	}
+	if len(block.Fields) == 0 {
+		return nil
+	}
+	return block
+}
+
+// priceFields 單檔股票收盤摘要
+func (b *DigestBuilder) priceFields(symbol string) *Block {
+	stockInfo, err := b.registry.stockService.GetStockPrice(symbol, b.date)
+	if err != nil {
+		b.registry.logger.Error("取得股價資訊失敗", zap.String("symbol", symbol), zap.Error(err))
+		return nil
+	}
+	return &Block{Fields: []Field{{
+		Label:  fmt.Sprintf("%s (%s)", stockInfo.StockName, stockInfo.StockID),
+		Value:  fmt.Sprintf("%.2f %s%.2f (%s)", stockInfo.ClosePrice, stockInfo.UpDownSign, stockInfo.ChangeAmount, stockInfo.PercentageChange),
+		Trend:  TrendFromSign(stockInfo.UpDownSign),
+		Action: CommandAction("查看收盤資訊", "/d "+symbol),
+	}}}
+}
+
+// LimitDigestNews 摘要中每檔股票顯示的新聞
+func LimitDigestNews(news []dto.TaiwanNewsResponseData) []dto.TaiwanNewsResponseData {
+	if len(news) > digestNewsPerSymbol {
+		return news[:digestNewsPerSymbol]
+	}
+	return news
+}
+
+// newsBlock 自選股尚未推播過的新聞標題，沒有新新聞時不顯示，並回傳列入區塊的新聞
+func newsBlock(symbols []string, news map[string][]dto.TaiwanNewsResponseData) (*Block, map[string][]dto.TaiwanNewsResponseData) {
+	block := &Block{Heading: "⚡️ 自選股新聞"}
+	rendered := make(map[string][]dto.TaiwanNewsResponseData)
+	for _, symbol := range symbols {
+		for _, n := range LimitDigestNews(news[symbol]) {
+			link := URLButton(n.Title, n.Link)
+			block.Fields = append(block.Fields, Field{Label: symbol + " " + n.Title, Action: &link})
+			rendered[symbol] = append(rendered[symbol], n)
+		}
+	}
+	if len(block.Fields) == 0 {
+		return nil, nil
+	}
+	return block, rendered
+}
+
+// marketBlock 最近交易日大盤摘要
+func (b *DigestBuilder) marketBlock() *Block {
+	marketInfo, err := b.registry.stockService.GetDailyMarketInfo(1)
+	if err != nil || len(marketInfo.Data) == 0 {
+		b.registry.logger.Error("取得大盤資訊失敗", zap.Error(err))
+		return nil
+	}
+
+	// TWSE 的欄位順序為: ["日期", "成交股數", "成交金額", "成交筆數", "發行量加權股價指數", "漲跌點數"]
+	row := marketInfo.Data[len(marketInfo.Data)-1]
+	if len(row) < 6 {
+		return nil
+	}
+	change, _ := strconv.ParseFloat(strings.ReplaceAll(row[5], ",", ""), 64)
+	return &Block{
+		Heading: "🏛 大盤",
+		Fields: []Field{
+			{Label: "加權指數", Value: row[4]},
+			{Label: "漲跌點數", Value: row[5], Trend: TrendFromValue(change)},
+			{Label: "成交金額", Value: row[2]},
+		},
+		Trend: TrendFromValue(change),
+	}
+}
+
+// topVolumeBlock 交易量排行前幾名
+func (b *DigestBuilder) topVolumeBlock() *Block {
+	topItems, err := b.registry.stockService.GetTopVolumeItems()
+	if err != nil || len(topItems) == 0 {
+		b.registry.logger.Error("取得交易量前20名失敗", zap.Error(err))
+		return nil
+	}
+
+	block := &Block{Heading: fmt.Sprintf("🔝 交易量前%d名", digestTopVolume)}
+	for i, item := range topItems {
+		if i >= digestTopVolume {
+			break
+		}
+		block.Fields = append(block.Fields, Field{
+			Label:  fmt.Sprintf("%s (%s)", item.StockName, item.StockID),
+			Value:  fmt.Sprintf("%.2f %s%.2f", item.ClosePrice, item.UpDownSign, item.ChangeAmount),
+			Trend:  TrendFromSign(item.UpDownSign),
+			Action: CommandAction("查看收盤資訊", "/d "+item.StockID),
+		})
+	}
+	return block
+}
+
+// yieldBlock 美債殖利率利差摘要
+func (b *DigestBuilder) yieldBlock() *Block {
+	yield, err := b.registry.stockService.GetTreasuryYield()
+	if err != nil || len(yield.Spread) == 0 {
+		b.registry.logger.Error("取得美國公債殖利率失敗", zap.Error(err))
+		return nil
+	}
+
+	latest := yield.Spread[len(yield.Spread)-1]
+	spread := fmt.Sprintf("%+.2f", latest.Spread)
+	if latest.Spread < 0 {
+		spread += " (倒掛)"
+	}
+	return &Block{
+		Heading: "🇺🇸 美債殖利率",
+		Fields: []Field{
+			{Label: "2年期", Value: fmt.Sprintf("%.2f%%", latest.Yield2Y)},
+			{Label: "10年期", Value: fmt.Sprintf("%.2f%%", latest.Yield10Y)},
+			{Label: "10Y-2Y 利差", Value: spread, Trend: TrendFromValue(latest.Spread)},
+		},
+	}
+}
+
+// chartCollage 將自選股K線圖拼成一張圖片，失敗時不附圖
+// 相同股票、週期及主題的圖表同一批次只產生一次
+func (b *DigestBuilder) chartCollage(symbols []string, preference *models.UserPreference) *Image {
+	var charts [][]byte
+	for _, symbol := range symbols {
+		if len(charts) >= digestChartSymbols {
+			break
+		}
+		key := fmt.Sprintf("%s:%s:%s", symbol, preference.KLineTimeframe, preference.ChartTheme)
+		chart, ok := b.charts[key]
+		if !ok {
+			response, err := b.registry.candlesChart(symbol, preference)
+			if err == nil && response.Image != nil {
+				chart = response.Image.Data
+			}
+			b.charts[key] = chart
+		}
+		if len(chart) > 0 {
+			charts = append(charts, chart)
+		}
+	}
+	if len(charts) == 0 {
+		return nil
+	}
+
+	collage, err := imageutil.GenerateCollagePNG(charts, 2, digestChartWidth, digestChartHeight)
+	if err != nil {
+		b.registry.logger.Error("產生圖表拼貼失敗", zap.Error(err))
+		return nil
+	}
+	return &Image{Data: collage, Name: fmt.Sprintf("digest_%s.png", time.Now().Format("20060102"))}
+}
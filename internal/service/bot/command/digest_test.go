@@ -0,0 +1,86 @@
+package command
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	fugleDto "github.com/tian841224/stock-bot/internal/infrastructure/fugle/dto"
+	"github.com/tian841224/stock-bot/internal/service/twstock"
+)
+
+// fakeChartService 記錄產生K線圖時使用的週期及主題
+type fakeChartService struct {
+	twstock.StockService
+	requests []string
+}
+
+func (f *fakeChartService) GetStockHistoricalCandlesChart(dto fugleDto.FugleCandlesRequestDto, theme string) ([]byte, string, error) {
+	f.requests = append(f.requests, dto.Symbol+":"+dto.Timeframe+":"+theme)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), dto.Symbol, nil
+}
+
+func TestNewsBlock(t *testing.T) {
+	news := map[string][]dto.TaiwanNewsResponseData{
+		"2330": {
+			{Title: "台積電法說會", Link: "https://example.com/1"},
+			{Title: "台積電營收", Link: "https://example.com/2"},
+			{Title: "台積電股利", Link: "https://example.com/3"},
+		},
+		"2317": nil,
+	}
+
+	block, rendered := newsBlock([]string{"2317", "2330"}, news)
+	if block == nil {
+		t.Fatal("newsBlock() = nil, want block")
+	}
+	if len(block.Fields) != digestNewsPerSymbol {
+		t.Fatalf("fields = %d, want %d", len(block.Fields), digestNewsPerSymbol)
+	}
+	if got := block.Fields[0].Label; got != "2330 台積電法說會" {
+		t.Errorf("first field = %q", got)
+	}
+	if got := len(LimitDigestNews(news["2330"])); got != digestNewsPerSymbol {
+		t.Errorf("LimitDigestNews() = %d items, want %d", got, digestNewsPerSymbol)
+	}
+	// 只回傳列入區塊的新聞，未顯示的新聞下次仍會推播
+	if got := rendered["2330"]; len(got) != digestNewsPerSymbol || got[0].Title != "台積電法說會" {
+		t.Errorf("rendered news = %+v, want first %d", got, digestNewsPerSymbol)
+	}
+	if _, ok := rendered["2317"]; ok {
+		t.Error("rendered news should not include symbols without news")
+	}
+
+	// 沒有尚未推播的新聞時不顯示區塊
+	if block, _ := newsBlock([]string{"2317"}, news); block != nil {
+		t.Errorf("newsBlock() without unseen news = %+v, want nil", block)
+	}
+}
+
+func TestChartCollageUsesPreference(t *testing.T) {
+	charts := &fakeChartService{}
+	builder := &DigestBuilder{
+		registry: &commandRegistry{stockService: charts},
+		sections: make(map[string]*Block),
+		charts:   make(map[string][]byte),
+	}
+	dark := &models.UserPreference{ChartTheme: models.ChartThemeDark, KLineTimeframe: models.KLineTimeframeWeek}
+	light := models.DefaultUserPreference(2)
+
+	for _, preference := range []*models.UserPreference{dark, light, dark} {
+		if image := builder.chartCollage([]string{"2330"}, preference); image == nil {
+			t.Fatal("chartCollage() = nil, want collage")
+		}
+	}
+	want := []string{"2330:W:dark", "2330:D:light"}
+	if len(charts.requests) != len(want) || charts.requests[0] != want[0] || charts.requests[1] != want[1] {
+		t.Errorf("chart requests = %v, want %v", charts.requests, want)
+	}
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/tian841224/stock-bot/internal/service/exchange_rate_alert"
+	"github.com/tian841224/stock-bot/internal/service/twstock"
+)
+
+// registerExchangeRateAlertCommands 註冊匯率警示指令
+func (r *commandRegistry) registerExchangeRateAlertCommands() {
+	r.Register(&Command{
+		Name:        "fxalert",
+		Category:    CategoryAlert,
+		Description: "新增匯率警示 (參數: 幣別 匯率種類 條件 門檻值，不帶參數時列出已設定的警示)",
+		Example:     "/fxalert JPY cash_sell below 0.205",
+		ExampleNote: "日圓現金賣出低於0.205時通知",
+		Args:        []ArgSpec{{Key: "args", Name: "條件", Type: ArgList}},
+		Handler:     r.exchangeRateAlert,
+	})
+	r.Register(&Command{
+		Name:        "fxdel",
+		Category:    CategoryAlert,
+		Description: "刪除匯率警示",
+		Example:     "/fxdel 1",
+		Args:        []ArgSpec{{Key: "id", Name: "警示編號", Type: ArgInt, Required: true}},
+		AdminOnly:   true,
+		Handler:     r.deleteExchangeRateAlert,
+	})
+}
+
+// exchangeRateAlert 處理 /fxalert 命令 - 新增或查詢匯率警示
+func (r *commandRegistry) exchangeRateAlert(ctx *Context, args Args) (*Response, error) {
+	// 無參數時列出已設定的警示
+	if len(args.List("args")) == 0 {
+		alerts, err := r.exchangeRateAlertSvc.ListAlerts(ctx.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		response := &Response{Title: "🔔 您目前的匯率警示"}
+		if len(alerts) == 0 {
+			response.Blocks = []Block{
+				{Text: "• 尚未設定任何匯率警示"},
+				{Text: "新增方式：/fxalert JPY cash_sell below 0.205"},
+			}
+			return response, nil
+		}
+
+		table := &Table{}
+		for _, alert := range alerts {
+			table.Rows = append(table.Rows, []string{
+				fmt.Sprintf("#%d", alert.ID), alert.Currency, alert.RateType.GetName(), alert.Condition.GetName(), formatExchangeRate(alert.Threshold),
+			})
+		}
+		response.Blocks = []Block{{Table: table}, {Text: "刪除方式：/fxdel [編號]"}}
+		return response, nil
+	}
+
+	// 查詢不限制，新增警示需為群組管理員
+	if err := ctx.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	alert, err := r.exchangeRateAlertSvc.AddAlert(ctx.UserID, args.List("args"))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTextResponse(fmt.Sprintf("新增匯率警示成功 #%d：%s %s %s %s",
+		alert.ID, alert.Currency, alert.RateType.GetName(), alert.Condition.GetName(), formatExchangeRate(alert.Threshold))), nil
+}
+
+// deleteExchangeRateAlert 處理 /fxdel 命令 - 刪除匯率警示
+func (r *commandRegistry) deleteExchangeRateAlert(ctx *Context, args Args) (*Response, error) {
+	if err := r.exchangeRateAlertSvc.DeleteAlert(ctx.UserID, uint(args.Int("id"))); err != nil {
+		return nil, err
+	}
+	return NewTextResponse("刪除匯率警示成功"), nil
+}
+
+// TriggeredExchangeRateAlertResponse 匯率警示觸發通知
+func TriggeredExchangeRateAlertResponse(triggered exchange_rate_alert.TriggeredAlert) *Response {
+	alert := triggered.Alert
+	return &Response{
+		Title: "⏰ 匯率警示觸發",
+		Blocks: []Block{{Fields: []Field{
+			{Label: "日期", Value: triggered.Date},
+			{Label: "幣別", Value: fmt.Sprintf("%s %s", alert.Currency, twstock.SupportedCurrencies[alert.Currency])},
+			{Label: alert.RateType.GetName(), Value: formatExchangeRate(triggered.Rate)},
+			{Label: "條件", Value: fmt.Sprintf("%s %s", alert.Condition.GetName(), formatExchangeRate(alert.Threshold))},
+		}}},
+	}
+}
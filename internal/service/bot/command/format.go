@@ -0,0 +1,106 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// changeEmoji 依漲跌符號取得表情符號
+func changeEmoji(sign string) string {
+	switch sign {
+	case "+":
+		return "📈"
+	case "-":
+		return "📉"
+	default:
+		return ""
+	}
+}
+
+// formatExchangeRate 格式化匯率，無報價時顯示 -
+func formatExchangeRate(rate float64) string {
+	if rate <= 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
+
+// FormatTable 將表格轉為以空白對齊的文字，中文字以兩個字元寬計算，適合等寬字型顯示
+func FormatTable(table *Table) string {
+	rows := table.Rows
+	if len(table.Header) > 0 {
+		rows = append([][]string{table.Header}, rows...)
+	}
+
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := displayWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)+2))
+			}
+		}
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatFields 將欄位轉為「名稱：數值」的多行文字，無數值的欄位顯示為清單項目
+func FormatFields(fields []Field) string {
+	lines := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Value == "" {
+			lines = append(lines, "• "+field.Label)
+			continue
+		}
+		lines = append(lines, field.Label+"："+field.Value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TruncateRunes 依字元數截斷字串，超過時以 … 結尾
+func TruncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}
+
+// displayWidth 計算字串在等寬字型下的顯示寬度
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if isWideRune(r) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// isWideRune 判斷是否為全形字元（中日韓文字與全形符號）
+func isWideRune(r rune) bool {
+	return (r >= 0x1100 && r <= 0x115F) ||
+		(r >= 0x2E80 && r <= 0xA4CF) ||
+		(r >= 0xAC00 && r <= 0xD7A3) ||
+		(r >= 0xF900 && r <= 0xFAFF) ||
+		(r >= 0xFE30 && r <= 0xFE4F) ||
+		(r >= 0xFF00 && r <= 0xFF60) ||
+		(r >= 0xFFE0 && r <= 0xFFE6)
+}
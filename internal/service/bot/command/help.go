@@ -0,0 +1,53 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+)
+
+// registerHelpCommands 註冊說明指令
+func (r *commandRegistry) registerHelpCommands() {
+	r.Register(&Command{
+		Name:        "start",
+		Description: "指令指南",
+		Handler:     r.help,
+	})
+}
+
+// help 處理 /start 命令 - 依註冊的指令產生說明
+func (r *commandRegistry) help(ctx *Context, args Args) (*Response, error) {
+	commands := r.Commands()
+
+	var blocks []Block
+	for _, category := range categoryOrder {
+		var lines []string
+		for _, cmd := range commands {
+			if cmd.Category != category {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("- %s - %s", cmd.Usage(), cmd.Description))
+		}
+		// 行內查詢僅 Telegram 支援
+		if category == CategoryStock && ctx.Platform == models.UserTypeTelegram {
+			lines = append(lines, "- @機器人 [股票代號] - 在任何聊天室行內查詢股價及K線圖")
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		blocks = append(blocks, Block{Heading: string(category), Text: strings.Join(lines, "\n")})
+	}
+
+	var examples []string
+	for _, cmd := range commands {
+		if cmd.Example != "" && cmd.ExampleNote != "" {
+			examples = append(examples, fmt.Sprintf("%s - %s", cmd.Example, cmd.ExampleNote))
+		}
+	}
+	if len(examples) > 0 {
+		blocks = append(blocks, Block{Heading: "💡 使用範例：", Text: strings.Join(examples, "\n")})
+	}
+
+	return &Response{Title: "台股機器人指令指南🤖", Blocks: blocks}, nil
+}
@@ -0,0 +1,209 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/service/job_run"
+
+	"go.uber.org/zap"
+)
+
+// jobStatusIcons 排程執行狀態圖示
+var jobStatusIcons = map[models.JobRunStatus]string{
+	models.JobRunStatusPending: "⏳",
+	models.JobRunStatusRunning: "🔄",
+	models.JobRunStatusSuccess: "✅",
+	models.JobRunStatusFailed:  "❌",
+}
+
+// jobInterruptedIcon 執行中但執行權已到期，排程器於執行期間中斷
+const jobInterruptedIcon = "⚠️"
+
+// registerJobCommands 註冊排程管理指令，僅系統管理員可執行，不列入說明
+func (r *commandRegistry) registerJobCommands() {
+	r.Register(&Command{
+		Name:         "jobs",
+		Description:  "排程執行紀錄 (不帶參數時列出各排程最近一次執行)",
+		Example:      "/jobs news_alert",
+		Args:         []ArgSpec{{Key: "name", Name: "排程名稱", Type: ArgText}},
+		OperatorOnly: true,
+		Handler:      r.jobs,
+	})
+	r.Register(&Command{
+		Name:         "runjob",
+		Description:  "手動執行排程",
+		Example:      "/runjob news",
+		Args:         []ArgSpec{{Key: "name", Name: "排程名稱", Type: ArgText}},
+		OperatorOnly: true,
+		Handler:      r.runJob,
+	})
+}
+
+// jobs 處理 /jobs 命令 - 查詢排程執行紀錄
+func (r *commandRegistry) jobs(ctx *Context, args Args) (*Response, error) {
+	name := job_run.NormalizeJobName(args.String("name"))
+	if name != "" {
+		return r.jobHistory(name)
+	}
+
+	runs, err := r.jobRunService.Latest()
+	if err != nil {
+		r.logger.Error("取得排程執行紀錄失敗", zap.Error(err))
+		return nil, fmt.Errorf("操作失敗，請稍後再試")
+	}
+
+	response := &Response{Title: "🗓 排程執行紀錄"}
+	if len(runs) == 0 {
+		response.Blocks = []Block{{Text: "• 尚無執行紀錄"}}
+		return response, nil
+	}
+
+	now := time.Now()
+	table := &Table{Header: []string{"排程", "狀態", "時間", "數量"}}
+	var retry []Button
+	for _, run := range runs {
+		icon := jobStatusIcons[run.Status]
+		if run.Interrupted(now) {
+			icon = jobInterruptedIcon
+		}
+		table.Rows = append(table.Rows, []string{run.JobName, icon, formatJobTime(run.ScheduledAt), fmt.Sprintf("%d", run.Count)})
+		failed := run.Status == models.JobRunStatusFailed || run.Interrupted(now)
+		if failed && job_run.IsTriggerable(run.JobName) {
+			retry = append(retry, CommandButton("🔁 "+run.JobName, "/runjob "+run.JobName))
+		}
+	}
+	response.Blocks = []Block{{Table: table}, {Text: "查詢單一排程：/jobs [排程名稱]\n手動執行：/runjob [排程名稱]"}}
+	if len(retry) > 0 {
+		response.Buttons = [][]Button{retry}
+	}
+	return response, nil
+}
+
+// jobHistory 單一排程最近的執行紀錄，失敗時顯示錯誤訊息
+func (r *commandRegistry) jobHistory(name string) (*Response, error) {
+	runs, err := r.jobRunService.History(name)
+	if err != nil {
+		r.logger.Error("取得排程執行紀錄失敗", zap.String("job", name), zap.Error(err))
+		return nil, fmt.Errorf("操作失敗，請稍後再試")
+	}
+
+	response := &Response{Title: "🗓 排程執行紀錄：" + name}
+	if len(runs) == 0 {
+		response.Blocks = []Block{{Text: "• 尚無執行紀錄"}}
+	}
+	now := time.Now()
+	for _, run := range runs {
+		status := jobStatusIcons[run.Status] + " " + string(run.Status)
+		if run.Interrupted(now) {
+			status = jobInterruptedIcon + " 排程器中斷"
+		}
+		fields := []Field{
+			{Label: "狀態", Value: status},
+			{Label: "數量", Value: fmt.Sprintf("%d", run.Count)},
+		}
+		if run.FinishedAt != nil {
+			fields = append(fields, Field{Label: "耗時", Value: run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()})
+		}
+		if run.Instance != "" {
+			fields = append(fields, Field{Label: "排程器", Value: run.Instance})
+		}
+		if run.TriggeredBy != "" {
+			fields = append(fields, Field{Label: "手動執行", Value: run.TriggeredBy})
+		}
+		response.Blocks = append(response.Blocks, Block{
+			Heading: formatJobTime(run.ScheduledAt),
+			Fields:  fields,
+			Text:    run.Error,
+		})
+	}
+	if job_run.IsTriggerable(name) {
+		response.Buttons = [][]Button{{CommandButton("▶️ 手動執行", "/runjob "+name)}}
+	}
+	return response, nil
+}
+
+// runJob 處理 /runjob 命令 - 建立手動執行請求，由排程器執行
+func (r *commandRegistry) runJob(ctx *Context, args Args) (*Response, error) {
+	name := job_run.NormalizeJobName(args.String("name"))
+	if name == "" {
+		return r.triggerableJobsResponse(), nil
+	}
+
+	run, err := r.jobRunService.Trigger(name, ctx.AccountID)
+	if errors.Is(err, job_run.ErrUnknownJob) {
+		return nil, fmt.Errorf("無效的排程名稱: %s\n\n可手動執行的排程：\n%s", name, triggerableJobNames())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("操作失敗，請稍後再試")
+	}
+	return NewTextResponse(fmt.Sprintf("已建立手動執行請求 #%d：%s\n排程器將於數秒內執行，可使用 /jobs %s 查詢結果", run.ID, name, name)), nil
+}
+
+// triggerableJobsResponse 可手動執行的排程及執行按鈕，推播時間排程取自已有執行紀錄的排程
+func (r *commandRegistry) triggerableJobsResponse() *Response {
+	response := &Response{Title: "▶️ 手動執行排程"}
+	fields := make([]Field, 0, len(job_run.TriggerableJobs))
+	for _, job := range job_run.TriggerableJobs {
+		fields = append(fields, Field{Label: job.Name, Value: job.Description})
+		response.Buttons = append(response.Buttons, []Button{CommandButton("▶️ "+job.Description, "/runjob "+job.Name)})
+	}
+	response.Blocks = []Block{{Fields: fields}}
+
+	runs, err := r.jobRunService.Latest()
+	if err != nil {
+		r.logger.Error("取得排程執行紀錄失敗", zap.Error(err))
+		return response
+	}
+	var slots []string
+	for _, run := range runs {
+		job, timezone, spec, ok := job_run.ParseSlotJob(run.JobName)
+		if !ok || !job_run.IsTriggerable(run.JobName) {
+			continue
+		}
+		slots = append(slots, "• "+run.JobName)
+		response.Buttons = append(response.Buttons, []Button{CommandButton(fmt.Sprintf("▶️ %s %s %s", jobDescription(job), timezone, spec), "/runjob "+run.JobName)})
+	}
+	if len(slots) > 0 {
+		response.Blocks = append(response.Blocks, Block{
+			Heading: "推播時間排程（只推播該推播時間的訂閱者）",
+			Text:    strings.Join(slots, "\n"),
+		})
+	}
+	return response
+}
+
+// jobDescription 排程說明，未列於可手動執行排程的名稱原樣回傳
+func jobDescription(name string) string {
+	if name == job_run.JobDigest {
+		return "摘要"
+	}
+	for _, job := range job_run.TriggerableJobs {
+		if job.Name == name {
+			return job.Description
+		}
+	}
+	return name
+}
+
+// triggerableJobNames 可手動執行的排程名稱清單
+func triggerableJobNames() string {
+	lines := make([]string, 0, len(job_run.TriggerableJobs)+1)
+	for _, job := range job_run.TriggerableJobs {
+		lines = append(lines, fmt.Sprintf("• %s：%s", job.Name, job.Description))
+	}
+	lines = append(lines, fmt.Sprintf("• [排程]:[時區]:[推播時間]：只推播該推播時間的訂閱者，例如 %s", job_run.SlotJobName(job_run.JobStockNews, "Asia/Taipei", "0 0 15 * * 1-5")))
+	return strings.Join(lines, "\n")
+}
+
+// formatJobTime 以台北時間顯示排程時間
+func formatJobTime(t time.Time) string {
+	taipeiLocation, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		return t.Format("01/02 15:04")
+	}
+	return t.In(taipeiLocation).Format("01/02 15:04")
+}
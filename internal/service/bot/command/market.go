@@ -0,0 +1,255 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/service/twstock"
+	stockDto "github.com/tian841224/stock-bot/internal/service/twstock/dto"
+
+	"go.uber.org/zap"
+)
+
+// 交易量排行每頁顯示筆數
+const topVolumePageSize = 5
+
+// registerMarketCommands 註冊市場總覽指令
+func (r *commandRegistry) registerMarketCommands() {
+	r.Register(&Command{
+		Name:        "m",
+		Category:    CategoryMarket,
+		Description: "查詢最新大盤資訊 (數量可省略，預設1筆)",
+		Example:     "/m 3",
+		ExampleNote: "查詢最新3筆大盤資訊",
+		Args:        []ArgSpec{{Key: "count", Name: "數量", Type: ArgInt, Default: "1"}},
+		Handler:     r.dailyMarketInfo,
+	})
+	r.Register(&Command{
+		Name:        "t",
+		Category:    CategoryMarket,
+		Description: "查詢當日交易量前20名 (可翻頁)",
+		Example:     "/t 2",
+		Args:        []ArgSpec{{Key: "page", Name: "頁數", Type: ArgInt, Default: "1"}},
+		Handler:     r.topVolumeItems,
+	})
+	r.Register(&Command{
+		Name:        "fx",
+		Category:    CategoryMarket,
+		Description: "查詢牌告匯率及近三個月走勢",
+		Example:     "/fx USD",
+		ExampleNote: "查詢美元匯率",
+		Args:        []ArgSpec{{Key: "currency", Name: "幣別", Type: ArgString, Required: true}},
+		Handler:     r.exchangeRate,
+	})
+	r.Register(&Command{
+		Name:        "yield",
+		Category:    CategoryMarket,
+		Description: "查詢美國公債殖利率曲線及利差",
+		Handler:     r.treasuryYield,
+	})
+	r.Register(&Command{
+		Name:        "heat",
+		Category:    CategoryMarket,
+		Description: "產業熱力圖 (依成交金額及漲跌幅，日期可省略)",
+		Example:     "/heat 2025-01-15",
+		Args:        []ArgSpec{{Key: "date", Name: "日期", Type: ArgDate}},
+		Handler:     r.heatmap,
+	})
+}
+
+// dailyMarketInfo 處理 /m 命令 - 大盤資訊
+func (r *commandRegistry) dailyMarketInfo(ctx *Context, args Args) (*Response, error) {
+	marketInfo, err := r.stockService.GetDailyMarketInfo(args.Int("count"))
+	if err != nil {
+		r.logger.Error("取得大盤資訊失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	response := &Response{Title: "台灣股市大盤資訊"}
+	if len(marketInfo.Fields) == 0 {
+		response.Blocks = []Block{{Text: "查無資料"}}
+		return response, nil
+	}
+
+	// TWSE 的欄位順序為: ["日期", "成交股數", "成交金額", "成交筆數", "發行量加權股價指數", "漲跌點數"]
+	for _, row := range marketInfo.Data {
+		if len(row) < 6 {
+			continue // 跳過資料不完整的行
+		}
+		change, _ := strconv.ParseFloat(strings.ReplaceAll(row[5], ",", ""), 64)
+		response.Blocks = append(response.Blocks, Block{
+			Heading: row[0],
+			Fields: []Field{
+				{Label: "成交股數", Value: row[1]},
+				{Label: "成交金額", Value: row[2]},
+				{Label: "成交筆數", Value: row[3]},
+				{Label: "發行量加權股價指數", Value: row[4]},
+				{Label: "漲跌點數", Value: row[5], Trend: TrendFromValue(change)},
+			},
+			Trend: TrendFromValue(change),
+		})
+	}
+	return response, nil
+}
+
+// topVolumeItems 處理 /t 命令 - 交易量前20名，分頁顯示
+func (r *commandRegistry) topVolumeItems(ctx *Context, args Args) (*Response, error) {
+	topItems, err := r.stockService.GetTopVolumeItems()
+	if err != nil {
+		r.logger.Error("取得交易量前20名失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+	if len(topItems) == 0 {
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	// 頁數從 1 開始，超出範圍時取最近的一頁
+	totalPages := (len(topItems) + topVolumePageSize - 1) / topVolumePageSize
+	page := args.Int("page")
+	if page < 1 {
+		page = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+
+	start := (page - 1) * topVolumePageSize
+	end := start + topVolumePageSize
+	if end > len(topItems) {
+		end = len(topItems)
+	}
+
+	response := &Response{
+		Title:   fmt.Sprintf("🔝今日交易量前二十 (第 %d-%d 名)", start+1, end),
+		Buttons: paginationButtons("/t", page, totalPages),
+		Replace: true,
+	}
+	for _, item := range topItems[start:end] {
+		response.Blocks = append(response.Blocks, topVolumeBlock(item))
+	}
+	return response, nil
+}
+
+// topVolumeBlock 單筆交易量排行資料
+func topVolumeBlock(item *stockDto.StockPriceInfo) Block {
+	return Block{
+		Heading: fmt.Sprintf("%s%s (%s)", changeEmoji(item.UpDownSign), item.StockName, item.StockID),
+		Fields: []Field{
+			{Label: "成交股數", Value: item.Volume},
+			{Label: "成交筆數", Value: item.Transaction},
+			{Label: "開盤價", Value: fmt.Sprintf("%.2f", item.OpenPrice)},
+			{Label: "收盤價", Value: fmt.Sprintf("%.2f", item.ClosePrice)},
+			{Label: "漲跌幅", Value: fmt.Sprintf("%s%.2f (%s)", item.UpDownSign, item.ChangeAmount, item.PercentageChange), Trend: TrendFromSign(item.UpDownSign)},
+			{Label: "最高價", Value: fmt.Sprintf("%.2f", item.HighPrice)},
+			{Label: "最低價", Value: fmt.Sprintf("%.2f", item.LowPrice)},
+		},
+		Trend:  TrendFromSign(item.UpDownSign),
+		Action: CommandAction("查看收盤資訊", "/d "+item.StockID),
+	}
+}
+
+// paginationButtons 分頁按鈕，page 從 1 開始
+func paginationButtons(command string, page, totalPages int) [][]Button {
+	if totalPages <= 1 {
+		return nil
+	}
+
+	var row []Button
+	if page > 1 {
+		row = append(row, CommandButton("⬅️ 上一頁", command+" "+strconv.Itoa(page-1)))
+	}
+	row = append(row, LabelButton(fmt.Sprintf("%d/%d", page, totalPages)))
+	if page < totalPages {
+		row = append(row, CommandButton("下一頁 ➡️", command+" "+strconv.Itoa(page+1)))
+	}
+	return [][]Button{row}
+}
+
+// exchangeRate 處理 /fx 命令 - 匯率報價與近三個月走勢圖
+func (r *commandRegistry) exchangeRate(ctx *Context, args Args) (*Response, error) {
+	currency := strings.ToUpper(args.String("currency"))
+	currencyName, ok := twstock.SupportedCurrencies[currency]
+	if !ok {
+		return nil, fmt.Errorf("不支援的幣別: %s", currency)
+	}
+
+	latest, err := r.stockService.GetLatestExchangeRate(currency)
+	if err != nil {
+		r.logger.Error("取得匯率失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	response := &Response{
+		Title: fmt.Sprintf("💱 %s (%s) 台灣銀行牌告匯率", currencyName, currency),
+		Blocks: []Block{{Fields: []Field{
+			{Label: "日期", Value: latest.Date},
+			{Label: "現金買入", Value: formatExchangeRate(latest.CashBuy)},
+			{Label: "現金賣出", Value: formatExchangeRate(latest.CashSell)},
+			{Label: "即期買入", Value: formatExchangeRate(latest.SpotBuy)},
+			{Label: "即期賣出", Value: formatExchangeRate(latest.SpotSell)},
+		}}},
+	}
+
+	// 走勢圖失敗時仍回傳報價
+	chart, err := r.stockService.GetExchangeRateChart(currency)
+	if err != nil {
+		r.logger.Error("取得匯率走勢圖失敗", zap.Error(err))
+		return response, nil
+	}
+
+	response.Image = &Image{Data: chart, Name: "exchange_rate.png"}
+	return response, nil
+}
+
+// treasuryYield 處理 /yield 命令 - 美國公債殖利率曲線與 10Y-2Y 利差
+func (r *commandRegistry) treasuryYield(ctx *Context, args Args) (*Response, error) {
+	yield, err := r.stockService.GetTreasuryYield()
+	if err != nil {
+		r.logger.Error("取得美國公債殖利率失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	fields := make([]Field, 0, len(yield.Curve))
+	for _, point := range yield.Curve {
+		fields = append(fields, Field{Label: point.Label, Value: fmt.Sprintf("%.2f%%", point.Yield)})
+	}
+	response := &Response{
+		Title:  fmt.Sprintf("🇺🇸 美國公債殖利率 (%s)", yield.Date),
+		Blocks: []Block{{Fields: fields}},
+	}
+
+	if len(yield.Spread) > 0 {
+		latest := yield.Spread[len(yield.Spread)-1]
+		spread := fmt.Sprintf("10Y-2Y 利差：%+.2f", latest.Spread)
+		if latest.Spread < 0 {
+			spread += " (倒掛)"
+		}
+		response.Blocks = append(response.Blocks, Block{Text: spread})
+	}
+
+	// 圖表失敗時仍回傳文字
+	chart, err := r.stockService.GetTreasuryYieldChart(yield)
+	if err != nil {
+		r.logger.Error("產生殖利率圖表失敗", zap.Error(err))
+		return response, nil
+	}
+
+	response.Image = &Image{Data: chart, Name: "treasury_yield.png"}
+	return response, nil
+}
+
+// heatmap 處理 /heat 命令 - 產業熱力圖
+func (r *commandRegistry) heatmap(ctx *Context, args Args) (*Response, error) {
+	chart, title, err := r.stockService.GetMarketHeatmap(args.String("date"))
+	if err != nil {
+		r.logger.Error("取得產業熱力圖失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	return &Response{
+		Title:  "🗺 " + title,
+		Blocks: []Block{{Text: "方塊大小為成交金額，紅漲綠跌"}},
+		Image:  &Image{Data: chart, Name: "heatmap.png"},
+	}, nil
+}
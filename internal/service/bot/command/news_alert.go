@@ -0,0 +1,96 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	"github.com/tian841224/stock-bot/internal/service/stock_news"
+)
+
+// registerNewsAlertCommands 註冊新聞關鍵字警示指令
+func (r *commandRegistry) registerNewsAlertCommands() {
+	r.Register(&Command{
+		Name:        "newsalert",
+		Category:    CategoryAlert,
+		Description: "新增新聞關鍵字警示，標題包含所有關鍵字時通知 (不帶參數時列出已設定的警示)",
+		Example:     "/newsalert 台積電 法說會",
+		ExampleNote: "任何新聞標題同時出現「台積電」及「法說會」時通知",
+		Args:        []ArgSpec{{Key: "keywords", Name: "關鍵字", Type: ArgList}},
+		Handler:     r.newsAlert,
+	})
+	r.Register(&Command{
+		Name:        "newsdel",
+		Category:    CategoryAlert,
+		Description: "刪除新聞關鍵字警示",
+		Example:     "/newsdel 1",
+		Args:        []ArgSpec{{Key: "id", Name: "警示編號", Type: ArgInt, Required: true}},
+		AdminOnly:   true,
+		Handler:     r.deleteNewsAlert,
+	})
+}
+
+// newsAlert 處理 /newsalert 命令 - 新增或查詢新聞關鍵字警示
+func (r *commandRegistry) newsAlert(ctx *Context, args Args) (*Response, error) {
+	// 無參數時列出已設定的警示
+	if len(args.List("keywords")) == 0 {
+		alerts, err := r.stockNewsSvc.ListAlerts(ctx.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		response := &Response{Title: "📰 您目前的新聞警示"}
+		if len(alerts) == 0 {
+			response.Blocks = []Block{
+				{Text: "• 尚未設定任何新聞警示"},
+				{Text: "新增方式：/newsalert 台積電 法說會"},
+			}
+			return response, nil
+		}
+
+		table := &Table{}
+		for _, alert := range alerts {
+			table.Rows = append(table.Rows, []string{fmt.Sprintf("#%d", alert.ID), alert.Keywords})
+		}
+		response.Blocks = []Block{{Table: table}, {Text: "刪除方式：/newsdel [編號]"}}
+		return response, nil
+	}
+
+	// 查詢不限制，新增警示需為群組管理員
+	if err := ctx.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	alert, err := r.stockNewsSvc.AddAlert(ctx.UserID, args.List("keywords"))
+	if err != nil {
+		return nil, err
+	}
+	return NewTextResponse(fmt.Sprintf("新增新聞警示成功 #%d：%s", alert.ID, alert.Keywords)), nil
+}
+
+// deleteNewsAlert 處理 /newsdel 命令 - 刪除新聞關鍵字警示
+func (r *commandRegistry) deleteNewsAlert(ctx *Context, args Args) (*Response, error) {
+	if err := r.stockNewsSvc.DeleteAlert(ctx.UserID, uint(args.Int("id"))); err != nil {
+		return nil, err
+	}
+	return NewTextResponse("刪除新聞警示成功"), nil
+}
+
+// TriggeredNewsAlertResponse 新聞關鍵字警示通知
+func TriggeredNewsAlertResponse(triggered stock_news.TriggeredAlert) *Response {
+	response := &Response{
+		Title:  "📰 新聞警示：" + strings.Join(triggered.Keywords, "、"),
+		Blocks: []Block{{Text: fmt.Sprintf("有 %d 則符合關鍵字的新聞", len(triggered.News))}},
+	}
+	response.Buttons = newsButtons(triggered.News)
+	return response
+}
+
+// newsButtons 每則新聞一列開啟連結的按鈕
+func newsButtons(news []dto.TaiwanNewsResponseData) [][]Button {
+	buttons := make([][]Button, 0, len(news))
+	for _, n := range news {
+		buttons = append(buttons, []Button{URLButton(n.Title, n.Link)})
+	}
+	return buttons
+}
@@ -0,0 +1,393 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	"github.com/tian841224/stock-bot/internal/service/exchange_rate_alert"
+	"github.com/tian841224/stock-bot/internal/service/job_run"
+	"github.com/tian841224/stock-bot/internal/service/stock_news"
+	"github.com/tian841224/stock-bot/internal/service/symbol_search"
+	"github.com/tian841224/stock-bot/internal/service/trading_calendar"
+	"github.com/tian841224/stock-bot/internal/service/twstock"
+	"github.com/tian841224/stock-bot/internal/service/user_preference"
+	"github.com/tian841224/stock-bot/internal/service/user_subscription"
+	"github.com/tian841224/stock-bot/pkg/logger"
+)
+
+// ErrUnknownCommand 訊息不是已註冊的指令
+var ErrUnknownCommand = errors.New("unknown command")
+
+// ArgType 指令參數型別
+type ArgType int
+
+const (
+	ArgString ArgType = iota // 單一字串
+	ArgSymbol                // 股票代號，支援名稱與拼音
+	ArgDate                  // 日期 YYYY-MM-DD
+	ArgInt                   // 正整數
+	ArgText                  // 其餘所有參數，以空白連接
+	ArgList                  // 其餘所有參數
+)
+
+// ArgSpec 指令參數定義
+type ArgSpec struct {
+	Key      string  // 取值用的鍵
+	Name     string  // 顯示名稱，用於說明與錯誤訊息
+	Type     ArgType // 參數型別
+	Required bool    // 是否必填
+	Default  string  // 未填時的預設值
+}
+
+// Category 指令分類，用於產生說明
+type Category string
+
+const (
+	CategoryChart        Category = "📊 圖表指令"
+	CategoryStock        Category = "📈 股票資訊指令"
+	CategoryMarket       Category = "📊 市場總覽指令"
+	CategoryAlert        Category = "⏰ 匯率及新聞警示"
+	CategorySubscription Category = "🔔 訂閱管理"
+)
+
+// categoryOrder 說明中分類的顯示順序
+var categoryOrder = []Category{CategoryChart, CategoryStock, CategoryMarket, CategoryAlert, CategorySubscription}
+
+// HandlerFunc 指令處理函式
+type HandlerFunc func(ctx *Context, args Args) (*Response, error)
+
+// Command 指令定義
+type Command struct {
+	Name         string    // 指令名稱，不含斜線
+	Category     Category  // 分類，空白時不列入說明
+	Description  string    // 說明
+	Example      string    // 使用範例，例如 /k 2330
+	ExampleNote  string    // 範例說明，有填寫時列入說明的使用範例
+	Args         []ArgSpec // 參數定義，依序解析
+	AdminOnly    bool      // 群組中僅管理員可執行，用於變更群組訂閱等設定
+	OperatorOnly bool      // 僅系統管理員可執行，用於排程管理等維運指令
+	Handler      HandlerFunc
+}
+
+// Usage 指令格式，例如 /d [股票代號] [日期]
+func (c *Command) Usage() string {
+	usage := "/" + c.Name
+	for _, arg := range c.Args {
+		if arg.Type == ArgText || arg.Type == ArgList {
+			usage += fmt.Sprintf(" [%s...]", arg.Name)
+			continue
+		}
+		usage += fmt.Sprintf(" [%s]", arg.Name)
+	}
+	return usage
+}
+
+// Context 指令執行時的使用者資訊
+type Context struct {
+	Platform  models.UserType // 來源平台
+	AccountID string          // 平台帳號
+	UserID    uint            // 資料庫使用者 ID，排程推播時為 0
+	Group     bool            // 群組聊天室，UserID 為群組本身
+	IsAdmin   func() bool     // 判斷群組中的發送者是否為管理員，僅在需要時呼叫，nil 時視為非管理員
+	Operator  bool            // 系統管理員，即設定的管理員聊天室
+}
+
+// ErrAdminOnly 群組中非管理員執行限管理員的指令
+var ErrAdminOnly = errors.New("僅群組管理員可變更群組設定")
+
+// ErrOperatorOnly 非系統管理員執行維運指令
+var ErrOperatorOnly = errors.New("僅系統管理員可執行此指令")
+
+// requireAdmin 群組中的發送者需為管理員，私人聊天不限制
+func (c *Context) requireAdmin() error {
+	if !c.Group {
+		return nil
+	}
+	if c.IsAdmin == nil || !c.IsAdmin() {
+		return ErrAdminOnly
+	}
+	return nil
+}
+
+// Args 解析後的指令參數
+type Args struct {
+	values map[string]string
+	lists  map[string][]string
+}
+
+// String 取得字串參數
+func (a Args) String(key string) string {
+	return a.values[key]
+}
+
+// Int 取得整數參數，未填時回傳 0
+func (a Args) Int(key string) int {
+	value, _ := strconv.Atoi(a.values[key])
+	return value
+}
+
+// List 取得清單參數
+func (a Args) List(key string) []string {
+	return a.lists[key]
+}
+
+// CommandRegistry 指令註冊表介面
+type CommandRegistry interface {
+	Register(cmd *Command)
+	Lookup(name string) (*Command, bool)
+	Commands() []*Command
+	Execute(ctx *Context, text string) (*Response, error)
+	// ExecuteNamed 以參數鍵對應的值執行指令，用於斜線指令等具名參數，未填的選填參數不影響其他參數
+	ExecuteNamed(ctx *Context, name string, values map[string]string) (*Response, error)
+	GetQuoteCard(symbol, date string) (*QuoteCard, error)
+	NewDigestBuilder() *DigestBuilder
+	StockNewsResponse(symbol string, news []dto.TaiwanNewsResponseData) *Response
+}
+
+type commandRegistry struct {
+	stockService            twstock.StockService
+	userSubscriptionService user_subscription.UserSubscriptionService
+	userPreferenceService   user_preference.UserPreferenceService
+	exchangeRateAlertSvc    exchange_rate_alert.ExchangeRateAlertService
+	stockNewsSvc            stock_news.StockNewsService
+	jobRunService           job_run.JobRunService
+	symbolSearch            symbol_search.SymbolSearchService
+	tradingCalendar         trading_calendar.TradingCalendarService
+	logger                  logger.Logger
+
+	mu       sync.RWMutex
+	commands map[string]*Command
+	order    []*Command
+}
+
+// NewCommandRegistry 建立指令註冊表並註冊內建指令
+func NewCommandRegistry(
+	stockService twstock.StockService,
+	userSubscriptionService user_subscription.UserSubscriptionService,
+	userPreferenceService user_preference.UserPreferenceService,
+	exchangeRateAlertSvc exchange_rate_alert.ExchangeRateAlertService,
+	stockNewsSvc stock_news.StockNewsService,
+	jobRunService job_run.JobRunService,
+	symbolSearch symbol_search.SymbolSearchService,
+	tradingCalendar trading_calendar.TradingCalendarService,
+	log logger.Logger,
+) CommandRegistry {
+	r := &commandRegistry{
+		stockService:            stockService,
+		userSubscriptionService: userSubscriptionService,
+		userPreferenceService:   userPreferenceService,
+		exchangeRateAlertSvc:    exchangeRateAlertSvc,
+		stockNewsSvc:            stockNewsSvc,
+		jobRunService:           jobRunService,
+		symbolSearch:            symbolSearch,
+		tradingCalendar:         tradingCalendar,
+		logger:                  log,
+		commands:                make(map[string]*Command),
+	}
+
+	r.registerHelpCommands()
+	r.registerStockCommands()
+	r.registerMarketCommands()
+	r.registerExchangeRateAlertCommands()
+	r.registerNewsAlertCommands()
+	r.registerSubscriptionCommands()
+	r.registerScheduleCommands()
+	r.registerDigestCommands()
+	r.registerSettingsCommands()
+	r.registerJobCommands()
+	return r
+}
+
+// Register 註冊指令，同名指令會被覆蓋
+func (r *commandRegistry) Register(cmd *Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.commands[cmd.Name]; ok {
+		for i, c := range r.order {
+			if c == existing {
+				r.order = append(r.order[:i], r.order[i+1:]...)
+				break
+			}
+		}
+	}
+	r.commands[cmd.Name] = cmd
+	r.order = append(r.order, cmd)
+}
+
+// Lookup 依名稱取得指令，名稱可含斜線
+func (r *commandRegistry) Lookup(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cmd, ok := r.commands[strings.TrimPrefix(strings.ToLower(name), "/")]
+	return cmd, ok
+}
+
+// Commands 依註冊順序取得所有指令
+func (r *commandRegistry) Commands() []*Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	commands := make([]*Command, len(r.order))
+	copy(commands, r.order)
+	return commands
+}
+
+// Execute 解析訊息並執行對應指令，非指令訊息回傳 ErrUnknownCommand
+func (r *commandRegistry) Execute(ctx *Context, text string) (*Response, error) {
+	parts := strings.Fields(text)
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "/") {
+		return nil, ErrUnknownCommand
+	}
+
+	cmd, ok := r.Lookup(parts[0])
+	if !ok {
+		return nil, ErrUnknownCommand
+	}
+	return r.run(ctx, cmd, positionalValues(cmd, parts[1:]))
+}
+
+// ExecuteNamed 以參數鍵對應的值執行指令
+func (r *commandRegistry) ExecuteNamed(ctx *Context, name string, values map[string]string) (*Response, error) {
+	cmd, ok := r.Lookup(name)
+	if !ok {
+		return nil, ErrUnknownCommand
+	}
+	return r.run(ctx, cmd, values)
+}
+
+// run 檢查權限並解析參數後執行指令
+func (r *commandRegistry) run(ctx *Context, cmd *Command, values map[string]string) (*Response, error) {
+	if cmd.AdminOnly {
+		if err := ctx.requireAdmin(); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.OperatorOnly && !ctx.Operator {
+		return nil, ErrOperatorOnly
+	}
+
+	args, err := r.parseArgs(cmd, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return cmd.Handler(ctx, args)
+}
+
+// positionalValues 依參數定義順序對應文字指令的參數，文字及清單參數取用其餘所有參數
+func positionalValues(cmd *Command, values []string) map[string]string {
+	named := make(map[string]string, len(cmd.Args))
+	for i, spec := range cmd.Args {
+		if i >= len(values) {
+			break
+		}
+		if spec.Type == ArgText || spec.Type == ArgList {
+			named[spec.Key] = strings.Join(values[i:], " ")
+			break
+		}
+		named[spec.Key] = values[i]
+	}
+	return named
+}
+
+// parseArgs 依參數定義解析並驗證參數，未填的參數使用預設值
+func (r *commandRegistry) parseArgs(cmd *Command, values map[string]string) (Args, error) {
+	args := Args{values: make(map[string]string), lists: make(map[string][]string)}
+
+	for _, spec := range cmd.Args {
+		value := strings.Join(strings.Fields(values[spec.Key]), " ")
+
+		switch spec.Type {
+		case ArgList:
+			if value == "" && spec.Required {
+				return args, missingArgError(cmd, spec)
+			}
+			args.lists[spec.Key] = strings.Fields(value)
+			continue
+		case ArgText:
+			if value == "" && spec.Required {
+				return args, missingArgError(cmd, spec)
+			}
+			args.values[spec.Key] = value
+			continue
+		}
+
+		if value == "" {
+			value = spec.Default
+		}
+		if value == "" {
+			if spec.Required {
+				return args, missingArgError(cmd, spec)
+			}
+			continue
+		}
+
+		parsed, err := r.parseArg(spec, value)
+		if err != nil {
+			return args, err
+		}
+		args.values[spec.Key] = parsed
+	}
+
+	return args, nil
+}
+
+// parseArg 依型別轉換單一參數
+func (r *commandRegistry) parseArg(spec ArgSpec, value string) (string, error) {
+	switch spec.Type {
+	case ArgSymbol:
+		return r.symbolSearch.Resolve(value)
+	case ArgDate:
+		if !isValidDateFormat(value) {
+			return "", fmt.Errorf("日期格式錯誤，請使用 YYYY-MM-DD 格式\n例如：2025-09-01")
+		}
+	case ArgInt:
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return "", fmt.Errorf("%s須為正整數", spec.Name)
+		}
+	}
+	return value, nil
+}
+
+// missingArgError 缺少必填參數時的提示
+func missingArgError(cmd *Command, spec ArgSpec) error {
+	message := fmt.Sprintf("請輸入%s\n\n使用方式：\n%s", spec.Name, cmd.Usage())
+	if cmd.Example != "" {
+		message += "\n例如：" + cmd.Example
+	}
+	return errors.New(message)
+}
+
+// isValidDateFormat 驗證日期格式是否為 YYYY-MM-DD
+func isValidDateFormat(date string) bool {
+	matched, err := regexp.MatchString(`^\d{4}-\d{2}-\d{2}$`, date)
+	if err != nil || !matched {
+		return false
+	}
+
+	// 嘗試解析日期以確保是有效日期
+	_, err = time.Parse("2006-01-02", date)
+	return err == nil
+}
+
+// DefaultQuoteDate 取得今日股價的預設日期，非交易日或開盤前回傳前一個交易日
+func DefaultQuoteDate(tradingCalendar trading_calendar.TradingCalendarService) string {
+	taipeiLocation, _ := time.LoadLocation("Asia/Taipei")
+	now := time.Now().In(taipeiLocation)
+	marketOpenTime := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, taipeiLocation)
+
+	if now.Before(marketOpenTime) || !tradingCalendar.IsTradingDay(now) {
+		return tradingCalendar.PreviousTradingDay(now).Format("2006-01-02")
+	}
+	return now.Format("2006-01-02")
+}
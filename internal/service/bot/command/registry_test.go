@@ -0,0 +1,140 @@
+package command
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestRegistry(handler HandlerFunc) CommandRegistry {
+	r := &commandRegistry{commands: make(map[string]*Command)}
+	r.Register(&Command{
+		Name:    "echo",
+		Example: "/echo 3 2025-01-02 a b",
+		Args: []ArgSpec{
+			{Key: "count", Name: "數量", Type: ArgInt, Required: true},
+			{Key: "date", Name: "日期", Type: ArgDate, Default: "2025-01-01"},
+			{Key: "rest", Name: "其他", Type: ArgList},
+		},
+		Handler: handler,
+	})
+	return r
+}
+
+func TestExecuteParsesTypedArgs(t *testing.T) {
+	var got Args
+	registry := newTestRegistry(func(ctx *Context, args Args) (*Response, error) {
+		got = args
+		return NewTextResponse("ok"), nil
+	})
+
+	if _, err := registry.Execute(&Context{}, "/ECHO 3 2025-01-02 a b"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got.Int("count") != 3 || got.String("date") != "2025-01-02" || strings.Join(got.List("rest"), ",") != "a,b" {
+		t.Errorf("unexpected args: count=%d date=%s rest=%v", got.Int("count"), got.String("date"), got.List("rest"))
+	}
+
+	if _, err := registry.Execute(&Context{}, "/echo 1"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got.String("date") != "2025-01-01" || len(got.List("rest")) != 0 {
+		t.Errorf("defaults not applied: date=%s rest=%v", got.String("date"), got.List("rest"))
+	}
+}
+
+func TestExecuteNamedKeepsArgsByKey(t *testing.T) {
+	var got Args
+	registry := newTestRegistry(func(ctx *Context, args Args) (*Response, error) {
+		got = args
+		return NewTextResponse("ok"), nil
+	})
+
+	// 未填的選填參數不會讓後面的參數前移
+	if _, err := registry.ExecuteNamed(&Context{}, "echo", map[string]string{"count": "3", "rest": "a  b"}); err != nil {
+		t.Fatalf("ExecuteNamed() error = %v", err)
+	}
+	if got.Int("count") != 3 || got.String("date") != "2025-01-01" || strings.Join(got.List("rest"), ",") != "a,b" {
+		t.Errorf("unexpected args: count=%d date=%s rest=%v", got.Int("count"), got.String("date"), got.List("rest"))
+	}
+
+	if _, err := registry.ExecuteNamed(&Context{}, "echo", map[string]string{"date": "2025-01-02"}); err == nil || !strings.Contains(err.Error(), "請輸入數量") {
+		t.Errorf("ExecuteNamed() without required arg error = %v", err)
+	}
+	if _, err := registry.ExecuteNamed(&Context{}, "unknown", nil); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("ExecuteNamed(unknown) error = %v, want ErrUnknownCommand", err)
+	}
+}
+
+func TestExecuteValidatesArgs(t *testing.T) {
+	registry := newTestRegistry(func(ctx *Context, args Args) (*Response, error) {
+		return NewTextResponse("ok"), nil
+	})
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/echo", "請輸入數量"},
+		{"/echo abc", "數量須為正整數"},
+		{"/echo 1 2025-13-40", "日期格式錯誤"},
+	}
+	for _, tt := range tests {
+		_, err := registry.Execute(&Context{}, tt.text)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Execute(%q) error = %v, want containing %q", tt.text, err, tt.want)
+		}
+	}
+
+	for _, text := range []string{"hello", "/unknown 1", ""} {
+		if _, err := registry.Execute(&Context{}, text); !errors.Is(err, ErrUnknownCommand) {
+			t.Errorf("Execute(%q) error = %v, want ErrUnknownCommand", text, err)
+		}
+	}
+}
+
+func TestFormatTableAlignsWideRunes(t *testing.T) {
+	got := FormatTable(&Table{
+		Header: []string{"月份", "營收"},
+		Rows:   [][]string{{"2025/01", "1.00"}},
+	})
+	want := "月份     營收\n2025/01  1.00"
+	if got != want {
+		t.Errorf("FormatTable() = %q, want %q", got, want)
+	}
+}
+
+func TestExecuteAdminOnlyInGroup(t *testing.T) {
+	r := &commandRegistry{commands: make(map[string]*Command)}
+	r.Register(&Command{
+		Name:      "sub",
+		AdminOnly: true,
+		Handler: func(ctx *Context, args Args) (*Response, error) {
+			return NewTextResponse("ok"), nil
+		},
+	})
+
+	checked := 0
+	admin := false
+	isAdmin := func() bool {
+		checked++
+		return admin
+	}
+
+	if _, err := r.Execute(&Context{IsAdmin: isAdmin}, "/sub"); err != nil {
+		t.Errorf("Execute() in private chat error = %v, want nil", err)
+	}
+	if checked != 0 {
+		t.Errorf("IsAdmin called %d times in private chat, want 0", checked)
+	}
+	if _, err := r.Execute(&Context{Group: true, IsAdmin: isAdmin}, "/sub"); !errors.Is(err, ErrAdminOnly) {
+		t.Errorf("Execute() by member error = %v, want ErrAdminOnly", err)
+	}
+	admin = true
+	if _, err := r.Execute(&Context{Group: true, IsAdmin: isAdmin}, "/sub"); err != nil {
+		t.Errorf("Execute() by admin error = %v, want nil", err)
+	}
+	if _, err := r.Execute(&Context{Group: true}, "/sub"); !errors.Is(err, ErrAdminOnly) {
+		t.Errorf("Execute() without admin check error = %v, want ErrAdminOnly", err)
+	}
+}
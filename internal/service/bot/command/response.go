@@ -0,0 +1,113 @@
+// Package command 提供 Telegram 與 LINE 共用的指令層，指令只需實作一次，
+// 回傳與平台無關的回應，再由各平台的 renderer 轉換為實際訊息
+package command
+
+// Response 與平台無關的指令回應
+type Response struct {
+	Title   string     // 標題，顯示為粗體
+	Blocks  []Block    // 內容區塊，依序顯示
+	Image   *Image     // 圖片，有圖片時文字作為說明
+	Buttons [][]Button // 按鈕，每個元素為一列
+	Replace bool       // 由按鈕觸發時更新原訊息而非另發新訊息（分頁用）
+}
+
+// Block 內容區塊，Text、Fields、Table 可擇一或同時使用
+type Block struct {
+	Heading string  // 區塊標題
+	Text    string  // 文字段落
+	Fields  []Field // 欄位清單，以等寬字型顯示
+	Table   *Table  // 表格，以等寬字型對齊
+	Trend   Trend   // 區塊整體漲跌，支援顏色的平台以顏色標示標題
+	Action  *Button // 點擊整個區塊時執行的動作，不支援的平台忽略
+}
+
+// Field 名稱與數值，Value 為空時顯示為清單項目
+type Field struct {
+	Label  string
+	Value  string
+	Trend  Trend   // 數值漲跌，支援顏色的平台以顏色標示
+	Action *Button // 點擊此列時執行的動作，不支援的平台忽略
+}
+
+// Trend 漲跌方向
+type Trend int
+
+const (
+	TrendFlat Trend = iota // 平盤或不適用
+	TrendUp                // 上漲
+	TrendDown              // 下跌
+)
+
+// TrendFromSign 由漲跌符號 +、- 取得漲跌方向
+func TrendFromSign(sign string) Trend {
+	switch sign {
+	case "+":
+		return TrendUp
+	case "-":
+		return TrendDown
+	default:
+		return TrendFlat
+	}
+}
+
+// TrendFromValue 由數值正負取得漲跌方向
+func TrendFromValue(value float64) Trend {
+	switch {
+	case value > 0:
+		return TrendUp
+	case value < 0:
+		return TrendDown
+	default:
+		return TrendFlat
+	}
+}
+
+// Table 表格資料
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Image 圖片資料
+type Image struct {
+	Data []byte
+	Name string // 檔名，例如 chart.png
+}
+
+// Button 按鈕，Command 與 URL 擇一；皆為空時為純顯示用按鈕
+type Button struct {
+	Label   string
+	Command string // 按下後執行的指令，例如 /k 2330
+	URL     string // 按下後開啟的網址
+}
+
+// NewTextResponse 建立純文字回應
+func NewTextResponse(text string) *Response {
+	return &Response{Blocks: []Block{{Text: text}}}
+}
+
+// CommandButton 建立執行指令的按鈕
+func CommandButton(label, command string) Button {
+	return Button{Label: label, Command: command}
+}
+
+// URLButton 建立開啟網址的按鈕
+func URLButton(label, url string) Button {
+	return Button{Label: label, URL: url}
+}
+
+// CommandAction 建立點擊區塊或欄位時執行指令的動作
+func CommandAction(label, command string) *Button {
+	button := CommandButton(label, command)
+	return &button
+}
+
+// LabelButton 建立純顯示用按鈕，例如分頁頁碼
+func LabelButton(label string) Button {
+	return Button{Label: label}
+}
+
+// ImageUploader 將圖片上傳並取得可公開存取的網址，供只接受圖片網址的平台使用
+type ImageUploader interface {
+	UploadImage(data []byte, filename string, expiration int) (string, error)
+}
@@ -0,0 +1,159 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// scheduleWeekdays 自訂排程於平日執行，推播時仍會略過休市日
+const scheduleWeekdays = "1-5"
+
+// scheduleTimePattern 推播時間格式 HH:MM
+var scheduleTimePattern = regexp.MustCompile(`^([01]?\d|2[0-3]):([0-5]\d)$`)
+
+// scheduleCronPattern 由 ScheduleCron 產生的排程格式
+var scheduleCronPattern = regexp.MustCompile(`^0 (\d{1,2}) (\d{1,2}) \* \* ` + scheduleWeekdays + `$`)
+
+// scheduleResetWords 恢復預設排程的關鍵字
+var scheduleResetWords = map[string]bool{"default": true, "reset": true, "預設": true}
+
+// subscriptionItemAliases 訂閱項目別名，可代替編號使用
+var subscriptionItemAliases = map[string]models.SubscriptionItem{
+	"info":   models.SubscriptionItemStockInfo,
+	"stock":  models.SubscriptionItemStockInfo,
+	"news":   models.SubscriptionItemStockNews,
+	"market": models.SubscriptionItemDailyMarketInfo,
+	"top":    models.SubscriptionItemTopVolumeItems,
+	"volume": models.SubscriptionItemTopVolumeItems,
+	"yield":  models.SubscriptionItemTreasuryYield,
+}
+
+// registerScheduleCommands 註冊推播排程指令
+func (r *commandRegistry) registerScheduleCommands() {
+	r.Register(&Command{
+		Name:        "schedule",
+		Category:    CategorySubscription,
+		Description: "設定訂閱推播時間 (時間輸入 預設 可恢復預設時間)",
+		Example:     "/schedule news 12:30",
+		ExampleNote: "股票新聞改為平日 12:30 推播",
+		Args: []ArgSpec{
+			subscriptionItemArg,
+			{Key: "time", Name: "時間", Type: ArgString, Required: true},
+			scheduleTimezoneArg,
+		},
+		AdminOnly: true,
+		Handler:   r.setSchedule,
+	})
+}
+
+// scheduleTimezoneArg 排程時區參數，例如 Asia/Tokyo
+var scheduleTimezoneArg = ArgSpec{Key: "timezone", Name: "時區", Type: ArgString}
+
+// setSchedule 處理 /schedule 命令 - 設定訂閱項目的推播時間
+func (r *commandRegistry) setSchedule(ctx *Context, args Args) (*Response, error) {
+	item, err := parseSubscriptionItemArg(args.String("item"))
+	if err != nil {
+		return nil, err
+	}
+	name := item.GetName()
+
+	existing, err := r.userSubscriptionService.GetUserSubscriptionByItem(ctx.UserID, item)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		r.logger.Error("取得訂閱項目失敗", zap.Error(err))
+		return nil, fmt.Errorf("操作失敗，請稍後再試")
+	}
+	if existing == nil || !existing.Status {
+		return nil, fmt.Errorf("您尚未訂閱：%s，請先使用 /sub %d 訂閱", name, int(item))
+	}
+
+	clock := args.String("time")
+	if scheduleResetWords[strings.ToLower(clock)] {
+		if err := r.userSubscriptionService.UpdateUserSubscriptionSchedule(ctx.UserID, item, "", ""); err != nil {
+			r.logger.Error("更新訂閱排程失敗", zap.Error(err))
+			return nil, fmt.Errorf("操作失敗，請稍後再試")
+		}
+		return NewTextResponse(fmt.Sprintf("已恢復預設推播時間：%s", name)), nil
+	}
+
+	return r.updateSchedule(ctx.UserID, item, clock, args.String("timezone"))
+}
+
+// updateSchedule 驗證時間及時區後更新訂閱排程
+func (r *commandRegistry) updateSchedule(userID uint, item models.SubscriptionItem, clock, timezone string) (*Response, error) {
+	spec, err := ScheduleCron(clock)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTimezone(timezone); err != nil {
+		return nil, err
+	}
+
+	if err := r.userSubscriptionService.UpdateUserSubscriptionSchedule(userID, item, spec, timezone); err != nil {
+		r.logger.Error("更新訂閱排程失敗", zap.Error(err))
+		return nil, fmt.Errorf("操作失敗，請稍後再試")
+	}
+
+	subscription := &models.Subscription{ScheduleCron: spec, Timezone: timezone}
+	return NewTextResponse(fmt.Sprintf("已設定 %s 推播時間：平日 %s", item.GetName(), FormatSchedule(subscription))), nil
+}
+
+// ScheduleCron 將 HH:MM 轉為平日執行的 cron 排程（含秒欄位）
+func ScheduleCron(clock string) (string, error) {
+	matches := scheduleTimePattern.FindStringSubmatch(clock)
+	if matches == nil {
+		return "", fmt.Errorf("時間格式錯誤，請使用 HH:MM，例如 08:45")
+	}
+	hour, _ := strconv.Atoi(matches[1])
+	minute, _ := strconv.Atoi(matches[2])
+	return fmt.Sprintf("0 %d %d * * %s", minute, hour, scheduleWeekdays), nil
+}
+
+// FormatSchedule 將訂閱排程轉為顯示文字，例如 08:45 (Asia/Tokyo)，未設定時回傳空字串
+func FormatSchedule(subscription *models.Subscription) string {
+	if subscription.ScheduleCron == "" {
+		return ""
+	}
+
+	text := subscription.ScheduleCron
+	if matches := scheduleCronPattern.FindStringSubmatch(subscription.ScheduleCron); matches != nil {
+		minute, _ := strconv.Atoi(matches[1])
+		hour, _ := strconv.Atoi(matches[2])
+		text = fmt.Sprintf("%02d:%02d", hour, minute)
+	}
+	if subscription.Timezone != "" {
+		text += " (" + subscription.Timezone + ")"
+	}
+	return text
+}
+
+// validateTimezone 驗證 IANA 時區名稱，空白表示使用預設時區
+func validateTimezone(timezone string) error {
+	if timezone == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("無效的時區: %s，請使用例如 Asia/Taipei", timezone)
+	}
+	return nil
+}
+
+// parseSubscriptionItemArg 解析訂閱項目，支援編號及別名
+func parseSubscriptionItemArg(input string) (models.SubscriptionItem, error) {
+	item, exists := models.ParseSubscriptionItem(input)
+	if !exists {
+		item, exists = subscriptionItemAliases[strings.ToLower(input)]
+	}
+	if !exists || item == models.SubscriptionItemDefault {
+		return 0, fmt.Errorf("無效的訂閱項目: %s\n可訂閱項目：%s", input, subscriptionItemOptions())
+	}
+	return item, nil
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+)
+
+func TestScheduleCron(t *testing.T) {
+	spec, err := ScheduleCron("8:05")
+	if err != nil {
+		t.Fatalf("ScheduleCron() error = %v", err)
+	}
+	if spec != "0 5 8 * * 1-5" {
+		t.Errorf("ScheduleCron() = %q", spec)
+	}
+
+	for _, clock := range []string{"24:00", "12:60", "1230", ""} {
+		if _, err := ScheduleCron(clock); err == nil {
+			t.Errorf("ScheduleCron(%q) expected error", clock)
+		}
+	}
+}
+
+func TestFormatSchedule(t *testing.T) {
+	tests := []struct {
+		subscription models.Subscription
+		want         string
+	}{
+		{models.Subscription{}, ""},
+		{models.Subscription{ScheduleCron: "0 45 8 * * 1-5"}, "08:45"},
+		{models.Subscription{ScheduleCron: "0 30 12 * * 1-5", Timezone: "Asia/Tokyo"}, "12:30 (Asia/Tokyo)"},
+		{models.Subscription{ScheduleCron: "0 0 9 * * *"}, "0 0 9 * * *"},
+	}
+	for _, tt := range tests {
+		if got := FormatSchedule(&tt.subscription); got != tt.want {
+			t.Errorf("FormatSchedule(%q, %q) = %q, want %q", tt.subscription.ScheduleCron, tt.subscription.Timezone, got, tt.want)
+		}
+	}
+}
+
+func TestParseSubscriptionItemArg(t *testing.T) {
+	for input, want := range map[string]models.SubscriptionItem{
+		"3":    models.SubscriptionItemDailyMarketInfo,
+		"news": models.SubscriptionItemStockNews,
+		"TOP":  models.SubscriptionItemTopVolumeItems,
+	} {
+		if got, err := parseSubscriptionItemArg(input); err != nil || got != want {
+			t.Errorf("parseSubscriptionItemArg(%q) = %v, %v", input, got, err)
+		}
+	}
+	for _, input := range []string{"0", "foo"} {
+		if _, err := parseSubscriptionItemArg(input); err == nil {
+			t.Errorf("parseSubscriptionItemArg(%q) expected error", input)
+		}
+	}
+}
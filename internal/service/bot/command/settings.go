@@ -0,0 +1,295 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/service/user_preference"
+
+	"go.uber.org/zap"
+)
+
+// settingsOffWords 關閉勿擾時段的關鍵字
+var settingsOffWords = map[string]bool{"off": true, "關閉": true}
+
+// settingsLanguages 語言參數
+var settingsLanguages = map[string]models.Language{
+	"zh":    models.LanguageZhTW,
+	"zh-tw": models.LanguageZhTW,
+	"中文":    models.LanguageZhTW,
+	"en":    models.LanguageEn,
+	"英文":    models.LanguageEn,
+}
+
+// settingsThemes 圖表主題參數
+var settingsThemes = map[string]models.ChartTheme{
+	"light": models.ChartThemeLight,
+	"dark":  models.ChartThemeDark,
+	"淺色":    models.ChartThemeLight,
+	"深色":    models.ChartThemeDark,
+}
+
+// settingsTimeframes K線週期參數
+var settingsTimeframes = map[string]models.KLineTimeframe{
+	"d": models.KLineTimeframeDay,
+	"w": models.KLineTimeframeWeek,
+	"m": models.KLineTimeframeMonth,
+	"日": models.KLineTimeframeDay,
+	"週": models.KLineTimeframeWeek,
+	"月": models.KLineTimeframeMonth,
+}
+
+// registerSettingsCommands 註冊偏好設定指令
+func (r *commandRegistry) registerSettingsCommands() {
+	r.Register(&Command{
+		Name:        "settings",
+		Category:    CategorySubscription,
+		Description: "偏好設定 (quiet:勿擾時段 tz:時區 lang:語言 theme:圖表主題 k:K線週期 digest:摘要模式)",
+		Example:     "/settings quiet 22:00 07:00",
+		ExampleNote: "22:00 至隔天 07:00 的推播延後至勿擾結束後發送",
+		Args: []ArgSpec{
+			{Key: "key", Name: "項目", Type: ArgString},
+			{Key: "value", Name: "設定值", Type: ArgList},
+		},
+		Handler: r.settings,
+	})
+}
+
+// settings 處理 /settings 命令 - 未指定項目時顯示目前設定
+func (r *commandRegistry) settings(ctx *Context, args Args) (*Response, error) {
+	key := strings.ToLower(args.String("key"))
+	if key == "" {
+		return r.settingsPanel(ctx.UserID)
+	}
+
+	// 變更設定才需要群組管理員權限
+	if err := ctx.requireAdmin(); err != nil {
+		return nil, err
+	}
+
+	update, err := parseSettingsUpdate(key, args.List("value"))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.updatePreference(ctx.UserID, update); err != nil {
+		r.logger.Error("更新偏好設定失敗", zap.Error(err))
+		return nil, fmt.Errorf("操作失敗，請稍後再試")
+	}
+	return r.settingsPanel(ctx.UserID)
+}
+
+// settingsText 設定面板文字，依使用者偏好語言切換
+type settingsText struct {
+	title, quiet, timezone, language, theme, timeframe, digest string
+	unset, defaultTimezone                                     string
+	quietOn, quietOff, themeDark, themeLight                   string
+	digestOn, digestOff                                        string
+	themes                                                     map[models.ChartTheme]string
+	timeframes                                                 map[models.KLineTimeframe]string
+	digestModes                                                map[models.DigestMode]string
+}
+
+// settingsTexts 各語言的設定面板文字
+var settingsTexts = map[models.Language]settingsText{
+	models.LanguageZhTW: {
+		title: "⚙️ 偏好設定", quiet: "勿擾時段", timezone: "時區", language: "語言",
+		theme: "圖表主題", timeframe: "K線週期", digest: "摘要模式",
+		unset: "未設定", defaultTimezone: "預設",
+		quietOn: "🌙 勿擾 22:00-07:00", quietOff: "🔔 關閉勿擾",
+		themeDark: "🌑 深色圖表", themeLight: "☀️ 淺色圖表",
+		digestOn: "📋 開啟摘要", digestOff: "📋 關閉摘要",
+		themes: map[models.ChartTheme]string{models.ChartThemeLight: "淺色", models.ChartThemeDark: "深色"},
+		timeframes: map[models.KLineTimeframe]string{
+			models.KLineTimeframeDay: "日K", models.KLineTimeframeWeek: "週K", models.KLineTimeframeMonth: "月K",
+		},
+		digestModes: map[models.DigestMode]string{
+			models.DigestModeOff:   digestModeName(models.DigestModeOff),
+			models.DigestModeText:  digestModeName(models.DigestModeText),
+			models.DigestModeChart: digestModeName(models.DigestModeChart),
+		},
+	},
+	models.LanguageEn: {
+		title: "⚙️ Settings", quiet: "Quiet hours", timezone: "Timezone", language: "Language",
+		theme: "Chart theme", timeframe: "Timeframe", digest: "Digest",
+		unset: "Off", defaultTimezone: "default",
+		quietOn: "🌙 Quiet 22:00-07:00", quietOff: "🔔 Quiet hours off",
+		themeDark: "🌑 Dark charts", themeLight: "☀️ Light charts",
+		digestOn: "📋 Digest on", digestOff: "📋 Digest off",
+		themes: map[models.ChartTheme]string{models.ChartThemeLight: "Light", models.ChartThemeDark: "Dark"},
+		timeframes: map[models.KLineTimeframe]string{
+			models.KLineTimeframeDay: "Daily", models.KLineTimeframeWeek: "Weekly", models.KLineTimeframeMonth: "Monthly",
+		},
+		digestModes: map[models.DigestMode]string{
+			models.DigestModeOff:   "Separate messages",
+			models.DigestModeText:  "One digest",
+			models.DigestModeChart: "One digest with charts",
+		},
+	},
+}
+
+// settingsTextFor 取得偏好語言的設定面板文字，未知語言使用繁體中文
+func settingsTextFor(language models.Language) settingsText {
+	if text, ok := settingsTexts[language]; ok {
+		return text
+	}
+	return settingsTexts[models.LanguageZhTW]
+}
+
+// settingsPanel 目前設定及切換按鈕，由按鈕觸發時更新原訊息
+func (r *commandRegistry) settingsPanel(userID uint) (*Response, error) {
+	preference, err := r.userPreferenceService.GetUserPreference(userID)
+	if err != nil {
+		r.logger.Error("取得偏好設定失敗", zap.Error(err))
+		return nil, fmt.Errorf("操作失敗，請稍後再試")
+	}
+	text := settingsTextFor(preference.Language)
+
+	quiet := text.unset
+	quietButton := CommandButton(text.quietOn, "/settings quiet 22:00 07:00")
+	if preference.QuietStart != "" {
+		quiet = preference.QuietStart + " - " + preference.QuietEnd
+		quietButton = CommandButton(text.quietOff, "/settings quiet off")
+	}
+	timezone := preference.Timezone
+	if timezone == "" {
+		timezone = "Asia/Taipei (" + text.defaultTimezone + ")"
+	}
+
+	theme := CommandButton(text.themeDark, "/settings theme dark")
+	if preference.ChartTheme == models.ChartThemeDark {
+		theme = CommandButton(text.themeLight, "/settings theme light")
+	}
+	language := CommandButton("English", "/settings lang en")
+	if preference.Language == models.LanguageEn {
+		language = CommandButton("中文", "/settings lang zh-TW")
+	}
+	digest := CommandButton(text.digestOn, "/settings digest on")
+	if preference.DigestMode != models.DigestModeOff {
+		digest = CommandButton(text.digestOff, "/settings digest off")
+	}
+	timeframe := preference.KLineTimeframe
+	if _, ok := text.timeframes[timeframe]; !ok {
+		timeframe = models.KLineTimeframeDay
+	}
+	chartTheme := preference.ChartTheme
+	if chartTheme != models.ChartThemeDark {
+		chartTheme = models.ChartThemeLight
+	}
+
+	return &Response{
+		Title: text.title,
+		Blocks: []Block{{Fields: []Field{
+			{Label: text.quiet, Value: quiet},
+			{Label: text.timezone, Value: timezone},
+			{Label: text.language, Value: languageName(preference.Language)},
+			{Label: text.theme, Value: text.themes[chartTheme]},
+			{Label: text.timeframe, Value: text.timeframes[timeframe]},
+			{Label: text.digest, Value: text.digestModes[preference.DigestMode]},
+		}}},
+		Buttons: [][]Button{
+			{quietButton, digest},
+			{theme, language},
+			{
+				CommandButton(text.timeframes[models.KLineTimeframeDay], "/settings k D"),
+				CommandButton(text.timeframes[models.KLineTimeframeWeek], "/settings k W"),
+				CommandButton(text.timeframes[models.KLineTimeframeMonth], "/settings k M"),
+			},
+		},
+		Replace: true,
+	}, nil
+}
+
+// parseSettingsUpdate 解析設定項目及設定值，回傳套用設定的函式
+func parseSettingsUpdate(key string, values []string) (func(*models.UserPreference), error) {
+	value := ""
+	if len(values) > 0 {
+		value = values[0]
+	}
+
+	switch key {
+	case "quiet", "勿擾":
+		if settingsOffWords[strings.ToLower(value)] {
+			return func(p *models.UserPreference) { p.QuietStart, p.QuietEnd = "", "" }, nil
+		}
+		if len(values) < 2 {
+			return nil, fmt.Errorf("請輸入勿擾開始及結束時間，例如 /settings quiet 22:00 07:00")
+		}
+		start, end := normalizeClock(values[0]), normalizeClock(values[1])
+		if err := user_preference.ValidateQuietHours(start, end); err != nil {
+			return nil, err
+		}
+		return func(p *models.UserPreference) { p.QuietStart, p.QuietEnd = start, end }, nil
+
+	case "tz", "timezone", "時區":
+		if scheduleResetWords[strings.ToLower(value)] {
+			return func(p *models.UserPreference) { p.Timezone = "" }, nil
+		}
+		if value == "" {
+			return nil, fmt.Errorf("請輸入時區，例如 /settings tz Asia/Tokyo")
+		}
+		if err := validateTimezone(value); err != nil {
+			return nil, err
+		}
+		return func(p *models.UserPreference) { p.Timezone = value }, nil
+
+	case "lang", "language", "語言":
+		language, ok := settingsLanguages[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("無效的語言: %s，請輸入 zh-TW 或 en", value)
+		}
+		return func(p *models.UserPreference) { p.Language = language }, nil
+
+	case "theme", "主題":
+		theme, ok := settingsThemes[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("無效的圖表主題: %s，請輸入 light 或 dark", value)
+		}
+		return func(p *models.UserPreference) { p.ChartTheme = theme }, nil
+
+	case "k", "kline", "k線":
+		timeframe, ok := settingsTimeframes[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("無效的K線週期: %s，請輸入 D、W 或 M", value)
+		}
+		return func(p *models.UserPreference) { p.KLineTimeframe = timeframe }, nil
+
+	case "digest", "摘要":
+		mode, ok := digestModes[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("無效的模式: %s，請輸入 on、chart 或 off", value)
+		}
+		return func(p *models.UserPreference) { p.DigestMode = mode }, nil
+	}
+	return nil, fmt.Errorf("無效的設定項目: %s，請輸入 quiet、tz、lang、theme、k 或 digest", key)
+}
+
+// updatePreference 讀取使用者偏好設定，套用變更後儲存
+func (r *commandRegistry) updatePreference(userID uint, update func(*models.UserPreference)) error {
+	preference, err := r.userPreferenceService.GetUserPreference(userID)
+	if err != nil {
+		return err
+	}
+	update(preference)
+	return r.userPreferenceService.UpdateUserPreference(preference)
+}
+
+// normalizeClock 將 7:00 補零為 07:00，格式錯誤時原樣回傳交由驗證處理
+func normalizeClock(clock string) string {
+	matches := scheduleTimePattern.FindStringSubmatch(clock)
+	if matches == nil {
+		return clock
+	}
+	hour, _ := strconv.Atoi(matches[1])
+	minute, _ := strconv.Atoi(matches[2])
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
+
+// languageName 語言說明
+func languageName(language models.Language) string {
+	if language == models.LanguageEn {
+		return "English"
+	}
+	return "繁體中文"
+}
@@ -0,0 +1,51 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+)
+
+func TestParseSettingsUpdateLanguage(t *testing.T) {
+	update, err := parseSettingsUpdate("lang", []string{"EN"})
+	if err != nil {
+		t.Fatalf("parseSettingsUpdate() error = %v", err)
+	}
+	preference := models.DefaultUserPreference(1)
+	update(preference)
+	if preference.Language != models.LanguageEn {
+		t.Errorf("Language = %q, want %q", preference.Language, models.LanguageEn)
+	}
+
+	if _, err := parseSettingsUpdate("lang", []string{"fr"}); err == nil {
+		t.Error("parseSettingsUpdate(lang fr) expected error")
+	}
+}
+
+func TestSettingsTextFor(t *testing.T) {
+	if got := settingsTextFor(models.LanguageEn).title; got != "⚙️ Settings" {
+		t.Errorf("en title = %q", got)
+	}
+	if got := settingsTextFor("").title; got != "⚙️ 偏好設定" {
+		t.Errorf("default title = %q", got)
+	}
+
+	// 每種語言皆需涵蓋所有設定值
+	for language, text := range settingsTexts {
+		for _, mode := range []models.DigestMode{models.DigestModeOff, models.DigestModeText, models.DigestModeChart} {
+			if text.digestModes[mode] == "" {
+				t.Errorf("%s: missing digest mode %d", language, mode)
+			}
+		}
+		for _, timeframe := range []models.KLineTimeframe{models.KLineTimeframeDay, models.KLineTimeframeWeek, models.KLineTimeframeMonth} {
+			if text.timeframes[timeframe] == "" {
+				t.Errorf("%s: missing timeframe %s", language, timeframe)
+			}
+		}
+		for _, theme := range []models.ChartTheme{models.ChartThemeLight, models.ChartThemeDark} {
+			if text.themes[theme] == "" {
+				t.Errorf("%s: missing theme %s", language, theme)
+			}
+		}
+	}
+}
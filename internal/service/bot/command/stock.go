@@ -0,0 +1,466 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	finmindDto "github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	fugleDto "github.com/tian841224/stock-bot/internal/infrastructure/fugle/dto"
+	"github.com/tian841224/stock-bot/internal/service/symbol_search"
+	"github.com/tian841224/stock-bot/internal/service/twstock"
+	stockDto "github.com/tian841224/stock-bot/internal/service/twstock/dto"
+
+	"go.uber.org/zap"
+)
+
+// QuoteCard 股價卡片，供行內查詢等需要摘要的場合使用
+type QuoteCard struct {
+	StockID     string
+	StockName   string
+	Title       string    // 摘要標題
+	Description string    // 摘要說明
+	Response    *Response // 完整股價資訊
+}
+
+// symbolArg 股票代號參數
+var symbolArg = ArgSpec{Key: "symbol", Name: "股票代號", Type: ArgSymbol, Required: true}
+
+// registerStockCommands 註冊個股相關指令
+func (r *commandRegistry) registerStockCommands() {
+	r.Register(&Command{
+		Name:        "k",
+		Category:    CategoryChart,
+		Description: "K線圖 (含月均價、最高最低價標示、成交量)",
+		Example:     "/k 2330",
+		ExampleNote: "台積電K線圖",
+		Args:        []ArgSpec{symbolArg},
+		Handler:     r.historicalCandles,
+	})
+	r.Register(&Command{
+		Name:        "p",
+		Category:    CategoryChart,
+		Description: "股票績效圖表 (折線圖)",
+		Example:     "/p 0050",
+		ExampleNote: "元大台灣50績效圖表",
+		Args:        []ArgSpec{symbolArg},
+		Handler:     r.performanceChart,
+	})
+	r.Register(&Command{
+		Name:        "r",
+		Category:    CategoryChart,
+		Description: "月營收圖表 (柱狀圖+年增率折線)",
+		Example:     "/r 2330",
+		ExampleNote: "台積電月營收圖表",
+		Args:        []ArgSpec{symbolArg},
+		Handler:     r.revenue,
+	})
+	r.Register(&Command{
+		Name:        "cmp",
+		Category:    CategoryChart,
+		Description: fmt.Sprintf("多檔股票累積報酬比較 (最多%d檔，期間: 1M/3M/6M/YTD/1Y/3Y/5Y)", twstock.MaxComparisonSymbols),
+		Example:     "/cmp 2330 2454 2303 1Y",
+		Args:        []ArgSpec{{Key: "symbols", Name: "股票代號", Type: ArgList, Required: true}},
+		Handler:     r.compare,
+	})
+	r.Register(&Command{
+		Name:        "d",
+		Category:    CategoryStock,
+		Description: "查詢收盤資訊 (日期可省略，格式: YYYY-MM-DD)",
+		Example:     "/d 2330 2025-01-15",
+		ExampleNote: "查詢台積電指定日期股價",
+		Args:        []ArgSpec{symbolArg, {Key: "date", Name: "日期", Type: ArgDate}},
+		Handler:     r.stockPrice,
+	})
+	r.Register(&Command{
+		Name:        "i",
+		Category:    CategoryStock,
+		Description: "查詢公司資訊",
+		Example:     "/i 2330",
+		Args:        []ArgSpec{symbolArg},
+		Handler:     r.stockInfo,
+	})
+	r.Register(&Command{
+		Name:        "n",
+		Category:    CategoryStock,
+		Description: "查詢股票新聞",
+		Example:     "/n 2330",
+		Args:        []ArgSpec{symbolArg},
+		Handler:     r.news,
+	})
+	r.Register(&Command{
+		Name:        "s",
+		Category:    CategoryStock,
+		Description: "以名稱、代號或拼音搜尋股票代號",
+		Example:     "/s 鴻海",
+		Args:        []ArgSpec{{Key: "query", Name: "股票名稱或代號", Type: ArgText, Required: true}},
+		Handler:     r.searchSymbol,
+	})
+}
+
+// historicalCandles 處理 /k 命令 - 歷史K線圖
+func (r *commandRegistry) historicalCandles(ctx *Context, args Args) (*Response, error) {
+	return r.candlesChart(args.String("symbol"), r.chartPreference(ctx))
+}
+
+// candlesChart 依偏好設定的K線週期及圖表主題產生K線圖
+func (r *commandRegistry) candlesChart(symbol string, preference *models.UserPreference) (*Response, error) {
+	timeframe := preference.KLineTimeframe
+	if timeframe == "" {
+		timeframe = models.KLineTimeframeDay
+	}
+	dto := fugleDto.FugleCandlesRequestDto{
+		Symbol:    symbol,
+		From:      klineFrom(timeframe).Format("2006-01-02"),
+		Timeframe: string(timeframe),
+		Fields:    "open,high,low,close,volume",
+	}
+
+	chart, stockName, err := r.stockService.GetStockHistoricalCandlesChart(dto, string(preference.ChartTheme))
+	if err != nil {
+		r.logger.Error("取得股票歷史K線圖失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	return &Response{
+		Title: fmt.Sprintf("⚡️%s(%s)-歷史K線圖", stockName, symbol),
+		Image: &Image{Data: chart, Name: "kline.png"},
+	}, nil
+}
+
+// chartPreference 取得使用者的K線週期及圖表主題，未綁定使用者或查詢失敗時使用預設值
+func (r *commandRegistry) chartPreference(ctx *Context) *models.UserPreference {
+	if ctx.UserID == 0 {
+		return models.DefaultUserPreference(0)
+	}
+	preference, err := r.userPreferenceService.GetUserPreference(ctx.UserID)
+	if err != nil {
+		r.logger.Error("取得使用者偏好設定失敗", zap.Error(err))
+		return models.DefaultUserPreference(ctx.UserID)
+	}
+	return preference
+}
+
+// klineFrom K線圖起始日期，週線及月線拉長期間使K線數量相近
+func klineFrom(timeframe models.KLineTimeframe) time.Time {
+	now := time.Now()
+	switch timeframe {
+	case models.KLineTimeframeWeek:
+		return now.AddDate(-3, 0, 1)
+	case models.KLineTimeframeMonth:
+		return now.AddDate(-10, 0, 1)
+	default:
+		return now.AddDate(-1, 0, 1)
+	}
+}
+
+// performanceChart 處理 /p 命令 - 股票績效圖表 (折線圖)
+func (r *commandRegistry) performanceChart(ctx *Context, args Args) (*Response, error) {
+	symbol := args.String("symbol")
+
+	// 驗證股票代號並取得基本資訊
+	valid, stockName, err := r.stockService.ValidateStockID(symbol)
+	if err != nil || !valid {
+		return nil, fmt.Errorf("查無此股票代號，請重新確認")
+	}
+
+	// 取得績效圖表
+	performanceChartData, err := r.stockService.GetStockPerformanceWithChart(symbol, "line")
+	if err != nil {
+		r.logger.Error("取得股票績效失敗", zap.Error(err))
+		return nil, fmt.Errorf("取得績效資料失敗，請稍後再試")
+	}
+
+	// 取得各期間績效
+	performanceData, err := r.stockService.GetStockPerformance(symbol)
+	if err != nil {
+		r.logger.Error("取得股票績效失敗", zap.Error(err))
+		return nil, fmt.Errorf("取得績效資料失敗，請稍後再試")
+	}
+
+	fields := make([]Field, 0, len(performanceData.Data))
+	for _, data := range performanceData.Data {
+		fields = append(fields, Field{Label: performanceEmoji(data.Performance) + " " + data.Period, Value: data.Performance})
+	}
+
+	response := &Response{
+		Title:  fmt.Sprintf("📊 %s (%s) 績效表現", stockName, symbol),
+		Blocks: []Block{{Fields: fields}},
+	}
+	if len(performanceChartData.ChartData) > 0 {
+		response.Image = &Image{Data: performanceChartData.ChartData, Name: "performance.png"}
+	}
+	return response, nil
+}
+
+// performanceEmoji 依績效正負取得表情符號，無法解析時回傳 📊
+func performanceEmoji(performance string) string {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(performance, "%"), 64)
+	if err != nil {
+		return "📊"
+	}
+	if value >= 0 {
+		return "📈"
+	}
+	return "📉"
+}
+
+// revenue 處理 /r 命令 - 月營收
+func (r *commandRegistry) revenue(ctx *Context, args Args) (*Response, error) {
+	symbol := args.String("symbol")
+
+	revenue, err := r.stockService.GetStockRevenue(symbol)
+	if err != nil {
+		r.logger.Error("取得股票財報失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	chart, err := r.stockService.GetStockRevenueChart(symbol)
+	if err != nil {
+		r.logger.Error("取得股票財報圖表失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	response := &Response{
+		Title: fmt.Sprintf("📊 %s(%s) 月營收", revenue.Name, revenue.Code),
+		Image: &Image{Data: chart, Name: "revenue.png"},
+	}
+
+	// 檢查是否有資料
+	if len(revenue.SaleMonth) == 0 || len(revenue.YoY) == 0 {
+		response.Blocks = []Block{{Text: "❌ 暫無營收資料"}}
+		return response, nil
+	}
+
+	table := &Table{Header: []string{"月份", "營收(億)", "年增率", "累計(億)", "累計年增"}}
+	for i := 0; i < len(revenue.Time); i++ {
+		// 營收單位由千元轉為億元
+		table.Rows = append(table.Rows, []string{
+			time.Unix(revenue.Time[i], 0).Format("2006/01"),
+			fmt.Sprintf("%.2f", float64(revenue.SaleMonth[i])/100000.0),
+			fmt.Sprintf("%.2f%%", revenue.YoY[i]),
+			fmt.Sprintf("%.2f", float64(revenue.SaleAccumulated[i])/100000.0),
+			fmt.Sprintf("%.2f%%", revenue.YoYAccumulated[i]),
+		})
+	}
+	response.Blocks = []Block{{Table: table}}
+	return response, nil
+}
+
+// compare 處理 /cmp 命令 - 多檔股票累積報酬比較，最後一個參數可指定期間
+func (r *commandRegistry) compare(ctx *Context, args Args) (*Response, error) {
+	values := args.List("symbols")
+	period := ""
+	if twstock.IsComparisonPeriod(values[len(values)-1]) {
+		period = values[len(values)-1]
+		values = values[:len(values)-1]
+	}
+
+	// 將股票名稱解析為股票代號，不完全相同時回傳候選清單
+	symbols := make([]string, 0, len(values))
+	for _, value := range values {
+		symbol, err := r.symbolSearch.Resolve(value)
+		if err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	comparison, err := r.stockService.GetStockComparison(symbols, period)
+	if err != nil {
+		r.logger.Error("取得多股比較失敗", zap.Error(err))
+		return nil, err
+	}
+
+	fields := make([]Field, 0, len(comparison.Items))
+	for _, item := range comparison.Items {
+		fields = append(fields, Field{Label: item.StockID + " " + item.Name, Value: fmt.Sprintf("%+.2f%%", item.FinalReturn)})
+	}
+
+	response := &Response{
+		Title: fmt.Sprintf("📊 %s累積報酬比較", comparison.PeriodName),
+		Blocks: []Block{
+			{Text: fmt.Sprintf("%s ~ %s", comparison.StartDate, comparison.EndDate)},
+			{Fields: fields},
+		},
+	}
+	if len(comparison.ChartData) > 0 {
+		response.Image = &Image{Data: comparison.ChartData, Name: "comparison.png"}
+	}
+	return response, nil
+}
+
+// stockPrice 處理 /d 命令 - 股價詳細資訊，未指定日期時查詢最近交易日
+func (r *commandRegistry) stockPrice(ctx *Context, args Args) (*Response, error) {
+	symbol := args.String("symbol")
+	date := args.String("date")
+	if date == "" {
+		date = DefaultQuoteDate(r.tradingCalendar)
+	}
+
+	stockInfo, err := r.stockService.GetStockPrice(symbol, date)
+	if err != nil {
+		r.logger.Error("取得股價資訊失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	response := stockPriceResponse(stockInfo)
+	response.Buttons = stockActionButtons(symbol)
+	return response, nil
+}
+
+// GetQuoteCard 取得股價卡片
+func (r *commandRegistry) GetQuoteCard(symbol, date string) (*QuoteCard, error) {
+	stockInfo, err := r.stockService.GetStockPrice(symbol, date)
+	if err != nil {
+		r.logger.Error("取得股價資訊失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	return &QuoteCard{
+		StockID:     stockInfo.StockID,
+		StockName:   stockInfo.StockName,
+		Title:       fmt.Sprintf("%s (%s) %.2f", stockInfo.StockName, stockInfo.StockID, stockInfo.ClosePrice),
+		Description: fmt.Sprintf("%s 漲跌 %s%.2f (%s)", stockInfo.Date, stockInfo.UpDownSign, stockInfo.ChangeAmount, stockInfo.PercentageChange),
+		Response:    stockPriceResponse(stockInfo),
+	}, nil
+}
+
+// stockPriceResponse 股價資訊回應
+func stockPriceResponse(stockInfo *stockDto.StockPriceInfo) *Response {
+	displayDate := stockInfo.Date
+	if t, err := time.Parse("2006-01-02", stockInfo.Date); err == nil {
+		displayDate = t.Format("2006/01/02")
+	}
+
+	return &Response{
+		Title: displayDate,
+		Blocks: []Block{{
+			Heading: fmt.Sprintf("─── %s (%s) %s ───", stockInfo.StockName, stockInfo.StockID, changeEmoji(stockInfo.UpDownSign)),
+			Fields:  stockPriceFields(stockInfo),
+			Trend:   TrendFromSign(stockInfo.UpDownSign),
+		}},
+	}
+}
+
+// stockPriceFields 股價欄位
+func stockPriceFields(stockInfo *stockDto.StockPriceInfo) []Field {
+	return []Field{
+		{Label: "開盤價", Value: fmt.Sprintf("%.2f", stockInfo.OpenPrice)},
+		{Label: "收盤價", Value: fmt.Sprintf("%.2f", stockInfo.ClosePrice)},
+		{Label: "漲跌幅", Value: fmt.Sprintf("%s%.2f (%s)", stockInfo.UpDownSign, stockInfo.ChangeAmount, stockInfo.PercentageChange), Trend: TrendFromSign(stockInfo.UpDownSign)},
+		{Label: "最高價", Value: fmt.Sprintf("%.2f", stockInfo.HighPrice)},
+		{Label: "最低價", Value: fmt.Sprintf("%.2f", stockInfo.LowPrice)},
+		{Label: "成交股數", Value: stockInfo.Volume},
+		{Label: "成交筆數", Value: stockInfo.Transaction},
+	}
+}
+
+// stockActionButtons 個股查詢後的延伸操作按鈕
+func stockActionButtons(symbol string) [][]Button {
+	return [][]Button{
+		{
+			CommandButton("K線", "/k "+symbol),
+			CommandButton("績效", "/p "+symbol),
+			CommandButton("營收", "/r "+symbol),
+			CommandButton("新聞", "/n "+symbol),
+		},
+		{CommandButton("加入訂閱", "/add "+symbol)},
+	}
+}
+
+// stockInfo 處理 /i 命令 - 公司資訊
+func (r *commandRegistry) stockInfo(ctx *Context, args Args) (*Response, error) {
+	stockInfo, err := r.stockService.GetStockInfo(args.String("symbol"))
+	if err != nil {
+		r.logger.Error("取得股票詳細資訊失敗", zap.Error(err))
+		return nil, fmt.Errorf("查無資料，請確認後再試")
+	}
+
+	return &Response{
+		Title: fmt.Sprintf("🏢%s (%s) | %s | %s", stockInfo.StockName, stockInfo.StockID, stockInfo.Industry, stockInfo.Market),
+		Blocks: []Block{
+			{
+				Heading: "💼財務指標",
+				Fields: []Field{
+					{Label: "本益比", Value: fmt.Sprintf("%.2f", stockInfo.PE)},
+					{Label: "本淨比", Value: fmt.Sprintf("%.2f", stockInfo.PB)},
+					{Label: "市值", Value: fmt.Sprintf("%.2f 兆", stockInfo.MarketCap/1000000000000)},
+					{Label: "每股淨值", Value: fmt.Sprintf("%.2f", stockInfo.BookValue)},
+					{Label: "近四季EPS", Value: fmt.Sprintf("%.2f", stockInfo.EPS)},
+					{Label: "營季EPS", Value: fmt.Sprintf("%.2f", stockInfo.QuarterEPS)},
+					{Label: "年股利", Value: fmt.Sprintf("%.2f", stockInfo.Dividend)},
+					{Label: "殖利率", Value: fmt.Sprintf("%.2f%%", stockInfo.DividendRate)},
+				},
+			},
+			{
+				Heading: "💡獲利能力",
+				Fields: []Field{
+					{Label: "毛利率", Value: fmt.Sprintf("%.2f%%", stockInfo.GrossMargin)},
+					{Label: "營益率", Value: fmt.Sprintf("%.2f%%", stockInfo.OperMargin)},
+					{Label: "淨利率", Value: fmt.Sprintf("%.2f%%", stockInfo.NetMargin)},
+				},
+			},
+		},
+	}, nil
+}
+
+// news 處理 /n 命令 - 股票新聞，每則新聞為一個連結按鈕
+func (r *commandRegistry) news(ctx *Context, args Args) (*Response, error) {
+	symbol := args.String("symbol")
+
+	// 驗證股票代號
+	valid, stockName, err := r.stockService.ValidateStockID(symbol)
+	if err != nil || !valid {
+		return nil, fmt.Errorf("查無此股票代號，請重新確認")
+	}
+
+	news, err := r.stockService.GetStockNews(symbol)
+	if err != nil {
+		r.logger.Error("取得股票新聞失敗", zap.Error(err))
+		return nil, fmt.Errorf("取得新聞失敗，請稍後再試")
+	}
+
+	return stockNewsResponse(stockName, symbol, news), nil
+}
+
+// StockNewsResponse 產生指定新聞的股票新聞訊息，供排程只推播尚未推播過的新聞
+func (r *commandRegistry) StockNewsResponse(symbol string, news []finmindDto.TaiwanNewsResponseData) *Response {
+	stockName := symbol
+	if valid, name, err := r.stockService.ValidateStockID(symbol); err == nil && valid {
+		stockName = name
+	}
+	return stockNewsResponse(stockName, symbol, news)
+}
+
+// stockNewsResponse 股票新聞訊息，每則新聞一個連結按鈕
+func stockNewsResponse(stockName, symbol string, news []finmindDto.TaiwanNewsResponseData) *Response {
+	response := &Response{Title: fmt.Sprintf("⚡️%s(%s)-即時新聞", stockName, symbol)}
+	if len(news) == 0 {
+		response.Blocks = []Block{{Text: "暫無新聞資料"}}
+		return response
+	}
+	response.Buttons = newsButtons(news)
+	return response
+}
+
+// searchSymbol 處理 /s 命令 - 以名稱、代號或拼音搜尋股票
+func (r *commandRegistry) searchSymbol(ctx *Context, args Args) (*Response, error) {
+	query := args.String("query")
+
+	results, err := r.symbolSearch.Search(query, 10)
+	if err != nil {
+		r.logger.Error("搜尋股票失敗", zap.Error(err))
+		return nil, fmt.Errorf("系統錯誤，請稍後再試")
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("查無符合「%s」的股票", query)
+	}
+
+	return &Response{
+		Title:  fmt.Sprintf("🔍 「%s」搜尋結果：", query),
+		Blocks: []Block{{Text: symbol_search.FormatCandidates(results, 0)}},
+	}, nil
+}
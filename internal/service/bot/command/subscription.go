@@ -0,0 +1,246 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// registerSubscriptionCommands 註冊訂閱管理指令
+func (r *commandRegistry) registerSubscriptionCommands() {
+	r.Register(&Command{
+		Name:        "add",
+		Category:    CategorySubscription,
+		Description: "新增訂閱股票",
+		Example:     "/add 2330",
+		Args:        []ArgSpec{symbolArg},
+		AdminOnly:   true,
+		Handler:     r.addStock,
+	})
+	r.Register(&Command{
+		Name:        "del",
+		Category:    CategorySubscription,
+		Description: "刪除訂閱股票",
+		Example:     "/del 2330",
+		Args:        []ArgSpec{symbolArg},
+		AdminOnly:   true,
+		Handler:     r.deleteStock,
+	})
+	r.Register(&Command{
+		Name:        "sub",
+		Category:    CategorySubscription,
+		Description: "訂閱功能 (" + subscriptionItemOptions() + ")，可指定推播時間及時區",
+		Example:     "/sub 3 08:45",
+		ExampleNote: "訂閱每日大盤資訊並於平日 08:45 推播",
+		Args: []ArgSpec{
+			subscriptionItemArg,
+			{Key: "time", Name: "時間", Type: ArgString},
+			scheduleTimezoneArg,
+		},
+		AdminOnly: true,
+		Handler:   r.subscribe,
+	})
+	r.Register(&Command{
+		Name:        "unsub",
+		Category:    CategorySubscription,
+		Description: "取消訂閱功能",
+		Example:     "/unsub 3",
+		Args:        []ArgSpec{subscriptionItemArg},
+		AdminOnly:   true,
+		Handler:     r.unsubscribe,
+	})
+	r.Register(&Command{
+		Name:        "list",
+		Category:    CategorySubscription,
+		Description: "查詢已訂閱功能及股票",
+		Handler:     r.listSubscriptions,
+	})
+}
+
+// subscriptionItemArg 訂閱項目參數
+var subscriptionItemArg = ArgSpec{Key: "item", Name: "項目", Type: ArgString, Required: true}
+
+// subscriptionItemOptions 可訂閱項目說明，例如 1:股票資訊 2:股票新聞
+func subscriptionItemOptions() string {
+	keys := make([]string, 0, len(models.SubscriptionItemMap))
+	for key, item := range models.SubscriptionItemMap {
+		if item == models.SubscriptionItemDefault {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	options := make([]string, 0, len(keys))
+	for _, key := range keys {
+		options = append(options, key+":"+models.SubscriptionItemMap[key].GetName())
+	}
+	return strings.Join(options, " ")
+}
+
+// addStock 處理 /add 命令 - 新增股票訂閱
+func (r *commandRegistry) addStock(ctx *Context, args Args) (*Response, error) {
+	symbol := args.String("symbol")
+
+	// 驗證股票代號
+	valid, _, err := r.stockService.ValidateStockID(symbol)
+	if err != nil || !valid {
+		return nil, fmt.Errorf("無此股票代號，請重新確認")
+	}
+
+	success, err := r.userSubscriptionService.AddUserSubscriptionStock(ctx.UserID, symbol)
+	if err != nil {
+		r.logger.Error("新增股票訂閱失敗", zap.Error(err))
+		return nil, fmt.Errorf("訂閱失敗，請稍後再試")
+	}
+
+	if !success {
+		return NewTextResponse("已訂閱過此股票"), nil
+	}
+	return NewTextResponse("訂閱成功"), nil
+}
+
+// deleteStock 處理 /del 命令 - 刪除股票訂閱
+func (r *commandRegistry) deleteStock(ctx *Context, args Args) (*Response, error) {
+	success, err := r.userSubscriptionService.DeleteUserSubscriptionStock(ctx.UserID, args.String("symbol"))
+	if err != nil {
+		r.logger.Error("刪除股票訂閱失敗", zap.Error(err))
+		return nil, fmt.Errorf("取消訂閱失敗，請稍後再試")
+	}
+
+	if !success {
+		return NewTextResponse("取消訂閱失敗，請檢查是否已訂閱"), nil
+	}
+	return NewTextResponse("取消訂閱成功"), nil
+}
+
+// subscribe 處理 /sub 命令 - 訂閱功能
+func (r *commandRegistry) subscribe(ctx *Context, args Args) (*Response, error) {
+	item, err := parseSubscriptionItemArg(args.String("item"))
+	if err != nil {
+		return nil, err
+	}
+
+	clock, timezone := args.String("time"), args.String("timezone")
+	if clock == "" {
+		return r.updateUserSubscription(ctx.UserID, item, true)
+	}
+
+	// 先驗證時間及時區，避免訂閱成功但排程設定失敗
+	if _, err := ScheduleCron(clock); err != nil {
+		return nil, err
+	}
+	if err := validateTimezone(timezone); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.updateUserSubscription(ctx.UserID, item, true); err != nil {
+		return nil, err
+	}
+	return r.updateSchedule(ctx.UserID, item, clock, timezone)
+}
+
+// unsubscribe 處理 /unsub 命令 - 取消訂閱功能
+func (r *commandRegistry) unsubscribe(ctx *Context, args Args) (*Response, error) {
+	item, err := parseSubscriptionItemArg(args.String("item"))
+	if err != nil {
+		return nil, err
+	}
+	return r.updateUserSubscription(ctx.UserID, item, false)
+}
+
+// updateUserSubscription 更新使用者訂閱狀態
+func (r *commandRegistry) updateUserSubscription(userID uint, subscriptionItem models.SubscriptionItem, status bool) (*Response, error) {
+	name := subscriptionItem.GetName()
+
+	// 檢查是否已經有此訂閱項目
+	existing, err := r.userSubscriptionService.GetUserSubscriptionByItem(userID, subscriptionItem)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("取得訂閱項目失敗", zap.Error(err))
+			return nil, fmt.Errorf("操作失敗，請稍後再試")
+		}
+
+		// 記錄不存在時，取消訂閱表示尚未訂閱
+		if !status {
+			return NewTextResponse(fmt.Sprintf("您尚未訂閱：%s", name)), nil
+		}
+		if err := r.userSubscriptionService.AddUserSubscriptionItem(userID, subscriptionItem); err != nil {
+			r.logger.Error("新增訂閱項目失敗", zap.Error(err))
+			return nil, fmt.Errorf("訂閱失敗，請稍後再試")
+		}
+		return NewTextResponse(fmt.Sprintf("訂閱成功：%s", name)), nil
+	}
+
+	// 狀態相同時不需要更新
+	if existing.Status == status {
+		if status {
+			return NewTextResponse(fmt.Sprintf("已訂閱：%s", name)), nil
+		}
+		return NewTextResponse(fmt.Sprintf("您尚未訂閱：%s", name)), nil
+	}
+
+	if err := r.userSubscriptionService.UpdateUserSubscriptionItem(userID, subscriptionItem, status); err != nil {
+		r.logger.Error("更新訂閱狀態失敗", zap.Error(err))
+		return nil, fmt.Errorf("操作失敗，請稍後再試")
+	}
+
+	if status {
+		return NewTextResponse(fmt.Sprintf("訂閱成功：%s", name)), nil
+	}
+	return NewTextResponse(fmt.Sprintf("取消訂閱成功：%s", name)), nil
+}
+
+// listSubscriptions 處理 /list 命令 - 列出訂閱項目
+func (r *commandRegistry) listSubscriptions(ctx *Context, args Args) (*Response, error) {
+	subscriptions, err := r.userSubscriptionService.GetUserSubscriptionList(ctx.UserID)
+	if err != nil {
+		r.logger.Error("取得使用者訂閱項目失敗", zap.Error(err))
+		return nil, fmt.Errorf("取得訂閱清單失敗")
+	}
+
+	subscriptionStocks, err := r.userSubscriptionService.GetUserSubscriptionStockList(ctx.UserID)
+	if err != nil {
+		r.logger.Error("取得使用者訂閱股票失敗", zap.Error(err))
+		return nil, fmt.Errorf("取得訂閱清單失敗")
+	}
+
+	var features []string
+	for _, sub := range subscriptions {
+		if sub.Status && sub.Feature != nil {
+			line := "• " + sub.Feature.Description
+			if schedule := FormatSchedule(sub); schedule != "" {
+				line += "（" + schedule + "）"
+			}
+			features = append(features, line)
+		}
+	}
+	if len(features) == 0 {
+		features = append(features, "• 尚未訂閱任何功能")
+	}
+
+	// 點擊股票可查詢收盤資訊
+	stocksBlock := Block{Heading: "📈 已訂閱股票："}
+	for _, stock := range subscriptionStocks {
+		if stock.Status {
+			stocksBlock.Fields = append(stocksBlock.Fields, Field{Label: stock.Stock, Action: CommandAction("查詢", "/d "+stock.Stock)})
+		}
+	}
+	if len(stocksBlock.Fields) == 0 {
+		stocksBlock.Text = "• 尚未訂閱任何股票"
+	}
+
+	return &Response{
+		Title: "📋 您目前的訂閱項目",
+		Blocks: []Block{
+			{Heading: "🔔 已訂閱功能：", Text: strings.Join(features, "\n")},
+			stocksBlock,
+		},
+	}, nil
+}
@@ -0,0 +1,190 @@
+// Package discord 提供 Discord Bot 的處理器功能
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/discordbot"
+	"github.com/tian841224/stock-bot/internal/infrastructure/discordbot/dto"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/internal/service/user"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// 斜線指令說明字元上限
+const commandDescriptionMaxLength = 100
+
+// DiscordServiceHandler Discord 服務處理器介面
+type DiscordServiceHandler interface {
+	HandleInteraction(interaction *dto.Interaction) error
+	RegisterCommands() error
+}
+
+type discordServiceHandler struct {
+	botClient   *discordbot.DiscordBotClient
+	registry    command.CommandRegistry
+	renderer    *DiscordRenderer
+	userService user.UserService
+	logger      logger.Logger
+}
+
+func NewDiscordServiceHandler(botClient *discordbot.DiscordBotClient, registry command.CommandRegistry, renderer *DiscordRenderer, userService user.UserService, log logger.Logger) DiscordServiceHandler {
+	return &discordServiceHandler{
+		botClient:   botClient,
+		registry:    registry,
+		renderer:    renderer,
+		userService: userService,
+		logger:      log,
+	}
+}
+
+// RegisterCommands 依指令註冊表註冊斜線指令
+func (s *discordServiceHandler) RegisterCommands() error {
+	commands := buildApplicationCommands(s.registry.Commands())
+	if err := s.botClient.RegisterCommands(commands); err != nil {
+		return err
+	}
+	s.logger.Info("Discord 斜線指令註冊完成", zap.Int("count", len(commands)))
+	return nil
+}
+
+// HandleInteraction 處理已延遲回應的互動事件
+func (s *discordServiceHandler) HandleInteraction(interaction *dto.Interaction) error {
+	if interaction.Data == nil {
+		return nil
+	}
+
+	switch interaction.Type {
+	case dto.InteractionTypeApplicationCommand:
+		return s.processCommand(interaction)
+	case dto.InteractionTypeMessageComponent:
+		return s.processComponent(interaction)
+	}
+	return nil
+}
+
+// processCommand 處理斜線指令，結果取代「思考中」的延遲回應
+func (s *discordServiceHandler) processCommand(interaction *dto.Interaction) error {
+	data := interaction.Data
+	response, err := s.executeCommand(interaction, commandText(data), func(ctx *command.Context) (*command.Response, error) {
+		return s.registry.ExecuteNamed(ctx, data.Name, commandOptions(data))
+	})
+	if errors.Is(err, command.ErrUnknownCommand) {
+		return s.renderer.SendError(interaction.Token, fmt.Errorf("查無此指令"))
+	}
+	if err != nil {
+		return s.renderer.SendError(interaction.Token, err)
+	}
+
+	return s.renderer.EditOriginal(interaction.Token, response)
+}
+
+// processComponent 處理按鈕互動，custom_id 即為要執行的指令
+func (s *discordServiceHandler) processComponent(interaction *dto.Interaction) error {
+	text := interaction.Data.CustomID
+	if text == "" || strings.HasPrefix(text, customIDNoopPrefix) {
+		return nil
+	}
+
+	response, err := s.executeCommand(interaction, text, func(ctx *command.Context) (*command.Response, error) {
+		return s.registry.Execute(ctx, text)
+	})
+	if errors.Is(err, command.ErrUnknownCommand) {
+		return nil
+	}
+	if err != nil {
+		return s.renderer.FollowUpError(interaction.Token, err)
+	}
+
+	// 分頁等回應更新按鈕所在的訊息，其餘另發新訊息
+	if response.Replace {
+		return s.renderer.EditOriginal(interaction.Token, response)
+	}
+	return s.renderer.FollowUp(interaction.Token, response)
+}
+
+// executeCommand 取得使用者後執行指令，text 用於記錄
+func (s *discordServiceHandler) executeCommand(interaction *dto.Interaction, text string, execute func(ctx *command.Context) (*command.Response, error)) (*command.Response, error) {
+	discordUser := interaction.GetUser()
+	if discordUser == nil {
+		return nil, fmt.Errorf("無法取得使用者資訊")
+	}
+
+	s.logger.Info("收到 Discord 指令",
+		zap.String("user_id", discordUser.ID),
+		zap.String("message", text))
+
+	dbUser, err := s.userService.GetOrCreate(discordUser.ID, models.UserTypeDiscord)
+	if err != nil {
+		s.logger.Error("建立或取得使用者失敗", zap.Error(err))
+		return nil, fmt.Errorf("系統錯誤，請稍後再試")
+	}
+
+	ctx := &command.Context{
+		Platform:  models.UserTypeDiscord,
+		AccountID: discordUser.ID,
+		UserID:    dbUser.ID,
+	}
+	return execute(ctx)
+}
+
+// commandOptions 斜線指令的參數，依參數鍵傳入，未填的選填參數不影響其他參數的位置
+func commandOptions(data *dto.InteractionData) map[string]string {
+	values := make(map[string]string, len(data.Options))
+	for _, option := range data.Options {
+		values[option.Name] = strings.TrimSpace(fmt.Sprint(option.Value))
+	}
+	return values
+}
+
+// commandText 斜線指令的文字表示，用於記錄，例如 /d symbol=2330 date=2025-01-15
+func commandText(data *dto.InteractionData) string {
+	parts := []string{"/" + data.Name}
+	for _, option := range data.Options {
+		parts = append(parts, fmt.Sprintf("%s=%v", option.Name, option.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// buildApplicationCommands 將註冊表的指令轉為斜線指令定義
+func buildApplicationCommands(commands []*command.Command) []dto.ApplicationCommand {
+	minValue := 1
+	result := make([]dto.ApplicationCommand, 0, len(commands))
+	for _, cmd := range commands {
+		// 維運指令僅限 Telegram 管理員聊天室，不註冊為斜線指令
+		if cmd.OperatorOnly {
+			continue
+		}
+		description := cmd.Description
+		if description == "" {
+			description = cmd.Name
+		}
+
+		appCommand := dto.ApplicationCommand{
+			Name:        cmd.Name,
+			Type:        dto.ApplicationCommandTypeChatInput,
+			Description: command.TruncateRunes(description, commandDescriptionMaxLength),
+		}
+
+		for _, arg := range cmd.Args {
+			option := dto.ApplicationCommandOption{
+				Type:        dto.ApplicationCommandOptionString,
+				Name:        arg.Key,
+				Description: arg.Name,
+				Required:    arg.Required,
+			}
+			if arg.Type == command.ArgInt {
+				option.Type = dto.ApplicationCommandOptionInteger
+				option.MinValue = &minValue
+			}
+			appCommand.Options = append(appCommand.Options, option)
+		}
+		result = append(result, appCommand)
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/infrastructure/discordbot"
+	"github.com/tian841224/stock-bot/internal/infrastructure/discordbot/dto"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+)
+
+// Discord 訊息限制
+const (
+	embedColor                = 0x5865F2 // 嵌入內容左側色條
+	embedTitleMaxLength       = 256      // 嵌入標題字元上限
+	embedDescriptionMaxLength = 4096     // 嵌入內容字元上限
+	contentMaxLength          = 2000     // 一般文字訊息字元上限
+	actionRowsMax             = 5        // 每則訊息按鈕列數上限
+	buttonsPerRowMax          = 5        // 每列按鈕數上限
+	buttonLabelMaxLength      = 80       // 按鈕文字上限
+	customIDMaxLength         = 100      // custom_id 字元上限
+	// customIDNoopPrefix 純顯示用按鈕的 custom_id 前綴，同一則訊息內 custom_id 不可重複
+	customIDNoopPrefix = "noop:"
+	defaultImageName   = "chart.png"
+)
+
+// DiscordRenderer 將指令回應轉為 Discord 嵌入訊息
+type DiscordRenderer struct {
+	botClient *discordbot.DiscordBotClient
+}
+
+func NewDiscordRenderer(botClient *discordbot.DiscordBotClient) *DiscordRenderer {
+	return &DiscordRenderer{botClient: botClient}
+}
+
+// EditOriginal 以回應內容取代延遲回應或按鈕所在的原訊息
+func (r *DiscordRenderer) EditOriginal(interactionToken string, response *command.Response) error {
+	message, files := RenderMessage(response)
+	return r.botClient.EditOriginalResponse(interactionToken, message, files)
+}
+
+// FollowUp 另發新訊息回應互動
+func (r *DiscordRenderer) FollowUp(interactionToken string, response *command.Response) error {
+	message, files := RenderMessage(response)
+	return r.botClient.CreateFollowupMessage(interactionToken, message, files)
+}
+
+// SendDirect 私訊使用者，供排程推播使用
+func (r *DiscordRenderer) SendDirect(userID string, response *command.Response) error {
+	message, files := RenderMessage(response)
+	return r.botClient.SendDirectMessage(userID, message, files)
+}
+
+// SendError 以錯誤訊息取代延遲回應
+func (r *DiscordRenderer) SendError(interactionToken string, err error) error {
+	return r.botClient.EditOriginalResponse(interactionToken, textMessage(err.Error()), nil)
+}
+
+// FollowUpError 以新訊息回覆錯誤，用於按鈕互動，避免覆蓋原訊息
+func (r *DiscordRenderer) FollowUpError(interactionToken string, err error) error {
+	return r.botClient.CreateFollowupMessage(interactionToken, textMessage(err.Error()), nil)
+}
+
+// RenderMessage 將回應轉為嵌入訊息，圖片以附件上傳並顯示於嵌入內容中
+func RenderMessage(response *command.Response) (*dto.Message, []dto.File) {
+	embed := dto.Embed{
+		Title:       command.TruncateRunes(response.Title, embedTitleMaxLength),
+		Description: command.TruncateRunes(renderDescription(response.Blocks), embedDescriptionMaxLength),
+		Color:       embedColor,
+	}
+
+	message := &dto.Message{
+		Embeds:      []dto.Embed{},
+		Components:  renderComponents(response.Buttons),
+		Attachments: []dto.Attachment{},
+	}
+
+	var files []dto.File
+	if response.Image != nil && len(response.Image.Data) > 0 {
+		name := response.Image.Name
+		if name == "" {
+			name = defaultImageName
+		}
+		files = append(files, dto.File{Name: name, Data: response.Image.Data})
+		message.Attachments = append(message.Attachments, dto.Attachment{ID: 0, Filename: name})
+		embed.Image = &dto.EmbedImage{URL: "attachment://" + name}
+	}
+
+	if embed.Title != "" || embed.Description != "" || embed.Image != nil {
+		message.Embeds = append(message.Embeds, embed)
+	}
+
+	return message, files
+}
+
+// renderDescription 將內容區塊轉為 Markdown，欄位與表格以程式碼區塊對齊
+func renderDescription(blocks []command.Block) string {
+	var sections []string
+	for _, block := range blocks {
+		var lines []string
+		if block.Heading != "" {
+			lines = append(lines, "**"+block.Heading+"**")
+		}
+		if block.Text != "" {
+			lines = append(lines, block.Text)
+		}
+		if len(block.Fields) > 0 {
+			lines = append(lines, "```\n"+command.FormatFields(block.Fields)+"\n```")
+		}
+		if block.Table != nil {
+			lines = append(lines, "```\n"+command.FormatTable(block.Table)+"\n```")
+		}
+		if len(lines) > 0 {
+			sections = append(sections, strings.Join(lines, "\n"))
+		}
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+// renderComponents 將按鈕轉為按鈕列，指令按鈕以指令本身作為 custom_id
+func renderComponents(buttons [][]command.Button) []dto.Component {
+	rows := []dto.Component{}
+	noopCount := 0
+	for _, buttonRow := range buttons {
+		if len(rows) >= actionRowsMax {
+			break
+		}
+
+		var row []dto.Component
+		for _, button := range buttonRow {
+			if len(row) >= buttonsPerRowMax {
+				break
+			}
+			component := dto.Component{
+				Type:  dto.ComponentTypeButton,
+				Label: command.TruncateRunes(button.Label, buttonLabelMaxLength),
+			}
+			switch {
+			case button.URL != "":
+				component.Style = dto.ButtonStyleLink
+				component.URL = button.URL
+			case button.Command != "" && len(button.Command) <= customIDMaxLength:
+				component.Style = dto.ButtonStyleSecondary
+				component.CustomID = button.Command
+			case button.Command == "":
+				component.Style = dto.ButtonStyleSecondary
+				component.CustomID = fmt.Sprintf("%s%d", customIDNoopPrefix, noopCount)
+				component.Disabled = true
+				noopCount++
+			default:
+				continue
+			}
+			row = append(row, component)
+		}
+
+		if len(row) > 0 {
+			rows = append(rows, dto.Component{Type: dto.ComponentTypeActionRow, Components: row})
+		}
+	}
+	return rows
+}
+
+// textMessage 建立純文字訊息，並清除原有的嵌入內容、按鈕與附件
+func textMessage(text string) *dto.Message {
+	return &dto.Message{
+		Content:     command.TruncateRunes(text, contentMaxLength),
+		Embeds:      []dto.Embed{},
+		Components:  []dto.Component{},
+		Attachments: []dto.Attachment{},
+	}
+}
@@ -0,0 +1,385 @@
+package line
+
+import (
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+
+	"github.com/line/line-bot-sdk-go/linebot"
+)
+
+// Flex Message 限制與配色，台股慣例紅漲綠跌
+const (
+	carouselBubblesMax = 12  // 輪播最多 12 個泡泡
+	altTextMaxLength   = 400 // 通知預覽文字上限
+	footerButtonsMax   = 6   // 頁尾按鈕上限，避免泡泡過長
+
+	colorUp        = "#E53935"
+	colorDown      = "#2E7D32"
+	colorText      = "#333333"
+	colorSubText   = "#888888"
+	colorUpBg      = "#FDECEA"
+	colorDownBg    = "#E8F5E9"
+	colorHeaderBg  = "#F5F5F5"
+	colorSeparator = "#E0E0E0"
+)
+
+// shouldRenderFlex 有欄位或表格的結構化回應才使用 Flex Message，純文字回應維持文字訊息
+func shouldRenderFlex(response *command.Response) bool {
+	for _, block := range response.Blocks {
+		if len(block.Fields) > 0 || block.Table != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// renderFlexMessage 將回應轉為 Flex Message
+// 多個帶標題的欄位區塊（例如交易量排行）以輪播呈現，其餘合併為單一泡泡
+func renderFlexMessage(response *command.Response) *linebot.FlexMessage {
+	var container linebot.FlexContainer
+	if isCarousel(response) {
+		container = renderCarousel(response)
+	} else {
+		container = renderBubble(response)
+	}
+	return linebot.NewFlexMessage(command.TruncateRunes(flexAltText(response), altTextMaxLength), container)
+}
+
+// isCarousel 每個區塊都是獨立項目（有標題與欄位）時以輪播呈現
+func isCarousel(response *command.Response) bool {
+	if len(response.Blocks) < 2 {
+		return false
+	}
+	for _, block := range response.Blocks {
+		if block.Heading == "" || len(block.Fields) == 0 || block.Table != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// renderCarousel 每個區塊一個泡泡，回應的按鈕（例如分頁）放在最後一個泡泡
+func renderCarousel(response *command.Response) *linebot.CarouselContainer {
+	blocks := response.Blocks
+	bubblesMax := carouselBubblesMax
+	if len(response.Buttons) > 0 {
+		bubblesMax--
+	}
+	if len(blocks) > bubblesMax {
+		blocks = blocks[:bubblesMax]
+	}
+
+	carousel := &linebot.CarouselContainer{Type: linebot.FlexContainerTypeCarousel}
+	for _, block := range blocks {
+		bubble := &linebot.BubbleContainer{
+			Type:   linebot.FlexContainerTypeBubble,
+			Size:   linebot.FlexBubbleSizeTypeKilo,
+			Header: blockHeader(response.Title, block),
+			Body:   verticalBox(fieldRows(block.Fields)...),
+			Styles: &linebot.BubbleStyle{Header: &linebot.BlockStyle{BackgroundColor: trendBackground(block.Trend)}},
+		}
+		if block.Action != nil {
+			bubble.Footer = verticalBox(flexButton(*block.Action, linebot.FlexButtonStyleTypeLink))
+		}
+		carousel.Contents = append(carousel.Contents, bubble)
+	}
+
+	if footer := renderFooter(response.Buttons); footer != nil {
+		carousel.Contents = append(carousel.Contents, &linebot.BubbleContainer{
+			Type: linebot.FlexContainerTypeBubble,
+			Size: linebot.FlexBubbleSizeTypeKilo,
+			Body: verticalBox(&linebot.TextComponent{
+				Type:  linebot.FlexComponentTypeText,
+				Text:  flexAltText(response),
+				Wrap:  true,
+				Size:  linebot.FlexTextSizeTypeSm,
+				Color: colorSubText,
+			}),
+			Footer: footer,
+		})
+	}
+	return carousel
+}
+
+// renderBubble 將所有區塊合併為單一泡泡，區塊間以分隔線區隔
+func renderBubble(response *command.Response) *linebot.BubbleContainer {
+	var contents []linebot.FlexComponent
+	for i, block := range response.Blocks {
+		if i > 0 {
+			contents = append(contents, &linebot.SeparatorComponent{
+				Type:   linebot.FlexComponentTypeSeparator,
+				Margin: linebot.FlexComponentMarginTypeLg,
+				Color:  colorSeparator,
+			})
+		}
+		contents = append(contents, blockContents(block)...)
+	}
+
+	bubble := &linebot.BubbleContainer{
+		Type:   linebot.FlexContainerTypeBubble,
+		Size:   linebot.FlexBubbleSizeTypeMega,
+		Body:   verticalBox(contents...),
+		Footer: renderFooter(response.Buttons),
+	}
+	if response.Title != "" {
+		bubble.Header = verticalBox(&linebot.TextComponent{
+			Type:   linebot.FlexComponentTypeText,
+			Text:   response.Title,
+			Wrap:   true,
+			Weight: linebot.FlexTextWeightTypeBold,
+			Size:   linebot.FlexTextSizeTypeMd,
+			Color:  colorText,
+		})
+		bubble.Styles = &linebot.BubbleStyle{Header: &linebot.BlockStyle{BackgroundColor: colorHeaderBg}}
+	}
+	return bubble
+}
+
+// blockHeader 輪播泡泡的標題，回應標題以小字顯示於上方
+func blockHeader(title string, block command.Block) *linebot.BoxComponent {
+	var contents []linebot.FlexComponent
+	if title != "" {
+		contents = append(contents, &linebot.TextComponent{
+			Type:  linebot.FlexComponentTypeText,
+			Text:  title,
+			Size:  linebot.FlexTextSizeTypeXxs,
+			Color: colorSubText,
+		})
+	}
+	contents = append(contents, &linebot.TextComponent{
+		Type:   linebot.FlexComponentTypeText,
+		Text:   block.Heading,
+		Wrap:   true,
+		Weight: linebot.FlexTextWeightTypeBold,
+		Size:   linebot.FlexTextSizeTypeMd,
+		Color:  trendColor(block.Trend, colorText),
+	})
+
+	header := verticalBox(contents...)
+	if block.Action != nil {
+		header.Action = flexAction(*block.Action)
+	}
+	return header
+}
+
+// blockContents 單一區塊的內容：標題、文字、欄位、表格
+func blockContents(block command.Block) []linebot.FlexComponent {
+	var contents []linebot.FlexComponent
+	if block.Heading != "" {
+		heading := &linebot.TextComponent{
+			Type:   linebot.FlexComponentTypeText,
+			Text:   block.Heading,
+			Wrap:   true,
+			Weight: linebot.FlexTextWeightTypeBold,
+			Color:  trendColor(block.Trend, colorText),
+		}
+		if block.Action != nil {
+			heading.Action = flexAction(*block.Action)
+		}
+		contents = append(contents, heading)
+	}
+	if block.Text != "" {
+		contents = append(contents, &linebot.TextComponent{
+			Type:  linebot.FlexComponentTypeText,
+			Text:  block.Text,
+			Wrap:  true,
+			Size:  linebot.FlexTextSizeTypeSm,
+			Color: colorText,
+		})
+	}
+	contents = append(contents, fieldRows(block.Fields)...)
+	if block.Table != nil {
+		contents = append(contents, tableRows(block.Table)...)
+	}
+	return contents
+}
+
+// fieldRows 每個欄位一列，名稱靠左、數值靠右並依漲跌上色
+func fieldRows(fields []command.Field) []linebot.FlexComponent {
+	rows := make([]linebot.FlexComponent, 0, len(fields))
+	for _, field := range fields {
+		label := &linebot.TextComponent{
+			Type:  linebot.FlexComponentTypeText,
+			Text:  field.Label,
+			Size:  linebot.FlexTextSizeTypeSm,
+			Color: colorSubText,
+			Wrap:  true,
+			Flex:  linebot.IntPtr(2),
+		}
+
+		var row *linebot.BoxComponent
+		if field.Value == "" {
+			// 清單項目只顯示名稱，可點擊時加上箭頭提示
+			label.Color = colorText
+			if field.Action != nil {
+				label.Text = field.Label + " ›"
+			}
+			row = horizontalBox(label)
+		} else {
+			row = horizontalBox(label, &linebot.TextComponent{
+				Type:   linebot.FlexComponentTypeText,
+				Text:   field.Value,
+				Size:   linebot.FlexTextSizeTypeSm,
+				Weight: linebot.FlexTextWeightTypeBold,
+				Color:  trendColor(field.Trend, colorText),
+				Align:  linebot.FlexComponentAlignTypeEnd,
+				Wrap:   true,
+				Flex:   linebot.IntPtr(3),
+			})
+		}
+		if field.Action != nil {
+			row.Action = flexAction(*field.Action)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// tableRows 表格每列一個水平排列的 box，首欄靠左、其餘靠右
+func tableRows(table *command.Table) []linebot.FlexComponent {
+	var rows []linebot.FlexComponent
+	appendRow := func(cells []string, header bool) {
+		var contents []linebot.FlexComponent
+		for i, cell := range cells {
+			text := &linebot.TextComponent{
+				Type:  linebot.FlexComponentTypeText,
+				Text:  cell,
+				Size:  linebot.FlexTextSizeTypeXs,
+				Color: colorText,
+				Align: linebot.FlexComponentAlignTypeEnd,
+				Flex:  linebot.IntPtr(1),
+			}
+			if i == 0 {
+				text.Align = linebot.FlexComponentAlignTypeStart
+			}
+			if header {
+				text.Weight = linebot.FlexTextWeightTypeBold
+				text.Color = colorSubText
+			}
+			if text.Text == "" {
+				text.Text = "-"
+			}
+			contents = append(contents, text)
+		}
+		if len(contents) > 0 {
+			rows = append(rows, horizontalBox(contents...))
+		}
+	}
+
+	if len(table.Header) > 0 {
+		appendRow(table.Header, true)
+	}
+	for _, row := range table.Rows {
+		appendRow(row, false)
+	}
+	return rows
+}
+
+// renderFooter 將回應的按鈕轉為頁尾，每列按鈕水平排列，純顯示用按鈕轉為文字
+func renderFooter(buttons [][]command.Button) *linebot.BoxComponent {
+	var rows []linebot.FlexComponent
+	count := 0
+	for _, buttonRow := range buttons {
+		var contents []linebot.FlexComponent
+		for _, button := range buttonRow {
+			if count >= footerButtonsMax {
+				break
+			}
+			if button.Command == "" && button.URL == "" {
+				contents = append(contents, &linebot.TextComponent{
+					Type:    linebot.FlexComponentTypeText,
+					Text:    button.Label,
+					Size:    linebot.FlexTextSizeTypeSm,
+					Color:   colorSubText,
+					Align:   linebot.FlexComponentAlignTypeCenter,
+					Gravity: linebot.FlexComponentGravityTypeCenter,
+				})
+				continue
+			}
+			contents = append(contents, flexButton(button, linebot.FlexButtonStyleTypeSecondary))
+			count++
+		}
+		if len(contents) > 0 {
+			rows = append(rows, horizontalBox(contents...))
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return verticalBox(rows...)
+}
+
+// flexButton 建立按鈕元件
+func flexButton(button command.Button, style linebot.FlexButtonStyleType) *linebot.ButtonComponent {
+	return &linebot.ButtonComponent{
+		Type:   linebot.FlexComponentTypeButton,
+		Action: flexAction(button),
+		Style:  style,
+		Height: linebot.FlexButtonHeightTypeSm,
+		Flex:   linebot.IntPtr(1),
+	}
+}
+
+// flexAction 網址按鈕開啟連結，指令按鈕以 postback 傳送指令並顯示按鈕文字
+func flexAction(button command.Button) linebot.TemplateAction {
+	label := command.TruncateRunes(button.Label, actionLabelMaxLength)
+	if button.URL != "" {
+		return linebot.NewURIAction(label, button.URL)
+	}
+	return linebot.NewPostbackAction(label, button.Command, "", label)
+}
+
+// flexAltText 通知及不支援 Flex Message 時顯示的文字
+func flexAltText(response *command.Response) string {
+	if response.Title != "" {
+		return response.Title
+	}
+	for _, block := range response.Blocks {
+		if block.Heading != "" {
+			return block.Heading
+		}
+	}
+	return defaultButtonsTemplate
+}
+
+// trendColor 依漲跌取得文字顏色
+func trendColor(trend command.Trend, defaultColor string) string {
+	switch trend {
+	case command.TrendUp:
+		return colorUp
+	case command.TrendDown:
+		return colorDown
+	default:
+		return defaultColor
+	}
+}
+
+// trendBackground 依漲跌取得標題背景色
+func trendBackground(trend command.Trend) string {
+	switch trend {
+	case command.TrendUp:
+		return colorUpBg
+	case command.TrendDown:
+		return colorDownBg
+	default:
+		return colorHeaderBg
+	}
+}
+
+func verticalBox(contents ...linebot.FlexComponent) *linebot.BoxComponent {
+	return &linebot.BoxComponent{
+		Type:     linebot.FlexComponentTypeBox,
+		Layout:   linebot.FlexBoxLayoutTypeVertical,
+		Contents: contents,
+		Spacing:  linebot.FlexComponentSpacingTypeSm,
+	}
+}
+
+func horizontalBox(contents ...linebot.FlexComponent) *linebot.BoxComponent {
+	return &linebot.BoxComponent{
+		Type:     linebot.FlexComponentTypeBox,
+		Layout:   linebot.FlexBoxLayoutTypeHorizontal,
+		Contents: contents,
+		Spacing:  linebot.FlexComponentSpacingTypeSm,
+	}
+}
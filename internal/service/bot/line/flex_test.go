@@ -0,0 +1,61 @@
+package line
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+
+	"github.com/line/line-bot-sdk-go/linebot"
+)
+
+func TestRenderFlexMessageCarousel(t *testing.T) {
+	response := &command.Response{
+		Title: "🔝今日交易量前二十 (第 1-2 名)",
+		Blocks: []command.Block{
+			{
+				Heading: "台積電 (2330)",
+				Fields:  []command.Field{{Label: "漲跌幅", Value: "+5.00 (0.5%)", Trend: command.TrendUp}},
+				Trend:   command.TrendUp,
+				Action:  command.CommandAction("查看收盤資訊", "/d 2330"),
+			},
+			{
+				Heading: "鴻海 (2317)",
+				Fields:  []command.Field{{Label: "漲跌幅", Value: "-1.00 (0.5%)", Trend: command.TrendDown}},
+				Trend:   command.TrendDown,
+			},
+		},
+		Buttons: [][]command.Button{{command.LabelButton("1/4"), command.CommandButton("下一頁 ➡️", "/t 2")}},
+	}
+
+	if !shouldRenderFlex(response) {
+		t.Fatal("shouldRenderFlex() = false, want true for field blocks")
+	}
+
+	message := renderFlexMessage(response)
+	carousel, ok := message.Contents.(*linebot.CarouselContainer)
+	if !ok {
+		t.Fatalf("Contents = %T, want *linebot.CarouselContainer", message.Contents)
+	}
+	// 兩個項目加上分頁泡泡
+	if len(carousel.Contents) != 3 {
+		t.Errorf("len(carousel.Contents) = %d, want 3", len(carousel.Contents))
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, want := range []string{colorUp, colorDown, `"type":"postback"`, `"data":"/d 2330"`, `"data":"/t 2"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("flex message missing %s", want)
+		}
+	}
+}
+
+func TestShouldRenderFlexSkipsPlainText(t *testing.T) {
+	if shouldRenderFlex(command.NewTextResponse("訂閱成功")) {
+		t.Error("shouldRenderFlex() = true, want false for text response")
+	}
+}
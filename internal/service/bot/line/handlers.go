@@ -1,11 +1,11 @@
 package line
 
 import (
-	"strconv"
-	"strings"
-	"time"
+	"errors"
+	"fmt"
 
 	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
 	"github.com/tian841224/stock-bot/internal/service/user"
 	"github.com/tian841224/stock-bot/pkg/logger"
 
@@ -13,165 +13,175 @@ import (
 
 	"github.com/line/line-bot-sdk-go/linebot"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// onboardingMessage 加入好友或群組時的歡迎訊息
+const onboardingMessage = "歡迎使用台股機器人！\n可直接輸入指令，或點選下方選單快速查詢。"
+
 // LineServiceHandler LINE 服務處理器介面
 type LineServiceHandler interface {
 	HandleTextMessage(event *linebot.Event, message *linebot.TextMessage) error
+	HandlePostback(event *linebot.Event) error
+	HandleFollow(event *linebot.Event) error
+	HandleUnfollow(event *linebot.Event) error
+	HandleJoin(event *linebot.Event) error
 }
 
 // lineServiceHandler 處理對話邏輯
 type lineServiceHandler struct {
-	botClient      *linebotInfra.LineBotClient
-	commandHandler *LineCommandHandler
-	userService    user.UserService
-	logger         logger.Logger
+	botClient   *linebotInfra.LineBotClient
+	registry    command.CommandRegistry
+	renderer    *LineRenderer
+	userService user.UserService
+	logger      logger.Logger
 }
 
 // NewBotService 創建 service
 func NewBotService(
 	botClient *linebotInfra.LineBotClient,
-	commandHandler *LineCommandHandler,
+	registry command.CommandRegistry,
+	renderer *LineRenderer,
 	userService user.UserService,
 	log logger.Logger,
 ) LineServiceHandler {
 	return &lineServiceHandler{
-		botClient:      botClient,
-		commandHandler: commandHandler,
-		userService:    userService,
-		logger:         log,
+		botClient:   botClient,
+		registry:    registry,
+		renderer:    renderer,
+		userService: userService,
+		logger:      log,
 	}
 }
 
-// parseMessageArgs 解析訊息參數
-func parseMessageArgs(messageText string) (command string, arg1 string, arg2 string) {
-	parts := strings.Fields(messageText)
-	if len(parts) == 0 {
-		return "", "", ""
+// HandleTextMessage 處理文字訊息
+func (s *lineServiceHandler) HandleTextMessage(event *linebot.Event, message *linebot.TextMessage) error {
+	if message.Text == "" {
+		return nil
 	}
 
-	command = parts[0]
-	if len(parts) > 1 {
-		arg1 = parts[1]
-	}
-	if len(parts) > 2 {
-		arg2 = parts[2]
-	}
-	return command, arg1, arg2
-}
+	userID := event.Source.UserID
+	messageText := message.Text
 
-// getDefaultDateForTodayPrice 取得今日股價的預設日期
-func getDefaultDateForTodayPrice() string {
-	taipeiLocation, _ := time.LoadLocation("Asia/Taipei")
-	now := time.Now().In(taipeiLocation)
-	marketOpenTime := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, taipeiLocation)
+	s.logger.Info("收到 LINE 訊息",
+		zap.String("user_id", userID),
+		zap.String("message", messageText))
 
-	if now.Before(marketOpenTime) {
-		return now.AddDate(0, 0, -1).Format("2006-01-02")
+	response, err := s.executeCommand(userID, messageText)
+	if errors.Is(err, command.ErrUnknownCommand) {
+		reply := "你說了: " + messageText
+		return s.botClient.ReplyMessage(event.ReplyToken, reply)
 	}
-	return now.Format("2006-01-02")
-}
-
-// parseMarketInfoCount 解析大盤資訊的顯示筆數
-func parseMarketInfoCount(arg1 string) int {
-	count := 1
-	if arg1 == "" {
-		return count
+	if err != nil {
+		return s.renderer.ReplyError(event.ReplyToken, err)
 	}
 
-	parsedCount, err := strconv.Atoi(arg1)
-	if err == nil && parsedCount > 0 {
-		count = parsedCount
-	}
-	return count
+	return s.renderer.Reply(event.ReplyToken, response)
 }
 
-// HandleTextMessage 處理文字訊息
-func (s *lineServiceHandler) HandleTextMessage(event *linebot.Event, message *linebot.TextMessage) error {
-	if message.Text == "" {
+// HandlePostback 處理 Flex、按鈕樣板及圖文選單的 postback，data 即為要執行的指令
+// LINE 無法編輯已發送的訊息，換頁等操作一律回覆新訊息
+func (s *lineServiceHandler) HandlePostback(event *linebot.Event) error {
+	if event.Postback == nil || event.Postback.Data == "" {
 		return nil
 	}
 
 	userID := event.Source.UserID
-	messageText := message.Text
+	data := event.Postback.Data
 
-	s.logger.Info("收到 LINE 訊息",
+	s.logger.Info("收到 LINE postback",
 		zap.String("user_id", userID),
-		zap.String("message", messageText))
+		zap.String("data", data))
+
+	response, err := s.executeCommand(userID, data)
+	if errors.Is(err, command.ErrUnknownCommand) {
+		s.logger.Warn("未知的 postback 指令", zap.String("data", data))
+		return nil
+	}
+	if err != nil {
+		return s.renderer.ReplyError(event.ReplyToken, err)
+	}
+
+	return s.renderer.Reply(event.ReplyToken, response)
+}
+
+// HandleFollow 處理加入好友及解除封鎖，建立或重新啟用使用者並回覆新手指南
+func (s *lineServiceHandler) HandleFollow(event *linebot.Event) error {
+	userID := event.Source.UserID
+
+	s.logger.Info("LINE 使用者加入好友", zap.String("user_id", userID))
 
-	_, err := s.userService.GetOrCreate(userID, models.UserTypeLine)
+	dbUser, err := s.userService.GetOrCreate(userID, models.UserTypeLine)
 	if err != nil {
 		s.logger.Error("建立或取得使用者失敗", zap.Error(err))
 		return s.botClient.ReplyMessage(event.ReplyToken, "系統錯誤，請稍後再試")
 	}
 
-	command, arg1, arg2 := parseMessageArgs(messageText)
-	if command == "" {
+	// 解除封鎖時恢復推播
+	if !dbUser.Status {
+		if err := s.userService.UpdateUserStatus(dbUser.ID, true); err != nil {
+			s.logger.Error("重新啟用使用者失敗", zap.Uint("userID", dbUser.ID), zap.Error(err))
+		}
+	}
+
+	return s.replyOnboarding(event.ReplyToken, &command.Context{
+		Platform:  models.UserTypeLine,
+		AccountID: userID,
+		UserID:    dbUser.ID,
+	})
+}
+
+// HandleUnfollow 處理封鎖，停用使用者避免持續推播，封鎖事件無法回覆
+func (s *lineServiceHandler) HandleUnfollow(event *linebot.Event) error {
+	userID := event.Source.UserID
+
+	s.logger.Info("LINE 使用者封鎖", zap.String("user_id", userID))
+
+	dbUser, err := s.userService.GetUserByAccountID(userID, models.UserTypeLine)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil
 	}
+	if err != nil {
+		return err
+	}
+	if !dbUser.Status {
+		return nil
+	}
+	return s.userService.UpdateUserStatus(dbUser.ID, false)
+}
+
+// HandleJoin 處理機器人被加入群組或聊天室，回覆指令指南
+func (s *lineServiceHandler) HandleJoin(event *linebot.Event) error {
+	s.logger.Info("LINE 機器人加入群組",
+		zap.String("group_id", event.Source.GroupID),
+		zap.String("room_id", event.Source.RoomID))
 
-	return s.executeCommand(command, userID, event.ReplyToken, arg1, arg2, messageText)
+	return s.replyOnboarding(event.ReplyToken, &command.Context{Platform: models.UserTypeLine})
 }
 
-// executeCommand 執行對應的命令
-func (s *lineServiceHandler) executeCommand(command, userID, replyToken, arg1, arg2, messageText string) error {
-	commandMap := map[string]func() error{
-		"/start": func() error {
-			return s.commandHandler.CommandStart(replyToken)
-		},
-		"/k": func() error {
-			return s.commandHandler.CommandHistoricalCandles(replyToken, arg1)
-		},
-		"/p": func() error {
-			return s.commandHandler.CommandPerformanceChart(replyToken, arg1)
-		},
-		"/d": func() error {
-			dateArg := arg2
-			if dateArg == "" {
-				dateArg = getDefaultDateForTodayPrice()
-			}
-			return s.commandHandler.CommandTodayStockPrice(replyToken, arg1, dateArg)
-		},
-		"/t": func() error {
-			return s.commandHandler.CommandTopVolumeItems(replyToken)
-		},
-		"/i": func() error {
-			return s.commandHandler.CommandStockInfo(replyToken, arg1, arg2)
-		},
-		"/r": func() error {
-			return s.commandHandler.CommandRevenue(replyToken, arg1)
-		},
-		"/n": func() error {
-			return s.commandHandler.CommandNews(replyToken, arg1)
-		},
-		"/m": func() error {
-			count := parseMarketInfoCount(arg1)
-			return s.commandHandler.CommandDailyMarketInfo(replyToken, count)
-		},
-		"/sub": func() error {
-			return s.commandHandler.CommandSubscribe(userID, replyToken, arg1)
-		},
-		"/unsub": func() error {
-			return s.commandHandler.CommandUnsubscribe(userID, replyToken, arg1)
-		},
-		"/add": func() error {
-			return s.commandHandler.CommandAddStock(userID, replyToken, arg1)
-		},
-		"/del": func() error {
-			return s.commandHandler.CommandDeleteStock(userID, replyToken, arg1)
-		},
-		"/list": func() error {
-			return s.commandHandler.CommandListSubscriptions(userID, replyToken)
-		},
-		"test": func() error {
-			return s.botClient.ReplyMessage(replyToken, "新增成功")
-		},
+// executeCommand 取得或建立使用者後執行指令
+func (s *lineServiceHandler) executeCommand(userID, text string) (*command.Response, error) {
+	dbUser, err := s.userService.GetOrCreate(userID, models.UserTypeLine)
+	if err != nil {
+		s.logger.Error("建立或取得使用者失敗", zap.Error(err))
+		return nil, fmt.Errorf("系統錯誤，請稍後再試")
+	}
+
+	ctx := &command.Context{
+		Platform:  models.UserTypeLine,
+		AccountID: userID,
+		UserID:    dbUser.ID,
 	}
+	return s.registry.Execute(ctx, text)
+}
 
-	if handler, exists := commandMap[command]; exists {
-		return handler()
+// replyOnboarding 回覆歡迎訊息及指令指南
+func (s *lineServiceHandler) replyOnboarding(replyToken string, ctx *command.Context) error {
+	response, err := s.registry.Execute(ctx, "/start")
+	if err != nil {
+		return s.botClient.ReplyMessage(replyToken, onboardingMessage)
 	}
 
-	reply := "你說了: " + messageText
-	return s.botClient.ReplyMessage(replyToken, reply)
+	response.Blocks = append([]command.Block{{Text: onboardingMessage}}, response.Blocks...)
+	return s.renderer.Reply(replyToken, response)
 }
@@ -0,0 +1,158 @@
+package line
+
+import (
+	"errors"
+	"strings"
+
+	linebotInfra "github.com/tian841224/stock-bot/internal/infrastructure/linebot"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"github.com/line/line-bot-sdk-go/linebot"
+	"go.uber.org/zap"
+)
+
+// LINE 訊息限制
+const (
+	textMaxLength          = 5000 // 文字訊息字元上限
+	buttonsMaxActions      = 4    // 按鈕樣板動作上限
+	buttonsTextMaxLength   = 160  // 按鈕樣板文字上限
+	actionLabelMaxLength   = 20   // 按鈕文字上限
+	defaultButtonsTemplate = "請選擇"
+)
+
+// errImageUploaderNotConfigured 未設定圖片上傳服務
+var errImageUploaderNotConfigured = errors.New("未設定圖片上傳服務")
+
+// LineRenderer 將指令回應轉為 LINE 訊息
+type LineRenderer struct {
+	botClient     *linebotInfra.LineBotClient
+	imageUploader command.ImageUploader
+	logger        logger.Logger
+}
+
+// NewLineRenderer 建立 renderer，imageUploader 為 nil 時僅回覆文字
+func NewLineRenderer(botClient *linebotInfra.LineBotClient, imageUploader command.ImageUploader, log logger.Logger) *LineRenderer {
+	return &LineRenderer{
+		botClient:     botClient,
+		imageUploader: imageUploader,
+		logger:        log,
+	}
+}
+
+// Reply 回覆指令回應
+func (r *LineRenderer) Reply(replyToken string, response *command.Response) error {
+	messages := r.renderMessages(response)
+	if len(messages) == 0 {
+		return nil
+	}
+	return r.botClient.ReplyMessages(replyToken, messages...)
+}
+
+// Push 主動推播指令回應，用於排程通知
+func (r *LineRenderer) Push(to string, response *command.Response) error {
+	messages := r.renderMessages(response)
+	if len(messages) == 0 {
+		return nil
+	}
+	return r.botClient.PushMessages(to, messages...)
+}
+
+// renderMessages 將回應轉為 LINE 訊息，依序為圖片、內容
+// 有欄位或表格的回應以 Flex Message 呈現，其餘以文字加按鈕樣板呈現
+func (r *LineRenderer) renderMessages(response *command.Response) []linebot.SendingMessage {
+	var messages []linebot.SendingMessage
+
+	// LINE 圖片需公開網址，上傳失敗時仍回覆文字
+	if response.Image != nil && len(response.Image.Data) > 0 {
+		if imageURL, err := r.uploadImage(response.Image); err != nil {
+			r.logger.Warn("上傳圖片失敗，只發送文字訊息", zap.Error(err))
+		} else {
+			messages = append(messages, linebot.NewImageMessage(imageURL, imageURL))
+		}
+	}
+
+	if shouldRenderFlex(response) {
+		messages = append(messages, renderFlexMessage(response))
+	} else {
+		if text := RenderText(response); text != "" {
+			messages = append(messages, linebot.NewTextMessage(command.TruncateRunes(text, textMaxLength)))
+		}
+
+		if template := renderButtonsTemplate(response); template != nil {
+			messages = append(messages, template)
+		}
+	}
+	return messages
+}
+
+// ReplyError 回覆錯誤訊息
+func (r *LineRenderer) ReplyError(replyToken string, err error) error {
+	return r.botClient.ReplyMessage(replyToken, err.Error())
+}
+
+// uploadImage 上傳圖片取得網址
+func (r *LineRenderer) uploadImage(image *command.Image) (string, error) {
+	if r.imageUploader == nil {
+		return "", errImageUploaderNotConfigured
+	}
+	return r.imageUploader.UploadImage(image.Data, image.Name, 0)
+}
+
+// RenderText 將回應轉為純文字
+func RenderText(response *command.Response) string {
+	var sections []string
+	if response.Title != "" {
+		sections = append(sections, response.Title)
+	}
+
+	for _, block := range response.Blocks {
+		var lines []string
+		if block.Heading != "" {
+			lines = append(lines, block.Heading)
+		}
+		if block.Text != "" {
+			lines = append(lines, block.Text)
+		}
+		if len(block.Fields) > 0 {
+			lines = append(lines, command.FormatFields(block.Fields))
+		}
+		if block.Table != nil {
+			lines = append(lines, command.FormatTable(block.Table))
+		}
+		if len(lines) > 0 {
+			sections = append(sections, strings.Join(lines, "\n"))
+		}
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+// renderButtonsTemplate 將按鈕轉為按鈕樣板，指令按鈕以 postback 傳送指令
+func renderButtonsTemplate(response *command.Response) *linebot.TemplateMessage {
+	var actions []linebot.TemplateAction
+	for _, row := range response.Buttons {
+		for _, button := range row {
+			if len(actions) >= buttonsMaxActions {
+				break
+			}
+			label := command.TruncateRunes(button.Label, actionLabelMaxLength)
+			switch {
+			case button.URL != "":
+				actions = append(actions, linebot.NewURIAction(label, button.URL))
+			case button.Command != "":
+				actions = append(actions, linebot.NewPostbackAction(label, button.Command, "", label))
+			}
+		}
+	}
+
+	if len(actions) == 0 {
+		return nil
+	}
+
+	text := defaultButtonsTemplate
+	if response.Title != "" {
+		text = command.TruncateRunes(response.Title, buttonsTextMaxLength)
+	}
+	return linebot.NewTemplateMessage(text, linebot.NewButtonsTemplate("", "", text, actions...))
+}
@@ -0,0 +1,74 @@
+package line
+
+import (
+	"github.com/tian841224/stock-bot/pkg/imageutil"
+
+	"github.com/line/line-bot-sdk-go/linebot"
+)
+
+// 圖文選單尺寸（LINE 大型選單規格）
+const (
+	richMenuWidth   = 2500
+	richMenuHeight  = 1686
+	richMenuColumns = 3
+	RichMenuName    = "stock-bot-default"
+	richMenuChatBar = "常用功能"
+)
+
+// richMenuItem 圖文選單按鈕，點擊後以 postback 執行指令
+type richMenuItem struct {
+	Label   string
+	Command string
+}
+
+// richMenuItems 預設圖文選單內容，依序由左至右、由上至下排列
+var richMenuItems = []richMenuItem{
+	{Label: "大盤資訊", Command: "/m"},
+	{Label: "成交量排行", Command: "/t"},
+	{Label: "產業熱力圖", Command: "/heat"},
+	{Label: "美債殖利率", Command: "/yield"},
+	{Label: "我的訂閱", Command: "/list"},
+	{Label: "指令說明", Command: "/start"},
+}
+
+// BuildRichMenu 建立預設圖文選單設定，每個格子對應一個 postback 動作
+func BuildRichMenu() linebot.RichMenu {
+	rows := (len(richMenuItems) + richMenuColumns - 1) / richMenuColumns
+	tileWidth := richMenuWidth / richMenuColumns
+	tileHeight := richMenuHeight / rows
+
+	areas := make([]linebot.AreaDetail, 0, len(richMenuItems))
+	for i, item := range richMenuItems {
+		areas = append(areas, linebot.AreaDetail{
+			Bounds: linebot.RichMenuBounds{
+				X:      (i % richMenuColumns) * tileWidth,
+				Y:      (i / richMenuColumns) * tileHeight,
+				Width:  tileWidth,
+				Height: tileHeight,
+			},
+			Action: linebot.RichMenuAction{
+				Type: linebot.RichMenuActionTypePostback,
+				Data: item.Command,
+				// SDK 未提供 displayText 欄位，以 text 顯示於聊天室
+				Text: item.Label,
+			},
+		})
+	}
+
+	return linebot.RichMenu{
+		Size:        linebot.RichMenuSize{Width: richMenuWidth, Height: richMenuHeight},
+		Selected:    true,
+		Name:        RichMenuName,
+		ChatBarText: richMenuChatBar,
+		Areas:       areas,
+	}
+}
+
+// RenderRichMenuImage 產生與 BuildRichMenu 格子對應的選單圖片
+func RenderRichMenuImage() ([]byte, error) {
+	tiles := make([]imageutil.RichMenuTile, 0, len(richMenuItems))
+	for _, item := range richMenuItems {
+		tiles = append(tiles, imageutil.RichMenuTile{Label: item.Label, SubLabel: item.Command})
+	}
+	return imageutil.GenerateRichMenuPNG(richMenuWidth, richMenuHeight, richMenuColumns, tiles)
+}
@@ -0,0 +1,176 @@
+// Package slack 提供 Slack Bot 的處理器功能
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/slackbot"
+	"github.com/tian841224/stock-bot/internal/infrastructure/slackbot/dto"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/internal/service/user"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// mentionPattern 訊息中的使用者提及，例如 <@U012ABCDEF>
+var mentionPattern = regexp.MustCompile(`<@[A-Z0-9]+(\|[^>]*)?>`)
+
+// SlackServiceHandler Slack 服務處理器介面
+type SlackServiceHandler interface {
+	HandleSlashCommand(slashCommand *dto.SlashCommand) error
+	HandleEvent(event *dto.Event) error
+	HandleBlockActions(payload *dto.InteractionPayload) error
+}
+
+// slackServiceHandler Slack 以頻道為訂閱單位，同一頻道的成員共用訂閱設定
+type slackServiceHandler struct {
+	botClient   *slackbot.SlackBotClient
+	registry    command.CommandRegistry
+	renderer    *SlackRenderer
+	userService user.UserService
+	logger      logger.Logger
+}
+
+func NewSlackServiceHandler(botClient *slackbot.SlackBotClient, registry command.CommandRegistry, renderer *SlackRenderer, userService user.UserService, log logger.Logger) SlackServiceHandler {
+	return &slackServiceHandler{
+		botClient:   botClient,
+		registry:    registry,
+		renderer:    renderer,
+		userService: userService,
+		logger:      log,
+	}
+}
+
+// HandleSlashCommand 處理斜線指令
+// 支援兩種設定方式：每個指令各自註冊（/d 2330），或共用一個指令帶子指令（/stock d 2330）
+func (s *slackServiceHandler) HandleSlashCommand(slashCommand *dto.SlashCommand) error {
+	text := s.buildSlashCommandText(slashCommand.Command, slashCommand.Text)
+
+	s.logger.Info("收到 Slack 斜線指令",
+		zap.String("channel_id", slashCommand.ChannelID),
+		zap.String("user_id", slashCommand.UserID),
+		zap.String("message", text))
+
+	response, err := s.executeCommand(slashCommand.ChannelID, text)
+	if errors.Is(err, command.ErrUnknownCommand) {
+		return s.respondEphemeral(slashCommand.ResponseURL, fmt.Sprintf("查無此指令，請輸入 %s start 查看指令指南", slashCommand.Command))
+	}
+	if err != nil {
+		return s.respondEphemeral(slashCommand.ResponseURL, err.Error())
+	}
+
+	if err := s.renderer.Send(slashCommand.ChannelID, response); err != nil {
+		// 機器人未加入頻道時無法發送訊息，改以 response_url 提示使用者
+		_ = s.respondEphemeral(slashCommand.ResponseURL, "無法在此頻道發送訊息，請先將機器人加入頻道")
+		return err
+	}
+	return nil
+}
+
+// HandleEvent 處理提及機器人及私訊，訊息內容即為指令，可省略斜線
+func (s *slackServiceHandler) HandleEvent(event *dto.Event) error {
+	// 略過機器人自己發送的訊息及編輯、刪除等子類型，避免重複處理
+	if event.BotID != "" || event.Subtype != "" {
+		return nil
+	}
+
+	switch {
+	case event.Type == dto.EventTypeAppMention:
+	case event.Type == dto.EventTypeMessage && event.ChannelType == "im":
+	default:
+		return nil
+	}
+
+	text := strings.TrimSpace(mentionPattern.ReplaceAllString(event.Text, ""))
+	if text == "" {
+		text = "/start"
+	}
+	if !strings.HasPrefix(text, "/") {
+		text = "/" + text
+	}
+
+	s.logger.Info("收到 Slack 訊息",
+		zap.String("channel_id", event.Channel),
+		zap.String("user_id", event.User),
+		zap.String("message", text))
+
+	response, err := s.executeCommand(event.Channel, text)
+	if errors.Is(err, command.ErrUnknownCommand) {
+		return nil
+	}
+	if err != nil {
+		return s.renderer.SendError(event.Channel, err)
+	}
+	return s.renderer.Send(event.Channel, response)
+}
+
+// HandleBlockActions 處理按鈕互動，按鈕 value 即為要執行的指令
+func (s *slackServiceHandler) HandleBlockActions(payload *dto.InteractionPayload) error {
+	channelID := payload.Channel.ID
+	for _, action := range payload.Actions {
+		if action.Value == "" {
+			continue
+		}
+
+		s.logger.Info("收到 Slack 按鈕互動",
+			zap.String("channel_id", channelID),
+			zap.String("user_id", payload.User.ID),
+			zap.String("data", action.Value))
+
+		response, err := s.executeCommand(channelID, action.Value)
+		if errors.Is(err, command.ErrUnknownCommand) {
+			continue
+		}
+		if err != nil {
+			return s.renderer.SendError(channelID, err)
+		}
+
+		if response.Replace && payload.Message != nil {
+			return s.renderer.Update(channelID, payload.Message.TS, response)
+		}
+		return s.renderer.Send(channelID, response)
+	}
+	return nil
+}
+
+// executeCommand 以頻道作為使用者執行指令
+func (s *slackServiceHandler) executeCommand(channelID, text string) (*command.Response, error) {
+	dbUser, err := s.userService.GetOrCreate(channelID, models.UserTypeSlack)
+	if err != nil {
+		s.logger.Error("建立或取得使用者失敗", zap.Error(err))
+		return nil, fmt.Errorf("系統錯誤，請稍後再試")
+	}
+
+	ctx := &command.Context{
+		Platform:  models.UserTypeSlack,
+		AccountID: channelID,
+		UserID:    dbUser.ID,
+	}
+	return s.registry.Execute(ctx, text)
+}
+
+// buildSlashCommandText 將斜線指令轉為註冊表指令
+// 斜線指令名稱已註冊時直接使用，否則以第一個參數作為子指令，未帶參數時顯示說明
+func (s *slackServiceHandler) buildSlashCommandText(slashCommand, text string) string {
+	text = strings.TrimSpace(text)
+	if _, ok := s.registry.Lookup(slashCommand); ok {
+		return strings.TrimSpace(slashCommand + " " + text)
+	}
+	if text == "" {
+		return "/start"
+	}
+	return "/" + strings.TrimPrefix(text, "/")
+}
+
+// respondEphemeral 透過 response_url 回覆僅觸發者可見的訊息
+func (s *slackServiceHandler) respondEphemeral(responseURL, text string) error {
+	if responseURL == "" {
+		return nil
+	}
+	return s.botClient.RespondURL(responseURL, dto.ResponseURLMessage{ResponseType: "ephemeral", Text: text})
+}
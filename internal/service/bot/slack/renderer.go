@@ -0,0 +1,191 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/infrastructure/slackbot"
+	"github.com/tian841224/stock-bot/internal/infrastructure/slackbot/dto"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Block Kit 限制
+const (
+	headerTextMaxLength   = 150  // header 區塊文字上限
+	sectionTextMaxLength  = 3000 // section 區塊文字上限
+	sectionFieldsMax      = 10   // 每個 section 欄位數上限
+	fieldTextMaxLength    = 2000 // 欄位文字上限
+	buttonTextMaxLength   = 75   // 按鈕文字上限
+	buttonValueMaxLength  = 2000 // 按鈕 value 上限
+	actionsElementsMax    = 25   // 每個 actions 區塊元件數上限
+	blocksMax             = 50   // 每則訊息區塊數上限
+	fallbackTextMaxLength = 3000 // 通知用文字上限
+	defaultImageName      = "chart.png"
+)
+
+// markdownEscaper Slack mrkdwn 需跳脫的控制字元
+var markdownEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SlackRenderer 將指令回應轉為 Block Kit 訊息
+type SlackRenderer struct {
+	botClient *slackbot.SlackBotClient
+	logger    logger.Logger
+}
+
+func NewSlackRenderer(botClient *slackbot.SlackBotClient, log logger.Logger) *SlackRenderer {
+	return &SlackRenderer{botClient: botClient, logger: log}
+}
+
+// Send 發送回應至頻道，有圖片時先上傳圖片再發送內容
+func (r *SlackRenderer) Send(channelID string, response *command.Response) error {
+	if response.Image != nil && len(response.Image.Data) > 0 {
+		name := response.Image.Name
+		if name == "" {
+			name = defaultImageName
+		}
+		// 圖片上傳失敗時仍發送文字內容
+		if err := r.botClient.UploadFile(channelID, name, response.Title, response.Image.Data); err != nil {
+			r.logger.Warn("上傳圖片失敗，只發送文字訊息", zap.Error(err))
+		}
+	}
+
+	blocks := RenderBlocks(response)
+	if len(blocks) == 0 {
+		return nil
+	}
+	return r.botClient.PostMessage(channelID, renderFallbackText(response), blocks)
+}
+
+// Update 更新既有訊息，含圖片的回應無法更新，改為另發新訊息
+func (r *SlackRenderer) Update(channelID, ts string, response *command.Response) error {
+	if response.Image != nil {
+		return r.Send(channelID, response)
+	}
+	return r.botClient.UpdateMessage(channelID, ts, renderFallbackText(response), RenderBlocks(response))
+}
+
+// SendError 發送錯誤訊息
+func (r *SlackRenderer) SendError(channelID string, err error) error {
+	return r.botClient.PostMessage(channelID, err.Error(), nil)
+}
+
+// RenderBlocks 將回應轉為 Block Kit 區塊
+// 欄位以 section fields 兩欄排列，表格以程式碼區塊對齊，按鈕每列轉為一個 actions 區塊
+func RenderBlocks(response *command.Response) []dto.Block {
+	var blocks []dto.Block
+	if response.Title != "" {
+		blocks = append(blocks, dto.Block{
+			Type: dto.BlockTypeHeader,
+			Text: &dto.TextObject{Type: dto.TextTypePlain, Text: command.TruncateRunes(response.Title, headerTextMaxLength), Emoji: true},
+		})
+	}
+
+	for i, block := range response.Blocks {
+		if i > 0 {
+			blocks = append(blocks, dto.Block{Type: dto.BlockTypeDivider})
+		}
+
+		var lines []string
+		if block.Heading != "" {
+			lines = append(lines, "*"+escapeMarkdown(block.Heading)+"*")
+		}
+		if block.Text != "" {
+			lines = append(lines, escapeMarkdown(block.Text))
+		}
+		if len(lines) > 0 {
+			blocks = append(blocks, markdownSection(strings.Join(lines, "\n")))
+		}
+
+		blocks = append(blocks, fieldSections(block.Fields)...)
+
+		if block.Table != nil {
+			blocks = append(blocks, markdownSection("```"+escapeMarkdown(command.FormatTable(block.Table))+"```"))
+		}
+	}
+
+	blocks = append(blocks, actionBlocks(response.Buttons)...)
+
+	if len(blocks) > blocksMax {
+		blocks = blocks[:blocksMax]
+	}
+	return blocks
+}
+
+// markdownSection 建立 mrkdwn 文字區塊
+func markdownSection(text string) dto.Block {
+	return dto.Block{
+		Type: dto.BlockTypeSection,
+		Text: &dto.TextObject{Type: dto.TextTypeMarkdown, Text: command.TruncateRunes(text, sectionTextMaxLength)},
+	}
+}
+
+// fieldSections 將欄位轉為 section fields，每個 section 最多 10 個欄位
+func fieldSections(fields []command.Field) []dto.Block {
+	var blocks []dto.Block
+	for start := 0; start < len(fields); start += sectionFieldsMax {
+		end := min(start+sectionFieldsMax, len(fields))
+
+		var objects []dto.TextObject
+		for _, field := range fields[start:end] {
+			text := fmt.Sprintf("*%s*\n%s", escapeMarkdown(field.Label), escapeMarkdown(field.Value))
+			if field.Value == "" {
+				text = "• " + escapeMarkdown(field.Label)
+			}
+			objects = append(objects, dto.TextObject{Type: dto.TextTypeMarkdown, Text: command.TruncateRunes(text, fieldTextMaxLength)})
+		}
+		blocks = append(blocks, dto.Block{Type: dto.BlockTypeSection, Fields: objects})
+	}
+	return blocks
+}
+
+// actionBlocks 將按鈕轉為 actions 區塊，指令按鈕以指令本身作為 value
+func actionBlocks(buttons [][]command.Button) []dto.Block {
+	var blocks []dto.Block
+	count := 0
+	for _, buttonRow := range buttons {
+		var elements []dto.BlockElement
+		for _, button := range buttonRow {
+			if len(elements) >= actionsElementsMax || len(button.Command) > buttonValueMaxLength {
+				continue
+			}
+			// 同一則訊息內 action_id 不可重複
+			element := dto.BlockElement{
+				Type:     dto.ElementTypeButton,
+				Text:     &dto.TextObject{Type: dto.TextTypePlain, Text: command.TruncateRunes(button.Label, buttonTextMaxLength), Emoji: true},
+				ActionID: fmt.Sprintf("command_%d", count),
+				Value:    button.Command,
+				URL:      button.URL,
+			}
+			elements = append(elements, element)
+			count++
+		}
+		if len(elements) > 0 {
+			blocks = append(blocks, dto.Block{Type: dto.BlockTypeActions, Elements: elements})
+		}
+	}
+	return blocks
+}
+
+// renderFallbackText 通知及不支援 Block Kit 時顯示的文字
+func renderFallbackText(response *command.Response) string {
+	if response.Title != "" {
+		return command.TruncateRunes(response.Title, fallbackTextMaxLength)
+	}
+	for _, block := range response.Blocks {
+		if block.Text != "" {
+			return command.TruncateRunes(block.Text, fallbackTextMaxLength)
+		}
+		if block.Heading != "" {
+			return command.TruncateRunes(block.Heading, fallbackTextMaxLength)
+		}
+	}
+	return ""
+}
+
+// escapeMarkdown 跳脫 mrkdwn 控制字元
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
@@ -0,0 +1,126 @@
+package tgbot
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// callback_data 格式，前綴含版本以便日後調整格式時仍能解析舊訊息的按鈕
+const (
+	callbackPrefixCommand = "c1:" // 指令本身，長度未超過限制時使用
+	callbackPrefixToken   = "t1:" // 存放於資料庫的指令代碼
+)
+
+const (
+	// 代碼隨機位元組數，base64 編碼後為 16 字元
+	callbackTokenBytes = 12
+	// 按鈕回呼資料保留時間，超過後舊訊息的按鈕失效
+	callbackRetention = 90 * 24 * time.Hour
+	// 清除過期按鈕回呼資料的間隔
+	callbackPruneInterval = 24 * time.Hour
+)
+
+// ErrCallbackExpired 按鈕回呼資料已過期或不存在
+var ErrCallbackExpired = errors.New("按鈕已失效，請重新查詢")
+
+// CallbackCodec 將按鈕指令編碼為 callback_data，過長的指令存放於資料庫並以代碼取代
+// 排程器及 Bot 共用資料庫，排程推播訊息的按鈕由 Bot 解碼
+type CallbackCodec struct {
+	payloadRepo repository.CallbackPayloadRepository
+	logger      logger.Logger
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+func NewCallbackCodec(payloadRepo repository.CallbackPayloadRepository, log logger.Logger) *CallbackCodec {
+	return &CallbackCodec{payloadRepo: payloadRepo, logger: log}
+}
+
+// Encode 將指令編碼為 callback_data
+func (c *CallbackCodec) Encode(command string) (string, error) {
+	if command == "" {
+		return callbackDataNoop, nil
+	}
+	if data := callbackPrefixCommand + command; len(data) <= callbackDataMaxLength {
+		return data, nil
+	}
+	if c == nil || c.payloadRepo == nil {
+		return "", fmt.Errorf("指令超過 %d bytes 且未設定按鈕回呼資料儲存", callbackDataMaxLength)
+	}
+
+	token, err := newCallbackToken()
+	if err != nil {
+		return "", err
+	}
+	if err := c.payloadRepo.Create(&models.CallbackPayload{Token: token, Command: command}); err != nil {
+		return "", err
+	}
+	c.pruneIfDue()
+	return callbackPrefixToken + token, nil
+}
+
+// Decode 將 callback_data 還原為指令，純顯示用按鈕回傳空字串
+func (c *CallbackCodec) Decode(data string) (string, error) {
+	switch {
+	case data == "" || data == callbackDataNoop:
+		return "", nil
+	case strings.HasPrefix(data, "/"):
+		// 舊版訊息的按鈕直接以指令作為 callback_data
+		return data, nil
+	case strings.HasPrefix(data, callbackPrefixCommand):
+		return strings.TrimPrefix(data, callbackPrefixCommand), nil
+	case strings.HasPrefix(data, callbackPrefixToken):
+		if c == nil || c.payloadRepo == nil {
+			return "", ErrCallbackExpired
+		}
+		payload, err := c.payloadRepo.GetByToken(strings.TrimPrefix(data, callbackPrefixToken))
+		if err != nil {
+			return "", ErrCallbackExpired
+		}
+		return payload.Command, nil
+	}
+	return "", fmt.Errorf("無法解析的按鈕資料: %s", data)
+}
+
+// pruneIfDue 每日清除一次過期的按鈕回呼資料
+func (c *CallbackCodec) pruneIfDue() {
+	c.mu.Lock()
+	if time.Since(c.lastPruned) < callbackPruneInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.lastPruned = time.Now()
+	c.mu.Unlock()
+
+	go func() {
+		count, err := c.payloadRepo.DeleteBefore(time.Now().Add(-callbackRetention))
+		if err != nil {
+			c.logger.Error("刪除過期的按鈕回呼資料失敗", zap.Error(err))
+			return
+		}
+		if count > 0 {
+			c.logger.Info("已刪除過期的按鈕回呼資料", zap.Int64("數量", count))
+		}
+	}()
+}
+
+// newCallbackToken 產生隨機代碼
+func newCallbackToken() (string, error) {
+	b := make([]byte, callbackTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
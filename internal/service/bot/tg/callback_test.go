@@ -0,0 +1,92 @@
+package tgbot
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...zap.Field)  {}
+func (nopLogger) Error(string, ...zap.Field) {}
+func (nopLogger) Warn(string, ...zap.Field)  {}
+func (nopLogger) Debug(string, ...zap.Field) {}
+func (nopLogger) Panic(string, ...zap.Field) {}
+func (nopLogger) Fatal(string, ...zap.Field) {}
+func (nopLogger) Sync() error                { return nil }
+
+type fakeCallbackPayloadRepo struct {
+	mu       sync.Mutex
+	payloads map[string]string
+}
+
+func (f *fakeCallbackPayloadRepo) Create(payload *models.CallbackPayload) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.payloads[payload.Token] = payload.Command
+	return nil
+}
+
+func (f *fakeCallbackPayloadRepo) GetByToken(token string) (*models.CallbackPayload, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	command, ok := f.payloads[token]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.CallbackPayload{Token: token, Command: command}, nil
+}
+
+func (f *fakeCallbackPayloadRepo) DeleteBefore(time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestCallbackCodec(t *testing.T) {
+	codec := NewCallbackCodec(&fakeCallbackPayloadRepo{payloads: make(map[string]string)}, nopLogger{})
+	long := "/newsalert 台積電 法說會 營收 創新高 股利 配息 除權息 董事會"
+
+	for _, command := range []string{"/d 2330", "/settings quiet 22:00 07:00", long, ""} {
+		data, err := codec.Encode(command)
+		if err != nil {
+			t.Fatalf("Encode(%q) error = %v", command, err)
+		}
+		if len(data) > callbackDataMaxLength {
+			t.Errorf("Encode(%q) = %q exceeds %d bytes", command, data, callbackDataMaxLength)
+		}
+		got, err := codec.Decode(data)
+		if err != nil || got != command {
+			t.Errorf("Decode(Encode(%q)) = %q, %v", command, got, err)
+		}
+	}
+
+	if data, _ := codec.Encode(long); !strings.HasPrefix(data, callbackPrefixToken) {
+		t.Errorf("long command should be stored as token, got %q", data)
+	}
+	if got, err := codec.Decode("/k 2330"); err != nil || got != "/k 2330" {
+		t.Errorf("legacy callback data: got %q, %v", got, err)
+	}
+	if _, err := codec.Decode(callbackPrefixToken + "missing"); !errors.Is(err, ErrCallbackExpired) {
+		t.Errorf("unknown token: error = %v, want ErrCallbackExpired", err)
+	}
+	if _, err := codec.Decode("x9:unknown"); err == nil {
+		t.Error("unknown version should return error")
+	}
+}
+
+func TestCallbackCodecWithoutStore(t *testing.T) {
+	codec := NewCallbackCodec(nil, nopLogger{})
+	if _, err := codec.Encode("/newsalert 台積電 法說會 營收 創新高 股利 配息 除權息 董事會"); err == nil {
+		t.Error("long command without store should return error")
+	}
+	if data, err := codec.Encode("/d 2330"); err != nil || data != callbackPrefixCommand+"/d 2330" {
+		t.Errorf("short command: got %q, %v", data, err)
+	}
+}
@@ -1,368 +0,0 @@
-package tgbot
-
-import (
-	"errors"
-	"fmt"
-	"regexp"
-	"strconv"
-	"time"
-
-	"github.com/tian841224/stock-bot/internal/db/models"
-	"github.com/tian841224/stock-bot/internal/infrastructure/tgbot"
-	"github.com/tian841224/stock-bot/internal/service/user"
-	"github.com/tian841224/stock-bot/internal/service/user_subscription"
-	"github.com/tian841224/stock-bot/pkg/logger"
-
-	"go.uber.org/zap"
-	"gorm.io/gorm"
-)
-
-type TgCommandHandler struct {
-	botClient               *tgbot.TgBotClient
-	tgService               TgService
-	userService             user.UserService
-	userSubscriptionService user_subscription.UserSubscriptionService
-	subscriptionItemMap     map[string]models.SubscriptionItem
-	logger                  logger.Logger
-}
-
-func NewTgCommandHandler(
-	botClient *tgbot.TgBotClient,
-	tgService TgService,
-	userService user.UserService,
-	userSubscriptionService user_subscription.UserSubscriptionService,
-	log logger.Logger,
-) *TgCommandHandler {
-	return &TgCommandHandler{
-		botClient:               botClient,
-		tgService:               tgService,
-		userService:             userService,
-		userSubscriptionService: userSubscriptionService,
-		subscriptionItemMap:     models.SubscriptionItemMap,
-		logger:                  log,
-	}
-}
-
-// Subscription status constants
-const (
-	SubscriptionStatusActive   = true
-	SubscriptionStatusInactive = false
-)
-
-// CommandStart 處理 /start 命令
-func (c *TgCommandHandler) CommandStart(userID int64) error {
-	text := `台股機器人指令指南🤖
-
-📊 圖表指令
-- /k [股票代碼] - K線圖 (含月均價、最高最低價標示、成交量)
-- /p [股票代碼] - 股票績效圖表 (折線圖)
-- /r [股票代碼] - 月營收圖表 (柱狀圖+年增率折線)
-
-📈 股票資訊指令
-- /d [股票代碼] - 查詢當日收盤資訊 (可指定日期)
-- /d [股票代碼] [日期] - 查詢指定日期股價 (格式: YYYY-MM-DD)
-- /i [股票代碼] - 查詢公司資訊
-- /n [股票代碼] - 查詢股票新聞
-
-📊 市場總覽指令
-- /m - 查詢最新大盤資訊 (預設1筆)
-- /m [數量] - 查詢指定筆數的大盤資訊
-- /t - 查詢當日交易量前20名
-
-🔔 訂閱管理
-- /add [股票代碼] - 新增訂閱股票
-- /del [股票代碼] - 刪除訂閱股票
-- /sub [項目] - 訂閱功能
-- /unsub [項目] - 取消訂閱功能
-- /list - 查詢已訂閱功能及股票
-
-💡 使用範例：
-/k 2330 - 台積電K線圖
-/p 0050 - 元大台灣50績效圖表
-/r 2330 - 台積電月營收圖表
-/d 2330 2025-01-15 - 查詢台積電指定日期股價
-/m 3 - 查詢最新3筆大盤資訊`
-
-	return c.botClient.SendMessage(userID, text)
-}
-
-// CommandPerformanceChart 處理 /p 命令 - 股票績效圖表 (折線圖)
-func (c *TgCommandHandler) CommandPerformanceChart(userID int64, symbol string) error {
-	if symbol == "" {
-		return c.botClient.SendMessage(userID, "請輸入股票代號")
-	}
-
-	// 取得績效圖表資料
-	chartData, caption, err := c.tgService.GetStockPerformanceWithChart(symbol, "line")
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	// 檢查是否有圖表資料
-	if len(chartData) == 0 {
-		// 如果沒有圖表資料，發送文字版本
-		return c.botClient.SendMessageHTML(userID, caption)
-	}
-
-	// 發送圖表
-	return c.botClient.SendPhoto(userID, chartData, caption)
-}
-
-// CommandTodayStockPrice 處理 /d 命令 - 股價詳細資訊（支援日期查詢），
-func (c *TgCommandHandler) CommandTodayStockPrice(userID int64, symbol, date string) error {
-	// 輸入驗證
-	if symbol == "" {
-		return c.botClient.SendMessage(userID, "請輸入股票代號\n\n使用方式：\n/d 股票代號 - 查詢今日股價\n/d 股票代號 2025-09-01 - 查詢指定日期股價")
-	}
-
-	var message string
-	var err error
-
-	// 根據是否有日期參數決定呼叫哪個方法
-	if date != "" {
-		// 驗證日期格式
-		if !c.isValidDateFormat(date) {
-			return c.botClient.SendMessage(userID, "日期格式錯誤，請使用 YYYY-MM-DD 格式\n例如：2025-09-01")
-		}
-		// 查詢指定日期股價
-		message, err = c.tgService.GetStockPriceByDate(symbol, date)
-	} else {
-		message, err = c.tgService.GetStockPriceByDate(symbol, time.Now().Format("2006-01-02"))
-	}
-
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	// 發送回應
-	return c.botClient.SendMessageHTML(userID, message)
-}
-
-// CommandHistoricalCandles 處理 /k 命令 - 歷史K線圖
-func (c *TgCommandHandler) CommandHistoricalCandles(userID int64, symbol string) error {
-	if symbol == "" {
-		return c.botClient.SendMessage(userID, "請輸入股票代號")
-	}
-
-	chartData, caption, err := c.tgService.GetStockHistoricalCandlesChart(symbol)
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	return c.botClient.SendPhoto(userID, chartData, caption)
-}
-
-// CommandNews 處理 /n 命令 - 股票新聞
-func (c *TgCommandHandler) CommandNews(userID int64, symbol string) error {
-	if symbol == "" {
-		return c.botClient.SendMessage(userID, "請輸入股票代號")
-	}
-
-	// 取得新聞資料
-	newsMessage, err := c.tgService.GetTaiwanStockNews(symbol)
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	return c.botClient.SendMessageWithKeyboard(userID, newsMessage.Text, newsMessage.InlineKeyboardMarkup)
-}
-
-// CommandDailyMarketInfo 處理 /m 命令 - 大盤資訊
-func (c *TgCommandHandler) CommandDailyMarketInfo(userID int64, count int) error {
-	// 呼叫業務邏輯
-	messageText, err := c.tgService.GetDailyMarketInfo(count)
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	// 發送回應
-	return c.botClient.SendMessageHTML(userID, messageText)
-}
-
-// CommandTopVolumeItems 處理 /t 命令 - 交易量前20名
-func (c *TgCommandHandler) CommandTopVolumeItems(userID int64) error {
-	// 取得交易量前20名資料
-	messageText, err := c.tgService.GetTopVolumeItemsFormatted()
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	return c.botClient.SendMessageHTML(userID, messageText)
-}
-
-// CommandStockInfo 處理 /i 命令 - 股票資訊（可指定日期）
-func (c *TgCommandHandler) CommandStockInfo(userID int64, symbol, date string) error {
-	if symbol == "" {
-		return c.botClient.SendMessage(userID, "請輸入股票代號")
-	}
-
-	// 取得股票資訊
-	message, err := c.tgService.GetStockInfo(symbol)
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	return c.botClient.SendMessageHTML(userID, message)
-}
-
-// CommandRevenue 處理 /r 命令 - 股票財報
-func (c *TgCommandHandler) CommandRevenue(userID int64, symbol string) error {
-	if symbol == "" {
-		return c.botClient.SendMessage(userID, "請輸入股票代號")
-	}
-
-	chartData, caption, err := c.tgService.GetStockRevenueWithChart(symbol)
-
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	// 檢查是否有圖表資料
-	if len(chartData) == 0 {
-		// 如果沒有圖表資料，發送文字版本
-		return c.botClient.SendMessageHTML(userID, caption)
-	}
-
-	// 發送圖表
-	return c.botClient.SendPhoto(userID, chartData, caption)
-}
-
-// CommandSubscribe 處理 /sub 命令 - 訂閱功能
-func (c *TgCommandHandler) CommandSubscribe(userID int64, item string) error {
-	return c.updateUserSubscription(userID, item, true)
-}
-
-// CommandUnsubscribe 處理 /unsub 命令 - 取消訂閱功能
-func (c *TgCommandHandler) CommandUnsubscribe(userID int64, item string) error {
-	return c.updateUserSubscription(userID, item, false)
-}
-
-// UpdateUserSubscription 更新使用者訂閱狀態
-func (c *TgCommandHandler) updateUserSubscription(userID int64, item string, status bool) error {
-	subscriptionItem, exists := c.subscriptionItemMap[item]
-	if !exists {
-		return c.botClient.SendMessage(userID, fmt.Sprintf("無效的訂閱項目: %s", item))
-	}
-
-	// 取得使用者資料
-	user, err := c.userService.GetUserByAccountID(strconv.FormatInt(userID, 10), models.UserTypeTelegram)
-	if err != nil {
-		c.logger.Error("取得使用者失敗", zap.Error(err))
-		return c.botClient.SendMessage(userID, "無法取得使用者")
-	}
-
-	// 檢查是否已經有此訂閱項目
-	_, err = c.userSubscriptionService.GetUserSubscriptionByItem(user.ID, subscriptionItem)
-	if err != nil {
-		// 如果是記錄不存在
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// 如果要訂閱，則新增
-			if status == SubscriptionStatusActive {
-				if err := c.userSubscriptionService.AddUserSubscriptionItem(user.ID, subscriptionItem); err != nil {
-					c.logger.Error("新增訂閱項目失敗", zap.Error(err))
-					return c.botClient.SendMessage(userID, "訂閱失敗，請稍後再試")
-				}
-				return c.botClient.SendMessage(userID, fmt.Sprintf("訂閱成功：%s", subscriptionItem.GetName()))
-			}
-			// 如果是要取消訂閱，但記錄不存在，表示尚未訂閱
-			return c.botClient.SendMessage(userID, fmt.Sprintf("您尚未訂閱：%s", subscriptionItem.GetName()))
-		}
-		// 其他錯誤應該記錄並返回
-		c.logger.Error("取得訂閱項目失敗", zap.Error(err))
-		return c.botClient.SendMessage(userID, "操作失敗，請稍後再試")
-	}
-
-	// 更新訂閱狀態
-	if err := c.userSubscriptionService.UpdateUserSubscriptionItem(user.ID, subscriptionItem, status); err != nil {
-		c.logger.Error("更新訂閱狀態失敗", zap.Error(err))
-		return c.botClient.SendMessage(userID, "操作失敗，請稍後再試")
-	}
-
-	if status == SubscriptionStatusActive {
-		return c.botClient.SendMessage(userID, fmt.Sprintf("訂閱成功：%s", subscriptionItem.GetName()))
-	} else {
-		return c.botClient.SendMessage(userID, fmt.Sprintf("取消訂閱成功：%s", subscriptionItem.GetName()))
-	}
-}
-
-// CommandAddStock 處理 /add 命令 - 新增股票訂閱
-func (c *TgCommandHandler) CommandAddStock(userID int64, symbol string) error {
-	if symbol == "" {
-		return c.botClient.SendMessage(userID, "請輸入股票代號")
-	}
-
-	// 取得使用者資料
-	user, err := c.userService.GetUserByAccountID(strconv.FormatInt(userID, 10), models.UserTypeTelegram)
-	if err != nil {
-		c.logger.Error("取得使用者失敗", zap.Error(err))
-		return c.botClient.SendMessage(userID, "無法取得使用者")
-	}
-
-	// 新增股票訂閱
-	message, err := c.tgService.AddUserStockSubscription(user.ID, symbol)
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	return c.botClient.SendMessage(userID, message)
-}
-
-// CommandDeleteStock 處理 /del 命令 - 刪除股票訂閱
-func (c *TgCommandHandler) CommandDeleteStock(userID int64, symbol string) error {
-	if symbol == "" {
-		return c.botClient.SendMessage(userID, "請輸入股票代號")
-	}
-
-	// 取得使用者資料
-	user, err := c.userService.GetUserByAccountID(strconv.FormatInt(userID, 10), models.UserTypeTelegram)
-	if err != nil {
-		c.logger.Error("取得使用者失敗", zap.Error(err))
-		return c.botClient.SendMessage(userID, "無法取得使用者")
-	}
-
-	// 刪除股票訂閱
-	message, err := c.tgService.DeleteUserStockSubscription(user.ID, symbol)
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	return c.botClient.SendMessage(userID, message)
-}
-
-// CommandListSubscriptions 處理 /list 命令 - 列出訂閱項目
-func (c *TgCommandHandler) CommandListSubscriptions(userID int64) error {
-	// 取得使用者資料
-	user, err := c.userService.GetUserByAccountID(strconv.FormatInt(userID, 10), models.UserTypeTelegram)
-	if err != nil {
-		c.logger.Error("取得使用者失敗", zap.Error(err))
-		return c.botClient.SendMessage(userID, "無法取得使用者")
-	}
-
-	// 取得訂閱清單
-	messageText, err := c.tgService.GetUserSubscriptionList(user.ID)
-	if err != nil {
-		return c.botClient.SendMessage(userID, err.Error())
-	}
-
-	return c.botClient.SendMessageHTML(userID, messageText)
-}
-
-// 輔助方法
-
-// 驗證日期格式是否為 YYYY-MM-DD
-func (c *TgCommandHandler) isValidDateFormat(date string) bool {
-	// 檢查長度
-	if len(date) != 10 {
-		return false
-	}
-
-	// 使用正則表達式驗證格式
-	matched, err := regexp.MatchString(`^\d{4}-\d{2}-\d{2}$`, date)
-	if err != nil || !matched {
-		return false
-	}
-
-	// 嘗試解析日期以確保是有效日期
-	_, err = time.Parse("2006-01-02", date)
-	return err == nil
-}
@@ -2,38 +2,61 @@
 package tgbot
 
 import (
+	"errors"
 	"strconv"
 	"strings"
-	"time"
+	"unicode"
 
 	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/tgbot"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
 	"github.com/tian841224/stock-bot/internal/service/user"
 	"github.com/tian841224/stock-bot/pkg/logger"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// groupWelcomeMessage 機器人加入群組時的歡迎訊息
+const groupWelcomeMessage = "大家好！我是台股機器人🤖\n群組可訂閱股票及每日推播，訂閱設定僅限群組管理員變更。"
+
 // TgServiceHandler Telegram 服務處理器介面
 type TgServiceHandler interface {
 	ProcessUpdate(update *tgbotapi.Update) error
 }
 
 type tgServiceHandler struct {
-	commandHandler *TgCommandHandler
-	userService    user.UserService
-	logger         logger.Logger
+	botClient     *tgbot.TgBotClient
+	registry      command.CommandRegistry
+	renderer      *TgRenderer
+	inlineHandler *TgInlineQueryHandler
+	userService   user.UserService
+	adminChatID   string
+	logger        logger.Logger
 }
 
-func NewTgServiceHandler(commandHandler *TgCommandHandler, userService user.UserService, log logger.Logger) TgServiceHandler {
+func NewTgServiceHandler(botClient *tgbot.TgBotClient, registry command.CommandRegistry, renderer *TgRenderer, inlineHandler *TgInlineQueryHandler, userService user.UserService, adminChatID string, log logger.Logger) TgServiceHandler {
 	return &tgServiceHandler{
-		commandHandler: commandHandler,
-		userService:    userService,
-		logger:         log,
+		botClient:     botClient,
+		registry:      registry,
+		renderer:      renderer,
+		inlineHandler: inlineHandler,
+		userService:   userService,
+		adminChatID:   adminChatID,
+		logger:        log,
 	}
 }
 
 func (s *tgServiceHandler) ProcessUpdate(update *tgbotapi.Update) error {
+	if update.CallbackQuery != nil {
+		return s.processCallbackQuery(update.CallbackQuery)
+	}
+
+	if update.InlineQuery != nil {
+		return s.inlineHandler.HandleInlineQuery(update.InlineQuery)
+	}
+
 	if update.Message == nil {
 		return nil
 	}
@@ -41,130 +64,187 @@ func (s *tgServiceHandler) ProcessUpdate(update *tgbotapi.Update) error {
 	return s.processCommand(update.Message)
 }
 
-// parseMessageArgs 解析訊息參數
-func parseMessageArgs(messageText string) (command string, arg1 string, arg2 string) {
-	parts := strings.Fields(messageText)
-	if len(parts) == 0 {
-		return "", "", ""
+func (s *tgServiceHandler) processCommand(message *tgbotapi.Message) error {
+	chat := message.Chat
+	if chat == nil || chat.IsChannel() {
+		return nil
+	}
+
+	if isGroupChat(chat) {
+		if handled, err := s.processGroupEvent(message); handled {
+			return err
+		}
 	}
 
-	command = parts[0]
-	if len(parts) > 1 {
-		arg1 = parts[1]
+	if message.Text == "" {
+		return nil
 	}
-	if len(parts) > 2 {
-		arg2 = parts[2]
+
+	// 群組中的指令可能帶有機器人名稱，指定其他機器人時略過
+	text, ok := normalizeCommand(message.Text, s.botClient.Username())
+	if !ok {
+		return nil
 	}
-	return command, arg1, arg2
+
+	s.logger.Info("收到 Telegram 訊息",
+		zap.Int64("chat_id", chat.ID),
+		zap.String("message", text))
+
+	return s.executeCommand(chat, s.adminChecker(chat, message.From, message.SenderChat), 0, text)
 }
 
-// getDefaultDateForTodayPrice 取得今日股價的預設日期
-func getDefaultDateForTodayPrice() string {
-	taipeiLocation, _ := time.LoadLocation("Asia/Taipei")
-	now := time.Now().In(taipeiLocation)
-	marketOpenTime := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, taipeiLocation)
+// processCallbackQuery 處理行內按鈕回呼，callback_data 解碼後即為要執行的指令
+func (s *tgServiceHandler) processCallbackQuery(query *tgbotapi.CallbackQuery) error {
+	text, err := s.renderer.DecodeCallback(query.Data)
+	if err != nil {
+		s.logger.Warn("無法解析 Telegram 按鈕回呼", zap.String("data", query.Data), zap.Error(err))
+		_ = s.botClient.AnswerCallbackQuery(query.ID, ErrCallbackExpired.Error())
+		return nil
+	}
+	// 先結束按鈕的載入狀態，避免使用者端持續轉圈
+	_ = s.botClient.AnswerCallbackQuery(query.ID, "")
 
-	if now.Before(marketOpenTime) {
-		return now.AddDate(0, 0, -1).Format("2006-01-02")
+	if query.Message == nil || query.Message.Chat == nil || text == "" {
+		return nil
 	}
-	return now.Format("2006-01-02")
+
+	chat := query.Message.Chat
+
+	s.logger.Info("收到 Telegram 按鈕回呼",
+		zap.Int64("chat_id", chat.ID),
+		zap.String("data", query.Data),
+		zap.String("command", text))
+
+	return s.executeCommand(chat, s.adminChecker(chat, query.From, nil), query.Message.MessageID, text)
 }
 
-// parseMarketInfoCount 解析大盤資訊的顯示筆數
-func parseMarketInfoCount(arg1 string) int {
-	count := 1
-	if arg1 == "" {
-		return count
+// processGroupEvent 處理機器人加入、離開群組及群組升級，回傳是否為群組事件
+func (s *tgServiceHandler) processGroupEvent(message *tgbotapi.Message) (bool, error) {
+	accountID := strconv.FormatInt(message.Chat.ID, 10)
+
+	// 群組升級為超級群組後 chat ID 改變，沿用原有訂閱
+	if message.MigrateToChatID != 0 {
+		s.logger.Info("Telegram 群組升級為超級群組",
+			zap.Int64("chat_id", message.Chat.ID),
+			zap.Int64("new_chat_id", message.MigrateToChatID))
+		return true, s.userService.UpdateAccountID(accountID, strconv.FormatInt(message.MigrateToChatID, 10), models.UserTypeTelegramGroup)
 	}
 
-	parsedCount, err := strconv.Atoi(arg1)
-	if err == nil && parsedCount > 0 {
-		count = parsedCount
+	if message.LeftChatMember != nil && message.LeftChatMember.ID == s.botClient.Client.Self.ID {
+		s.logger.Info("機器人被移出 Telegram 群組", zap.Int64("chat_id", message.Chat.ID))
+		dbUser, err := s.userService.GetUserByAccountID(accountID, models.UserTypeTelegramGroup)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		if err != nil {
+			return true, err
+		}
+		return true, s.userService.UpdateUserStatus(dbUser.ID, false)
 	}
-	return count
+
+	for _, member := range message.NewChatMembers {
+		if member.ID != s.botClient.Client.Self.ID {
+			continue
+		}
+		s.logger.Info("機器人加入 Telegram 群組", zap.Int64("chat_id", message.Chat.ID))
+		dbUser, err := s.userService.GetOrCreate(accountID, models.UserTypeTelegramGroup)
+		if err != nil {
+			return true, err
+		}
+		// 重新加入時恢復推播
+		if !dbUser.Status {
+			if err := s.userService.UpdateUserStatus(dbUser.ID, true); err != nil {
+				return true, err
+			}
+		}
+		if err := s.botClient.SendMessage(message.Chat.ID, groupWelcomeMessage); err != nil {
+			return true, err
+		}
+		return true, s.executeCommand(message.Chat, nil, 0, "/start")
+	}
+
+	return false, nil
 }
 
-func (s *tgServiceHandler) processCommand(message *tgbotapi.Message) error {
-	if message.Text == "" {
+// adminChecker 群組中判斷發送者是否為管理員，以群組身分匿名發言的管理員視為管理員
+func (s *tgServiceHandler) adminChecker(chat *tgbotapi.Chat, from *tgbotapi.User, senderChat *tgbotapi.Chat) func() bool {
+	if !isGroupChat(chat) {
 		return nil
 	}
+	return func() bool {
+		if senderChat != nil && senderChat.ID == chat.ID {
+			return true
+		}
+		if from == nil {
+			return false
+		}
+		isAdmin, err := s.botClient.IsChatAdmin(chat.ID, from.ID)
+		return err == nil && isAdmin
+	}
+}
 
-	userID := message.Chat.ID
-	messageText := message.Text
+// executeCommand 執行指令並回覆，群組以群組本身作為訂閱者，messageID 不為 0 時可更新原訊息
+func (s *tgServiceHandler) executeCommand(chat *tgbotapi.Chat, isAdmin func() bool, messageID int, text string) error {
+	chatID := chat.ID
+	accountID := strconv.FormatInt(chatID, 10)
 
-	s.logger.Info("收到 Telegram 訊息",
-		zap.Int64("user_id", userID),
-		zap.String("message", messageText))
+	userType := models.UserTypeTelegram
+	group := isGroupChat(chat)
+	if group {
+		userType = models.UserTypeTelegramGroup
+	}
 
-	_, err := s.userService.GetOrCreate(strconv.FormatInt(userID, 10), models.UserTypeTelegram)
+	dbUser, err := s.userService.GetOrCreate(accountID, userType)
 	if err != nil {
 		s.logger.Error("建立或取得使用者失敗", zap.Error(err))
-		return s.commandHandler.botClient.SendMessage(userID, "系統錯誤，請稍後再試")
+		return s.botClient.SendMessage(chatID, "系統錯誤，請稍後再試")
+	}
+
+	ctx := &command.Context{
+		Platform:  models.UserTypeTelegram,
+		AccountID: accountID,
+		UserID:    dbUser.ID,
+		Group:     group,
+		IsAdmin:   isAdmin,
+		Operator:  s.adminChatID != "" && accountID == s.adminChatID,
 	}
 
-	command, arg1, arg2 := parseMessageArgs(messageText)
-	if command == "" {
+	response, err := s.registry.Execute(ctx, text)
+	if errors.Is(err, command.ErrUnknownCommand) {
 		return nil
 	}
+	if err != nil {
+		return s.renderer.SendError(chatID, err)
+	}
+
+	if response.Replace && messageID != 0 {
+		return s.renderer.Edit(chatID, messageID, response)
+	}
+	return s.renderer.Send(chatID, response)
+}
 
-	return s.executeCommand(command, userID, arg1, arg2)
+// isGroupChat 是否為群組或超級群組
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat.IsGroup() || chat.IsSuperGroup()
 }
 
-// executeCommand 執行對應的命令
-func (s *tgServiceHandler) executeCommand(command string, userID int64, arg1, arg2 string) error {
-	commandMap := map[string]func() error{
-		"/start": func() error {
-			return s.commandHandler.CommandStart(userID)
-		},
-		"/k": func() error {
-			return s.commandHandler.CommandHistoricalCandles(userID, arg1)
-		},
-		"/p": func() error {
-			return s.commandHandler.CommandPerformanceChart(userID, arg1)
-		},
-		"/d": func() error {
-			dateArg := arg2
-			if dateArg == "" {
-				dateArg = getDefaultDateForTodayPrice()
-			}
-			return s.commandHandler.CommandTodayStockPrice(userID, arg1, dateArg)
-		},
-		"/t": func() error {
-			return s.commandHandler.CommandTopVolumeItems(userID)
-		},
-		"/i": func() error {
-			return s.commandHandler.CommandStockInfo(userID, arg1, arg2)
-		},
-		"/r": func() error {
-			return s.commandHandler.CommandRevenue(userID, arg1)
-		},
-		"/n": func() error {
-			return s.commandHandler.CommandNews(userID, arg1)
-		},
-		"/m": func() error {
-			count := parseMarketInfoCount(arg1)
-			return s.commandHandler.CommandDailyMarketInfo(userID, count)
-		},
-		"/sub": func() error {
-			return s.commandHandler.CommandSubscribe(userID, arg1)
-		},
-		"/unsub": func() error {
-			return s.commandHandler.CommandUnsubscribe(userID, arg1)
-		},
-		"/add": func() error {
-			return s.commandHandler.CommandAddStock(userID, arg1)
-		},
-		"/del": func() error {
-			return s.commandHandler.CommandDeleteStock(userID, arg1)
-		},
-		"/list": func() error {
-			return s.commandHandler.CommandListSubscriptions(userID)
-		},
-	}
-
-	if handler, exists := commandMap[command]; exists {
-		return handler()
-	}
-
-	return nil
+// normalizeCommand 去除指令後的機器人名稱，例如 /d@StockBot 2330 轉為 /d 2330
+// 指定的是其他機器人時回傳 false
+func normalizeCommand(text, botUsername string) (string, bool) {
+	if !strings.HasPrefix(text, "/") {
+		return text, true
+	}
+
+	end := strings.IndexFunc(text, unicode.IsSpace)
+	if end < 0 {
+		end = len(text)
+	}
+	name, mention, found := strings.Cut(text[:end], "@")
+	if !found {
+		return text, true
+	}
+	if !strings.EqualFold(mention, botUsername) {
+		return "", false
+	}
+	return name + text[end:], true
 }
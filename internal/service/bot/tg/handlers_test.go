@@ -0,0 +1,24 @@
+package tgbot
+
+import "testing"
+
+func TestNormalizeCommand(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{text: "/d 2330", want: "/d 2330", wantOK: true},
+		{text: "/d@StockBot 2330", want: "/d 2330", wantOK: true},
+		{text: "/start@stockbot", want: "/start", wantOK: true},
+		{text: "/d@OtherBot 2330", wantOK: false},
+		{text: "台積電 @StockBot", want: "台積電 @StockBot", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		got, ok := normalizeCommand(tt.text, "StockBot")
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("normalizeCommand(%q) = (%q, %v), want (%q, %v)", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
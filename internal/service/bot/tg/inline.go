@@ -0,0 +1,169 @@
+package tgbot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/tgbot"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/internal/service/symbol_search"
+	"github.com/tian841224/stock-bot/internal/service/trading_calendar"
+	"github.com/tian841224/stock-bot/pkg/imageutil"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// inlineCacheTTL 行內查詢結果在本地快取的時間
+	inlineCacheTTL = 5 * time.Minute
+	// inlineTelegramCacheTime Telegram 端快取行內結果的秒數
+	inlineTelegramCacheTime = 300
+	// inlinePhotoExpiration 上傳圖表的保存秒數
+	inlinePhotoExpiration = 24 * 60 * 60
+	// inlinePhotoQuality 行內圖片 JPEG 品質
+	inlinePhotoQuality = 90
+)
+
+// inlineCacheEntry 行內查詢快取項目
+type inlineCacheEntry struct {
+	results   []interface{}
+	expiresAt time.Time
+}
+
+// TgInlineQueryHandler 處理 Telegram 行內查詢（@bot 2330）
+type TgInlineQueryHandler struct {
+	botClient       *tgbot.TgBotClient
+	registry        command.CommandRegistry
+	symbolSearch    symbol_search.SymbolSearchService
+	photoSource     command.ImageUploader
+	tradingCalendar trading_calendar.TradingCalendarService
+	logger          logger.Logger
+
+	mu    sync.Mutex
+	cache map[string]inlineCacheEntry
+}
+
+// NewTgInlineQueryHandler 建立行內查詢處理器，photoSource 為 nil 時僅回傳文字結果
+func NewTgInlineQueryHandler(
+	botClient *tgbot.TgBotClient,
+	registry command.CommandRegistry,
+	symbolSearch symbol_search.SymbolSearchService,
+	photoSource command.ImageUploader,
+	tradingCalendar trading_calendar.TradingCalendarService,
+	log logger.Logger,
+) *TgInlineQueryHandler {
+	return &TgInlineQueryHandler{
+		botClient:       botClient,
+		registry:        registry,
+		symbolSearch:    symbolSearch,
+		photoSource:     photoSource,
+		tradingCalendar: tradingCalendar,
+		logger:          log,
+		cache:           make(map[string]inlineCacheEntry),
+	}
+}
+
+// HandleInlineQuery 依查詢的股票代號回傳股價卡片與 K 線圖
+func (h *TgInlineQueryHandler) HandleInlineQuery(query *tgbotapi.InlineQuery) error {
+	text := strings.TrimSpace(query.Query)
+	if text == "" {
+		return h.botClient.AnswerInlineQuery(query.ID, []interface{}{}, inlineTelegramCacheTime)
+	}
+
+	// 支援以完整的股票名稱查詢，僅部分符合時不回傳結果
+	symbol, err := h.symbolSearch.Resolve(text)
+	if err != nil || symbol == "" {
+		return h.botClient.AnswerInlineQuery(query.ID, []interface{}{}, 10)
+	}
+	symbol = strings.ToUpper(symbol)
+
+	date := command.DefaultQuoteDate(h.tradingCalendar)
+	cacheKey := symbol + "|" + date
+	if results, ok := h.getCache(cacheKey); ok {
+		return h.botClient.AnswerInlineQuery(query.ID, results, inlineTelegramCacheTime)
+	}
+
+	results, err := h.buildResults(symbol, date)
+	if err != nil {
+		h.logger.Warn("建立行內查詢結果失敗", zap.String("query", symbol), zap.Error(err))
+		return h.botClient.AnswerInlineQuery(query.ID, []interface{}{}, 10)
+	}
+
+	h.setCache(cacheKey, results)
+	return h.botClient.AnswerInlineQuery(query.ID, results, inlineTelegramCacheTime)
+}
+
+// buildResults 建立股價卡片與圖表結果
+func (h *TgInlineQueryHandler) buildResults(symbol, date string) ([]interface{}, error) {
+	card, err := h.registry.GetQuoteCard(symbol, date)
+	if err != nil {
+		return nil, err
+	}
+
+	article := tgbotapi.NewInlineQueryResultArticleHTML(fmt.Sprintf("quote-%s-%s", card.StockID, date), card.Title, RenderHTML(card.Response))
+	article.Description = card.Description
+	results := []interface{}{article}
+
+	// 圖表需上傳取得網址，失敗時仍回傳文字卡片
+	if h.photoSource == nil {
+		return results, nil
+	}
+
+	chart, err := h.registry.Execute(&command.Context{Platform: models.UserTypeTelegram}, "/k "+card.StockID)
+	if err != nil || chart.Image == nil {
+		h.logger.Warn("產生行內查詢K線圖失敗", zap.Error(err))
+		return results, nil
+	}
+	photoURL, err := h.uploadChart(chart.Image.Data, fmt.Sprintf("%s_kline.jpg", card.StockID))
+	if err != nil {
+		h.logger.Warn("上傳行內查詢K線圖失敗", zap.Error(err))
+		return results, nil
+	}
+
+	photo := tgbotapi.NewInlineQueryResultPhotoWithThumb(fmt.Sprintf("kline-%s-%s", card.StockID, date), photoURL, photoURL)
+	photo.Title = fmt.Sprintf("%s K線圖", card.StockName)
+	photo.Description = card.Description
+	photo.Caption = RenderHTML(chart)
+	photo.ParseMode = tgbotapi.ModeHTML
+	return append(results, photo), nil
+}
+
+// uploadChart Telegram 行內圖片僅接受 JPEG，先轉檔再上傳
+func (h *TgInlineQueryHandler) uploadChart(chart []byte, filename string) (string, error) {
+	jpegData, err := imageutil.PNGToJPEG(chart, inlinePhotoQuality)
+	if err != nil {
+		return "", err
+	}
+	return h.photoSource.UploadImage(jpegData, filename, inlinePhotoExpiration)
+}
+
+// getCache 取得未過期的快取結果
+func (h *TgInlineQueryHandler) getCache(key string) ([]interface{}, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry, ok := h.cache[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.results, true
+}
+
+// setCache 寫入快取並清除過期項目
+func (h *TgInlineQueryHandler) setCache(key string, results []interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range h.cache {
+		if now.After(entry.expiresAt) {
+			delete(h.cache, k)
+		}
+	}
+	h.cache[key] = inlineCacheEntry{results: results, expiresAt: now.Add(inlineCacheTTL)}
+}
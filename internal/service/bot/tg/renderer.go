@@ -0,0 +1,264 @@
+package tgbot
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tian841224/stock-bot/internal/infrastructure/tgbot"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// Telegram 圖片說明最多 1024 字元
+	captionMaxLength = 1024
+	// Telegram 文字訊息最多 4096 字元
+	messageMaxLength = 4096
+	// Telegram 限制 callback_data 最多 64 bytes
+	callbackDataMaxLength = 64
+	// callbackDataNoop 純顯示用按鈕的 callback_data
+	callbackDataNoop = "noop"
+)
+
+// TgRenderer 將指令回應轉為 Telegram HTML 訊息
+type TgRenderer struct {
+	botClient *tgbot.TgBotClient
+	callbacks *CallbackCodec
+	logger    logger.Logger
+}
+
+func NewTgRenderer(botClient *tgbot.TgBotClient, callbacks *CallbackCodec, log logger.Logger) *TgRenderer {
+	return &TgRenderer{botClient: botClient, callbacks: callbacks, logger: log}
+}
+
+// tgMessage 發送回應時的單則訊息，photo 不為空時 text 為圖片說明
+type tgMessage struct {
+	photo []byte
+	text  string
+}
+
+// Send 發送回應，有圖片時以文字作為圖片說明
+func (r *TgRenderer) Send(chatID int64, response *command.Response) error {
+	_, err := r.Push(chatID, response, 0)
+	return err
+}
+
+// Push 推播回應，內容超過長度限制時分為多則訊息發送，按鈕附於最後一則
+// sent 為先前已送出的則數，重試時略過已送出的訊息，回傳累計送出的則數
+func (r *TgRenderer) Push(chatID int64, response *command.Response, sent int) (int, error) {
+	messages := splitMessages(response)
+	keyboard := r.renderKeyboard(response.Buttons)
+	for i := sent; i < len(messages); i++ {
+		var markup *tgbotapi.InlineKeyboardMarkup
+		if i == len(messages)-1 {
+			markup = keyboard
+		}
+		var err error
+		if messages[i].photo != nil {
+			err = r.botClient.SendPhotoWithKeyboard(chatID, messages[i].photo, messages[i].text, markup)
+		} else {
+			err = r.botClient.SendMessageWithKeyboard(chatID, messages[i].text, markup)
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+	return max(sent, len(messages)), nil
+}
+
+// Edit 更新既有訊息，含圖片或需分為多則的回應無法編輯原訊息，改為另發新訊息
+func (r *TgRenderer) Edit(chatID int64, messageID int, response *command.Response) error {
+	if response.Image != nil || len(splitMessages(response)) > 1 {
+		return r.Send(chatID, response)
+	}
+	return r.botClient.EditMessageWithKeyboard(chatID, messageID, RenderHTML(response), r.renderKeyboard(response.Buttons))
+}
+
+// SendError 發送錯誤訊息
+func (r *TgRenderer) SendError(chatID int64, err error) error {
+	return r.botClient.SendMessage(chatID, html.EscapeString(err.Error()))
+}
+
+// RenderHTML 將回應轉為 Telegram HTML 文字
+func RenderHTML(response *command.Response) string {
+	var sections []string
+	if response.Title != "" {
+		sections = append(sections, "<b>"+html.EscapeString(response.Title)+"</b>")
+	}
+
+	for _, block := range response.Blocks {
+		if section := renderBlock(block); section != "" {
+			sections = append(sections, section)
+		}
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+// renderBlock 將單一區塊轉為 Telegram HTML 文字
+func renderBlock(block command.Block) string {
+	var lines []string
+	if block.Heading != "" {
+		lines = append(lines, "<b>"+html.EscapeString(block.Heading)+"</b>")
+	}
+	if block.Text != "" {
+		lines = append(lines, html.EscapeString(block.Text))
+	}
+	if len(block.Fields) > 0 {
+		lines = append(lines, "<code>"+html.EscapeString(command.FormatFields(block.Fields))+"</code>")
+	}
+	if block.Table != nil {
+		lines = append(lines, "<pre>"+html.EscapeString(command.FormatTable(block.Table))+"</pre>")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitMessages 將回應分為不超過 Telegram 長度限制的訊息
+// 有圖片且說明放得下時合併為一則，否則圖片只以標題作為說明，內容另以文字訊息發送
+func splitMessages(response *command.Response) []tgMessage {
+	hasImage := response.Image != nil && len(response.Image.Data) > 0
+	if hasImage {
+		if text := RenderHTML(response); utf8.RuneCountInString(text) <= captionMaxLength {
+			return []tgMessage{{photo: response.Image.Data, text: text}}
+		}
+	}
+
+	var messages []tgMessage
+	body := *response
+	if hasImage {
+		photo := tgMessage{photo: response.Image.Data}
+		if title := RenderHTML(&command.Response{Title: response.Title}); utf8.RuneCountInString(title) <= captionMaxLength {
+			photo.text = title
+			body.Title = ""
+		}
+		messages = append(messages, photo)
+	}
+	for _, text := range splitHTML(&body, messageMaxLength) {
+		messages = append(messages, tgMessage{text: text})
+	}
+	if len(messages) == 0 {
+		// 沒有任何內容時仍發送一則，由 Telegram 回傳錯誤
+		messages = append(messages, tgMessage{text: RenderHTML(response)})
+	}
+	return messages
+}
+
+// splitHTML 將回應轉為多段不超過 limit 的 HTML 文字，以區塊為單位分段以免截斷 HTML 標籤
+func splitHTML(response *command.Response, limit int) []string {
+	var sections []string
+	if response.Title != "" {
+		sections = append(sections, "<b>"+html.EscapeString(response.Title)+"</b>")
+	}
+	for _, block := range response.Blocks {
+		for _, part := range splitBlock(block, limit) {
+			if section := renderBlock(part); section != "" {
+				sections = append(sections, section)
+			}
+		}
+	}
+
+	var chunks []string
+	current := ""
+	for _, section := range sections {
+		if current != "" && utf8.RuneCountInString(current)+2+utf8.RuneCountInString(section) > limit {
+			chunks = append(chunks, current)
+			current = ""
+		}
+		if current == "" {
+			current = section
+		} else {
+			current += "\n\n" + section
+		}
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// splitBlock 將超過 limit 的區塊拆為多個區塊
+// 文字、欄位及表格先分開，欄位及表格再依列對半拆分（表格保留表頭），文字依換行或長度對半拆分
+func splitBlock(block command.Block, limit int) []command.Block {
+	if utf8.RuneCountInString(renderBlock(block)) <= limit {
+		return []command.Block{block}
+	}
+
+	var parts []command.Block
+	if block.Heading != "" || block.Text != "" {
+		parts = append(parts, command.Block{Heading: block.Heading, Text: block.Text})
+	}
+	if len(block.Fields) > 0 {
+		parts = append(parts, command.Block{Fields: block.Fields})
+	}
+	if block.Table != nil {
+		parts = append(parts, command.Block{Table: block.Table})
+	}
+	if len(parts) > 1 {
+		var blocks []command.Block
+		for _, part := range parts {
+			blocks = append(blocks, splitBlock(part, limit)...)
+		}
+		return blocks
+	}
+
+	var first, second command.Block
+	switch {
+	case len(block.Fields) > 1:
+		half := len(block.Fields) / 2
+		first, second = command.Block{Fields: block.Fields[:half]}, command.Block{Fields: block.Fields[half:]}
+	case block.Table != nil && len(block.Table.Rows) > 1:
+		half := len(block.Table.Rows) / 2
+		first = command.Block{Table: &command.Table{Header: block.Table.Header, Rows: block.Table.Rows[:half]}}
+		second = command.Block{Table: &command.Table{Header: block.Table.Header, Rows: block.Table.Rows[half:]}}
+	case utf8.RuneCountInString(block.Text) > 1:
+		runes := []rune(block.Text)
+		half := len(runes) / 2
+		if i := strings.LastIndex(string(runes[:half]), "\n"); i > 0 {
+			half = utf8.RuneCountInString(block.Text[:i])
+		}
+		first = command.Block{Heading: block.Heading, Text: strings.TrimRight(string(runes[:half]), "\n")}
+		second = command.Block{Text: strings.TrimLeft(string(runes[half:]), "\n")}
+	default:
+		// 單一欄位或標題即超過長度，無法再拆分
+		return []command.Block{block}
+	}
+	return append(splitBlock(first, limit), splitBlock(second, limit)...)
+}
+
+// DecodeCallback 將按鈕的 callback_data 還原為指令
+func (r *TgRenderer) DecodeCallback(data string) (string, error) {
+	return r.callbacks.Decode(data)
+}
+
+// renderKeyboard 將按鈕轉為行內鍵盤，指令按鈕的 callback_data 由 CallbackCodec 編碼
+func (r *TgRenderer) renderKeyboard(buttons [][]command.Button) *tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, buttonRow := range buttons {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, button := range buttonRow {
+			if button.URL != "" {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonURL(button.Label, button.URL))
+				continue
+			}
+			data, err := r.callbacks.Encode(button.Command)
+			if err != nil {
+				r.logger.Error("按鈕編碼失敗，略過按鈕", zap.String("label", button.Label), zap.String("command", button.Command), zap.Error(err))
+				continue
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(button.Label, data))
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
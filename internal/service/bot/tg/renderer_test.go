@@ -0,0 +1,67 @@
+package tgbot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+)
+
+func TestSplitMessagesCaption(t *testing.T) {
+	image := &command.Image{Data: []byte("png")}
+	short := &command.Response{Title: "台積電", Blocks: []command.Block{{Text: "收盤 1000"}}, Image: image}
+	messages := splitMessages(short)
+	if len(messages) != 1 || messages[0].photo == nil || messages[0].text != RenderHTML(short) {
+		t.Fatalf("short response = %+v, want one photo with full caption", messages)
+	}
+
+	// 說明過長時圖片只附標題，內容完整以文字訊息發送
+	long := &command.Response{Title: "每日摘要", Image: image, Blocks: []command.Block{
+		{Heading: "大盤", Text: "加權指數上漲"},
+		{Heading: "⚡️ 自選股新聞", Text: strings.Repeat("新聞內容", 300)},
+	}}
+	messages = splitMessages(long)
+	if len(messages) != 2 || messages[0].photo == nil || messages[0].text != "<b>每日摘要</b>" {
+		t.Fatalf("long response = %+v, want photo with title then text", messages)
+	}
+	if text := messages[1].text; !strings.Contains(text, "加權指數上漲") || !strings.Contains(text, "自選股新聞") || strings.Contains(text, "每日摘要") {
+		t.Errorf("text message should hold every block without the title, got %q", text)
+	}
+}
+
+func TestSplitMessagesText(t *testing.T) {
+	var fields []command.Field
+	for i := 0; i < 300; i++ {
+		fields = append(fields, command.Field{Label: fmt.Sprintf("股票%03d", i), Value: "1000.00 ▲5.00 (0.50%)"})
+	}
+	response := &command.Response{Title: "每日摘要", Blocks: []command.Block{
+		{Heading: "📈 自選股收盤", Fields: fields},
+		{Heading: "新聞", Text: strings.Repeat("新聞內容\n", 1200)},
+	}}
+
+	messages := splitMessages(response)
+	if len(messages) < 2 {
+		t.Fatalf("messages = %d, want split", len(messages))
+	}
+	var all strings.Builder
+	for i, message := range messages {
+		if n := utf8.RuneCountInString(message.text); n > messageMaxLength {
+			t.Errorf("message %d length = %d, want <= %d", i, n, messageMaxLength)
+		}
+		if strings.Count(message.text, "<code>") != strings.Count(message.text, "</code>") {
+			t.Errorf("message %d has unbalanced tags", i)
+		}
+		all.WriteString(message.text)
+	}
+	// 拆分後不遺漏任何內容
+	for _, want := range []string{"股票000", "股票299"} {
+		if !strings.Contains(all.String(), want) {
+			t.Errorf("split messages missing %q", want)
+		}
+	}
+	if got := strings.Count(all.String(), "新聞內容"); got != 1200 {
+		t.Errorf("news lines = %d, want 1200", got)
+	}
+}
@@ -0,0 +1,211 @@
+// Package exchange_rate_alert 提供匯率到價警示服務
+package exchange_rate_alert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/internal/service/twstock"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// TriggeredAlert 已觸發的匯率警示
+type TriggeredAlert struct {
+	Alert *models.ExchangeRateAlert
+	Rate  float64
+	Date  string
+}
+
+// ExchangeRateAlertService 匯率警示服務介面
+type ExchangeRateAlertService interface {
+	AddAlert(userID uint, args []string) (*models.ExchangeRateAlert, error)
+	ListAlerts(userID uint) ([]*models.ExchangeRateAlert, error)
+	DeleteAlert(userID uint, alertID uint) error
+	// EvaluateAlerts 回傳達到觸發條件的警示，通知建立後需呼叫 MarkTriggered 停用
+	EvaluateAlerts() ([]TriggeredAlert, error)
+	MarkTriggered(alertID uint) error
+}
+
+type exchangeRateAlertService struct {
+	alertRepo    repository.ExchangeRateAlertRepository
+	stockService twstock.StockService
+	logger       logger.Logger
+}
+
+func NewExchangeRateAlertService(alertRepo repository.ExchangeRateAlertRepository, stockService twstock.StockService, log logger.Logger) ExchangeRateAlertService {
+	return &exchangeRateAlertService{
+		alertRepo:    alertRepo,
+		stockService: stockService,
+		logger:       log,
+	}
+}
+
+// 匯率種類別名
+var rateTypeAliases = map[string]models.ExchangeRateType{
+	"cash_buy":  models.ExchangeRateTypeCashBuy,
+	"現金買入":      models.ExchangeRateTypeCashBuy,
+	"cash_sell": models.ExchangeRateTypeCashSell,
+	"現金賣出":      models.ExchangeRateTypeCashSell,
+	"spot_buy":  models.ExchangeRateTypeSpotBuy,
+	"即期買入":      models.ExchangeRateTypeSpotBuy,
+	"spot_sell": models.ExchangeRateTypeSpotSell,
+	"即期賣出":      models.ExchangeRateTypeSpotSell,
+}
+
+// 觸發條件別名
+var conditionAliases = map[string]models.ExchangeRateAlertCondition{
+	"<":     models.ExchangeRateAlertConditionBelow,
+	"<=":    models.ExchangeRateAlertConditionBelow,
+	"below": models.ExchangeRateAlertConditionBelow,
+	"低於":    models.ExchangeRateAlertConditionBelow,
+	">":     models.ExchangeRateAlertConditionAbove,
+	">=":    models.ExchangeRateAlertConditionAbove,
+	"above": models.ExchangeRateAlertConditionAbove,
+	"高於":    models.ExchangeRateAlertConditionAbove,
+}
+
+// AddAlert 新增匯率警示，參數格式：[幣別] [匯率種類] [條件] [門檻值]
+func (s *exchangeRateAlertService) AddAlert(userID uint, args []string) (*models.ExchangeRateAlert, error) {
+	if len(args) != 4 {
+		return nil, fmt.Errorf("參數格式錯誤\n\n使用方式：\n/fxalert 幣別 匯率種類 條件 門檻值\n例如：/fxalert JPY cash_sell below 0.205")
+	}
+
+	currency := strings.ToUpper(args[0])
+	if _, ok := twstock.SupportedCurrencies[currency]; !ok {
+		return nil, fmt.Errorf("不支援的幣別: %s", args[0])
+	}
+
+	rateType, ok := rateTypeAliases[strings.ToLower(args[1])]
+	if !ok {
+		return nil, fmt.Errorf("無效的匯率種類: %s\n可用：cash_buy、cash_sell、spot_buy、spot_sell", args[1])
+	}
+
+	condition, ok := conditionAliases[strings.ToLower(args[2])]
+	if !ok {
+		return nil, fmt.Errorf("無效的條件: %s\n可用：below (低於)、above (高於)", args[2])
+	}
+
+	threshold, err := strconv.ParseFloat(args[3], 64)
+	if err != nil || threshold <= 0 {
+		return nil, fmt.Errorf("無效的門檻值: %s", args[3])
+	}
+
+	alert := &models.ExchangeRateAlert{
+		UserID:    userID,
+		Currency:  currency,
+		RateType:  rateType,
+		Condition: condition,
+		Threshold: threshold,
+		Status:    true,
+	}
+	if err := s.alertRepo.Create(alert); err != nil {
+		s.logger.Error("新增匯率警示失敗", zap.Error(err))
+		return nil, fmt.Errorf("新增匯率警示失敗，請稍後再試")
+	}
+
+	return alert, nil
+}
+
+// ListAlerts 取得使用者啟用中的匯率警示
+func (s *exchangeRateAlertService) ListAlerts(userID uint) ([]*models.ExchangeRateAlert, error) {
+	alerts, err := s.alertRepo.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("取得匯率警示失敗", zap.Error(err))
+		return nil, fmt.Errorf("取得匯率警示失敗，請稍後再試")
+	}
+	return alerts, nil
+}
+
+// DeleteAlert 刪除使用者的匯率警示
+func (s *exchangeRateAlertService) DeleteAlert(userID uint, alertID uint) error {
+	alert, err := s.alertRepo.GetByID(alertID)
+	if err != nil || alert.UserID != userID {
+		return fmt.Errorf("查無此警示編號，請重新確認")
+	}
+
+	if err := s.alertRepo.Delete(alertID); err != nil {
+		s.logger.Error("刪除匯率警示失敗", zap.Error(err))
+		return fmt.Errorf("刪除匯率警示失敗，請稍後再試")
+	}
+	return nil
+}
+
+// EvaluateAlerts 比對所有啟用中的警示與最新匯率，不在此停用，避免通知建立失敗時警示已失效
+func (s *exchangeRateAlertService) EvaluateAlerts() ([]TriggeredAlert, error) {
+	alerts, err := s.alertRepo.GetActiveAlerts()
+	if err != nil {
+		s.logger.Error("取得匯率警示失敗", zap.Error(err))
+		return nil, err
+	}
+
+	// 每個幣別只查詢一次
+	latestRates := make(map[string]*dto.TaiwanExchangeRateData)
+	triggered := make([]TriggeredAlert, 0)
+	for _, alert := range alerts {
+		latest, exists := latestRates[alert.Currency]
+		if !exists {
+			latest, err = s.stockService.GetLatestExchangeRate(alert.Currency)
+			if err != nil {
+				s.logger.Error("取得最新匯率失敗", zap.String("currency", alert.Currency), zap.Error(err))
+			}
+			latestRates[alert.Currency] = latest
+		}
+		if latest == nil {
+			continue
+		}
+
+		rate := rateByType(latest, alert.RateType)
+		// FinMind 以 -1 或 0 表示無報價
+		if rate <= 0 || !isTriggered(alert.Condition, rate, alert.Threshold) {
+			continue
+		}
+
+		triggered = append(triggered, TriggeredAlert{Alert: alert, Rate: rate, Date: latest.Date})
+	}
+
+	return triggered, nil
+}
+
+// MarkTriggered 標記警示已觸發並停用，於通知建立成功後呼叫
+func (s *exchangeRateAlertService) MarkTriggered(alertID uint) error {
+	if err := s.alertRepo.MarkTriggered(alertID, time.Now()); err != nil {
+		s.logger.Error("更新匯率警示狀態失敗", zap.Uint("alertID", alertID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// rateByType 依匯率種類取得對應報價
+func rateByType(data *dto.TaiwanExchangeRateData, rateType models.ExchangeRateType) float64 {
+	switch rateType {
+	case models.ExchangeRateTypeCashBuy:
+		return data.CashBuy
+	case models.ExchangeRateTypeCashSell:
+		return data.CashSell
+	case models.ExchangeRateTypeSpotBuy:
+		return data.SpotBuy
+	case models.ExchangeRateTypeSpotSell:
+		return data.SpotSell
+	default:
+		return 0
+	}
+}
+
+// isTriggered 判斷匯率是否達到觸發條件
+func isTriggered(condition models.ExchangeRateAlertCondition, rate, threshold float64) bool {
+	switch condition {
+	case models.ExchangeRateAlertConditionBelow:
+		return rate <= threshold
+	case models.ExchangeRateAlertConditionAbove:
+		return rate >= threshold
+	default:
+		return false
+	}
+}
@@ -0,0 +1,31 @@
+package job_run
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DefaultCatchUpGrace 未設定時補執行的時間範圍，超過此範圍的排程視為已過時不補執行
+const DefaultCatchUpGrace = 30 * time.Minute
+
+// specParser 與排程器相同的 cron 規格解析，含秒數及 CRON_TZ 前綴
+var specParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// LastScheduled 取得 (now-grace, now] 之間最後一次應觸發的時間，未指定時區的規格以 loc 計算
+// 排程器停止期間錯過多次時只回傳最後一次，避免重複推播相同內容
+func LastScheduled(spec string, loc *time.Location, now time.Time, grace time.Duration) (time.Time, bool) {
+	if grace <= 0 {
+		return time.Time{}, false
+	}
+	schedule, err := specParser.Parse(spec)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var last time.Time
+	for next := schedule.Next(now.Add(-grace).In(loc)); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		last = next
+	}
+	return last, !last.IsZero()
+}
@@ -0,0 +1,44 @@
+package job_run
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastScheduled(t *testing.T) {
+	taipei := time.FixedZone("Asia/Taipei", 8*60*60)
+	now := time.Date(2025, 1, 2, 15, 5, 30, 0, taipei)
+
+	tests := []struct {
+		name  string
+		spec  string
+		grace time.Duration
+		want  time.Time
+		ok    bool
+	}{
+		{"missed daily run", "0 0 15 * * 1-5", 30 * time.Minute, time.Date(2025, 1, 2, 15, 0, 0, 0, taipei), true},
+		{"outside grace window", "0 0 15 * * 1-5", 5 * time.Minute, time.Time{}, false},
+		{"latest of many", "0 */10 * * * *", time.Hour, time.Date(2025, 1, 2, 15, 0, 0, 0, taipei), true},
+		{"not yet due", "0 30 16 * * 1-5", 30 * time.Minute, time.Time{}, false},
+		{"timezone prefix", "CRON_TZ=UTC 0 0 7 * * *", 30 * time.Minute, time.Date(2025, 1, 2, 7, 0, 0, 0, time.UTC), true},
+		{"disabled", "0 0 15 * * 1-5", 0, time.Time{}, false},
+		{"invalid spec", "invalid", 30 * time.Minute, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := LastScheduled(tt.spec, taipei, now, tt.grace)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("%s: LastScheduled() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSpecLocation(t *testing.T) {
+	if loc := specLocation("CRON_TZ=Asia/Tokyo 0 30 8 * * 1-5"); loc == nil || loc.String() != "Asia/Tokyo" {
+		t.Errorf("specLocation(CRON_TZ) = %v, want Asia/Tokyo", loc)
+	}
+	for _, spec := range []string{"0 30 8 * * 1-5", "CRON_TZ=Mars/Base 0 30 8 * * 1-5"} {
+		if loc := specLocation(spec); loc != nil {
+			t.Errorf("specLocation(%q) = %v, want nil", spec, loc)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package job_run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/tian841224/stock-bot/internal/service/trading_calendar"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Job 排程工作定義
+type Job struct {
+	Name           string
+	Spec           string        // cron 規格，空白時僅能手動執行，可加 CRON_TZ= 前綴指定時區
+	Timeout        time.Duration // 執行逾時時間，逾時取消 ctx 並記錄為失敗，0 表示不限制
+	Concurrency    int           // 同一排程器可同時執行的數量，超過時略過本次執行，預設 1
+	TradingDayOnly bool          // 僅台股交易日執行，以排程時區的當地日期判斷，手動執行不受限制
+	Local          bool          // 每個排程器各自執行，不取得執行權也不記錄，例如同步排程
+	// Run 回傳處理數量，ctx 於逾時或執行權由其他排程器接手時取消，排程應停止處理剩餘項目
+	Run func(ctx context.Context) (int, error)
+}
+
+// JobRegistry 排程工作註冊表
+type JobRegistry interface {
+	// Register 註冊排程工作，有 cron 規格時加入排程
+	Register(job *Job) error
+	// Unregister 移除排程工作，用於依設定動態註冊的排程，例如訂閱排程
+	Unregister(name string)
+	Lookup(name string) (*Job, bool)
+	Jobs() []*Job
+	// RunTriggered 執行等待中的手動執行請求
+	RunTriggered()
+	// CatchUp 補執行排程器停止期間錯過的排程，grace 為補執行的時間範圍
+	CatchUp(grace time.Duration)
+}
+
+type jobRegistry struct {
+	cron            *cron.Cron
+	jobRunService   JobRunService
+	tradingCalendar trading_calendar.TradingCalendarService
+	logger          logger.Logger
+
+	mu   sync.RWMutex
+	jobs map[string]*registeredJob
+}
+
+// registeredJob 已註冊的排程工作及執行中的數量限制
+type registeredJob struct {
+	*Job
+	slots   chan struct{}
+	entryID cron.EntryID
+}
+
+func NewJobRegistry(c *cron.Cron, jobRunService JobRunService, tradingCalendar trading_calendar.TradingCalendarService, log logger.Logger) JobRegistry {
+	return &jobRegistry{
+		cron:            c,
+		jobRunService:   jobRunService,
+		tradingCalendar: tradingCalendar,
+		logger:          log,
+		jobs:            make(map[string]*registeredJob),
+	}
+}
+
+// Register 註冊排程工作，名稱重複或 cron 規格無效時回傳錯誤
+func (r *jobRegistry) Register(job *Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.Name]; ok {
+		return fmt.Errorf("排程 %s 已註冊", job.Name)
+	}
+	concurrency := job.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	registered := &registeredJob{Job: job, slots: make(chan struct{}, concurrency)}
+
+	if job.Spec != "" {
+		entryID, err := r.cron.AddFunc(job.Spec, func() { r.runScheduled(registered, time.Now()) })
+		if err != nil {
+			return fmt.Errorf("註冊排程 %s 失敗: %w", job.Name, err)
+		}
+		registered.entryID = entryID
+	}
+	r.jobs[job.Name] = registered
+	r.logger.Info("註冊排程", zap.String("job", job.Name), zap.String("spec", job.Spec))
+	return nil
+}
+
+// Unregister 移除排程工作，執行中的排程不受影響
+func (r *jobRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[name]
+	if !ok {
+		return
+	}
+	if job.entryID != 0 {
+		r.cron.Remove(job.entryID)
+	}
+	delete(r.jobs, name)
+	r.logger.Info("移除排程", zap.String("job", name))
+}
+
+// Lookup 依名稱取得排程工作
+func (r *jobRegistry) Lookup(name string) (*Job, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[name]
+	if !ok {
+		return nil, false
+	}
+	return job.Job, true
+}
+
+// Jobs 依名稱排序取得所有排程工作
+func (r *jobRegistry) Jobs() []*Job {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := make([]*Job, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		jobs = append(jobs, job.Job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs
+}
+
+// runScheduled 執行 scheduledAt 觸發的排程，非交易日略過
+func (r *jobRegistry) runScheduled(job *registeredJob, scheduledAt time.Time) {
+	if job.TradingDayOnly && !r.isTradingDay(job.Spec, scheduledAt) {
+		r.logger.Info("今日非交易日，略過排程", zap.String("job", job.Name))
+		return
+	}
+	if job.Local {
+		r.invoke(job, func(fn func(ctx context.Context) (int, error)) bool {
+			if _, err := fn(context.Background()); err != nil {
+				r.logger.Error("排程執行失敗", zap.String("job", job.Name), zap.Error(err))
+			}
+			return true
+		})
+		return
+	}
+	r.invoke(job, func(fn func(ctx context.Context) (int, error)) bool {
+		return r.jobRunService.Run(job.Name, scheduledAt, Lease(job.Timeout), fn)
+	})
+}
+
+// isTradingDay 以排程規格的時區判斷 scheduledAt 的當地日期是否為台股交易日，未指定時區時以排程器時區判斷
+func (r *jobRegistry) isTradingDay(spec string, scheduledAt time.Time) bool {
+	loc := specLocation(spec)
+	if loc == nil {
+		return r.tradingCalendar.IsTradingDay(scheduledAt)
+	}
+	local := scheduledAt.In(loc)
+	// 以 UTC 中午表示當地日期，換算台北時間後仍為同一天
+	return r.tradingCalendar.IsTradingDay(time.Date(local.Year(), local.Month(), local.Day(), 12, 0, 0, 0, time.UTC))
+}
+
+// specLocation 取得 cron 規格 CRON_TZ= 或 TZ= 前綴指定的時區，未指定或無效時回傳 nil
+func specLocation(spec string) *time.Location {
+	prefix, _, _ := strings.Cut(spec, " ")
+	for _, key := range []string{"CRON_TZ=", "TZ="} {
+		if name, ok := strings.CutPrefix(prefix, key); ok {
+			if loc, err := time.LoadLocation(name); err == nil {
+				return loc
+			}
+		}
+	}
+	return nil
+}
+
+// CatchUp 補執行錯過的排程，已有執行紀錄的排程時間由執行權判斷略過，不會重複執行
+func (r *jobRegistry) CatchUp(grace time.Duration) {
+	now := time.Now()
+	r.mu.RLock()
+	jobs := make([]*registeredJob, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		if job.Spec != "" && !job.Local {
+			jobs = append(jobs, job)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, job := range jobs {
+		scheduledAt, ok := LastScheduled(job.Spec, r.cron.Location(), now, grace)
+		if !ok {
+			continue
+		}
+		r.logger.Info("檢查錯過的排程", zap.String("job", job.Name), zap.Time("scheduledAt", scheduledAt))
+		r.runScheduled(job, scheduledAt)
+	}
+}
+
+// RunTriggered 執行等待中的手動執行請求，排程仍在執行中時保留請求待下次檢查
+func (r *jobRegistry) RunTriggered() {
+	runs, err := r.jobRunService.Pending()
+	if err != nil {
+		r.logger.Error("取得手動執行請求失敗", zap.Error(err))
+		return
+	}
+
+	for _, run := range runs {
+		r.mu.RLock()
+		job, ok := r.jobs[run.JobName]
+		r.mu.RUnlock()
+		if !ok || job.Local {
+			// 其他版本的排程器可能支援此排程，保留請求
+			r.logger.Warn("未註冊的手動執行排程", zap.String("job", run.JobName))
+			continue
+		}
+
+		run := run
+		go r.invoke(job, func(fn func(ctx context.Context) (int, error)) bool {
+			return r.jobRunService.RunTriggered(run, Lease(job.Timeout), fn)
+		})
+	}
+}
+
+// invoke 取得執行數量限制後執行，start 回傳 false 表示未執行
+// 執行數量限制保留至排程實際結束，逾時的排程於取消後結束，不會與下次執行重疊
+func (r *jobRegistry) invoke(job *registeredJob, start func(fn func(ctx context.Context) (int, error)) bool) {
+	select {
+	case job.slots <- struct{}{}:
+	default:
+		r.logger.Warn("排程上次執行尚未完成，略過本次執行", zap.String("job", job.Name))
+		return
+	}
+	defer func() { <-job.slots }()
+
+	start(func(ctx context.Context) (int, error) {
+		return r.runWithTimeout(ctx, job)
+	})
+}
+
+// runWithTimeout 執行排程工作，逾時取消 ctx 並等待排程結束後回傳逾時錯誤
+func (r *jobRegistry) runWithTimeout(ctx context.Context, job *registeredJob) (count int, err error) {
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			count, err = 0, fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	count, err = job.Run(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return count, fmt.Errorf("執行逾時 (%s)", job.Timeout)
+	}
+	return count, err
+}
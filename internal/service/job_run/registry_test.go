@@ -0,0 +1,37 @@
+package job_run
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func TestRunWithTimeoutCancelsJob(t *testing.T) {
+	registry := NewJobRegistry(cron.New(), nil, nil, nopLogger{}).(*jobRegistry)
+	returned := false
+	job := &registeredJob{Job: &Job{
+		Name:    JobNewsAlert,
+		Timeout: 20 * time.Millisecond,
+		Run: func(ctx context.Context) (int, error) {
+			defer func() { returned = true }()
+			select {
+			case <-ctx.Done():
+				return 2, ctx.Err()
+			case <-time.After(time.Second):
+				return 5, nil
+			}
+		},
+	}}
+
+	// 逾時取消後等待排程結束才回傳，不留下背景執行的排程
+	count, err := registry.runWithTimeout(context.Background(), job)
+	if err == nil || !strings.Contains(err.Error(), "執行逾時") {
+		t.Errorf("err = %v, want timeout", err)
+	}
+	if !returned || count != 2 {
+		t.Errorf("returned = %v, count = %d, want job stopped by cancellation with partial count", returned, count)
+	}
+}
@@ -0,0 +1,341 @@
+// Package job_run 提供排程執行權及執行紀錄，多個排程器同時運作時同一排程只執行一次
+package job_run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxErrorLength = 2000 // 錯誤訊息保留長度，避免部分失敗時寫入過長的訊息
+	historyLimit   = 10   // 查詢單一排程時顯示的執行紀錄數
+	// DefaultLease 未設定逾時時間的排程執行權期限，排程器中斷後超過期限即可由其他排程器接手
+	DefaultLease = 30 * time.Minute
+	// leaseMargin 有逾時時間的排程，執行權期限為逾時時間再加上此緩衝，避免逾時記錄前即被接手
+	leaseMargin = time.Minute
+	// leaseRenewals 執行期間於執行權期限內延長的次數，單次延長失敗時仍有時間重試
+	leaseRenewals = 3
+)
+
+// 排程名稱，排程器註冊及 Bot 手動執行指令共用
+const (
+	JobExchangeRate  = "fx_alert"
+	JobNewsAlert     = "news_alert"
+	JobStockPrice    = "price"
+	JobStockNews     = "news"
+	JobDailyMarket   = "market"
+	JobTopVolume     = "top_volume"
+	JobTreasuryYield = "yield"
+	JobDigest        = "digest" // 摘要模式的使用者合併推播，僅依推播時間執行，見 SlotJobName
+)
+
+// JobInfo 可手動執行的排程
+type JobInfo struct {
+	Name        string
+	Description string
+}
+
+// TriggerableJobs 固定名稱的可手動執行排程，依顯示順序排列
+// 訂閱項目的排程推播給該項目的所有訂閱者，只推播單一推播時間的訂閱者時以 SlotJobName 的名稱手動執行
+var TriggerableJobs = []JobInfo{
+	{JobExchangeRate, "匯率警示"},
+	{JobNewsAlert, "新聞關鍵字警示"},
+	{JobStockPrice, "自選股收盤"},
+	{JobStockNews, "自選股新聞"},
+	{JobDailyMarket, "大盤資訊"},
+	{JobTopVolume, "交易量前20名"},
+	{JobTreasuryYield, "美債殖利率"},
+}
+
+// SubscriptionJobs 訂閱項目對應的排程名稱
+var SubscriptionJobs = map[models.SubscriptionItem]string{
+	models.SubscriptionItemStockInfo:       JobStockPrice,
+	models.SubscriptionItemStockNews:       JobStockNews,
+	models.SubscriptionItemDailyMarketInfo: JobDailyMarket,
+	models.SubscriptionItemTopVolumeItems:  JobTopVolume,
+	models.SubscriptionItemTreasuryYield:   JobTreasuryYield,
+}
+
+// ErrUnknownJob 不是可手動執行的排程
+var ErrUnknownJob = errors.New("unknown job")
+
+// JobRunService 排程執行服務介面
+type JobRunService interface {
+	// Run 取得排程執行權後執行 fn 並記錄結果，其他排程器已執行相同排程時略過並回傳 false
+	// lease 為執行權期限，執行期間定期延長，排程器中斷後超過期限的紀錄可由下次執行接手
+	// 執行權已由其他排程器接手時取消 fn 的 ctx
+	Run(name string, scheduledAt time.Time, lease time.Duration, fn func(ctx context.Context) (int, error)) bool
+	// Trigger 建立手動執行請求，由排程器取得後執行
+	Trigger(name, triggeredBy string) (*models.JobRun, error)
+	// Pending 取得等待執行的手動執行請求
+	Pending() ([]*models.JobRun, error)
+	// RunTriggered 取得手動執行請求後執行 fn 並記錄結果，已由其他排程器取得時回傳 false
+	RunTriggered(run *models.JobRun, lease time.Duration, fn func(ctx context.Context) (int, error)) bool
+	// Latest 取得各排程最近一次的執行紀錄
+	Latest() ([]*models.JobRun, error)
+	// History 取得指定排程最近的執行紀錄
+	History(name string) ([]*models.JobRun, error)
+}
+
+type jobRunService struct {
+	jobRunRepo repository.JobRunRepository
+	instance   string
+	logger     logger.Logger
+}
+
+func NewJobRunService(jobRunRepo repository.JobRunRepository, log logger.Logger) JobRunService {
+	return &jobRunService{
+		jobRunRepo: jobRunRepo,
+		instance:   instanceName(),
+		logger:     log,
+	}
+}
+
+// Run 以排程名稱及觸發時間建立執行紀錄作為執行權，紀錄已存在表示其他排程器已執行
+func (s *jobRunService) Run(name string, scheduledAt time.Time, lease time.Duration, fn func(ctx context.Context) (int, error)) bool {
+	now := time.Now()
+	leaseUntil := now.Add(lease)
+	run := &models.JobRun{
+		JobName:     name,
+		ScheduledAt: ScheduledAt(scheduledAt),
+		Status:      models.JobRunStatusRunning,
+		Instance:    s.instance,
+		StartedAt:   now,
+		LeaseUntil:  &leaseUntil,
+	}
+	acquired, err := s.jobRunRepo.TryStart(run)
+	if err != nil {
+		// 無法確認其他排程器是否執行時不執行，避免重複推播
+		s.logger.Error("取得排程執行權失敗", zap.String("job", name), zap.Error(err))
+		return false
+	}
+	if !acquired {
+		s.logger.Info("排程已有執行紀錄，略過", zap.String("job", name), zap.Time("scheduledAt", run.ScheduledAt))
+		return false
+	}
+
+	s.finish(run, lease, fn)
+	return true
+}
+
+// Trigger 建立手動執行請求，觸發時間不取至分鐘，避免與同一分鐘的排程執行衝突
+func (s *jobRunService) Trigger(name, triggeredBy string) (*models.JobRun, error) {
+	if !IsTriggerable(name) {
+		return nil, ErrUnknownJob
+	}
+	run := &models.JobRun{
+		JobName:     name,
+		ScheduledAt: time.Now(),
+		Status:      models.JobRunStatusPending,
+		TriggeredBy: truncate(triggeredBy, 128),
+	}
+	if err := s.jobRunRepo.Create(run); err != nil {
+		s.logger.Error("建立手動執行請求失敗", zap.String("job", name), zap.Error(err))
+		return nil, err
+	}
+	return run, nil
+}
+
+// Pending 取得等待執行的手動執行請求，包含執行中但排程器已中斷的請求
+func (s *jobRunService) Pending() ([]*models.JobRun, error) {
+	return s.jobRunRepo.GetPending(time.Now())
+}
+
+// RunTriggered 取得手動執行請求後執行並記錄結果，排程器中斷的請求於執行權到期後重新執行
+func (s *jobRunService) RunTriggered(run *models.JobRun, lease time.Duration, fn func(ctx context.Context) (int, error)) bool {
+	now := time.Now()
+	leaseUntil := now.Add(lease)
+	run.Instance = s.instance
+	run.StartedAt = now
+	run.LeaseUntil = &leaseUntil
+	claimed, err := s.jobRunRepo.Claim(run)
+	if err != nil {
+		s.logger.Error("取得手動執行請求失敗", zap.String("job", run.JobName), zap.Uint("id", run.ID), zap.Error(err))
+		return false
+	}
+	if !claimed {
+		return false
+	}
+
+	s.logger.Info("手動執行排程", zap.String("job", run.JobName), zap.String("triggeredBy", run.TriggeredBy))
+	s.finish(run, lease, fn)
+	return true
+}
+
+// Latest 取得各排程最近一次的執行紀錄
+func (s *jobRunService) Latest() ([]*models.JobRun, error) {
+	return s.jobRunRepo.GetLatestPerJob()
+}
+
+// History 取得指定排程最近的執行紀錄
+func (s *jobRunService) History(name string) ([]*models.JobRun, error) {
+	return s.jobRunRepo.GetByJobName(name, historyLimit)
+}
+
+// finish 執行排程並記錄結果，執行期間持續延長執行權
+func (s *jobRunService) finish(run *models.JobRun, lease time.Duration, fn func(ctx context.Context) (int, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := s.keepLease(run, lease, cancel)
+	count, err := s.execute(ctx, fn)
+	stop()
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Count = count
+	run.Status = models.JobRunStatusSuccess
+	if err != nil {
+		run.Status = models.JobRunStatusFailed
+		run.Error = truncate(err.Error(), maxErrorLength)
+		s.logger.Error("排程執行失敗", zap.String("job", run.JobName), zap.Int("count", count), zap.Error(err))
+	} else {
+		s.logger.Info("排程執行完成", zap.String("job", run.JobName), zap.Int("count", count), zap.Duration("duration", finishedAt.Sub(run.StartedAt)))
+	}
+
+	if err := s.jobRunRepo.Finish(run); err != nil {
+		s.logger.Error("更新排程執行紀錄失敗", zap.String("job", run.JobName), zap.Error(err))
+	}
+}
+
+// keepLease 執行期間定期延長執行權期限，執行時間超過 lease 時不會被其他排程器視為中斷而重複執行
+// 執行權已由其他排程器接手時呼叫 cancel 停止執行，回傳的 stop 停止延長並等待結束
+func (s *jobRunService) keepLease(run *models.JobRun, lease time.Duration, cancel context.CancelFunc) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(lease / leaseRenewals)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			leaseUntil := time.Now().Add(lease)
+			run.LeaseUntil = &leaseUntil
+			renewed, err := s.jobRunRepo.RenewLease(run)
+			if err != nil {
+				// 暫時無法連線時繼續執行，期限到期前仍會重試
+				s.logger.Warn("延長排程執行權失敗", zap.String("job", run.JobName), zap.Error(err))
+				continue
+			}
+			if !renewed {
+				s.logger.Warn("排程執行權已由其他排程器接手，停止執行", zap.String("job", run.JobName), zap.Uint("id", run.ID))
+				cancel()
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// execute 執行排程，panic 時記錄為失敗，避免執行紀錄停留在執行中
+func (s *jobRunService) execute(ctx context.Context, fn func(ctx context.Context) (int, error)) (count int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// Lease 依排程逾時時間取得執行權期限
+func Lease(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return DefaultLease
+	}
+	return timeout + leaseMargin
+}
+
+// IsTriggerable 是否為可手動執行的排程，包含時區及排程規格有效的推播時間排程
+func IsTriggerable(name string) bool {
+	for _, job := range TriggerableJobs {
+		if job.Name == name {
+			return true
+		}
+	}
+
+	_, timezone, spec, ok := ParseSlotJob(name)
+	if !ok {
+		return false
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return false
+	}
+	_, err := specParser.Parse(spec)
+	return err == nil
+}
+
+// SlotJobName 訂閱項目或摘要於單一推播時間的排程名稱，例如 news:Asia/Taipei:0 0 15 * * 1-5
+// 排程規格的空白統一為單一空白，手動執行指令解析後名稱相同
+func SlotJobName(job, timezone, spec string) string {
+	return fmt.Sprintf("%s:%s:%s", job, timezone, strings.Join(strings.Fields(spec), " "))
+}
+
+// ParseSlotJob 由推播時間排程名稱取得訂閱項目或摘要的排程名稱、時區及排程規格
+func ParseSlotJob(name string) (job, timezone, spec string, ok bool) {
+	parts := strings.SplitN(name, ":", 3)
+	if len(parts) != 3 || !isSlotJob(parts[0]) || parts[1] == "" || strings.TrimSpace(parts[2]) == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// isSlotJob 是否為依推播時間執行的排程
+func isSlotJob(job string) bool {
+	if job == JobDigest {
+		return true
+	}
+	for _, name := range SubscriptionJobs {
+		if name == job {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeJobName 排程名稱不分大小寫，推播時間排程名稱含時區，只將排程名稱轉為小寫
+func NormalizeJobName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if job, rest, ok := strings.Cut(name, ":"); ok {
+		return strings.ToLower(job) + ":" + rest
+	}
+	return strings.ToLower(name)
+}
+
+// ScheduledAt 排程觸發時間取至分鐘，各排程器觸發時間略有差異仍視為同一次執行
+func ScheduledAt(t time.Time) time.Time {
+	return t.Truncate(time.Minute)
+}
+
+// instanceName 排程器識別名稱，容器中主機名稱即容器ID
+func instanceName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", hostname, os.Getpid())
+}
+
+// truncate 依字元截斷字串
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,295 @@
+package job_run
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"go.uber.org/zap"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...zap.Field)  {}
+func (nopLogger) Error(string, ...zap.Field) {}
+func (nopLogger) Warn(string, ...zap.Field)  {}
+func (nopLogger) Debug(string, ...zap.Field) {}
+func (nopLogger) Panic(string, ...zap.Field) {}
+func (nopLogger) Fatal(string, ...zap.Field) {}
+func (nopLogger) Sync() error                { return nil }
+
+// fakeJobRunRepo 以記憶體模擬排程名稱及時間的唯一索引與執行權接手
+type fakeJobRunRepo struct {
+	runs      []*models.JobRun
+	renewed   int
+	takenOver atomic.Bool // 模擬其他排程器已接手，延長執行權失敗
+}
+
+func (f *fakeJobRunRepo) find(name string, scheduledAt time.Time) *models.JobRun {
+	for _, run := range f.runs {
+		if run.JobName == name && run.ScheduledAt.Equal(scheduledAt) {
+			return run
+		}
+	}
+	return nil
+}
+
+func (f *fakeJobRunRepo) TryStart(run *models.JobRun) (bool, error) {
+	existing := f.find(run.JobName, run.ScheduledAt)
+	if existing == nil {
+		run.ID = uint(len(f.runs) + 1)
+		stored := *run
+		f.runs = append(f.runs, &stored)
+		return true, nil
+	}
+	if !existing.Interrupted(run.StartedAt) {
+		return false, nil
+	}
+	existing.Instance, existing.StartedAt, existing.LeaseUntil = run.Instance, run.StartedAt, run.LeaseUntil
+	run.ID = existing.ID
+	return true, nil
+}
+
+func (f *fakeJobRunRepo) RenewLease(run *models.JobRun) (bool, error) {
+	if f.takenOver.Load() {
+		return false, nil
+	}
+	f.runs[run.ID-1].LeaseUntil = run.LeaseUntil
+	f.renewed++
+	return true, nil
+}
+
+func (f *fakeJobRunRepo) Finish(run *models.JobRun) error {
+	stored := f.runs[run.ID-1]
+	if stored.Instance != run.Instance {
+		return nil
+	}
+	stored.Status, stored.FinishedAt, stored.Count, stored.Error = run.Status, run.FinishedAt, run.Count, run.Error
+	return nil
+}
+
+func (f *fakeJobRunRepo) Create(run *models.JobRun) error {
+	run.ID = uint(len(f.runs) + 1)
+	stored := *run
+	f.runs = append(f.runs, &stored)
+	return nil
+}
+
+func (f *fakeJobRunRepo) GetPending(now time.Time) ([]*models.JobRun, error) {
+	var pending []*models.JobRun
+	for _, run := range f.runs {
+		if run.Status == models.JobRunStatusPending || (run.TriggeredBy != "" && run.Interrupted(now)) {
+			copied := *run
+			pending = append(pending, &copied)
+		}
+	}
+	return pending, nil
+}
+
+func (f *fakeJobRunRepo) Claim(run *models.JobRun) (bool, error) {
+	stored := f.runs[run.ID-1]
+	if stored.Status != models.JobRunStatusPending && !stored.Interrupted(run.StartedAt) {
+		return false, nil
+	}
+	stored.Status, stored.Instance, stored.StartedAt, stored.LeaseUntil = models.JobRunStatusRunning, run.Instance, run.StartedAt, run.LeaseUntil
+	return true, nil
+}
+
+func (f *fakeJobRunRepo) GetLatestPerJob() ([]*models.JobRun, error) { return f.runs, nil }
+
+func (f *fakeJobRunRepo) GetByJobName(string, int) ([]*models.JobRun, error) { return f.runs, nil }
+
+// seedRunning 建立排程器中斷時留下的執行中紀錄
+func (f *fakeJobRunRepo) seedRunning(name string, scheduledAt time.Time, leaseUntil time.Time, triggeredBy string) *models.JobRun {
+	run := &models.JobRun{
+		Model:       models.Model{ID: uint(len(f.runs) + 1)},
+		JobName:     name,
+		ScheduledAt: scheduledAt,
+		Status:      models.JobRunStatusRunning,
+		Instance:    "crashed:1",
+		StartedAt:   leaseUntil.Add(-time.Minute),
+		LeaseUntil:  &leaseUntil,
+		TriggeredBy: triggeredBy,
+	}
+	f.runs = append(f.runs, run)
+	return run
+}
+
+func TestRunSkipsActiveLease(t *testing.T) {
+	repo := &fakeJobRunRepo{}
+	service := NewJobRunService(repo, nopLogger{})
+	scheduledAt := ScheduledAt(time.Now())
+	repo.seedRunning(JobExchangeRate, scheduledAt, time.Now().Add(5*time.Minute), "")
+
+	called := false
+	if service.Run(JobExchangeRate, scheduledAt, time.Minute, func(context.Context) (int, error) { called = true; return 0, nil }) {
+		t.Error("Run() = true, want false while another scheduler holds the lease")
+	}
+	if called {
+		t.Error("job should not run while another scheduler holds the lease")
+	}
+}
+
+func TestRunTakesOverExpiredLease(t *testing.T) {
+	repo := &fakeJobRunRepo{}
+	service := NewJobRunService(repo, nopLogger{})
+	scheduledAt := ScheduledAt(time.Now().Add(-20 * time.Minute))
+	stale := repo.seedRunning(JobExchangeRate, scheduledAt, time.Now().Add(-time.Minute), "")
+
+	if !service.Run(JobExchangeRate, scheduledAt, time.Minute, func(context.Context) (int, error) { return 3, nil }) {
+		t.Fatal("Run() = false, want takeover of expired lease")
+	}
+	if stale.Status != models.JobRunStatusSuccess || stale.Count != 3 || stale.Instance == "crashed:1" {
+		t.Errorf("run = %s/%d by %s, want success/3 by this scheduler", stale.Status, stale.Count, stale.Instance)
+	}
+}
+
+func TestRunRecordsLease(t *testing.T) {
+	repo := &fakeJobRunRepo{}
+	service := NewJobRunService(repo, nopLogger{})
+	start := time.Now()
+
+	service.Run(JobExchangeRate, start, 11*time.Minute, func(context.Context) (int, error) {
+		run := repo.runs[0]
+		if run.Status != models.JobRunStatusRunning || run.LeaseUntil == nil {
+			t.Fatalf("run during execution = %+v, want running with lease", run)
+		}
+		if lease := run.LeaseUntil.Sub(run.StartedAt); lease != 11*time.Minute {
+			t.Errorf("lease = %v, want 11m", lease)
+		}
+		return 0, nil
+	})
+	if repo.runs[0].Interrupted(start.Add(time.Hour)) {
+		t.Error("finished run should not be reported as interrupted")
+	}
+}
+
+func TestRunRenewsLease(t *testing.T) {
+	repo := &fakeJobRunRepo{}
+	service := NewJobRunService(repo, nopLogger{})
+	lease := 30 * time.Millisecond
+
+	// 執行時間超過執行權期限時仍持續延長，不會被其他排程器接手
+	service.Run(JobExchangeRate, time.Now(), lease, func(context.Context) (int, error) {
+		time.Sleep(4 * lease)
+		return 1, nil
+	})
+	run := repo.runs[0]
+	if repo.renewed == 0 {
+		t.Fatal("lease was never renewed during a long run")
+	}
+	if !run.LeaseUntil.After(run.StartedAt.Add(lease)) {
+		t.Errorf("lease_until = %v, want extended past %v", run.LeaseUntil, run.StartedAt.Add(lease))
+	}
+}
+
+func TestRunCancelsWhenLeaseLost(t *testing.T) {
+	repo := &fakeJobRunRepo{}
+	service := NewJobRunService(repo, nopLogger{})
+	repo.takenOver.Store(true)
+
+	var canceled bool
+	service.Run(JobExchangeRate, time.Now(), 30*time.Millisecond, func(ctx context.Context) (int, error) {
+		select {
+		case <-ctx.Done():
+			canceled = true
+			return 0, ctx.Err()
+		case <-time.After(time.Second):
+			return 1, nil
+		}
+	})
+	if !canceled {
+		t.Error("job should be canceled once another scheduler takes over the lease")
+	}
+	if run := repo.runs[0]; run.Status != models.JobRunStatusFailed || run.Error == "" {
+		t.Errorf("run = %s %q, want failed with cancellation", run.Status, run.Error)
+	}
+}
+
+func TestRunTriggeredReclaimsInterrupted(t *testing.T) {
+	repo := &fakeJobRunRepo{}
+	service := NewJobRunService(repo, nopLogger{})
+	repo.seedRunning(JobNewsAlert, time.Now().Add(-time.Hour), time.Now().Add(-time.Minute), "admin")
+	repo.seedRunning(JobExchangeRate, ScheduledAt(time.Now().Add(-time.Hour)), time.Now().Add(-time.Minute), "")
+	subscriptionJob := SlotJobName(JobStockNews, "Asia/Taipei", "0 0 15 * * 1-5")
+	if _, err := service.Trigger(subscriptionJob, "admin"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+
+	pending, err := service.Pending()
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	var names []string
+	for _, run := range pending {
+		names = append(names, run.JobName)
+	}
+	if len(names) != 2 || names[0] != JobNewsAlert || names[1] != subscriptionJob {
+		t.Fatalf("pending = %v, want interrupted trigger and new trigger", names)
+	}
+
+	for _, run := range pending {
+		if !service.RunTriggered(run, time.Minute, func(context.Context) (int, error) { return 1, nil }) {
+			t.Errorf("RunTriggered(%s) = false, want true", run.JobName)
+		}
+	}
+	// 已由其他排程器重新取得的請求不重複執行
+	if service.RunTriggered(pending[0], time.Minute, func(context.Context) (int, error) { return 1, nil }) {
+		t.Error("RunTriggered() on finished run = true, want false")
+	}
+	if repo.runs[1].Status != models.JobRunStatusRunning {
+		t.Error("interrupted scheduled run should be left for catch-up or a manual retry")
+	}
+}
+
+func TestLease(t *testing.T) {
+	if got := Lease(0); got != DefaultLease {
+		t.Errorf("Lease(0) = %v, want %v", got, DefaultLease)
+	}
+	if got := Lease(10 * time.Minute); got != 11*time.Minute {
+		t.Errorf("Lease(10m) = %v, want 11m", got)
+	}
+}
+
+func TestIsTriggerable(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{JobExchangeRate, true},
+		{JobStockNews, true},
+		{JobDigest, false},
+		{"news:Asia/Taipei:0 0 15 * * 1-5", true},
+		{"digest:Asia/Taipei:0 0 15 * * 1-5", true},
+		{"subscription:Asia/Taipei:0 0 15 * * 1-5", false},
+		{"news:Asia/Taipei:not a spec", false},
+		{"news:Mars/Base:0 0 15 * * 1-5", false},
+		{"news:Asia/Taipei:", false},
+	}
+	for _, tt := range tests {
+		if got := IsTriggerable(tt.name); got != tt.want {
+			t.Errorf("IsTriggerable(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeJobName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"FX_Alert", JobExchangeRate},
+		{"News:Asia/Taipei:0  0 15 * * 1-5", "news:Asia/Taipei:0 0 15 * * 1-5"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeJobName(tt.name); got != tt.want {
+			t.Errorf("NormalizeJobName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := SlotJobName(JobStockNews, "Asia/Taipei", "0  0 15 * * 1-5"); got != "news:Asia/Taipei:0 0 15 * * 1-5" {
+		t.Errorf("SlotJobName() = %q", got)
+	}
+}
@@ -0,0 +1,107 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	tgbotInfra "github.com/tian841224/stock-bot/internal/infrastructure/tgbot"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/internal/service/bot/discord"
+	"github.com/tian841224/stock-bot/internal/service/bot/line"
+	"github.com/tian841224/stock-bot/internal/service/bot/slack"
+	tgbot "github.com/tian841224/stock-bot/internal/service/bot/tg"
+)
+
+// ErrRecipientBlocked 使用者已封鎖機器人或無法再接收訊息，使用者應標記為停用
+var ErrRecipientBlocked = errors.New("使用者無法接收訊息")
+
+// Notifier 將指令回應推播給單一平台的使用者，accountID 即為 models.User.AccountID
+// 回應可能分為多則訊息發送，sent 為先前已送出的則數，重試時略過已送出的訊息，回傳累計送出的則數
+type Notifier interface {
+	Notify(accountID string, response *command.Response, sent int) (int, error)
+}
+
+// Notifiers 依使用者類型對應推播方式，未設定的平台不推播
+type Notifiers map[models.UserType]Notifier
+
+// Notify 依使用者平台發送回應
+func (n Notifiers) Notify(user *models.User, response *command.Response, sent int) (int, error) {
+	notifier, ok := n[user.UserType]
+	if !ok || notifier == nil {
+		return sent, fmt.Errorf("未設定使用者類型 %d 的推播方式", user.UserType)
+	}
+	return notifier.Notify(user.AccountID, response, sent)
+}
+
+// notifyOnce 只需一則訊息的平台，已送出時不再發送
+func notifyOnce(sent int, send func() error) (int, error) {
+	if sent > 0 {
+		return sent, nil
+	}
+	if err := send(); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// tgNotifier Telegram 推播，AccountID 為個人或群組的 chat ID
+type tgNotifier struct {
+	renderer *tgbot.TgRenderer
+}
+
+func NewTgNotifier(renderer *tgbot.TgRenderer) Notifier {
+	return &tgNotifier{renderer: renderer}
+}
+
+func (n *tgNotifier) Notify(accountID string, response *command.Response, sent int) (int, error) {
+	chatID, err := strconv.ParseInt(accountID, 10, 64)
+	if err != nil {
+		return sent, fmt.Errorf("轉換使用者 AccountID 失敗: %w", err)
+	}
+	sent, err = n.renderer.Push(chatID, response, sent)
+	if errors.Is(err, tgbotInfra.ErrChatBlocked) {
+		return sent, fmt.Errorf("%w: %v", ErrRecipientBlocked, err)
+	}
+	return sent, err
+}
+
+// lineNotifier LINE 推播，AccountID 為 LINE user ID
+type lineNotifier struct {
+	renderer *line.LineRenderer
+}
+
+func NewLineNotifier(renderer *line.LineRenderer) Notifier {
+	return &lineNotifier{renderer: renderer}
+}
+
+func (n *lineNotifier) Notify(accountID string, response *command.Response, sent int) (int, error) {
+	return notifyOnce(sent, func() error { return n.renderer.Push(accountID, response) })
+}
+
+// discordNotifier Discord 推播，以私訊發送給使用者
+type discordNotifier struct {
+	renderer *discord.DiscordRenderer
+}
+
+func NewDiscordNotifier(renderer *discord.DiscordRenderer) Notifier {
+	return &discordNotifier{renderer: renderer}
+}
+
+func (n *discordNotifier) Notify(accountID string, response *command.Response, sent int) (int, error) {
+	return notifyOnce(sent, func() error { return n.renderer.SendDirect(accountID, response) })
+}
+
+// slackNotifier Slack 推播，使用者即為訂閱的頻道
+type slackNotifier struct {
+	renderer *slack.SlackRenderer
+}
+
+func NewSlackNotifier(renderer *slack.SlackRenderer) Notifier {
+	return &slackNotifier{renderer: renderer}
+}
+
+func (n *slackNotifier) Notify(accountID string, response *command.Response, sent int) (int, error) {
+	return notifyOnce(sent, func() error { return n.renderer.Send(accountID, response) })
+}
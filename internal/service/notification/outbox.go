@@ -0,0 +1,402 @@
+package notification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/internal/service/user_preference"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	outboxPollInterval = 10 * time.Second // 輪詢待發送通知的間隔
+	outboxBatchSize    = 100              // 每次處理的投遞數量
+	outboxMaxAttempts  = 5                // 最多嘗試發送次數
+	outboxBaseBackoff  = 30 * time.Second // 第一次重試的等待時間，之後每次加倍
+	outboxMaxBackoff   = time.Hour        // 重試等待時間上限
+	outboxSendingLease = 10 * time.Minute // 發送中超過此時間視為發送工作中斷
+	outboxEmptyJSON    = "{}"             // jsonb 欄位不接受空字串
+	// 通知圖片保留時間，需大於勿擾時段及重試的最長延後時間
+	outboxImageRetention = 7 * 24 * time.Hour
+	outboxPruneInterval  = 24 * time.Hour
+)
+
+// outboxPayload 通知事件內容，圖片另存於 notification_images 並以雜湊值參照，避免每位使用者各存一份圖片
+type outboxPayload struct {
+	Response  *command.Response `json:"response"`
+	ImageHash string            `json:"image_hash,omitempty"`
+}
+
+// errUserInactive 排入佇列後才停用的使用者，不再重試
+var errUserInactive = errors.New("使用者已停用")
+
+// Outbox 通知發送佇列
+// 通知先與投遞紀錄一併寫入資料庫，再由背景工作發送，重啟後未發送的通知會繼續發送
+// 使用者勿擾時段內的通知延後至勿擾結束後發送
+type Outbox interface {
+	// Enqueue 建立通知事件及投遞紀錄，item 為 SubscriptionItemDefault 時表示非訂閱項目的通知
+	Enqueue(item models.SubscriptionItem, userIDs []uint, response *command.Response) error
+	// Start 啟動背景發送工作，ctx 結束時停止
+	Start(ctx context.Context)
+	// Process 發送已到期的通知
+	Process()
+}
+
+type outbox struct {
+	notifiers    Notifiers
+	userRepo     repository.UserRepository
+	featureRepo  repository.FeatureRepository
+	eventRepo    repository.NotificationEventRepository
+	deliveryRepo repository.NotificationDeliveryRepository
+	imageRepo    repository.NotificationImageRepository
+	preferences  user_preference.UserPreferenceService
+	logger       logger.Logger
+	now          func() time.Time
+
+	wake       chan struct{}
+	mu         sync.Mutex // 同一行程內同時只有一個發送工作
+	lastPruned time.Time
+}
+
+func NewOutbox(notifiers Notifiers, userRepo repository.UserRepository, featureRepo repository.FeatureRepository, eventRepo repository.NotificationEventRepository, deliveryRepo repository.NotificationDeliveryRepository, imageRepo repository.NotificationImageRepository, preferences user_preference.UserPreferenceService, log logger.Logger) Outbox {
+	return &outbox{
+		notifiers:    notifiers,
+		userRepo:     userRepo,
+		featureRepo:  featureRepo,
+		eventRepo:    eventRepo,
+		deliveryRepo: deliveryRepo,
+		imageRepo:    imageRepo,
+		preferences:  preferences,
+		logger:       log,
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Enqueue 建立通知事件及投遞紀錄，已停用的使用者不建立
+func (o *outbox) Enqueue(item models.SubscriptionItem, userIDs []uint, response *command.Response) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	var featureID *uint
+	if item != models.SubscriptionItemDefault {
+		feature, err := o.featureRepo.GetByCode(fmt.Sprintf("%d", int(item)))
+		if err != nil {
+			return fmt.Errorf("取得功能失敗: %w", err)
+		}
+		featureID = &feature.ID
+	}
+
+	now := o.now()
+	events := make([]*models.NotificationEvent, 0, len(userIDs))
+	for _, userID := range userIDs {
+		user, err := o.userRepo.GetByID(userID)
+		if err != nil || user == nil {
+			o.logger.Error("取得使用者失敗", zap.Uint("userID", userID), zap.Error(err))
+			continue
+		}
+		// 已封鎖或停用的使用者不再推播
+		if !user.Status {
+			continue
+		}
+		nextAttemptAt := now
+		if until, quiet := o.preferences.QuietUntil(userID, now); quiet {
+			nextAttemptAt = until
+		}
+		events = append(events, &models.NotificationEvent{
+			UserID:     userID,
+			FeatureID:  featureID,
+			OccurredAt: now,
+			Deliveries: []*models.NotificationDelivery{{
+				ChannelID:     uint(user.UserType),
+				Status:        models.NotificationDeliveryStatusQueued,
+				Response:      outboxEmptyJSON,
+				NextAttemptAt: nextAttemptAt,
+			}},
+		})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	payload, err := o.encodePayload(response)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		event.Payload = payload
+	}
+
+	// 事件與投遞紀錄於同一交易中建立
+	if err := o.eventRepo.BatchCreate(events); err != nil {
+		return fmt.Errorf("建立通知事件失敗: %w", err)
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start 啟動背景發送工作，定期輪詢並於新增通知時立即發送
+func (o *outbox) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(outboxPollInterval)
+		defer ticker.Stop()
+
+		o.Process()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-o.wake:
+			}
+			o.Process()
+		}
+	}()
+}
+
+// Process 發送已到期的待發送及可重試的通知
+func (o *outbox) Process() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	o.recoverStale(now)
+	o.pruneImages(now)
+
+	// 無法取得發送權或延後（例如資料庫錯誤）的投遞紀錄仍留在佇列，同一輪不再處理以免重複查詢到相同紀錄
+	tried := make(map[uint]bool)
+	for {
+		queued, err := o.deliveryRepo.GetQueuedDeliveries(now, outboxBatchSize)
+		if err != nil {
+			o.logger.Error("取得待發送通知失敗", zap.Error(err))
+			return
+		}
+		failed, err := o.deliveryRepo.GetFailedDeliveries(outboxMaxAttempts, now, outboxBatchSize)
+		if err != nil {
+			o.logger.Error("取得待重試通知失敗", zap.Error(err))
+			return
+		}
+
+		processed := 0
+		for _, delivery := range append(queued, failed...) {
+			if tried[delivery.ID] {
+				continue
+			}
+			tried[delivery.ID] = true
+			o.deliver(delivery)
+			processed++
+		}
+		if processed == 0 {
+			return
+		}
+		// 未滿一批表示已處理完畢，避免重複查詢
+		if len(queued) < outboxBatchSize && len(failed) < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// recoverStale 發送工作中斷時無法確認通知是否已送達，標記為無法確認而不重送，避免重複發送
+func (o *outbox) recoverStale(now time.Time) {
+	response, _ := json.Marshal(map[string]string{"error": "發送中斷，無法確認是否已送達"})
+	count, err := o.deliveryRepo.MarkStaleSending(now.Add(-outboxSendingLease), string(response))
+	if err != nil {
+		o.logger.Error("處理中斷的通知失敗", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		o.logger.Warn("發送中斷的通知無法確認是否已送達，已標記為待確認", zap.Int64("數量", count))
+	}
+}
+
+// pruneImages 每日清除已超過保留時間的通知圖片
+func (o *outbox) pruneImages(now time.Time) {
+	if now.Sub(o.lastPruned) < outboxPruneInterval {
+		return
+	}
+	o.lastPruned = now
+	count, err := o.imageRepo.DeleteBefore(now.Add(-outboxImageRetention))
+	if err != nil {
+		o.logger.Error("清除通知圖片失敗", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		o.logger.Info("已清除過期的通知圖片", zap.Int64("數量", count))
+	}
+}
+
+// deliver 取得投遞紀錄後發送，失敗時依嘗試次數延後重試
+func (o *outbox) deliver(delivery *models.NotificationDelivery) {
+	// 排入佇列後才設定的勿擾時段或重試時間落在勿擾時段內，延後至勿擾結束
+	if delivery.Event != nil {
+		if until, quiet := o.preferences.QuietUntil(delivery.Event.UserID, o.now()); quiet {
+			if err := o.deliveryRepo.Defer(delivery.ID, delivery.Status, until); err != nil {
+				o.logger.Error("延後通知發送失敗", zap.Uint("deliveryID", delivery.ID), zap.Error(err))
+			}
+			return
+		}
+	}
+
+	// 先將狀態改為發送中，其他發送工作已取得時略過
+	claimed, err := o.deliveryRepo.Claim(delivery.ID, delivery.Status)
+	if err != nil {
+		o.logger.Error("取得通知發送權失敗", zap.Uint("deliveryID", delivery.ID), zap.Error(err))
+		return
+	}
+	if !claimed {
+		return
+	}
+	delivery.Attempts++
+
+	sendErr := o.send(delivery)
+	now := o.now()
+	result := map[string]any{"attempts": delivery.Attempts}
+	switch {
+	case sendErr == nil:
+		delivery.Status = models.NotificationDeliveryStatusSent
+		delivery.SentAt = now
+	case errors.Is(sendErr, errUserInactive), errors.Is(sendErr, ErrRecipientBlocked):
+		delivery.Status = models.NotificationDeliveryStatusFailed
+		result["error"] = sendErr.Error()
+		delivery.Attempts = outboxMaxAttempts
+		result["attempts"] = delivery.Attempts
+	case delivery.Attempts >= outboxMaxAttempts:
+		delivery.Status = models.NotificationDeliveryStatusFailed
+		result["error"] = sendErr.Error()
+		o.logger.Error("發送通知失敗，已達重試上限", zap.Uint("deliveryID", delivery.ID), zap.Error(sendErr))
+	default:
+		delivery.Status = models.NotificationDeliveryStatusFailed
+		delivery.NextAttemptAt = now.Add(outboxBackoff(delivery.Attempts))
+		result["error"] = sendErr.Error()
+		o.logger.Warn("發送通知失敗，稍後重試", zap.Uint("deliveryID", delivery.ID), zap.Int("attempts", delivery.Attempts), zap.Error(sendErr))
+	}
+
+	response, _ := json.Marshal(result)
+	delivery.Response = string(response)
+	if err := o.deliveryRepo.UpdateResult(delivery); err != nil {
+		o.logger.Error("更新通知發送結果失敗", zap.Uint("deliveryID", delivery.ID), zap.Error(err))
+	}
+}
+
+// send 依投遞紀錄發送通知
+func (o *outbox) send(delivery *models.NotificationDelivery) error {
+	if delivery.Event == nil {
+		return fmt.Errorf("通知事件不存在")
+	}
+
+	response, err := o.decodePayload(delivery.Event.Payload)
+	if err != nil {
+		return err
+	}
+
+	user, err := o.userRepo.GetByID(delivery.Event.UserID)
+	if err != nil {
+		return fmt.Errorf("取得使用者失敗: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("使用者資料為空")
+	}
+	if !user.Status {
+		return errUserInactive
+	}
+
+	delivery.SentParts, err = o.notifiers.Notify(user, response, delivery.SentParts)
+	if errors.Is(err, ErrRecipientBlocked) {
+		o.deactivate(user)
+	}
+	return err
+}
+
+// encodePayload 將回應轉為通知事件內容，圖片存入 notification_images，相同圖片只存一份
+func (o *outbox) encodePayload(response *command.Response) (string, error) {
+	payload := outboxPayload{Response: response}
+	if response.Image != nil && len(response.Image.Data) > 0 {
+		sum := sha256.Sum256(response.Image.Data)
+		payload.ImageHash = hex.EncodeToString(sum[:])
+		if err := o.imageRepo.Save(&models.NotificationImage{
+			Hash: payload.ImageHash,
+			Name: response.Image.Name,
+			Data: response.Image.Data,
+		}); err != nil {
+			return "", fmt.Errorf("儲存通知圖片失敗: %w", err)
+		}
+		withoutImage := *response
+		withoutImage.Image = nil
+		payload.Response = &withoutImage
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("轉換通知內容失敗: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodePayload 還原通知事件內容，圖片已被清除時只發送文字
+// 舊版事件內容為完整的回應，直接解析
+func (o *outbox) decodePayload(data string) (*command.Response, error) {
+	var payload outboxPayload
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return nil, fmt.Errorf("解析通知內容失敗: %w", err)
+	}
+	if payload.Response == nil {
+		var response command.Response
+		if err := json.Unmarshal([]byte(data), &response); err != nil {
+			return nil, fmt.Errorf("解析通知內容失敗: %w", err)
+		}
+		return &response, nil
+	}
+	if payload.ImageHash == "" {
+		return payload.Response, nil
+	}
+
+	image, err := o.imageRepo.GetByHash(payload.ImageHash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		o.logger.Warn("通知圖片已不存在，只發送文字", zap.String("hash", payload.ImageHash))
+		return payload.Response, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("取得通知圖片失敗: %w", err)
+	}
+	payload.Response.Image = &command.Image{Data: image.Data, Name: image.Name}
+	return payload.Response, nil
+}
+
+// deactivate 使用者封鎖機器人時標記為停用，之後不再推播
+func (o *outbox) deactivate(user *models.User) {
+	user.Status = false
+	if err := o.userRepo.Update(user); err != nil {
+		o.logger.Error("停用使用者失敗", zap.Uint("userID", user.ID), zap.Error(err))
+		return
+	}
+	o.logger.Info("使用者已封鎖機器人，標記為停用", zap.Uint("userID", user.ID))
+}
+
+// outboxBackoff 第 attempts 次失敗後的重試等待時間
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,453 @@
+package notification
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/internal/service/user_preference"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...zap.Field)  {}
+func (nopLogger) Error(string, ...zap.Field) {}
+func (nopLogger) Warn(string, ...zap.Field)  {}
+func (nopLogger) Debug(string, ...zap.Field) {}
+func (nopLogger) Panic(string, ...zap.Field) {}
+func (nopLogger) Fatal(string, ...zap.Field) {}
+func (nopLogger) Sync() error                { return nil }
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[uint]*models.User
+}
+
+func (f *fakeUserRepo) GetByID(id uint) (*models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUserRepo) Update(user *models.User) error {
+	f.users[user.ID] = user
+	return nil
+}
+
+type fakeEventRepo struct {
+	repository.NotificationEventRepository
+	events []*models.NotificationEvent
+}
+
+func (f *fakeEventRepo) BatchCreate(events []*models.NotificationEvent) error {
+	f.events = append(f.events, events...)
+	return nil
+}
+
+type fakeImageRepo struct {
+	images map[string]*models.NotificationImage
+	saves  int
+}
+
+func (f *fakeImageRepo) Save(image *models.NotificationImage) error {
+	f.saves++
+	f.images[image.Hash] = image
+	return nil
+}
+
+func (f *fakeImageRepo) GetByHash(hash string) (*models.NotificationImage, error) {
+	image, ok := f.images[hash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return image, nil
+}
+
+func (f *fakeImageRepo) DeleteBefore(time.Time) (int64, error) { return 0, nil }
+
+// fakeDeliveryRepo 以記憶體模擬投遞紀錄的狀態轉換
+type fakeDeliveryRepo struct {
+	repository.NotificationDeliveryRepository
+	deliveries   map[uint]*models.NotificationDelivery
+	staleBefore  time.Time
+	claimErr     error
+	deferredTill map[uint]time.Time
+}
+
+func (f *fakeDeliveryRepo) Claim(id uint, status models.NotificationDeliveryStatus) (bool, error) {
+	if f.claimErr != nil {
+		return false, f.claimErr
+	}
+	delivery := f.deliveries[id]
+	if delivery.Status != status {
+		return false, nil
+	}
+	delivery.Status = models.NotificationDeliveryStatusSending
+	delivery.Attempts++
+	return true, nil
+}
+
+func (f *fakeDeliveryRepo) UpdateResult(delivery *models.NotificationDelivery) error {
+	stored := f.deliveries[delivery.ID]
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.SentParts = delivery.SentParts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.Response = delivery.Response
+	return nil
+}
+
+func (f *fakeDeliveryRepo) Defer(id uint, status models.NotificationDeliveryStatus, until time.Time) error {
+	if f.deferredTill == nil {
+		f.deferredTill = make(map[uint]time.Time)
+	}
+	f.deferredTill[id] = until
+	return nil
+}
+
+func (f *fakeDeliveryRepo) MarkStaleSending(before time.Time, response string) (int64, error) {
+	f.staleBefore = before
+	var count int64
+	for _, delivery := range f.deliveries {
+		if delivery.Status == models.NotificationDeliveryStatusSending {
+			delivery.Status = models.NotificationDeliveryStatusUnknown
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeDeliveryRepo) GetQueuedDeliveries(before time.Time, limit int) ([]*models.NotificationDelivery, error) {
+	return f.due(func(d *models.NotificationDelivery) bool {
+		return d.Status == models.NotificationDeliveryStatusQueued && !d.NextAttemptAt.After(before)
+	}, limit), nil
+}
+
+func (f *fakeDeliveryRepo) GetFailedDeliveries(maxAttempts int, before time.Time, limit int) ([]*models.NotificationDelivery, error) {
+	return f.due(func(d *models.NotificationDelivery) bool {
+		return d.Status == models.NotificationDeliveryStatusFailed && d.Attempts < maxAttempts && !d.NextAttemptAt.After(before)
+	}, limit), nil
+}
+
+// due 依 ID 順序回傳符合條件的投遞紀錄副本
+func (f *fakeDeliveryRepo) due(match func(*models.NotificationDelivery) bool, limit int) []*models.NotificationDelivery {
+	ids := make([]uint, 0, len(f.deliveries))
+	for id := range f.deliveries {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var deliveries []*models.NotificationDelivery
+	for _, id := range ids {
+		if delivery := f.deliveries[id]; match(delivery) && len(deliveries) < limit {
+			copied := *delivery
+			deliveries = append(deliveries, &copied)
+		}
+	}
+	return deliveries
+}
+
+type fakePreferences struct {
+	user_preference.UserPreferenceService
+	quietUntil map[uint]time.Time
+}
+
+func (f *fakePreferences) QuietUntil(userID uint, now time.Time) (time.Time, bool) {
+	until, ok := f.quietUntil[userID]
+	return until, ok && now.Before(until)
+}
+
+// fakeNotifier 每則通知分為 parts 則訊息，failAfter 大於 0 時送出該則數後失敗一次
+type fakeNotifier struct {
+	err       error
+	parts     int
+	failAfter int
+	resumed   []int
+	responses []*command.Response
+}
+
+func (f *fakeNotifier) Notify(accountID string, response *command.Response, sent int) (int, error) {
+	f.responses = append(f.responses, response)
+	f.resumed = append(f.resumed, sent)
+	if f.err != nil {
+		return sent, f.err
+	}
+	if f.failAfter > sent {
+		sent, f.failAfter = f.failAfter, 0
+		return sent, errors.New("timeout")
+	}
+	return max(f.parts, 1), nil
+}
+
+type outboxFixture struct {
+	outbox     *outbox
+	notifier   *fakeNotifier
+	events     *fakeEventRepo
+	deliveries *fakeDeliveryRepo
+	images     *fakeImageRepo
+	now        time.Time
+}
+
+func newOutboxFixture() *outboxFixture {
+	f := &outboxFixture{
+		notifier:   &fakeNotifier{},
+		events:     &fakeEventRepo{},
+		deliveries: &fakeDeliveryRepo{deliveries: make(map[uint]*models.NotificationDelivery)},
+		images:     &fakeImageRepo{images: make(map[string]*models.NotificationImage)},
+		now:        time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC),
+	}
+	users := &fakeUserRepo{users: map[uint]*models.User{
+		1: {Model: models.Model{ID: 1}, AccountID: "1001", UserType: models.UserTypeTelegram, Status: true},
+		2: {Model: models.Model{ID: 2}, AccountID: "1002", UserType: models.UserTypeTelegram, Status: true},
+	}}
+	preferences := &fakePreferences{quietUntil: map[uint]time.Time{2: f.now.Add(8 * time.Hour)}}
+	f.outbox = NewOutbox(Notifiers{models.UserTypeTelegram: f.notifier}, users, nil, f.events, f.deliveries, f.images, preferences, nopLogger{}).(*outbox)
+	f.outbox.now = func() time.Time { return f.now }
+	return f
+}
+
+// enqueue 建立通知並以事件順序編號投遞紀錄
+func (f *outboxFixture) enqueue(t *testing.T, response *command.Response, userIDs ...uint) []*models.NotificationDelivery {
+	t.Helper()
+	start := len(f.events.events)
+	if err := f.outbox.Enqueue(models.SubscriptionItemDefault, userIDs, response); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	var deliveries []*models.NotificationDelivery
+	for i, event := range f.events.events[start:] {
+		delivery := event.Deliveries[0]
+		delivery.ID = uint(start + i + 1)
+		delivery.Event = event
+		stored := *delivery
+		f.deliveries.deliveries[delivery.ID] = &stored
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
+
+// load 模擬發送工作由資料庫讀出投遞紀錄
+func (f *outboxFixture) load(id uint) *models.NotificationDelivery {
+	delivery := *f.deliveries.deliveries[id]
+	return &delivery
+}
+
+func TestOutboxStoresImageOnce(t *testing.T) {
+	f := newOutboxFixture()
+	image := []byte("png-bytes")
+	deliveries := f.enqueue(t, &command.Response{Title: "圖表", Image: &command.Image{Data: image, Name: "chart.png"}}, 1, 2)
+
+	if f.images.saves != 1 || len(f.images.images) != 1 {
+		t.Fatalf("image saves = %d, stored = %d, want 1 and 1", f.images.saves, len(f.images.images))
+	}
+	for _, event := range f.events.events {
+		if strings.Contains(event.Payload, "cG5nLWJ5dGVz") { // base64("png-bytes")
+			t.Fatalf("payload should not contain image data: %s", event.Payload)
+		}
+	}
+
+	f.outbox.deliver(deliveries[0])
+	if len(f.notifier.responses) != 1 {
+		t.Fatalf("notifications = %d, want 1", len(f.notifier.responses))
+	}
+	got := f.notifier.responses[0]
+	if got.Title != "圖表" || got.Image == nil || string(got.Image.Data) != string(image) || got.Image.Name != "chart.png" {
+		t.Errorf("delivered response = %+v, want title and image restored", got)
+	}
+}
+
+func TestOutboxSendsTextWhenImagePruned(t *testing.T) {
+	f := newOutboxFixture()
+	deliveries := f.enqueue(t, &command.Response{Title: "圖表", Image: &command.Image{Data: []byte("png"), Name: "chart.png"}}, 1)
+	f.images.images = make(map[string]*models.NotificationImage)
+
+	f.outbox.deliver(deliveries[0])
+	if len(f.notifier.responses) != 1 || f.notifier.responses[0].Image != nil {
+		t.Fatalf("responses = %+v, want one text-only notification", f.notifier.responses)
+	}
+}
+
+func TestOutboxDecodesLegacyPayload(t *testing.T) {
+	f := newOutboxFixture()
+	response, err := f.outbox.decodePayload(`{"Title":"舊通知","Blocks":null,"Image":null,"Buttons":null,"Replace":false}`)
+	if err != nil || response.Title != "舊通知" {
+		t.Fatalf("decodePayload() = (%+v, %v), want legacy title", response, err)
+	}
+}
+
+func TestOutboxSkipsDeliveryClaimedElsewhere(t *testing.T) {
+	f := newOutboxFixture()
+	delivery := f.enqueue(t, &command.Response{Title: "通知"}, 1)[0]
+	// 其他發送工作已取得
+	f.deliveries.deliveries[delivery.ID].Status = models.NotificationDeliveryStatusSending
+	stale := *delivery
+	stale.Status = models.NotificationDeliveryStatusQueued
+
+	f.outbox.deliver(&stale)
+	if len(f.notifier.responses) != 0 {
+		t.Fatalf("notifications = %d, want 0 when claim fails", len(f.notifier.responses))
+	}
+	if got := f.deliveries.deliveries[delivery.ID]; got.Status != models.NotificationDeliveryStatusSending || got.Attempts != 0 {
+		t.Errorf("delivery = %s/%d, want untouched sending/0", got.Status, got.Attempts)
+	}
+}
+
+func TestOutboxRetryBackoff(t *testing.T) {
+	f := newOutboxFixture()
+	f.notifier.err = errors.New("timeout")
+	id := f.enqueue(t, &command.Response{Title: "通知"}, 1)[0].ID
+	delivery := f.deliveries.deliveries[id]
+
+	for attempt := 1; attempt <= outboxMaxAttempts; attempt++ {
+		f.outbox.deliver(f.load(id))
+		if delivery.Attempts != attempt || delivery.Status != models.NotificationDeliveryStatusFailed {
+			t.Fatalf("attempt %d: delivery = %s/%d", attempt, delivery.Status, delivery.Attempts)
+		}
+		if attempt < outboxMaxAttempts {
+			if want := f.now.Add(outboxBackoff(attempt)); !delivery.NextAttemptAt.Equal(want) {
+				t.Errorf("attempt %d: next attempt = %v, want %v", attempt, delivery.NextAttemptAt, want)
+			}
+		}
+	}
+
+	f.notifier.err = nil
+	f.outbox.deliver(f.load(id))
+	if delivery.Status != models.NotificationDeliveryStatusSent {
+		t.Errorf("status after recovery = %s, want sent", delivery.Status)
+	}
+}
+
+func TestOutboxResumesPartialPush(t *testing.T) {
+	f := newOutboxFixture()
+	f.notifier.parts, f.notifier.failAfter = 3, 2
+	id := f.enqueue(t, &command.Response{Title: "通知"}, 1)[0].ID
+
+	f.outbox.deliver(f.load(id))
+	if delivery := f.deliveries.deliveries[id]; delivery.Status != models.NotificationDeliveryStatusFailed || delivery.SentParts != 2 {
+		t.Fatalf("delivery = %s/%d parts, want failed/2", delivery.Status, delivery.SentParts)
+	}
+
+	// 重試時由未送出的訊息繼續，已送出的訊息不重複發送
+	f.outbox.deliver(f.load(id))
+	if delivery := f.deliveries.deliveries[id]; delivery.Status != models.NotificationDeliveryStatusSent || delivery.SentParts != 3 {
+		t.Errorf("delivery = %s/%d parts, want sent/3", delivery.Status, delivery.SentParts)
+	}
+	if len(f.notifier.resumed) != 2 || f.notifier.resumed[1] != 2 {
+		t.Errorf("resumed from = %v, want [0 2]", f.notifier.resumed)
+	}
+}
+
+func TestOutboxBlockedRecipientNotRetried(t *testing.T) {
+	f := newOutboxFixture()
+	f.notifier.err = ErrRecipientBlocked
+	id := f.enqueue(t, &command.Response{Title: "通知"}, 1)[0].ID
+
+	f.outbox.deliver(f.load(id))
+	if delivery := f.deliveries.deliveries[id]; delivery.Status != models.NotificationDeliveryStatusFailed || delivery.Attempts != outboxMaxAttempts {
+		t.Errorf("delivery = %s/%d, want failed/%d", delivery.Status, delivery.Attempts, outboxMaxAttempts)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestOutboxDoesNotResendStaleSending(t *testing.T) {
+	f := newOutboxFixture()
+	id := f.enqueue(t, &command.Response{Title: "通知"}, 1)[0].ID
+	// 發送工作於送出後、更新結果前中斷
+	f.deliveries.deliveries[id].Status = models.NotificationDeliveryStatusSending
+	f.outbox.Process()
+
+	if want := f.now.Add(-outboxSendingLease); !f.deliveries.staleBefore.Equal(want) {
+		t.Errorf("stale before = %v, want %v", f.deliveries.staleBefore, want)
+	}
+	if got := f.deliveries.deliveries[id].Status; got != models.NotificationDeliveryStatusUnknown {
+		t.Errorf("status = %s, want unknown", got)
+	}
+	f.outbox.Process()
+	if len(f.notifier.responses) != 0 {
+		t.Errorf("notifications = %d, want 0 for a delivery that may already be sent", len(f.notifier.responses))
+	}
+}
+
+func TestOutboxProcessStopsOnStuckBatch(t *testing.T) {
+	f := newOutboxFixture()
+	userIDs := make([]uint, outboxBatchSize)
+	for i := range userIDs {
+		userIDs[i] = 1
+	}
+	f.enqueue(t, &command.Response{Title: "通知"}, userIDs...)
+	f.deliveries.claimErr = errors.New("connection reset")
+
+	done := make(chan struct{})
+	go func() {
+		f.outbox.Process()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Process() did not return while deliveries could not be claimed")
+	}
+
+	// 資料庫恢復後下一輪照常發送
+	f.deliveries.claimErr = nil
+	f.outbox.Process()
+	if len(f.notifier.responses) != outboxBatchSize {
+		t.Errorf("notifications = %d, want %d", len(f.notifier.responses), outboxBatchSize)
+	}
+}
+
+func TestOutboxQuietHours(t *testing.T) {
+	f := newOutboxFixture()
+	deliveries := f.enqueue(t, &command.Response{Title: "通知"}, 1, 2)
+	quietUntil := f.now.Add(8 * time.Hour)
+
+	// 排入佇列時已在勿擾時段，發送時間延後至勿擾結束
+	if !deliveries[0].NextAttemptAt.Equal(f.now) {
+		t.Errorf("user 1 next attempt = %v, want %v", deliveries[0].NextAttemptAt, f.now)
+	}
+	if !deliveries[1].NextAttemptAt.Equal(quietUntil) {
+		t.Errorf("user 2 next attempt = %v, want %v", deliveries[1].NextAttemptAt, quietUntil)
+	}
+
+	// 發送時仍在勿擾時段（例如排入後才設定），延後而不發送
+	f.outbox.deliver(deliveries[1])
+	if len(f.notifier.responses) != 0 {
+		t.Fatalf("notifications = %d, want 0 during quiet hours", len(f.notifier.responses))
+	}
+	if got := f.deliveries.deferredTill[deliveries[1].ID]; !got.Equal(quietUntil) {
+		t.Errorf("deferred until = %v, want %v", got, quietUntil)
+	}
+
+	f.now = quietUntil
+	f.outbox.deliver(deliveries[1])
+	if len(f.notifier.responses) != 1 {
+		t.Errorf("notifications after quiet hours = %d, want 1", len(f.notifier.responses))
+	}
+}
@@ -2,187 +2,475 @@
 package notification
 
 import (
-	"strconv"
-	"time"
-
-	tgbotInfra "github.com/tian841224/stock-bot/internal/infrastructure/tgbot"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
 	"github.com/tian841224/stock-bot/internal/repository"
-	tgbot "github.com/tian841224/stock-bot/internal/service/bot/tg"
+	"github.com/tian841224/stock-bot/internal/service/bot/command"
+	"github.com/tian841224/stock-bot/internal/service/exchange_rate_alert"
+	"github.com/tian841224/stock-bot/internal/service/stock_news"
+	"github.com/tian841224/stock-bot/internal/service/user_preference"
 	"github.com/tian841224/stock-bot/pkg/logger"
 	"go.uber.org/zap"
 )
 
 // SchedulerJobService 排程任務服務介面
+// 訂閱項目通知皆傳入本次排程到期的使用者，由排程管理依各訂閱的推播時間決定
+// 各通知回傳已建立通知的使用者數，部分失敗時仍回傳已建立的數量及錯誤
+// ctx 取消時停止處理剩餘的股票或使用者，已建立的通知不受影響
 type SchedulerJobService interface {
-	NotificationStockPrice()
-	NotificationStockNews()
-	NotificationDailyMarketInfo()
-	NotificationTopVolumeItems()
+	NotifyScheduled(ctx context.Context, due map[models.SubscriptionItem][]uint) (int, error)
+	NotifyScheduledItem(ctx context.Context, item models.SubscriptionItem, userIDs []uint) (int, error)
+	NotifyScheduledDigest(ctx context.Context, due map[models.SubscriptionItem][]uint) (int, error)
+	NotifyItemSubscribers(ctx context.Context, item models.SubscriptionItem) (int, error)
+	NotifySubscribers(ctx context.Context, item models.SubscriptionItem, userIDs []uint) (int, error)
+	NotificationDigest(ctx context.Context, userItems map[uint][]models.SubscriptionItem, preferences map[uint]*models.UserPreference) (int, error)
+	NotificationStockPrice(ctx context.Context, userIDs []uint) (int, error)
+	NotificationStockNews(ctx context.Context, userIDs []uint) (int, error)
+	NotificationDailyMarketInfo(userIDs []uint) (int, error)
+	NotificationTopVolumeItems(userIDs []uint) (int, error)
+	NotificationExchangeRateAlerts(ctx context.Context) (int, error)
+	NotificationNewsAlerts(ctx context.Context) (int, error)
+	NotificationTreasuryYield(userIDs []uint) (int, error)
 }
 
 type schedulerJobService struct {
-	tgService              tgbot.TgService
-	tgClient               *tgbotInfra.TgBotClient
-	userRepo               repository.UserRepository
+	registry               command.CommandRegistry
+	outbox                 Outbox
+	userPreferenceSvc      user_preference.UserPreferenceService
 	subscriptionRepo       repository.SubscriptionRepository
 	subscriptionSymbolRepo repository.SubscriptionSymbolRepository
+	exchangeRateAlertSvc   exchange_rate_alert.ExchangeRateAlertService
+	stockNewsSvc           stock_news.StockNewsService
 	logger                 logger.Logger
 }
 
-func NewSchedulerJobService(tgService tgbot.TgService, tgClient *tgbotInfra.TgBotClient, userRepo repository.UserRepository, subscriptionRepo repository.SubscriptionRepository, subscriptionSymbolRepo repository.SubscriptionSymbolRepository, log logger.Logger) SchedulerJobService {
+func NewSchedulerJobService(registry command.CommandRegistry, outbox Outbox, userPreferenceSvc user_preference.UserPreferenceService, subscriptionRepo repository.SubscriptionRepository, subscriptionSymbolRepo repository.SubscriptionSymbolRepository, exchangeRateAlertSvc exchange_rate_alert.ExchangeRateAlertService, stockNewsSvc stock_news.StockNewsService, log logger.Logger) SchedulerJobService {
 	return &schedulerJobService{
-		tgService:              tgService,
-		tgClient:               tgClient,
-		userRepo:               userRepo,
+		registry:               registry,
+		outbox:                 outbox,
+		userPreferenceSvc:      userPreferenceSvc,
 		subscriptionRepo:       subscriptionRepo,
 		subscriptionSymbolRepo: subscriptionSymbolRepo,
+		exchangeRateAlertSvc:   exchangeRateAlertSvc,
+		stockNewsSvc:           stockNewsSvc,
 		logger:                 log,
 	}
 }
 
+// NotifyScheduled 推播排程到期的訂閱，摘要模式的使用者合併為一則訊息，其餘依項目分別推播
+func (s *schedulerJobService) NotifyScheduled(ctx context.Context, due map[models.SubscriptionItem][]uint) (int, error) {
+	individual, digestItems, preferences := s.splitByDigestMode(due)
+
+	total := 0
+	var errs []error
+	for item, userIDs := range individual {
+		if ctx.Err() != nil {
+			return total, errors.Join(append(errs, ctx.Err())...)
+		}
+		count, err := s.NotifySubscribers(ctx, item, userIDs)
+		total += count
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", item.GetName(), err))
+		}
+	}
+	if len(digestItems) > 0 {
+		count, err := s.NotificationDigest(ctx, digestItems, preferences)
+		total += count
+		if err != nil {
+			errs = append(errs, fmt.Errorf("摘要: %w", err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// NotifyScheduledItem 推播單一訂閱項目給未開啟摘要模式的使用者，摘要模式的使用者由 NotifyScheduledDigest 推播
+func (s *schedulerJobService) NotifyScheduledItem(ctx context.Context, item models.SubscriptionItem, userIDs []uint) (int, error) {
+	individual, _, _ := s.splitByDigestMode(map[models.SubscriptionItem][]uint{item: userIDs})
+	return s.NotifySubscribers(ctx, item, individual[item])
+}
+
+// NotifyScheduledDigest 將到期的訂閱項目合併為摘要推播給摘要模式的使用者，其餘使用者由 NotifyScheduledItem 推播
+func (s *schedulerJobService) NotifyScheduledDigest(ctx context.Context, due map[models.SubscriptionItem][]uint) (int, error) {
+	_, digestItems, preferences := s.splitByDigestMode(due)
+	if len(digestItems) == 0 {
+		return 0, nil
+	}
+	return s.NotificationDigest(ctx, digestItems, preferences)
+}
+
+// NotifyItemSubscribers 推播訂閱項目給所有訂閱者，不限推播時間，用於上游資料異常後手動重新推播
+func (s *schedulerJobService) NotifyItemSubscribers(ctx context.Context, item models.SubscriptionItem) (int, error) {
+	subscriptions, err := s.subscriptionRepo.GetActiveByItem(item)
+	if err != nil {
+		return 0, fmt.Errorf("取得訂閱清單失敗: %w", err)
+	}
+	userIDs := make([]uint, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		userIDs = append(userIDs, subscription.UserID)
+	}
+	return s.NotifyScheduled(ctx, map[models.SubscriptionItem][]uint{item: userIDs})
+}
+
+// splitByDigestMode 依使用者的摘要模式分為依項目推播的使用者及合併為摘要的項目，並回傳各使用者的偏好設定
+func (s *schedulerJobService) splitByDigestMode(due map[models.SubscriptionItem][]uint) (map[models.SubscriptionItem][]uint, map[uint][]models.SubscriptionItem, map[uint]*models.UserPreference) {
+	preferences := make(map[uint]*models.UserPreference)
+	digestItems := make(map[uint][]models.SubscriptionItem)
+	individual := make(map[models.SubscriptionItem][]uint)
+	for item, userIDs := range due {
+		for _, userID := range userIDs {
+			preference, ok := preferences[userID]
+			if !ok {
+				var err error
+				// 取得偏好設定失敗時改為分別推播
+				if preference, err = s.userPreferenceSvc.GetUserPreference(userID); err != nil {
+					preference = models.DefaultUserPreference(userID)
+				}
+				preferences[userID] = preference
+			}
+			if preference.DigestMode == models.DigestModeOff {
+				individual[item] = append(individual[item], userID)
+			} else {
+				digestItems[userID] = append(digestItems[userID], item)
+			}
+		}
+	}
+	return individual, digestItems, preferences
+}
+
+// NotificationDigest 將每位使用者到期的訂閱項目合併為一則摘要
+func (s *schedulerJobService) NotificationDigest(ctx context.Context, userItems map[uint][]models.SubscriptionItem, preferences map[uint]*models.UserPreference) (int, error) {
+	userIDs := make([]uint, 0, len(userItems))
+	for userID := range userItems {
+		userIDs = append(userIDs, userID)
+	}
+
+	// 將按 symbol 分組的訂閱者轉為每位使用者的股票清單
+	userSymbols := make(map[uint][]string)
+	newsSubscribers := make(map[string][]uint)
+	symbolSubscriptions, err := s.getSymbolSubscriptions(userIDs)
+	if err != nil {
+		return 0, err
+	}
+	for symbol, subscribers := range symbolSubscriptions {
+		for _, userID := range subscribers {
+			userSymbols[userID] = append(userSymbols[userID], symbol)
+			if slices.Contains(userItems[userID], models.SubscriptionItemStockNews) {
+				newsSubscribers[symbol] = append(newsSubscribers[symbol], userID)
+			}
+		}
+	}
+
+	// 自選股新聞只列出各使用者尚未推播過的新聞，同一股票只查詢一次
+	userNews := make(map[uint]map[string][]dto.TaiwanNewsResponseData)
+	for symbol, subscribers := range newsSubscribers {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		unseen, err := s.stockNewsSvc.UnseenNews(symbol, subscribers)
+		if err != nil {
+			s.logger.Error("取得股票新聞失敗", zap.String("symbol", symbol), zap.Error(err))
+			continue
+		}
+		for userID, news := range unseen {
+			if userNews[userID] == nil {
+				userNews[userID] = make(map[string][]dto.TaiwanNewsResponseData)
+			}
+			userNews[userID][symbol] = command.LimitDigestNews(news)
+		}
+	}
+
+	// 同一批次共用查詢結果，相同股票只查詢一次
+	builder := s.registry.NewDigestBuilder()
+	sent, failed := 0, 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		symbols := userSymbols[userID]
+		sort.Strings(symbols)
+		response, rendered := builder.Build(userItems[userID], symbols, userNews[userID], preferences[userID])
+		if response == nil {
+			continue
+		}
+		if !s.sendNotificationToSubscribers(models.SubscriptionItemDefault, response, []uint{userID}) {
+			failed++
+			continue
+		}
+		// 摘要排入佇列後才記錄已推播，避免建立通知失敗時遺漏新聞，且只記錄實際列入摘要的新聞
+		for symbol, news := range rendered {
+			if err := s.stockNewsSvc.MarkSeen(symbol, []uint{userID}, news); err != nil {
+				s.logger.Error("記錄已推播新聞失敗", zap.String("symbol", symbol), zap.Error(err))
+			}
+		}
+		sent++
+	}
+
+	s.logger.Info("摘要通知完成", zap.Int("訂閱數量", sent))
+	return sent, errors.Join(failedError(failed, "則摘要建立通知失敗"), ctx.Err())
+}
+
+// NotifySubscribers 依訂閱項目通知指定使用者
+func (s *schedulerJobService) NotifySubscribers(ctx context.Context, item models.SubscriptionItem, userIDs []uint) (int, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	switch item {
+	case models.SubscriptionItemStockInfo:
+		return s.NotificationStockPrice(ctx, userIDs)
+	case models.SubscriptionItemStockNews:
+		return s.NotificationStockNews(ctx, userIDs)
+	case models.SubscriptionItemDailyMarketInfo:
+		return s.NotificationDailyMarketInfo(userIDs)
+	case models.SubscriptionItemTopVolumeItems:
+		return s.NotificationTopVolumeItems(userIDs)
+	case models.SubscriptionItemTreasuryYield:
+		return s.NotificationTreasuryYield(userIDs)
+	default:
+		s.logger.Warn("未支援的訂閱項目", zap.Int("item", int(item)))
+		return 0, nil
+	}
+}
+
 // NotificationStockPrice 通知當日股價資訊
-func (s *schedulerJobService) NotificationStockPrice() {
+func (s *schedulerJobService) NotificationStockPrice(ctx context.Context, userIDs []uint) (int, error) {
 	// 取得按 symbol 分組的訂閱者清單
-	symbolSubscriptions, err := s.getSymbolSubscriptions()
+	symbolSubscriptions, err := s.getSymbolSubscriptions(userIDs)
 	if err != nil {
-		return
+		return 0, err
 	}
 
 	if symbolSubscriptions == nil {
-		return
+		return 0, nil
 	}
 
-	totalSubscriptions := 0
+	totalSubscriptions, failed := 0, 0
 	// 對每個唯一的 symbol 只查一次股票資訊
 	for symbol, userIDs := range symbolSubscriptions {
-		tgStockInfoMessage, err := s.tgService.GetStockPriceByDate(symbol, time.Now().Format("2006-01-02"))
+		if ctx.Err() != nil {
+			break
+		}
+		stockInfoResponse, err := s.execute("/d " + symbol)
 		if err != nil {
 			s.logger.Error("取得股票資訊失敗", zap.String("symbol", symbol), zap.Error(err))
+			failed++
 			continue
 		}
 
 		// 將股票資訊發送給所有訂閱該 symbol 的使用者
-		s.sendNotificationToSubscribers(tgStockInfoMessage, userIDs)
+		if !s.sendNotificationToSubscribers(models.SubscriptionItemStockInfo, stockInfoResponse, userIDs) {
+			failed++
+			continue
+		}
 		totalSubscriptions += len(userIDs)
 	}
 
 	s.logger.Info("股票資訊通知完成", zap.Int("symbol數量", len(symbolSubscriptions)), zap.Int("訂閱數量", totalSubscriptions))
+	return totalSubscriptions, errors.Join(failedError(failed, "檔股票資訊通知失敗"), ctx.Err())
 }
 
 // NotificationStockNews 通知股票新聞
-func (s *schedulerJobService) NotificationStockNews() {
+// 依使用者訂閱的股票推播，同一股票只查詢一次
+func (s *schedulerJobService) NotificationStockNews(ctx context.Context, userIDs []uint) (int, error) {
 	// 取得按 symbol 分組的訂閱者清單
-	symbolSubscriptions, err := s.getSymbolSubscriptions()
+	symbolSubscriptions, err := s.getSymbolSubscriptions(userIDs)
 	if err != nil {
-		return
+		return 0, err
 	}
 
 	if symbolSubscriptions == nil {
-		return
+		return 0, nil
 	}
 
-	totalSubscriptions := 0
-	// 對每個唯一的 symbol 只查一次股票新聞
+	totalSubscriptions, failed := 0, 0
+	// 對每個唯一的 symbol 只查一次股票新聞，只推播各使用者尚未推播過的新聞
 	for symbol, userIDs := range symbolSubscriptions {
-		tgStockNewsMessage, err := s.tgService.GetTaiwanStockNews(symbol)
+		if ctx.Err() != nil {
+			break
+		}
+		unseen, err := s.stockNewsSvc.UnseenNews(symbol, userIDs)
 		if err != nil {
 			s.logger.Error("取得股票新聞失敗", zap.String("symbol", symbol), zap.Error(err))
+			failed++
 			continue
 		}
 
-		// 將股票資訊發送給所有訂閱該 symbol 的使用者
-		for _, userID := range userIDs {
-			user, err := s.userRepo.GetByID(userID)
-			if err != nil {
-				s.logger.Error("取得使用者失敗", zap.Uint("userID", userID), zap.Error(err))
+		// 未推播新聞相同的使用者共用同一則通知
+		for _, group := range groupUnseenNews(unseen) {
+			response := s.registry.StockNewsResponse(symbol, group.news)
+			if !s.sendNotificationToSubscribers(models.SubscriptionItemStockNews, response, group.userIDs) {
+				failed++
 				continue
 			}
-			if user == nil {
-				s.logger.Error("使用者資料為空", zap.Uint("userID", userID))
-				continue
-			}
-			accountIDInt, err := strconv.ParseInt(user.AccountID, 10, 64)
-			if err != nil {
-				s.logger.Error("轉換使用者 AccountID 失敗", zap.String("accountID", user.AccountID), zap.Error(err))
-				continue
-			}
-			if err := s.tgClient.SendMessageWithKeyboard(accountIDInt, tgStockNewsMessage.Text, tgStockNewsMessage.InlineKeyboardMarkup); err != nil {
-				s.logger.Error("發送股票新聞通知失敗", zap.String("symbol", symbol), zap.Uint("userID", userID), zap.Error(err))
+			if err := s.stockNewsSvc.MarkSeen(symbol, group.userIDs, group.news); err != nil {
+				s.logger.Error("記錄已推播新聞失敗", zap.String("symbol", symbol), zap.Error(err))
 			}
+			totalSubscriptions += len(group.userIDs)
 		}
-
-		// 將股票新聞發送給所有訂閱該 symbol 的使用者
-		// s.sendNotificationToSubscribers(tgStockNewsMessage.Text, userIDs)
-		totalSubscriptions += len(userIDs)
 	}
 
 	s.logger.Info("股票新聞通知完成", zap.Int("symbol數量", len(symbolSubscriptions)), zap.Int("訂閱數量", totalSubscriptions))
+	return totalSubscriptions, errors.Join(failedError(failed, "檔股票新聞通知失敗"), ctx.Err())
 }
 
-// NotificationDailyMarketInfo 通知大盤資訊
-func (s *schedulerJobService) NotificationDailyMarketInfo() {
-	// 取得大盤訂閱者清單
-	subscriptionsList, err := s.getSubscriptions(3)
-	if err != nil {
-		return
+// unseenNewsGroup 未推播新聞相同的使用者
+type unseenNewsGroup struct {
+	news    []dto.TaiwanNewsResponseData
+	userIDs []uint
+}
+
+// groupUnseenNews 依未推播的新聞將使用者分組，沒有新新聞的使用者不推播
+func groupUnseenNews(unseen map[uint][]dto.TaiwanNewsResponseData) []*unseenNewsGroup {
+	groups := make(map[string]*unseenNewsGroup)
+	keys := make([]string, 0)
+	for userID, news := range unseen {
+		if len(news) == 0 {
+			continue
+		}
+		links := make([]string, 0, len(news))
+		for _, n := range news {
+			links = append(links, n.Link+"\n"+n.Title)
+		}
+		key := strings.Join(links, "\n")
+		group, ok := groups[key]
+		if !ok {
+			group = &unseenNewsGroup{news: news}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.userIDs = append(group.userIDs, userID)
+	}
+
+	sort.Strings(keys)
+	result := make([]*unseenNewsGroup, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, groups[key])
 	}
+	return result
+}
 
-	if subscriptionsList == nil {
-		return
+// NotificationDailyMarketInfo 通知大盤資訊
+func (s *schedulerJobService) NotificationDailyMarketInfo(userIDs []uint) (int, error) {
+	dailyMarketInfoResponse, err := s.execute("/m 1")
+	if err != nil {
+		s.logger.Error("取得大盤資訊失敗", zap.Error(err))
+		return 0, err
 	}
-	tgDailyMarketInfoMessage, err := s.tgService.GetDailyMarketInfo(1)
 
 	// 將大盤資訊發送給所有訂閱者
-	s.sendNotificationToSubscribers(tgDailyMarketInfoMessage, subscriptionsList)
+	if !s.sendNotificationToSubscribers(models.SubscriptionItemDailyMarketInfo, dailyMarketInfoResponse, userIDs) {
+		return 0, errEnqueueFailed
+	}
 
-	s.logger.Info("大盤資訊通知完成", zap.Int("訂閱數量", len(subscriptionsList)))
+	s.logger.Info("大盤資訊通知完成", zap.Int("訂閱數量", len(userIDs)))
+	return len(userIDs), nil
 }
 
 // NotificationTopVolumeItems 通知當日交易量前20名資訊
-func (s *schedulerJobService) NotificationTopVolumeItems() {
-	// 取得大盤訂閱者清單
-	subscriptionsList, err := s.getSubscriptions(4)
+func (s *schedulerJobService) NotificationTopVolumeItems(userIDs []uint) (int, error) {
+	// 推播第一頁，其餘可透過分頁按鈕查看
+	topVolumeItemsResponse, err := s.execute("/t")
 	if err != nil {
-		return
+		s.logger.Error("取得交易量前20名失敗", zap.Error(err))
+		return 0, err
 	}
 
-	if subscriptionsList == nil {
-		return
+	// 將交易量排行發送給所有訂閱者
+	if !s.sendNotificationToSubscribers(models.SubscriptionItemTopVolumeItems, topVolumeItemsResponse, userIDs) {
+		return 0, errEnqueueFailed
 	}
-	tgTopVolumeItemsMessage, err := s.tgService.GetTopVolumeItemsFormatted()
 
-	// 將大盤資訊發送給所有訂閱者
-	s.sendNotificationToSubscribers(tgTopVolumeItemsMessage, subscriptionsList)
+	s.logger.Info("交易量前20名資訊通知完成", zap.Int("訂閱數量", len(userIDs)))
+	return len(userIDs), nil
+}
 
-	s.logger.Info("交易量前20名資訊通知完成", zap.Int("訂閱數量", len(subscriptionsList)))
+// NotificationTreasuryYield 通知美國公債殖利率曲線
+func (s *schedulerJobService) NotificationTreasuryYield(userIDs []uint) (int, error) {
+	treasuryYieldResponse, err := s.execute("/yield")
+	if err != nil {
+		s.logger.Error("取得美國公債殖利率失敗", zap.Error(err))
+		return 0, err
+	}
+
+	if !s.sendNotificationToSubscribers(models.SubscriptionItemTreasuryYield, treasuryYieldResponse, userIDs) {
+		return 0, errEnqueueFailed
+	}
+
+	s.logger.Info("美國公債殖利率通知完成", zap.Int("訂閱數量", len(userIDs)))
+	return len(userIDs), nil
 }
 
-// getSubscriptions 取得按 symbol 分組的訂閱者清單
-func (s *schedulerJobService) getSubscriptions(featureID uint) ([]uint, error) {
-	// 取得所有股票訂閱清單
-	subscriptionList, err := s.subscriptionRepo.GetByFeatureID(featureID)
+// NotificationExchangeRateAlerts 檢查匯率警示並通知達到條件的使用者
+func (s *schedulerJobService) NotificationExchangeRateAlerts(ctx context.Context) (int, error) {
+	triggeredAlerts, err := s.exchangeRateAlertSvc.EvaluateAlerts()
 	if err != nil {
-		s.logger.Error("取得所有訂閱清單失敗", zap.Error(err))
-		return nil, err
+		return 0, err
 	}
 
-	if len(subscriptionList) == 0 {
-		s.logger.Info("沒有資料需要通知")
-		return nil, nil
+	sent, failed := 0, 0
+	for _, triggered := range triggeredAlerts {
+		if ctx.Err() != nil {
+			break
+		}
+		response := command.TriggeredExchangeRateAlertResponse(triggered)
+		if !s.sendNotificationToSubscribers(models.SubscriptionItemDefault, response, []uint{triggered.Alert.UserID}) {
+			// 通知建立失敗時保留警示，下次排程重新檢查
+			failed++
+			continue
+		}
+		sent++
+		// 停用失敗時下次排程可能重複通知，仍優於遺漏通知
+		if err := s.exchangeRateAlertSvc.MarkTriggered(triggered.Alert.ID); err != nil {
+			failed++
+		}
 	}
 
-	// 按 symbol 分組，彙整相同 symbol 的所有訂閱
-	subscriptions := make([]uint, 0)
-	for _, subscription := range subscriptionList {
-		userID := subscription.UserID
-		subscriptions = append(subscriptions, userID)
+	s.logger.Info("匯率警示通知完成", zap.Int("觸發數量", len(triggeredAlerts)))
+	return sent, errors.Join(failedError(failed, "則匯率警示建立通知或停用失敗"), ctx.Err())
+}
+
+// NotificationNewsAlerts 檢查新聞關鍵字警示並通知有新符合新聞的使用者
+func (s *schedulerJobService) NotificationNewsAlerts(ctx context.Context) (int, error) {
+	triggeredAlerts, err := s.stockNewsSvc.EvaluateAlerts()
+	if err != nil {
+		return 0, err
 	}
 
-	return subscriptions, nil
+	sent, failed := 0, 0
+	for _, triggered := range triggeredAlerts {
+		if ctx.Err() != nil {
+			break
+		}
+		response := command.TriggeredNewsAlertResponse(triggered)
+		if !s.sendNotificationToSubscribers(models.SubscriptionItemDefault, response, []uint{triggered.UserID}) {
+			failed++
+			continue
+		}
+		if err := s.stockNewsSvc.MarkSeen(models.NewsScopeKeyword, []uint{triggered.UserID}, triggered.News); err != nil {
+			s.logger.Error("記錄已推播新聞失敗", zap.Uint("userID", triggered.UserID), zap.Error(err))
+		}
+		sent++
+	}
+	s.stockNewsSvc.PruneSeen()
+
+	s.logger.Info("新聞警示通知完成", zap.Int("觸發數量", len(triggeredAlerts)))
+	return sent, errors.Join(failedError(failed, "則新聞警示建立通知失敗"), ctx.Err())
 }
 
-// getSymbolSubscriptions 取得按 symbol 分組的訂閱者清單
-func (s *schedulerJobService) getSymbolSubscriptions() (map[string][]uint, error) {
+// getSymbolSubscriptions 取得指定使用者按 symbol 分組的訂閱者清單
+func (s *schedulerJobService) getSymbolSubscriptions(userIDs []uint) (map[string][]uint, error) {
+	recipients := make(map[uint]bool, len(userIDs))
+	for _, userID := range userIDs {
+		recipients[userID] = true
+	}
+
 	// 取得所有股票訂閱清單
 	subscriptionSymbols, err := s.subscriptionSymbolRepo.GetAll("subscription_id")
 	if err != nil {
@@ -202,34 +490,38 @@ func (s *schedulerJobService) getSymbolSubscriptions() (map[string][]uint, error
 			s.logger.Warn("訂閱資料缺少關聯資訊，跳過")
 			continue
 		}
-		symbol := subscriptionSymbol.Symbol.Symbol
 		userID := subscriptionSymbol.Subscription.UserID
+		if !recipients[userID] {
+			continue
+		}
+		symbol := subscriptionSymbol.Symbol.Symbol
 		symbolSubscriptions[symbol] = append(symbolSubscriptions[symbol], userID)
 	}
 
 	return symbolSubscriptions, nil
 }
 
-// sendNotificationToSubscribers 將訊息發送給指定 symbol 的所有訂閱者
-func (s *schedulerJobService) sendNotificationToSubscribers(message string, userIDs []uint) {
-	// 將股票資訊發送給所有訂閱該 symbol 的使用者
-	for _, userID := range userIDs {
-		user, err := s.userRepo.GetByID(userID)
-		if err != nil {
-			s.logger.Error("取得使用者失敗", zap.Uint("userID", userID), zap.Error(err))
-			continue
-		}
-		if user == nil {
-			s.logger.Error("使用者資料為空", zap.Uint("userID", userID))
-			continue
-		}
-		accountIDInt, err := strconv.ParseInt(user.AccountID, 10, 64)
-		if err != nil {
-			s.logger.Error("轉換使用者 AccountID 失敗", zap.String("accountID", user.AccountID), zap.Error(err))
-			continue
-		}
-		if err := s.tgClient.SendMessage(accountIDInt, message); err != nil {
-			s.logger.Error("發送通知失敗", zap.Uint("userID", userID), zap.Error(err))
-		}
+// execute 以排程身分執行指令取得推播內容
+func (s *schedulerJobService) execute(text string) (*command.Response, error) {
+	return s.registry.Execute(&command.Context{Platform: models.UserTypeTelegram}, text)
+}
+
+// errEnqueueFailed 通知寫入佇列失敗，詳細原因已記錄於日誌
+var errEnqueueFailed = errors.New("建立通知失敗")
+
+// sendNotificationToSubscribers 將回應排入通知佇列，由佇列發送給所有訂閱者，回傳是否成功建立通知
+func (s *schedulerJobService) sendNotificationToSubscribers(item models.SubscriptionItem, response *command.Response, userIDs []uint) bool {
+	if err := s.outbox.Enqueue(item, userIDs, response); err != nil {
+		s.logger.Error("建立通知失敗", zap.String("item", item.GetName()), zap.Int("訂閱數量", len(userIDs)), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// failedError 部分項目失敗時回傳錯誤，詳細原因已記錄於日誌
+func failedError(failed int, message string) error {
+	if failed == 0 {
+		return nil
 	}
+	return fmt.Errorf("%d %s", failed, message)
 }
@@ -0,0 +1,189 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/internal/service/job_run"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// subscriptionJobTimeout 單一推播時間的訂閱項目排程逾時時間
+const subscriptionJobTimeout = 10 * time.Minute
+
+// SubscriptionScheduler 依各訂閱的推播時間及時區動態註冊排程
+type SubscriptionScheduler interface {
+	// Sync 依資料庫中的訂閱排程新增或移除排程項目，使用者變更排程後不需重啟
+	Sync()
+}
+
+// scheduleKey 推播時間，相同排程及時區的訂閱共用同一組排程，摘要模式的使用者可合併同時到期的項目
+type scheduleKey struct {
+	spec     string
+	timezone string
+}
+
+// cronSpec 含時區的 cron 規格
+func (k scheduleKey) cronSpec() string {
+	return fmt.Sprintf("CRON_TZ=%s %s", k.timezone, k.spec)
+}
+
+type subscriptionScheduler struct {
+	subscriptionRepo repository.SubscriptionRepository
+	jobService       SchedulerJobService
+	jobRegistry      job_run.JobRegistry
+	defaultSpec      string
+	defaultTimezone  string
+	logger           logger.Logger
+
+	mu   sync.Mutex
+	jobs map[string]bool // 已註冊的排程名稱，false 表示規格無效未註冊
+}
+
+// NewSubscriptionScheduler 建立訂閱排程管理，defaultSpec 及 defaultTimezone 用於未自訂排程的訂閱
+// 每個推播時間依訂閱項目各註冊一個排程，另註冊一個摘要排程推播給摘要模式的使用者
+// 排程由 jobRegistry 觸發，各自取得執行權並記錄，失敗時可只重新執行該項目
+func NewSubscriptionScheduler(subscriptionRepo repository.SubscriptionRepository, jobService SchedulerJobService, jobRegistry job_run.JobRegistry, defaultSpec, defaultTimezone string, log logger.Logger) SubscriptionScheduler {
+	return &subscriptionScheduler{
+		subscriptionRepo: subscriptionRepo,
+		jobService:       jobService,
+		jobRegistry:      jobRegistry,
+		defaultSpec:      defaultSpec,
+		defaultTimezone:  defaultTimezone,
+		logger:           log,
+		jobs:             make(map[string]bool),
+	}
+}
+
+// Sync 依資料庫中的訂閱排程新增或移除排程項目
+func (s *subscriptionScheduler) Sync() {
+	desired := make(map[string]*job_run.Job)
+	for _, item := range subscriptionItems() {
+		subscriptions, err := s.subscriptionRepo.GetActiveByItem(item)
+		if err != nil {
+			// 查詢失敗時保留現有排程，待下次同步
+			s.logger.Error("取得訂閱排程失敗", zap.Int("item", int(item)), zap.Error(err))
+			return
+		}
+		for _, subscription := range subscriptions {
+			key := s.keyOf(subscription)
+			itemJob := s.slotJob(job_run.SubscriptionJobs[item], key, func(ctx context.Context) (int, error) { return s.notifyItem(ctx, key, item) })
+			desired[itemJob.Name] = itemJob
+			digestJob := s.slotJob(job_run.JobDigest, key, func(ctx context.Context) (int, error) { return s.notifyDigest(ctx, key) })
+			desired[digestJob.Name] = digestJob
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range s.jobs {
+		if _, ok := desired[name]; ok {
+			continue
+		}
+		s.jobRegistry.Unregister(name)
+		delete(s.jobs, name)
+	}
+
+	for name, job := range desired {
+		if _, ok := s.jobs[name]; ok {
+			continue
+		}
+		if err := s.jobRegistry.Register(job); err != nil {
+			// 記錄無效的排程避免每次同步重複告警
+			s.logger.Warn("無效的訂閱排程", zap.String("job", name), zap.Error(err))
+			s.jobs[name] = false
+			continue
+		}
+		s.jobs[name] = true
+	}
+}
+
+// slotJob 單一推播時間的排程，非交易日略過，手動執行不受交易日限制
+func (s *subscriptionScheduler) slotJob(name string, key scheduleKey, run func(ctx context.Context) (int, error)) *job_run.Job {
+	return &job_run.Job{
+		Name:           job_run.SlotJobName(name, key.timezone, key.spec),
+		Spec:           key.cronSpec(),
+		Timeout:        subscriptionJobTimeout,
+		Concurrency:    1,
+		TradingDayOnly: true,
+		Run:            run,
+	}
+}
+
+// notifyItem 推播訂閱項目給該推播時間的訂閱者，執行時重新查詢訂閱以反映最新的訂閱狀態
+func (s *subscriptionScheduler) notifyItem(ctx context.Context, key scheduleKey, item models.SubscriptionItem) (int, error) {
+	userIDs, err := s.subscribers(key, item)
+	if err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+	return s.jobService.NotifyScheduledItem(ctx, item, userIDs)
+}
+
+// notifyDigest 將該推播時間到期的訂閱項目合併為摘要，推播給摘要模式的訂閱者
+func (s *subscriptionScheduler) notifyDigest(ctx context.Context, key scheduleKey) (int, error) {
+	due := make(map[models.SubscriptionItem][]uint)
+	for _, item := range subscriptionItems() {
+		userIDs, err := s.subscribers(key, item)
+		if err != nil {
+			return 0, err
+		}
+		if len(userIDs) > 0 {
+			due[item] = userIDs
+		}
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+	return s.jobService.NotifyScheduledDigest(ctx, due)
+}
+
+// subscribers 訂閱項目於該推播時間的訂閱者
+func (s *subscriptionScheduler) subscribers(key scheduleKey, item models.SubscriptionItem) ([]uint, error) {
+	subscriptions, err := s.subscriptionRepo.GetActiveByItem(item)
+	if err != nil {
+		return nil, fmt.Errorf("取得%s訂閱清單失敗: %w", item.GetName(), err)
+	}
+	var userIDs []uint
+	for _, subscription := range subscriptions {
+		if s.keyOf(subscription) == key {
+			userIDs = append(userIDs, subscription.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+// keyOf 取得訂閱對應的排程項目，未自訂排程或時區時使用預設值，排程規格的空白統一後與排程名稱一致
+func (s *subscriptionScheduler) keyOf(subscription *models.Subscription) scheduleKey {
+	key := scheduleKey{spec: strings.Join(strings.Fields(subscription.ScheduleCron), " "), timezone: subscription.Timezone}
+	if key.spec == "" {
+		key.spec = s.defaultSpec
+	}
+	if key.timezone == "" {
+		key.timezone = s.defaultTimezone
+	}
+	return key
+}
+
+// subscriptionItems 可推播的訂閱項目
+func subscriptionItems() []models.SubscriptionItem {
+	items := make([]models.SubscriptionItem, 0, len(models.SubscriptionItemMap))
+	for _, item := range models.SubscriptionItemMap {
+		if item != models.SubscriptionItemDefault {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+	return items
+}
@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"github.com/robfig/cron/v3"
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/internal/service/job_run"
+)
+
+type fakeSubscriptionRepo struct {
+	repository.SubscriptionRepository
+	byItem map[models.SubscriptionItem][]*models.Subscription
+}
+
+func (f *fakeSubscriptionRepo) GetActiveByItem(item models.SubscriptionItem) ([]*models.Subscription, error) {
+	return f.byItem[item], nil
+}
+
+type fakeSchedulerJobService struct {
+	SchedulerJobService
+	items  map[models.SubscriptionItem][][]uint
+	digest []map[models.SubscriptionItem][]uint
+}
+
+func (f *fakeSchedulerJobService) NotifyScheduledItem(_ context.Context, item models.SubscriptionItem, userIDs []uint) (int, error) {
+	if f.items == nil {
+		f.items = make(map[models.SubscriptionItem][][]uint)
+	}
+	f.items[item] = append(f.items[item], userIDs)
+	return len(userIDs), nil
+}
+
+func (f *fakeSchedulerJobService) NotifyScheduledDigest(_ context.Context, due map[models.SubscriptionItem][]uint) (int, error) {
+	f.digest = append(f.digest, due)
+	return len(due), nil
+}
+
+func TestSubscriptionSchedulerRegistersItemJobs(t *testing.T) {
+	c := cron.New(cron.WithSeconds())
+	registry := job_run.NewJobRegistry(c, nil, nil, nopLogger{})
+	subscriptions := &fakeSubscriptionRepo{byItem: map[models.SubscriptionItem][]*models.Subscription{
+		models.SubscriptionItemStockInfo: {
+			{UserID: 1},
+			{UserID: 2, ScheduleCron: "0 30 8 * * 1-5", Timezone: "Asia/Tokyo"},
+		},
+		models.SubscriptionItemStockNews: {
+			{UserID: 3},
+			{UserID: 4, ScheduleCron: "0  0 15 * * 1-5"},
+		},
+	}}
+	jobService := &fakeSchedulerJobService{}
+	scheduler := NewSubscriptionScheduler(subscriptions, jobService, registry, "0 0 15 * * 1-5", "Asia/Taipei", nopLogger{})
+	scheduler.Sync()
+
+	priceJob := job_run.SlotJobName(job_run.JobStockPrice, "Asia/Taipei", "0 0 15 * * 1-5")
+	newsJob := job_run.SlotJobName(job_run.JobStockNews, "Asia/Taipei", "0 0 15 * * 1-5")
+	tokyoJob := job_run.SlotJobName(job_run.JobStockPrice, "Asia/Tokyo", "0 30 8 * * 1-5")
+	digestJob := job_run.SlotJobName(job_run.JobDigest, "Asia/Taipei", "0 0 15 * * 1-5")
+	for _, name := range []string{priceJob, newsJob, tokyoJob, digestJob} {
+		job, ok := registry.Lookup(name)
+		if !ok {
+			t.Fatalf("%s not registered, jobs = %v", name, registry.Jobs())
+		}
+		if job.Timeout <= 0 || job.Concurrency != 1 {
+			t.Errorf("%s Timeout = %v, Concurrency = %d, want timeout and single concurrency", name, job.Timeout, job.Concurrency)
+		}
+	}
+	if _, ok := registry.Lookup(job_run.SlotJobName(job_run.JobStockNews, "Asia/Tokyo", "0 30 8 * * 1-5")); ok {
+		t.Error("news should not be registered for a slot without news subscribers")
+	}
+
+	// 重新執行單一項目只推播該項目於該推播時間的訂閱者
+	job, _ := registry.Lookup(newsJob)
+	if _, err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(jobService.items) != 1 {
+		t.Fatalf("NotifyScheduledItem items = %v, want news only", jobService.items)
+	}
+	if got := jobService.items[models.SubscriptionItemStockNews]; len(got) != 1 || len(got[0]) != 2 || got[0][0] != 3 || got[0][1] != 4 {
+		t.Errorf("news users = %v, want [[3 4]]", got)
+	}
+	if len(jobService.digest) != 0 {
+		t.Error("item job should not send the digest")
+	}
+
+	// 摘要排程合併該推播時間到期的所有項目
+	job, _ = registry.Lookup(digestJob)
+	if _, err := job.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(jobService.digest) != 1 {
+		t.Fatalf("NotifyScheduledDigest calls = %d, want 1", len(jobService.digest))
+	}
+	due := jobService.digest[0]
+	if got := due[models.SubscriptionItemStockInfo]; len(got) != 1 || got[0] != 1 {
+		t.Errorf("digest stock info users = %v, want [1]", got)
+	}
+	if got := due[models.SubscriptionItemStockNews]; len(got) != 2 {
+		t.Errorf("digest stock news users = %v, want [3 4]", got)
+	}
+
+	// 訂閱改為預設排程後移除東京排程
+	subscriptions.byItem[models.SubscriptionItemStockInfo] = []*models.Subscription{{UserID: 1}, {UserID: 2}}
+	scheduler.Sync()
+	for _, name := range []string{tokyoJob, job_run.SlotJobName(job_run.JobDigest, "Asia/Tokyo", "0 30 8 * * 1-5")} {
+		if _, ok := registry.Lookup(name); ok {
+			t.Errorf("%s should be unregistered after its subscriptions are removed", name)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Package stock_news 提供股票新聞去重及新聞關鍵字警示服務
+package stock_news
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/internal/service/twstock"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxAlertsPerUser  = 10                 // 每位使用者可設定的關鍵字警示數量
+	maxKeywordsLength = 255                // 關鍵字總長度上限，與資料表欄位一致
+	maxNewsPerAlert   = 10                 // 每則警示通知最多新聞數，其餘於下次檢查時通知
+	seenRetention     = 7 * 24 * time.Hour // 已推播紀錄保留時間，新聞僅查詢當日，保留一週已足夠
+)
+
+// TriggeredAlert 符合關鍵字的新聞，同一使用者的多個警示合併為一則通知
+type TriggeredAlert struct {
+	UserID   uint
+	Keywords []string // 符合的關鍵字組合
+	News     []dto.TaiwanNewsResponseData
+}
+
+// StockNewsService 股票新聞服務介面
+type StockNewsService interface {
+	// UnseenNews 取得股票今日新聞中，各使用者尚未推播過的新聞
+	UnseenNews(symbol string, userIDs []uint) (map[uint][]dto.TaiwanNewsResponseData, error)
+	// MarkSeen 記錄新聞已推播給使用者，scope 為股票代號或 models.NewsScopeKeyword
+	MarkSeen(scope string, userIDs []uint, news []dto.TaiwanNewsResponseData) error
+	AddAlert(userID uint, keywords []string) (*models.NewsAlert, error)
+	ListAlerts(userID uint) ([]*models.NewsAlert, error)
+	DeleteAlert(userID uint, alertID uint) error
+	// EvaluateAlerts 比對今日所有新聞與關鍵字警示，回傳尚未推播過的符合新聞
+	EvaluateAlerts() ([]TriggeredAlert, error)
+	// PruneSeen 刪除過期的已推播紀錄
+	PruneSeen()
+}
+
+type stockNewsService struct {
+	seenRepo     repository.NewsSeenRepository
+	alertRepo    repository.NewsAlertRepository
+	stockService twstock.StockService
+	logger       logger.Logger
+}
+
+func NewStockNewsService(seenRepo repository.NewsSeenRepository, alertRepo repository.NewsAlertRepository, stockService twstock.StockService, log logger.Logger) StockNewsService {
+	return &stockNewsService{
+		seenRepo:     seenRepo,
+		alertRepo:    alertRepo,
+		stockService: stockService,
+		logger:       log,
+	}
+}
+
+// UnseenNews 取得股票今日新聞中，各使用者尚未推播過的新聞，沒有新新聞的使用者不列入
+func (s *stockNewsService) UnseenNews(symbol string, userIDs []uint) (map[uint][]dto.TaiwanNewsResponseData, error) {
+	news, err := s.stockService.GetStockNews(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterUnseen(symbol, userIDs, news)
+}
+
+// filterUnseen 依已推播紀錄過濾各使用者的新聞
+func (s *stockNewsService) filterUnseen(scope string, userIDs []uint, news []dto.TaiwanNewsResponseData) (map[uint][]dto.TaiwanNewsResponseData, error) {
+	unseen := make(map[uint][]dto.TaiwanNewsResponseData)
+	if len(news) == 0 {
+		return unseen, nil
+	}
+
+	links := make([]string, 0, len(news))
+	for _, n := range news {
+		links = append(links, newsKey(n))
+	}
+	seen, err := s.seenRepo.GetSeenLinks(scope, userIDs, links)
+	if err != nil {
+		return nil, fmt.Errorf("取得已推播新聞失敗: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		for _, n := range news {
+			if !seen[userID][newsKey(n)] {
+				unseen[userID] = append(unseen[userID], n)
+			}
+		}
+	}
+	return unseen, nil
+}
+
+// MarkSeen 記錄新聞已推播給使用者
+func (s *stockNewsService) MarkSeen(scope string, userIDs []uint, news []dto.TaiwanNewsResponseData) error {
+	seen := make([]*models.NewsSeen, 0, len(userIDs)*len(news))
+	for _, userID := range userIDs {
+		for _, n := range news {
+			seen = append(seen, &models.NewsSeen{
+				UserID: userID,
+				Scope:  scope,
+				Link:   newsKey(n),
+				Title:  truncate(n.Title, 512),
+			})
+		}
+	}
+	return s.seenRepo.BatchCreate(seen)
+}
+
+// AddAlert 新增新聞關鍵字警示，標題需包含所有關鍵字才通知
+func (s *stockNewsService) AddAlert(userID uint, keywords []string) (*models.NewsAlert, error) {
+	terms := normalizeKeywords(keywords)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("請輸入關鍵字\n\n使用方式：\n/newsalert 關鍵字...\n例如：/newsalert 台積電 法說會")
+	}
+	joined := strings.Join(terms, " ")
+	if len([]rune(joined)) > maxKeywordsLength {
+		return nil, fmt.Errorf("關鍵字過長，請減少關鍵字")
+	}
+
+	alerts, err := s.alertRepo.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("取得新聞關鍵字警示失敗", zap.Error(err))
+		return nil, fmt.Errorf("新增新聞警示失敗，請稍後再試")
+	}
+	if len(alerts) >= maxAlertsPerUser {
+		return nil, fmt.Errorf("新聞警示已達上限 %d 組，請先使用 /newsdel 刪除", maxAlertsPerUser)
+	}
+	for _, alert := range alerts {
+		if alert.Keywords == joined {
+			return nil, fmt.Errorf("已設定相同的新聞警示 #%d", alert.ID)
+		}
+	}
+
+	alert := &models.NewsAlert{UserID: userID, Keywords: joined}
+	if err := s.alertRepo.Create(alert); err != nil {
+		s.logger.Error("新增新聞關鍵字警示失敗", zap.Error(err))
+		return nil, fmt.Errorf("新增新聞警示失敗，請稍後再試")
+	}
+	return alert, nil
+}
+
+// ListAlerts 取得使用者的新聞關鍵字警示
+func (s *stockNewsService) ListAlerts(userID uint) ([]*models.NewsAlert, error) {
+	alerts, err := s.alertRepo.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("取得新聞關鍵字警示失敗", zap.Error(err))
+		return nil, fmt.Errorf("取得新聞警示失敗，請稍後再試")
+	}
+	return alerts, nil
+}
+
+// DeleteAlert 刪除使用者的新聞關鍵字警示
+func (s *stockNewsService) DeleteAlert(userID uint, alertID uint) error {
+	alert, err := s.alertRepo.GetByID(alertID)
+	if err != nil || alert.UserID != userID {
+		return fmt.Errorf("查無此警示編號，請重新確認")
+	}
+
+	if err := s.alertRepo.Delete(alertID); err != nil {
+		s.logger.Error("刪除新聞關鍵字警示失敗", zap.Error(err))
+		return fmt.Errorf("刪除新聞警示失敗，請稍後再試")
+	}
+	return nil
+}
+
+// EvaluateAlerts 比對今日所有新聞與關鍵字警示，同一則新聞對同一使用者只通知一次
+func (s *stockNewsService) EvaluateAlerts() ([]TriggeredAlert, error) {
+	alerts, err := s.alertRepo.GetAll()
+	if err != nil {
+		s.logger.Error("取得新聞關鍵字警示失敗", zap.Error(err))
+		return nil, err
+	}
+	// 沒有任何警示時不查詢新聞
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	news, err := s.stockService.GetMarketNews()
+	if err != nil {
+		s.logger.Error("取得市場新聞失敗", zap.Error(err))
+		return nil, err
+	}
+
+	// 依使用者彙整符合的新聞，同一則新聞符合多組關鍵字時只列一次
+	matched := make(map[uint][]dto.TaiwanNewsResponseData)
+	matchedKeys := make(map[uint]map[string]bool)
+	keywords := make(map[uint][]string)
+	for _, alert := range alerts {
+		terms := strings.Fields(alert.Keywords)
+		hit := false
+		for _, n := range news {
+			if !MatchKeywords(n.Title, terms) {
+				continue
+			}
+			hit = true
+			if matchedKeys[alert.UserID] == nil {
+				matchedKeys[alert.UserID] = make(map[string]bool)
+			}
+			if key := newsKey(n); !matchedKeys[alert.UserID][key] {
+				matchedKeys[alert.UserID][key] = true
+				matched[alert.UserID] = append(matched[alert.UserID], n)
+			}
+		}
+		if hit {
+			keywords[alert.UserID] = append(keywords[alert.UserID], alert.Keywords)
+		}
+	}
+
+	triggered := make([]TriggeredAlert, 0, len(matched))
+	for userID, userNews := range matched {
+		unseen, err := s.filterUnseen(models.NewsScopeKeyword, []uint{userID}, userNews)
+		if err != nil {
+			s.logger.Error("過濾已推播新聞失敗", zap.Uint("userID", userID), zap.Error(err))
+			continue
+		}
+		if len(unseen[userID]) == 0 {
+			continue
+		}
+		items := unseen[userID]
+		if len(items) > maxNewsPerAlert {
+			items = items[:maxNewsPerAlert]
+		}
+		triggered = append(triggered, TriggeredAlert{UserID: userID, Keywords: keywords[userID], News: items})
+	}
+	return triggered, nil
+}
+
+// PruneSeen 刪除過期的已推播紀錄
+func (s *stockNewsService) PruneSeen() {
+	count, err := s.seenRepo.DeleteBefore(time.Now().Add(-seenRetention))
+	if err != nil {
+		s.logger.Error("刪除過期的已推播新聞失敗", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		s.logger.Info("已刪除過期的已推播新聞", zap.Int64("數量", count))
+	}
+}
+
+// MatchKeywords 標題是否包含所有關鍵字，英文不分大小寫
+func MatchKeywords(title string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return false
+	}
+	title = strings.ToLower(title)
+	for _, keyword := range keywords {
+		if !strings.Contains(title, strings.ToLower(keyword)) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeKeywords 去除空白及重複的關鍵字
+func normalizeKeywords(keywords []string) []string {
+	terms := make([]string, 0, len(keywords))
+	exists := make(map[string]bool)
+	for _, keyword := range keywords {
+		for _, term := range strings.Fields(keyword) {
+			if !exists[strings.ToLower(term)] {
+				exists[strings.ToLower(term)] = true
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+// newsKey 新聞識別鍵，沒有連結時使用標題
+func newsKey(n dto.TaiwanNewsResponseData) string {
+	if n.Link != "" {
+		return truncate(n.Link, 1024)
+	}
+	return truncate(n.Title, 1024)
+}
+
+// truncate 依字元截斷字串
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
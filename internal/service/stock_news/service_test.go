@@ -0,0 +1,32 @@
+package stock_news
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchKeywords(t *testing.T) {
+	tests := []struct {
+		title    string
+		keywords []string
+		want     bool
+	}{
+		{"台積電法說會前瞻 AI 需求強勁", []string{"台積電", "法說會"}, true},
+		{"台積電營收創新高", []string{"台積電", "法說會"}, false},
+		{"NVIDIA 財報亮眼", []string{"nvidia"}, true},
+		{"任何標題", nil, false},
+	}
+	for _, tt := range tests {
+		if got := MatchKeywords(tt.title, tt.keywords); got != tt.want {
+			t.Errorf("MatchKeywords(%q, %v) = %v, want %v", tt.title, tt.keywords, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeKeywords(t *testing.T) {
+	got := normalizeKeywords([]string{"台積電", " 法說會  AI", "ai", "台積電"})
+	want := []string{"台積電", "法說會", "AI"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizeKeywords() = %v, want %v", got, want)
+	}
+}
@@ -53,13 +53,20 @@ func (s *stockSyncService) SyncTaiwanStockInfo() error {
 
 	s.logger.Info("成功取得股票資訊", zap.Int("count", len(response.Data)))
 
-	// 轉換為 models.Symbol
+	// 轉換為 models.Symbol，同一股票可能重複出現在多個產業類別，僅保留第一筆
 	symbols := make([]*models.Symbol, 0, len(response.Data))
+	seen := make(map[string]bool, len(response.Data))
 	for _, stockInfo := range response.Data {
+		if seen[stockInfo.StockID] {
+			continue
+		}
+		seen[stockInfo.StockID] = true
+
 		symbol := &models.Symbol{
-			Symbol: stockInfo.StockID,
-			Name:   stockInfo.StockName,
-			Market: "TW",
+			Symbol:   stockInfo.StockID,
+			Name:     stockInfo.StockName,
+			Market:   "TW",
+			Industry: stockInfo.IndustryCategory,
 		}
 		symbols = append(symbols, symbol)
 	}
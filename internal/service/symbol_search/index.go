@@ -0,0 +1,220 @@
+package symbol_search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// 比對分數，分數越高越優先
+const (
+	scoreExactSymbol    = 100
+	scoreExactName      = 95
+	scoreNamePrefix     = 80
+	scoreSymbolPrefix   = 75
+	scorePinyinExact    = 70
+	scorePinyinPrefix   = 60
+	scoreNameContains   = 50
+	scorePinyinContains = 40
+	scoreFuzzy          = 30
+)
+
+// indexEntry 單一股票的索引鍵
+type indexEntry struct {
+	symbol         *models.Symbol
+	code           string // 小寫股票代號
+	name           string // 小寫名稱
+	pinyinFull     string // 全拼，例如 taijidian
+	pinyinInitials string // 首字母，例如 tjd
+}
+
+// symbolIndex 股票名稱搜尋索引
+type symbolIndex struct {
+	entries []indexEntry
+	byCode  map[string]*models.Symbol
+}
+
+// newSymbolIndex 由股票清單建立索引
+func newSymbolIndex(symbols []*models.Symbol) *symbolIndex {
+	index := &symbolIndex{
+		entries: make([]indexEntry, 0, len(symbols)),
+		byCode:  make(map[string]*models.Symbol, len(symbols)),
+	}
+
+	args := pinyin.NewArgs()
+	for _, symbol := range symbols {
+		if symbol == nil || symbol.Symbol == "" {
+			continue
+		}
+		code := strings.ToLower(strings.TrimSpace(symbol.Symbol))
+		full, initials := toPinyin(symbol.Name, args)
+		index.entries = append(index.entries, indexEntry{
+			symbol:         symbol,
+			code:           code,
+			name:           strings.ToLower(strings.TrimSpace(symbol.Name)),
+			pinyinFull:     full,
+			pinyinInitials: initials,
+		})
+		if _, exists := index.byCode[code]; !exists {
+			index.byCode[code] = symbol
+		}
+	}
+	return index
+}
+
+// toPinyin 轉換名稱為全拼與首字母，非中文字元保留原字（小寫）
+func toPinyin(name string, args pinyin.Args) (full string, initials string) {
+	var fullBuilder, initialsBuilder strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.Is(unicode.Han, r) {
+			readings := pinyin.SinglePinyin(r, args)
+			if len(readings) > 0 && readings[0] != "" {
+				fullBuilder.WriteString(readings[0])
+				initialsBuilder.WriteByte(readings[0][0])
+			}
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			fullBuilder.WriteRune(r)
+			initialsBuilder.WriteRune(r)
+		}
+	}
+	return fullBuilder.String(), initialsBuilder.String()
+}
+
+// lookup 以股票代號精確查詢
+func (idx *symbolIndex) lookup(code string) (*models.Symbol, bool) {
+	symbol, ok := idx.byCode[strings.ToLower(strings.TrimSpace(code))]
+	return symbol, ok
+}
+
+// search 依前綴、包含與編輯距離比對，回傳排序後的結果
+func (idx *symbolIndex) search(query string, limit int) []SearchResult {
+	query = strings.ToLower(strings.Join(strings.Fields(query), ""))
+	if query == "" {
+		return nil
+	}
+
+	var results []SearchResult
+	for _, entry := range idx.entries {
+		if score := entry.score(query); score > 0 {
+			results = append(results, SearchResult{Symbol: entry.symbol, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		// 同分時名稱較短者優先，再依代號排序
+		li, lj := len([]rune(results[i].Symbol.Name)), len([]rune(results[j].Symbol.Name))
+		if li != lj {
+			return li < lj
+		}
+		return results[i].Symbol.Symbol < results[j].Symbol.Symbol
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// score 計算查詢字串與索引鍵的相符分數，0 表示不相符
+func (e indexEntry) score(query string) int {
+	switch {
+	case query == e.code:
+		return scoreExactSymbol
+	case query == e.name:
+		return scoreExactName
+	case strings.HasPrefix(e.name, query):
+		return scoreNamePrefix
+	case strings.HasPrefix(e.code, query):
+		return scoreSymbolPrefix
+	case e.pinyinFull != "" && (query == e.pinyinFull || query == e.pinyinInitials):
+		return scorePinyinExact
+	case isASCII(query) && len(query) >= 2 && (strings.HasPrefix(e.pinyinFull, query) || strings.HasPrefix(e.pinyinInitials, query)):
+		return scorePinyinPrefix
+	case strings.Contains(e.name, query):
+		return scoreNameContains
+	case isASCII(query) && len(query) >= 3 && strings.Contains(e.pinyinFull, query):
+		return scorePinyinContains
+	}
+
+	// 編輯距離比對，容許少量錯字
+	maxDistance := allowedDistance(query)
+	if maxDistance == 0 {
+		return 0
+	}
+	best := maxDistance + 1
+	for _, key := range []string{e.name, e.pinyinFull} {
+		if key == "" {
+			continue
+		}
+		if d := levenshtein(query, key); d < best {
+			best = d
+		}
+	}
+	if best <= maxDistance {
+		return scoreFuzzy - best*5
+	}
+	return 0
+}
+
+// allowedDistance 依查詢長度決定可容許的編輯距離
+func allowedDistance(query string) int {
+	length := len([]rune(query))
+	switch {
+	case length < 2:
+		return 0
+	case isASCII(query) && length < 4:
+		return 0
+	case length <= 4:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// isASCII 判斷字串是否全為 ASCII
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// levenshtein 計算兩字串（以 rune 為單位）的編輯距離
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,74 @@
+package symbol_search
+
+import (
+	"testing"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+)
+
+func testSymbols() []*models.Symbol {
+	return []*models.Symbol{
+		{Symbol: "2330", Name: "台積電", Market: "TW"},
+		{Symbol: "2454", Name: "聯發科", Market: "TW"},
+		{Symbol: "2303", Name: "聯電", Market: "TW"},
+		{Symbol: "2317", Name: "鴻海", Market: "TW"},
+		{Symbol: "0050", Name: "元大台灣50", Market: "TW"},
+		{Symbol: "AAPL", Name: "Apple Inc.", Market: "US"},
+	}
+}
+
+func TestSymbolIndexSearch(t *testing.T) {
+	index := newSymbolIndex(testSymbols())
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"2330", "2330"},
+		{"台積電", "2330"},
+		{"聯發", "2454"},
+		{"鴻", "2317"},
+		{"taijidian", "2330"},
+		{"tjd", "2330"},
+		{"honghai", "2317"},
+		{"台灣50", "0050"},
+		{"台積店", "2330"},
+		{"apple", "AAPL"},
+	}
+
+	for _, tt := range tests {
+		results := index.search(tt.query, 1)
+		if len(results) == 0 {
+			t.Errorf("search(%q) 無結果，期望 %s", tt.query, tt.expected)
+			continue
+		}
+		if got := results[0].Symbol.Symbol; got != tt.expected {
+			t.Errorf("search(%q) = %s，期望 %s", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestSymbolIndexSearchNoMatch(t *testing.T) {
+	index := newSymbolIndex(testSymbols())
+	if results := index.search("不存在的公司", 5); len(results) != 0 {
+		t.Errorf("期望無結果，實際得到 %d 筆", len(results))
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "abc", 3},
+		{"台積電", "台積電", 0},
+		{"台積店", "台積電", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.expected {
+			t.Errorf("levenshtein(%q, %q) = %d，期望 %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
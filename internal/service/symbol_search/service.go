@@ -0,0 +1,160 @@
+// Package symbol_search 提供以代號、中英文名稱及拼音模糊搜尋股票的服務
+package symbol_search
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/repository"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// 索引有效時間，過期後重新從資料庫載入
+	indexTTL = 6 * time.Hour
+	// 查詢結果有多筆時列出的候選數量
+	maxCandidates = 5
+	// 解析指令參數時使用的市場
+	resolveMarket = "TW"
+)
+
+// 建立索引的市場
+var indexMarkets = []string{"TW", "US"}
+
+// SearchResult 搜尋結果
+type SearchResult struct {
+	Symbol *models.Symbol
+	Score  int
+}
+
+// SymbolSearchService 股票搜尋服務介面
+type SymbolSearchService interface {
+	Search(query string, limit int) ([]SearchResult, error)
+	Resolve(query string) (string, error)
+}
+
+type symbolSearchService struct {
+	symbolRepo repository.SymbolRepository
+	mu         sync.Mutex
+	index      *symbolIndex
+	builtAt    time.Time
+	logger     logger.Logger
+}
+
+func NewSymbolSearchService(symbolRepo repository.SymbolRepository, log logger.Logger) SymbolSearchService {
+	return &symbolSearchService{
+		symbolRepo: symbolRepo,
+		logger:     log,
+	}
+}
+
+// Search 依代號、名稱或拼音搜尋股票
+func (s *symbolSearchService) Search(query string, limit int) ([]SearchResult, error) {
+	index, err := s.getIndex()
+	if err != nil {
+		return nil, err
+	}
+	return index.search(query, limit), nil
+}
+
+// Resolve 將指令參數解析為台股代號，只有代號或名稱完全相同時解析，其餘回傳候選清單錯誤
+func (s *symbolSearchService) Resolve(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", nil
+	}
+
+	index, err := s.getIndex()
+	if err != nil {
+		// 索引無法使用時維持原本以代號查詢的行為
+		return query, nil
+	}
+
+	if symbol, ok := index.lookup(query); ok && symbol.Market == resolveMarket {
+		return symbol.Symbol, nil
+	}
+
+	var results []SearchResult
+	for _, result := range index.search(query, 0) {
+		if result.Symbol.Market == resolveMarket {
+			results = append(results, result)
+		}
+	}
+
+	if len(results) == 0 {
+		// 尚未同步的代號仍交由後續查詢處理
+		if isSymbolLike(query) {
+			return query, nil
+		}
+		return "", fmt.Errorf("查無符合「%s」的股票，請確認後再試", query)
+	}
+
+	// 只有代號或名稱完全相同時直接查詢，前綴或相近的結果可能不是使用者要查的股票，列出候選清單
+	if top := results[0]; top.Score >= scoreExactName {
+		return top.Symbol.Symbol, nil
+	}
+	if len(results) == 1 {
+		return "", fmt.Errorf("查無代號或名稱為「%s」的股票，是否要查詢：\n%s", query, FormatCandidates(results, maxCandidates))
+	}
+	return "", fmt.Errorf("找到多檔符合「%s」的股票，請改用代號查詢：\n%s", query, FormatCandidates(results, maxCandidates))
+}
+
+// FormatCandidates 格式化候選股票清單
+func FormatCandidates(results []SearchResult, limit int) string {
+	var builder strings.Builder
+	for i, result := range results {
+		if limit > 0 && i >= limit {
+			break
+		}
+		builder.WriteString(fmt.Sprintf("%s %s", result.Symbol.Symbol, result.Symbol.Name))
+		if result.Symbol.Market != resolveMarket {
+			builder.WriteString(fmt.Sprintf(" (%s)", result.Symbol.Market))
+		}
+		builder.WriteString("\n")
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// getIndex 取得搜尋索引，過期時重新建立
+func (s *symbolSearchService) getIndex() (*symbolIndex, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index != nil && time.Since(s.builtAt) < indexTTL {
+		return s.index, nil
+	}
+
+	var symbols []*models.Symbol
+	for _, market := range indexMarkets {
+		marketSymbols, err := s.symbolRepo.GetByMarket(market)
+		if err != nil {
+			s.logger.Error("載入股票清單失敗", zap.String("market", market), zap.Error(err))
+			if s.index != nil {
+				// 沿用舊索引
+				return s.index, nil
+			}
+			return nil, err
+		}
+		symbols = append(symbols, marketSymbols...)
+	}
+
+	s.index = newSymbolIndex(symbols)
+	s.builtAt = time.Now()
+	s.logger.Info("股票搜尋索引建立完成", zap.Int("count", len(s.index.entries)))
+	return s.index, nil
+}
+
+// isSymbolLike 判斷字串是否像股票代號（英數字）
+func isSymbolLike(query string) bool {
+	for _, r := range query {
+		if !(r >= '0' && r <= '9') && !(r >= 'A' && r <= 'Z') && !(r >= 'a' && r <= 'z') {
+			return false
+		}
+	}
+	return strings.IndexFunc(query, func(r rune) bool { return r >= '0' && r <= '9' }) >= 0
+}
@@ -0,0 +1,62 @@
+package symbol_search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...zap.Field)  {}
+func (nopLogger) Error(string, ...zap.Field) {}
+func (nopLogger) Warn(string, ...zap.Field)  {}
+func (nopLogger) Debug(string, ...zap.Field) {}
+func (nopLogger) Panic(string, ...zap.Field) {}
+func (nopLogger) Fatal(string, ...zap.Field) {}
+func (nopLogger) Sync() error                { return nil }
+
+type fakeSymbolRepo struct {
+	repository.SymbolRepository
+}
+
+func (fakeSymbolRepo) GetByMarket(market string) ([]*models.Symbol, error) {
+	var symbols []*models.Symbol
+	for _, symbol := range testSymbols() {
+		if symbol.Market == market {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols, nil
+}
+
+func TestResolveOnlyExactMatches(t *testing.T) {
+	service := NewSymbolSearchService(fakeSymbolRepo{}, nopLogger{})
+
+	for query, want := range map[string]string{"2330": "2330", "台積電": "2330", "9999": "9999"} {
+		got, err := service.Resolve(query)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", query, got, err, want)
+		}
+	}
+
+	// 代號或名稱的前綴只列出候選，不直接查詢
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"233", "2330 台積電"},
+		{"聯發", "2454 聯發科"},
+		{"聯", "2303 聯電"},
+	}
+	for _, tt := range tests {
+		got, err := service.Resolve(tt.query)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Resolve(%q) = %q, %v, want candidates containing %q", tt.query, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Package trading_calendar 提供台股交易日曆服務
+package trading_calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	twseDto "github.com/tian841224/stock-bot/internal/infrastructure/twse/dto"
+	"github.com/tian841224/stock-bot/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// 快取有效時間，過期後重新向 FinMind 取得
+	cacheTTL = 24 * time.Hour
+	// 載入失敗後的重試間隔
+	retryInterval = 10 * time.Minute
+	// 往前或往後搜尋交易日的最大天數
+	maxSearchDays = 30
+)
+
+// holidayDatePattern 休市日期格式，支援西元年 2025-01-01 及民國年 114/01/01、1140101
+var holidayDatePattern = regexp.MustCompile(`^(\d{2,4})[-/]?(\d{2})[-/]?(\d{2})$`)
+
+// TradingDateSource 已公告交易日資料來源，FinMind 僅提供已過去的交易日
+type TradingDateSource interface {
+	GetTaiwanStockTradingDate(requestDto dto.FinmindtradeRequestDto) (dto.TaiwanStockTradingDateResponseDto, error)
+}
+
+// HolidaySource 休市日資料來源，證交所於年初公告全年休市日
+type HolidaySource interface {
+	GetHolidaySchedule(year int) (twseDto.HolidayScheduleResponseDto, error)
+}
+
+// TradingCalendarService 交易日曆服務介面
+type TradingCalendarService interface {
+	IsTradingDay(date time.Time) bool
+	PreviousTradingDay(date time.Time) time.Time
+	NextTradingDay(date time.Time) time.Time
+}
+
+// yearCalendar 單一年度的交易日快取
+type yearCalendar struct {
+	dates     map[string]struct{} // 已公告的交易日
+	lastDate  string              // 已公告的最後交易日
+	holidays  map[string]struct{} // 證交所公告的休市日，用於判斷尚未公告交易日的日期
+	fetchedAt time.Time
+}
+
+type tradingCalendarService struct {
+	tradingDates TradingDateSource
+	holidays     HolidaySource
+	location     *time.Location
+	mu           sync.Mutex
+	years        map[int]*yearCalendar
+	loading      map[int]bool
+	logger       logger.Logger
+}
+
+func NewTradingCalendarService(tradingDates TradingDateSource, holidays HolidaySource, log logger.Logger) TradingCalendarService {
+	location, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		location = time.FixedZone("Asia/Taipei", 8*60*60)
+	}
+	return &tradingCalendarService{
+		tradingDates: tradingDates,
+		holidays:     holidays,
+		location:     location,
+		years:        make(map[int]*yearCalendar),
+		loading:      make(map[int]bool),
+		logger:       log,
+	}
+}
+
+// IsTradingDay 判斷指定日期（以台北時間計）是否為台股交易日
+func (s *tradingCalendarService) IsTradingDay(date time.Time) bool {
+	date = date.In(s.location)
+	calendar := s.getYearCalendar(date.Year())
+	key := date.Format("2006-01-02")
+
+	if key <= calendar.lastDate {
+		_, ok := calendar.dates[key]
+		return ok
+	}
+
+	// 超出已公告交易日範圍時，以平日且非證交所公告的休市日判斷
+	_, holiday := calendar.holidays[key]
+	return isWeekday(date) && !holiday
+}
+
+// PreviousTradingDay 取得指定日期之前（不含當日）的最近交易日
+func (s *tradingCalendarService) PreviousTradingDay(date time.Time) time.Time {
+	date = date.In(s.location)
+	for i := 1; i <= maxSearchDays; i++ {
+		candidate := date.AddDate(0, 0, -i)
+		if s.IsTradingDay(candidate) {
+			return candidate
+		}
+	}
+	return date.AddDate(0, 0, -1)
+}
+
+// NextTradingDay 取得指定日期之後（不含當日）的最近交易日
+func (s *tradingCalendarService) NextTradingDay(date time.Time) time.Time {
+	date = date.In(s.location)
+	for i := 1; i <= maxSearchDays; i++ {
+		candidate := date.AddDate(0, 0, i)
+		if s.IsTradingDay(candidate) {
+			return candidate
+		}
+	}
+	return date.AddDate(0, 0, 1)
+}
+
+// getYearCalendar 取得年度交易日快取，過期或不存在時重新載入
+// 載入外部資料時不持有鎖，其他查詢使用舊快取；同一年度同時只有一個載入
+func (s *tradingCalendarService) getYearCalendar(year int) *yearCalendar {
+	s.mu.Lock()
+	calendar, exists := s.years[year]
+	if exists && time.Since(calendar.fetchedAt) < cacheTTL {
+		s.mu.Unlock()
+		return calendar
+	}
+	if s.loading[year] {
+		s.mu.Unlock()
+		if exists {
+			return calendar
+		}
+		// 首次載入中，暫以平日判斷
+		return &yearCalendar{}
+	}
+	s.loading[year] = true
+	s.mu.Unlock()
+
+	loaded := s.loadYear(year, calendar)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.loading, year)
+	s.years[year] = loaded
+	return loaded
+}
+
+// loadYear 載入指定年度的已公告交易日及休市日，部分失敗時沿用舊快取的資料並於重試間隔後再載入
+func (s *tradingCalendarService) loadYear(year int, previous *yearCalendar) *yearCalendar {
+	if previous == nil {
+		previous = &yearCalendar{}
+	}
+	calendar := &yearCalendar{fetchedAt: time.Now()}
+	failed := false
+
+	dates, lastDate, err := s.loadTradingDates(year)
+	if err != nil {
+		s.logger.Warn("載入交易日曆失敗", zap.Int("year", year), zap.Error(err))
+		dates, lastDate, failed = previous.dates, previous.lastDate, true
+	}
+	calendar.dates, calendar.lastDate = dates, lastDate
+
+	holidays, err := s.loadHolidays(year)
+	if err != nil {
+		s.logger.Warn("載入休市日失敗", zap.Int("year", year), zap.Error(err))
+		holidays, failed = previous.holidays, true
+	}
+	calendar.holidays = holidays
+
+	if failed {
+		calendar.fetchedAt = time.Now().Add(retryInterval - cacheTTL)
+	}
+	return calendar
+}
+
+// loadTradingDates 從 FinMind 載入指定年度已公告的交易日
+func (s *tradingCalendarService) loadTradingDates(year int) (map[string]struct{}, string, error) {
+	response, err := s.tradingDates.GetTaiwanStockTradingDate(dto.FinmindtradeRequestDto{
+		StartDate: fmt.Sprintf("%d-01-01", year),
+		EndDate:   fmt.Sprintf("%d-12-31", year),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if response.Status != 200 {
+		return nil, "", fmt.Errorf("查無交易日資料")
+	}
+
+	// 年初尚無交易日時回傳空白資料，全部日期以休市日判斷
+	dates := make(map[string]struct{}, len(response.Data))
+	lastDate := ""
+	for _, item := range response.Data {
+		dates[item.Date] = struct{}{}
+		if item.Date > lastDate {
+			lastDate = item.Date
+		}
+	}
+	return dates, lastDate, nil
+}
+
+// loadHolidays 從證交所載入指定年度的休市日
+func (s *tradingCalendarService) loadHolidays(year int) (map[string]struct{}, error) {
+	response, err := s.holidays.GetHolidaySchedule(year)
+	if err != nil {
+		return nil, err
+	}
+	if response.Stat != "" && response.Stat != "OK" {
+		return nil, fmt.Errorf("查無休市日資料: %s", response.Stat)
+	}
+	return ParseHolidays(response), nil
+}
+
+// ParseHolidays 解析證交所開休市日期，開始交易日及最後交易日為交易日不列入
+func ParseHolidays(response twseDto.HolidayScheduleResponseDto) map[string]struct{} {
+	dateColumn := 0
+	for i, field := range response.Fields {
+		if strings.Contains(field, "日期") {
+			dateColumn = i
+			break
+		}
+	}
+
+	holidays := make(map[string]struct{})
+	for _, row := range response.Data {
+		if dateColumn >= len(row) {
+			continue
+		}
+		text := strings.Join(row, " ")
+		if strings.Contains(text, "開始交易") || strings.Contains(text, "最後交易") {
+			continue
+		}
+		if date, ok := parseHolidayDate(row[dateColumn]); ok {
+			holidays[date] = struct{}{}
+		}
+	}
+	return holidays
+}
+
+// parseHolidayDate 將西元年或民國年日期轉為 YYYY-MM-DD
+func parseHolidayDate(value string) (string, bool) {
+	matches := holidayDatePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if matches == nil {
+		return "", false
+	}
+	year, _ := strconv.Atoi(matches[1])
+	if year < 1911 {
+		year += 1911
+	}
+	return fmt.Sprintf("%04d-%s-%s", year, matches[2], matches[3]), true
+}
+
+// isWeekday 判斷是否為週一至週五
+func isWeekday(date time.Time) bool {
+	weekday := date.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday
+}
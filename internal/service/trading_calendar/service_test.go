@@ -0,0 +1,171 @@
+package trading_calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	twseDto "github.com/tian841224/stock-bot/internal/infrastructure/twse/dto"
+
+	"go.uber.org/zap"
+)
+
+var taipei = time.FixedZone("Asia/Taipei", 8*60*60)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...zap.Field)  {}
+func (nopLogger) Error(string, ...zap.Field) {}
+func (nopLogger) Warn(string, ...zap.Field)  {}
+func (nopLogger) Debug(string, ...zap.Field) {}
+func (nopLogger) Panic(string, ...zap.Field) {}
+func (nopLogger) Fatal(string, ...zap.Field) {}
+func (nopLogger) Sync() error                { return nil }
+
+// fakeTradingDates 已公告至 lastDate 的交易日，為平日且非休市日
+type fakeTradingDates struct {
+	lastDate map[int]string
+	holidays map[string]bool
+	block    chan struct{} // 不為 nil 時等待關閉後才回應
+}
+
+func (f *fakeTradingDates) GetTaiwanStockTradingDate(request dto.FinmindtradeRequestDto) (dto.TaiwanStockTradingDateResponseDto, error) {
+	if f.block != nil {
+		<-f.block
+	}
+	start, _ := time.Parse("2006-01-02", request.StartDate)
+	response := dto.TaiwanStockTradingDateResponseDto{Status: 200}
+	last := f.lastDate[start.Year()]
+	for d := start; d.Format("2006-01-02") <= last; d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		if isWeekday(d) && !f.holidays[key] {
+			response.Data = append(response.Data, dto.TaiwanStockTradingDateData{Date: key})
+		}
+	}
+	return response, nil
+}
+
+type fakeHolidays struct {
+	schedule map[int][][]string
+	err      error
+}
+
+func (f *fakeHolidays) GetHolidaySchedule(year int) (twseDto.HolidayScheduleResponseDto, error) {
+	if f.err != nil {
+		return twseDto.HolidayScheduleResponseDto{}, f.err
+	}
+	return twseDto.HolidayScheduleResponseDto{Stat: "OK", Fields: []string{"日期", "名稱", "說明"}, Data: f.schedule[year]}, nil
+}
+
+func newTestService(holidays HolidaySource) *tradingCalendarService {
+	tradingDates := &fakeTradingDates{
+		lastDate: map[int]string{2024: "2024-12-31", 2025: "2025-01-24"},
+		holidays: map[string]bool{"2025-01-01": true},
+	}
+	return NewTradingCalendarService(tradingDates, holidays, nopLogger{}).(*tradingCalendarService)
+}
+
+// 2025 年農曆春節休市 1/27-1/31，2/3 開始交易，2/28 和平紀念日
+var holidays2025 = &fakeHolidays{schedule: map[int][][]string{2025: {
+	{"2025-01-01", "中華民國開國紀念日", "依規定放假1日"},
+	{"2025-01-22", "農曆春節前最後交易日", ""},
+	{"114/01/27", "農曆春節", "依規定放假"},
+	{"114/01/28", "農曆除夕", "依規定放假"},
+	{"2025-01-29", "農曆春節", "依規定放假"},
+	{"2025-01-30", "農曆春節", "依規定放假"},
+	{"2025-01-31", "農曆春節", "依規定放假"},
+	{"2025-02-03", "農曆春節後開始交易日", ""},
+	{"1140228", "和平紀念日", "依規定放假1日"},
+}}}
+
+func TestIsTradingDay(t *testing.T) {
+	service := newTestService(holidays2025)
+
+	tests := []struct {
+		name string
+		date time.Time
+		want bool
+	}{
+		{"published trading day", time.Date(2025, 1, 2, 10, 0, 0, 0, taipei), true},
+		{"published holiday", time.Date(2025, 1, 1, 10, 0, 0, 0, taipei), false},
+		{"weekend", time.Date(2025, 1, 4, 10, 0, 0, 0, taipei), false},
+		{"previous year", time.Date(2024, 12, 31, 10, 0, 0, 0, taipei), true},
+		{"utc date in taipei", time.Date(2024, 12, 31, 20, 0, 0, 0, time.UTC), false}, // 台北時間 2025-01-01
+		{"fallback holiday", time.Date(2025, 1, 27, 10, 0, 0, 0, taipei), false},
+		{"fallback roc date", time.Date(2025, 2, 28, 10, 0, 0, 0, taipei), false},
+		{"fallback trading resumes", time.Date(2025, 2, 3, 10, 0, 0, 0, taipei), true},
+		{"fallback weekend", time.Date(2025, 2, 1, 10, 0, 0, 0, taipei), false},
+		{"fallback weekday", time.Date(2025, 3, 3, 10, 0, 0, 0, taipei), true},
+	}
+	for _, tt := range tests {
+		if got := service.IsTradingDay(tt.date); got != tt.want {
+			t.Errorf("%s: IsTradingDay(%v) = %v, want %v", tt.name, tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestPreviousAndNextTradingDay(t *testing.T) {
+	service := newTestService(holidays2025)
+
+	tests := []struct {
+		name string
+		got  time.Time
+		want string
+	}{
+		{"previous across year", service.PreviousTradingDay(time.Date(2025, 1, 2, 9, 0, 0, 0, taipei)), "2024-12-31"},
+		{"previous over weekend", service.PreviousTradingDay(time.Date(2025, 1, 6, 9, 0, 0, 0, taipei)), "2025-01-03"},
+		{"next across year", service.NextTradingDay(time.Date(2024, 12, 31, 9, 0, 0, 0, taipei)), "2025-01-02"},
+		{"next over lunar new year", service.NextTradingDay(time.Date(2025, 1, 24, 9, 0, 0, 0, taipei)), "2025-02-03"},
+		{"previous over lunar new year", service.PreviousTradingDay(time.Date(2025, 2, 3, 9, 0, 0, 0, taipei)), "2025-01-24"},
+	}
+	for _, tt := range tests {
+		if got := tt.got.Format("2006-01-02"); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsTradingDayHolidayFetchFailed(t *testing.T) {
+	service := newTestService(&fakeHolidays{err: errors.New("unavailable")})
+
+	// 休市日載入失敗時，已公告範圍外僅能以平日判斷
+	if !service.IsTradingDay(time.Date(2025, 1, 27, 10, 0, 0, 0, taipei)) {
+		t.Error("weekday outside published range should fall back to weekday rule")
+	}
+	if service.IsTradingDay(time.Date(2025, 1, 1, 10, 0, 0, 0, taipei)) {
+		t.Error("published holiday should not be a trading day")
+	}
+	if calendar := service.years[2025]; time.Since(calendar.fetchedAt) < cacheTTL-retryInterval {
+		t.Error("partial load should be retried after retry interval")
+	}
+}
+
+func TestGetYearCalendarDoesNotBlockDuringFetch(t *testing.T) {
+	tradingDates := &fakeTradingDates{lastDate: map[int]string{2025: "2025-01-24"}, block: make(chan struct{})}
+	service := NewTradingCalendarService(tradingDates, holidays2025, nopLogger{}).(*tradingCalendarService)
+
+	go service.IsTradingDay(time.Date(2025, 1, 2, 10, 0, 0, 0, taipei))
+	// 等待第一個查詢開始載入
+	for {
+		service.mu.Lock()
+		loading := service.loading[2025]
+		service.mu.Unlock()
+		if loading {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan bool)
+	go func() { done <- service.IsTradingDay(time.Date(2025, 1, 4, 10, 0, 0, 0, taipei)) }()
+	select {
+	case got := <-done:
+		if got {
+			t.Error("weekend should not be a trading day while loading")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("IsTradingDay blocked while another caller was loading the calendar")
+	}
+	close(tradingDates.block)
+}
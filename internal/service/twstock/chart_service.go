@@ -61,8 +61,8 @@ func (s *stockService) GetStockPerformanceWithChart(stockID string, chartType st
 	return performanceResponse, nil
 }
 
-// GetStockHistoricalCandlesChart 取得股票歷史 K 線圖
-func (s *stockService) GetStockHistoricalCandlesChart(dto fugleDto.FugleCandlesRequestDto) ([]byte, string, error) {
+// GetStockHistoricalCandlesChart 取得股票歷史 K 線圖，theme 為圖表主題 (light/dark)
+func (s *stockService) GetStockHistoricalCandlesChart(dto fugleDto.FugleCandlesRequestDto, theme string) ([]byte, string, error) {
 	response, err := s.fugleClient.GetStockHistoricalCandles(dto)
 	if err != nil {
 		return nil, "", err
@@ -92,7 +92,7 @@ func (s *stockService) GetStockHistoricalCandlesChart(dto fugleDto.FugleCandlesR
 	}
 
 	// 產生圖表
-	chartBytes, err := imageutil.GenerateCandlestickChartPNG(chartData, stockName, symbol.Symbol)
+	chartBytes, err := imageutil.GenerateCandlestickChartPNG(chartData, stockName, symbol.Symbol, imageutil.ChartColorsForTheme(theme))
 	if err != nil {
 		return nil, stockName, fmt.Errorf("產生K線圖失敗: %v", err)
 	}
@@ -0,0 +1,147 @@
+package twstock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	stockDto "github.com/tian841224/stock-bot/internal/service/twstock/dto"
+	"github.com/tian841224/stock-bot/pkg/imageutil"
+
+	"go.uber.org/zap"
+)
+
+// ========== 多股比較相關方法 ==========
+
+// MaxComparisonSymbols 單次比較的股票數量上限
+const MaxComparisonSymbols = 6
+
+// comparisonPeriods 支援的比較期間與中文名稱
+var comparisonPeriods = map[string]string{
+	"1M":  "近一個月",
+	"3M":  "近三個月",
+	"6M":  "近六個月",
+	"YTD": "今年以來",
+	"1Y":  "近一年",
+	"3Y":  "近三年",
+	"5Y":  "近五年",
+}
+
+// IsComparisonPeriod 判斷字串是否為支援的比較期間
+func IsComparisonPeriod(period string) bool {
+	_, ok := comparisonPeriods[strings.ToUpper(strings.TrimSpace(period))]
+	return ok
+}
+
+// comparisonStartDate 依期間計算起始日期
+func comparisonStartDate(period string, now time.Time) time.Time {
+	switch period {
+	case "YTD":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	case "1M", "3M", "6M":
+		months, _ := strconv.Atoi(strings.TrimSuffix(period, "M"))
+		return now.AddDate(0, -months, 0)
+	default:
+		years, _ := strconv.Atoi(strings.TrimSuffix(period, "Y"))
+		return now.AddDate(-years, 0, 0)
+	}
+}
+
+// GetStockComparison 取得多檔股票在指定期間的累積報酬比較圖
+func (s *stockService) GetStockComparison(stockIDs []string, period string) (*stockDto.StockComparisonDto, error) {
+	s.logger.Info("產生多股比較圖", zap.Strings("stockIDs", stockIDs), zap.String("period", period))
+
+	if len(stockIDs) < 2 {
+		return nil, fmt.Errorf("請至少輸入兩檔股票代號")
+	}
+	if len(stockIDs) > MaxComparisonSymbols {
+		return nil, fmt.Errorf("最多只能比較 %d 檔股票", MaxComparisonSymbols)
+	}
+
+	period = strings.ToUpper(strings.TrimSpace(period))
+	if period == "" {
+		period = "1Y"
+	}
+	periodName, ok := comparisonPeriods[period]
+	if !ok {
+		return nil, fmt.Errorf("不支援的期間: %s，可使用 1M、3M、6M、YTD、1Y、3Y、5Y", period)
+	}
+
+	// 取得股票名稱並檢查重複
+	names := make([]string, len(stockIDs))
+	seen := make(map[string]bool, len(stockIDs))
+	for i, stockID := range stockIDs {
+		if seen[stockID] {
+			return nil, fmt.Errorf("股票代號重複: %s", stockID)
+		}
+		seen[stockID] = true
+
+		symbol, err := s.symbolsRepo.GetBySymbolAndMarket(stockID, "TW")
+		if err != nil || symbol == nil {
+			return nil, fmt.Errorf("查無股票代號: %s", stockID)
+		}
+		names[i] = symbol.Name
+	}
+
+	// 並行取得各股票累積漲跌幅
+	now := time.Now()
+	startDate := comparisonStartDate(period, now)
+	histories := make([][]stockDto.StockPerformanceData, len(stockIDs))
+	errs := make([]error, len(stockIDs))
+	var wg sync.WaitGroup
+	for i, stockID := range stockIDs {
+		wg.Add(1)
+		go func(i int, stockID string) {
+			defer wg.Done()
+			histories[i], errs[i] = s.GetStockPriceHistoryByRange(stockID, startDate, now)
+		}(i, stockID)
+	}
+	wg.Wait()
+
+	result := &stockDto.StockComparisonDto{
+		PeriodName: periodName,
+		StartDate:  startDate.Format("2006-01-02"),
+		EndDate:    now.Format("2006-01-02"),
+	}
+	series := make([]imageutil.PerformanceSeries, len(stockIDs))
+	for i, stockID := range stockIDs {
+		if errs[i] != nil || len(histories[i]) == 0 {
+			s.logger.Error("取得股價歷史失敗", zap.String("stockID", stockID), zap.Error(errs[i]))
+			return nil, fmt.Errorf("查無 %s 股價資料", stockID)
+		}
+
+		chartData := make([]imageutil.PerformanceData, len(histories[i]))
+		for j, data := range histories[i] {
+			chartData[j] = imageutil.PerformanceData{
+				Period:      data.Period,
+				PeriodName:  data.PeriodName,
+				Performance: data.Performance,
+			}
+		}
+		series[i] = imageutil.PerformanceSeries{
+			Name: fmt.Sprintf("%s %s", stockID, names[i]),
+			Data: chartData,
+		}
+
+		finalReturn, _ := strconv.ParseFloat(strings.TrimSuffix(histories[i][len(histories[i])-1].Performance, "%"), 64)
+		result.Items = append(result.Items, stockDto.StockComparisonItem{
+			StockID:     stockID,
+			Name:        names[i],
+			FinalReturn: finalReturn,
+		})
+	}
+
+	config := imageutil.DefaultChartConfig()
+	config.Title = fmt.Sprintf("%s累積報酬比較 (%s ~ %s)", periodName, result.StartDate, result.EndDate)
+	chartBytes, err := imageutil.GenerateMultiPerformanceChartPNG(series, config)
+	if err != nil {
+		s.logger.Error("生成比較圖失敗", zap.Error(err))
+		// 圖表生成失敗時仍回傳比較結果
+		return result, nil
+	}
+	result.ChartData = chartBytes
+
+	return result, nil
+}
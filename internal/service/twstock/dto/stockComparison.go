@@ -0,0 +1,17 @@
+package dto
+
+// StockComparisonDto 多檔股票累積報酬比較結果
+type StockComparisonDto struct {
+	PeriodName string                `json:"period_name"`
+	StartDate  string                `json:"start_date"`
+	EndDate    string                `json:"end_date"`
+	Items      []StockComparisonItem `json:"items"`
+	ChartData  []byte                `json:"chart_data,omitempty"`
+}
+
+// StockComparisonItem 單一股票比較結果
+type StockComparisonItem struct {
+	StockID     string  `json:"stock_id"`
+	Name        string  `json:"name"`
+	FinalReturn float64 `json:"final_return"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+// TreasuryYieldDto 美國公債殖利率曲線與利差資料
+type TreasuryYieldDto struct {
+	Date   string                `json:"date"`
+	Curve  []TreasuryYieldPoint  `json:"curve"`
+	Spread []TreasurySpreadPoint `json:"spread"`
+}
+
+// TreasuryYieldPoint 殖利率曲線上的單一期別
+type TreasuryYieldPoint struct {
+	Label string  `json:"label"`
+	Date  string  `json:"date"`
+	Yield float64 `json:"yield"`
+}
+
+// TreasurySpreadPoint 10年期與2年期殖利率利差
+type TreasurySpreadPoint struct {
+	Date     string  `json:"date"`
+	Yield2Y  float64 `json:"yield_2y"`
+	Yield10Y float64 `json:"yield_10y"`
+	Spread   float64 `json:"spread"`
+}
@@ -0,0 +1,108 @@
+package twstock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	"github.com/tian841224/stock-bot/pkg/imageutil"
+
+	"go.uber.org/zap"
+)
+
+// ========== 匯率相關方法 ==========
+
+// SupportedCurrencies 支援查詢的幣別與中文名稱
+var SupportedCurrencies = map[string]string{
+	"USD": "美元",
+	"JPY": "日圓",
+	"EUR": "歐元",
+	"CNY": "人民幣",
+	"HKD": "港幣",
+	"GBP": "英鎊",
+	"AUD": "澳幣",
+	"CAD": "加拿大幣",
+	"SGD": "新加坡幣",
+	"CHF": "瑞士法郎",
+	"ZAR": "南非幣",
+	"SEK": "瑞典幣",
+	"NZD": "紐西蘭幣",
+	"THB": "泰銖",
+	"PHP": "菲律賓比索",
+	"IDR": "印尼盾",
+	"KRW": "韓元",
+	"VND": "越南盾",
+	"MYR": "馬來幣",
+}
+
+// GetExchangeRateHistory 取得指定幣別近 N 個月的匯率資料（依日期由舊到新）
+func (s *stockService) GetExchangeRateHistory(currency string, months int) ([]dto.TaiwanExchangeRateData, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if _, ok := SupportedCurrencies[currency]; !ok {
+		return nil, fmt.Errorf("不支援的幣別: %s", currency)
+	}
+	if months <= 0 {
+		months = 1
+	}
+
+	now := time.Now()
+	requestDto := dto.FinmindtradeRequestDto{
+		DataID:    currency,
+		StartDate: now.AddDate(0, -months, 0).Format("2006-01-02"),
+		EndDate:   now.Format("2006-01-02"),
+	}
+
+	response, err := s.finmindClient.GetTaiwanExchangeRate(requestDto)
+	if err != nil {
+		s.logger.Error("呼叫 FinMind API 失敗", zap.Error(err))
+		return nil, err
+	}
+
+	if response.Status != 200 || len(response.Data) == 0 {
+		return nil, fmt.Errorf("查無匯率資料")
+	}
+
+	return response.Data, nil
+}
+
+// GetLatestExchangeRate 取得指定幣別最新一筆匯率
+func (s *stockService) GetLatestExchangeRate(currency string) (*dto.TaiwanExchangeRateData, error) {
+	// 取近一個月資料，避免連假期間查無資料
+	history, err := s.GetExchangeRateHistory(currency, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := history[len(history)-1]
+	return &latest, nil
+}
+
+// GetExchangeRateChart 取得指定幣別近三個月的匯率走勢圖
+func (s *stockService) GetExchangeRateChart(currency string) ([]byte, error) {
+	s.logger.Info("產生匯率走勢圖", zap.String("currency", currency))
+
+	history, err := s.GetExchangeRateHistory(currency, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	chartData := make([]imageutil.ExchangeRateChartData, 0, len(history))
+	for _, item := range history {
+		chartData = append(chartData, imageutil.ExchangeRateChartData{
+			Date:     item.Date,
+			CashBuy:  item.CashBuy,
+			CashSell: item.CashSell,
+			SpotBuy:  item.SpotBuy,
+			SpotSell: item.SpotSell,
+		})
+	}
+
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	chartBytes, err := imageutil.GenerateExchangeRateChartPNG(chartData, SupportedCurrencies[currency], currency)
+	if err != nil {
+		return nil, fmt.Errorf("產生匯率走勢圖失敗: %v", err)
+	}
+
+	return chartBytes, nil
+}
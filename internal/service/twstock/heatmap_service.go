@@ -0,0 +1,142 @@
+package twstock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tian841224/stock-bot/pkg/imageutil"
+	"github.com/tian841224/stock-bot/pkg/utils"
+
+	"go.uber.org/zap"
+)
+
+// ========== 產業熱力圖相關方法 ==========
+
+const (
+	heatmapMaxIndustries = 20 // 最多顯示的產業數
+	heatmapMaxStocks     = 10 // 每個產業最多顯示的個股數
+)
+
+// 不列入熱力圖的產業分類
+var heatmapExcludedIndustries = map[string]bool{
+	"":               true,
+	"ETF":            true,
+	"ETN":            true,
+	"受益證券":           true,
+	"存託憑證":           true,
+	"Index":          true,
+	"大盤":             true,
+	"上櫃指數股票型基金(ETF)": true,
+}
+
+// GetMarketHeatmap 取得依產業分組的市場熱力圖（方塊大小為成交金額，顏色為漲跌幅）
+func (s *stockService) GetMarketHeatmap(date string) ([]byte, string, error) {
+	s.logger.Info("產生產業熱力圖", zap.String("date", date))
+
+	queryDate := ""
+	if strings.TrimSpace(date) != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, "", fmt.Errorf("日期格式錯誤，請使用 YYYY-MM-DD 格式")
+		}
+		queryDate = parsed.Format("20060102")
+	}
+
+	// 建立股票代號與產業的對照
+	symbols, err := s.symbolsRepo.GetByMarket("TW")
+	if err != nil {
+		s.logger.Error("取得股票清單失敗", zap.Error(err))
+		return nil, "", err
+	}
+	industryOf := make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		if heatmapExcludedIndustries[symbol.Industry] {
+			continue
+		}
+		industryOf[symbol.Symbol] = symbol.Industry
+	}
+	if len(industryOf) == 0 {
+		return nil, "", fmt.Errorf("尚無產業分類資料，請先同步股票清單")
+	}
+
+	response, err := s.twseAPI.GetAfterTradingVolume("", queryDate)
+	if err != nil {
+		s.logger.Error("呼叫 TWSE API 失敗", zap.Error(err))
+		return nil, "", err
+	}
+	if len(response.Tables) <= 8 || len(response.Tables[8].Data) == 0 {
+		return nil, "", fmt.Errorf("查無資料，請確認日期是否為交易日")
+	}
+
+	// 第 9 個 table 為個股清單
+	groupItems := make(map[string][]imageutil.HeatmapItem)
+	for _, row := range response.Tables[8].Data {
+		if len(row) < 11 {
+			continue
+		}
+		stockID := strings.TrimSpace(utils.ToString(row[0]))
+		industry, ok := industryOf[stockID]
+		if !ok {
+			continue
+		}
+
+		amount := utils.ToFloat(row[4])
+		closePrice := utils.ToFloat(row[8])
+		if amount <= 0 || closePrice <= 0 {
+			continue
+		}
+
+		changeAmount := utils.ToFloat(row[10])
+		if utils.ExtractUpDownSign(utils.ToString(row[9])) == "-" {
+			changeAmount = -changeAmount
+		}
+		change := 0.0
+		if prevClose := closePrice - changeAmount; prevClose > 0 {
+			change = changeAmount / prevClose * 100
+		}
+
+		groupItems[industry] = append(groupItems[industry], imageutil.HeatmapItem{
+			Label:  strings.TrimSpace(utils.ToString(row[1])),
+			Value:  amount,
+			Change: change,
+		})
+	}
+	if len(groupItems) == 0 {
+		return nil, "", fmt.Errorf("查無資料，請確認日期是否為交易日")
+	}
+
+	groups := make([]imageutil.HeatmapGroup, 0, len(groupItems))
+	for industry, items := range groupItems {
+		sort.Slice(items, func(i, j int) bool { return items[i].Value > items[j].Value })
+		if len(items) > heatmapMaxStocks {
+			items = items[:heatmapMaxStocks]
+		}
+		groups = append(groups, imageutil.HeatmapGroup{Name: industry, Items: items})
+	}
+
+	// 依產業成交金額排序，只保留前幾大產業
+	groupTotal := func(g imageutil.HeatmapGroup) float64 {
+		total := 0.0
+		for _, item := range g.Items {
+			total += item.Value
+		}
+		return total
+	}
+	sort.Slice(groups, func(i, j int) bool { return groupTotal(groups[i]) > groupTotal(groups[j]) })
+	if len(groups) > heatmapMaxIndustries {
+		groups = groups[:heatmapMaxIndustries]
+	}
+
+	title := "台股產業熱力圖"
+	if date != "" {
+		title = fmt.Sprintf("台股產業熱力圖 %s", date)
+	}
+	chartBytes, err := imageutil.GenerateHeatmapPNG(groups, title)
+	if err != nil {
+		return nil, "", fmt.Errorf("產生熱力圖失敗: %v", err)
+	}
+
+	return chartBytes, title, nil
+}
@@ -217,14 +217,21 @@ func (s *stockService) GetStockPerformance(stockID string) (*stockDto.StockPerfo
 // GetStockPriceHistory 取得股票每日價格歷史（近5年）
 func (s *stockService) GetStockPriceHistory(stockID string) ([]stockDto.StockPerformanceData, error) {
 	now := time.Now()
+	return s.GetStockPriceHistoryByRange(stockID, now.AddDate(-5, 0, 0), now)
+}
+
+// GetStockPriceHistoryByRange 取得股票指定期間內相對於起始日的累積漲跌幅
+func (s *stockService) GetStockPriceHistoryByRange(stockID string, startDate, endDate time.Time) ([]stockDto.StockPerformanceData, error) {
+	if !startDate.Before(endDate) {
+		return nil, fmt.Errorf("起始日期需早於結束日期")
+	}
+
 	var performancePeriods []stockDto.StockPerformanceData
 
-	// 取得最近5年的股價資料
-	startDate := now.AddDate(-5, 0, 0)
 	startRequestDto := dto.FinmindtradeRequestDto{
 		DataID:    stockID,
 		StartDate: startDate.Format("2006-01-02"),
-		EndDate:   now.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
 	}
 
 	priceResponse, err := s.finmindClient.GetTaiwanStockPrice(startRequestDto)
@@ -271,7 +278,7 @@ func (s *stockService) GetStockPriceHistory(stockID string) ([]stockDto.StockPer
 	}
 
 	// 每隔幾天取一個點，避免資料點過多
-	step := len(priceResponse.Data) / 50 // 最多50個點，適合5年資料
+	step := len(priceResponse.Data) / 50 // 最多約50個點
 	if step < 1 {
 		step = 1
 	}
@@ -398,6 +405,11 @@ func (s *stockService) GetStockNews(stockID string) ([]dto.TaiwanNewsResponseDat
 	return response.Data, nil
 }
 
+// GetMarketNews 取得今日所有股票的新聞，不指定 data_id 查詢需 FinMind 會員權限
+func (s *stockService) GetMarketNews() ([]dto.TaiwanNewsResponseData, error) {
+	return s.GetStockNews("")
+}
+
 // GetStockIntradayQuote 取得股票盤中即時資料
 func (s *stockService) GetStockIntradayQuote(dto fugleDto.FugleStockQuoteRequestDto) (*fugleDto.FugleStockQuoteResponseDto, error) {
 	response, err := s.fugleClient.GetStockIntradayQuote(dto)
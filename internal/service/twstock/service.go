@@ -1,6 +1,8 @@
 package twstock
 
 import (
+	"time"
+
 	"github.com/tian841224/stock-bot/internal/infrastructure/cnyes"
 	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade"
 	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
@@ -18,8 +20,10 @@ type StockService interface {
 	GetStockPrice(stockID string, date ...string) (*stockDto.StockPriceInfo, error)
 	GetStockPerformance(stockID string) (*stockDto.StockPerformanceResponseDto, error)
 	GetStockPriceHistory(stockID string) ([]stockDto.StockPerformanceData, error)
+	GetStockPriceHistoryByRange(stockID string, startDate, endDate time.Time) ([]stockDto.StockPerformanceData, error)
 	GetAfterTradingVolume(symbol, date string) (*twseDto.AfterTradingVolumeResponseDto, error)
 	GetStockNews(stockID string) ([]dto.TaiwanNewsResponseData, error)
+	GetMarketNews() ([]dto.TaiwanNewsResponseData, error)
 	GetStockIntradayQuote(dto fugleDto.FugleStockQuoteRequestDto) (*fugleDto.FugleStockQuoteResponseDto, error)
 	GetStockHistoricalCandles(dto fugleDto.FugleCandlesRequestDto) (*fugleDto.FugleCandlesResponseDto, error)
 	GetStockInfo(stockID string) (*stockDto.StockQuoteInfo, error)
@@ -30,8 +34,15 @@ type StockService interface {
 	GetStockRevenue(stockID string) (*stockDto.RevenueDto, error)
 	GetDailyMarketInfo(count int) (twseDto.DailyMarketInfoResponseDto, error)
 	GetStockPerformanceWithChart(stockID string, chartType string) (*stockDto.StockPerformanceResponseDto, error)
-	GetStockHistoricalCandlesChart(dto fugleDto.FugleCandlesRequestDto) ([]byte, string, error)
+	GetStockHistoricalCandlesChart(dto fugleDto.FugleCandlesRequestDto, theme string) ([]byte, string, error)
 	GetStockRevenueChart(stockID string) ([]byte, error)
+	GetExchangeRateHistory(currency string, months int) ([]dto.TaiwanExchangeRateData, error)
+	GetLatestExchangeRate(currency string) (*dto.TaiwanExchangeRateData, error)
+	GetExchangeRateChart(currency string) ([]byte, error)
+	GetTreasuryYield() (*stockDto.TreasuryYieldDto, error)
+	GetTreasuryYieldChart(yield *stockDto.TreasuryYieldDto) ([]byte, error)
+	GetMarketHeatmap(date string) ([]byte, string, error)
+	GetStockComparison(stockIDs []string, period string) (*stockDto.StockComparisonDto, error)
 }
 
 // stockService 股票服務
@@ -0,0 +1,166 @@
+package twstock
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/infrastructure/finmindtrade/dto"
+	stockDto "github.com/tian841224/stock-bot/internal/service/twstock/dto"
+	"github.com/tian841224/stock-bot/pkg/imageutil"
+
+	"go.uber.org/zap"
+)
+
+// ========== 美國公債殖利率相關方法 ==========
+
+// treasuryTenor 公債期別
+type treasuryTenor struct {
+	label  string
+	dataID string
+}
+
+// treasuryTenors 殖利率曲線期別（依到期時間排序）
+var treasuryTenors = []treasuryTenor{
+	{"1M", "United States 1-Month"},
+	{"3M", "United States 3-Month"},
+	{"6M", "United States 6-Month"},
+	{"1Y", "United States 1-Year"},
+	{"2Y", "United States 2-Year"},
+	{"3Y", "United States 3-Year"},
+	{"5Y", "United States 5-Year"},
+	{"7Y", "United States 7-Year"},
+	{"10Y", "United States 10-Year"},
+	{"20Y", "United States 20-Year"},
+	{"30Y", "United States 30-Year"},
+}
+
+// GetTreasuryYield 取得美國公債最新殖利率曲線與近一年 10Y-2Y 利差
+func (s *stockService) GetTreasuryYield() (*stockDto.TreasuryYieldDto, error) {
+	now := time.Now()
+	endDate := now.Format("2006-01-02")
+	curveStartDate := now.AddDate(0, 0, -14).Format("2006-01-02")
+	spreadStartDate := now.AddDate(-1, 0, 0).Format("2006-01-02")
+
+	// 各期別分開查詢，2Y 與 10Y 取一年資料供利差使用
+	histories := make([][]dto.USGovernmentBondsYieldData, len(treasuryTenors))
+	var wg sync.WaitGroup
+	for i, tenor := range treasuryTenors {
+		startDate := curveStartDate
+		if tenor.label == "2Y" || tenor.label == "10Y" {
+			startDate = spreadStartDate
+		}
+
+		wg.Add(1)
+		go func(i int, tenor treasuryTenor, startDate string) {
+			defer wg.Done()
+			response, err := s.finmindClient.GetUSGovernmentBondsYield(dto.FinmindtradeRequestDto{
+				DataID:    tenor.dataID,
+				StartDate: startDate,
+				EndDate:   endDate,
+			})
+			if err != nil {
+				s.logger.Error("呼叫 FinMind API 失敗", zap.String("dataID", tenor.dataID), zap.Error(err))
+				return
+			}
+			histories[i] = response.Data
+		}(i, tenor, startDate)
+	}
+	wg.Wait()
+
+	result := &stockDto.TreasuryYieldDto{}
+	var history2Y, history10Y []dto.USGovernmentBondsYieldData
+	for i, tenor := range treasuryTenors {
+		history := histories[i]
+		switch tenor.label {
+		case "2Y":
+			history2Y = history
+		case "10Y":
+			history10Y = history
+		}
+
+		latest, ok := latestTreasuryYield(history)
+		if !ok {
+			continue
+		}
+		result.Curve = append(result.Curve, stockDto.TreasuryYieldPoint{
+			Label: tenor.label,
+			Date:  latest.Date,
+			Yield: latest.Value,
+		})
+		if latest.Date > result.Date {
+			result.Date = latest.Date
+		}
+	}
+
+	if len(result.Curve) == 0 {
+		return nil, fmt.Errorf("查無美國公債殖利率資料")
+	}
+
+	result.Spread = buildTreasurySpread(history2Y, history10Y)
+	return result, nil
+}
+
+// GetTreasuryYieldChart 產生殖利率曲線與利差走勢圖
+func (s *stockService) GetTreasuryYieldChart(yield *stockDto.TreasuryYieldDto) ([]byte, error) {
+	if yield == nil || len(yield.Curve) == 0 {
+		return nil, fmt.Errorf("無殖利率資料可生成圖表")
+	}
+
+	curve := make([]imageutil.YieldCurvePoint, 0, len(yield.Curve))
+	for _, point := range yield.Curve {
+		curve = append(curve, imageutil.YieldCurvePoint{Label: point.Label, Yield: point.Yield})
+	}
+
+	spread := make([]imageutil.YieldSpreadPoint, 0, len(yield.Spread))
+	for _, point := range yield.Spread {
+		spread = append(spread, imageutil.YieldSpreadPoint{Date: point.Date, Spread: point.Spread})
+	}
+
+	chartBytes, err := imageutil.GenerateYieldCurveChartPNG(curve, spread, yield.Date)
+	if err != nil {
+		return nil, fmt.Errorf("產生殖利率圖表失敗: %v", err)
+	}
+
+	return chartBytes, nil
+}
+
+// latestTreasuryYield 取得最新一筆有效殖利率
+func latestTreasuryYield(history []dto.USGovernmentBondsYieldData) (dto.USGovernmentBondsYieldData, bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Value > 0 {
+			return history[i], true
+		}
+	}
+	return dto.USGovernmentBondsYieldData{}, false
+}
+
+// buildTreasurySpread 以日期對齊 2Y 與 10Y 殖利率並計算利差
+func buildTreasurySpread(history2Y, history10Y []dto.USGovernmentBondsYieldData) []stockDto.TreasurySpreadPoint {
+	yield2YByDate := make(map[string]float64, len(history2Y))
+	for _, item := range history2Y {
+		if item.Value > 0 {
+			yield2YByDate[item.Date] = item.Value
+		}
+	}
+
+	spread := make([]stockDto.TreasurySpreadPoint, 0, len(history10Y))
+	for _, item := range history10Y {
+		yield2Y, ok := yield2YByDate[item.Date]
+		if !ok || item.Value <= 0 {
+			continue
+		}
+		spread = append(spread, stockDto.TreasurySpreadPoint{
+			Date:     item.Date,
+			Yield2Y:  yield2Y,
+			Yield10Y: item.Value,
+			Spread:   item.Value - yield2Y,
+		})
+	}
+
+	sort.Slice(spread, func(i, j int) bool {
+		return spread[i].Date < spread[j].Date
+	})
+	return spread
+}
@@ -17,6 +17,7 @@ type UserService interface {
 	UpdateUserStatus(userID uint, status bool) error
 	GetUserList(page, pageSize int) ([]*models.User, error)
 	GetOrCreate(accountID string, userType models.UserType) (*models.User, error)
+	UpdateAccountID(accountID, newAccountID string, userType models.UserType) error
 }
 
 // userService 使用者服務實作
@@ -102,3 +103,17 @@ func (s *userService) GetOrCreate(accountID string, userType models.UserType) (*
 
 	return user, nil
 }
+
+// UpdateAccountID 更新使用者帳號 ID，例如 Telegram 群組升級為超級群組後 chat ID 改變，保留原有訂閱
+func (s *userService) UpdateAccountID(accountID, newAccountID string, userType models.UserType) error {
+	user, err := s.userRepo.GetByAccountID(accountID, userType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	user.AccountID = newAccountID
+	return s.userRepo.Update(user)
+}
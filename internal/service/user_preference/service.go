@@ -0,0 +1,127 @@
+// Package user_preference 提供使用者偏好設定服務，包含勿擾時段判斷
+package user_preference
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+	"github.com/tian841224/stock-bot/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// defaultTimezone 未設定時區時使用台北時間
+const defaultTimezone = "Asia/Taipei"
+
+// clockPattern 勿擾時段時間格式 HH:MM
+var clockPattern = regexp.MustCompile(`^([01]?\d|2[0-3]):([0-5]\d)$`)
+
+// UserPreferenceService 使用者偏好設定服務介面
+type UserPreferenceService interface {
+	GetUserPreference(userID uint) (*models.UserPreference, error)
+	UpdateUserPreference(preference *models.UserPreference) error
+	QuietUntil(userID uint, now time.Time) (time.Time, bool)
+}
+
+type userPreferenceService struct {
+	preferenceRepo repository.UserPreferenceRepository
+}
+
+func NewUserPreferenceService(preferenceRepo repository.UserPreferenceRepository) UserPreferenceService {
+	return &userPreferenceService{preferenceRepo: preferenceRepo}
+}
+
+// GetUserPreference 取得使用者偏好設定，尚未設定時回傳預設值
+func (s *userPreferenceService) GetUserPreference(userID uint) (*models.UserPreference, error) {
+	preference, err := s.preferenceRepo.GetByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultUserPreference(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// UpdateUserPreference 儲存使用者偏好設定
+func (s *userPreferenceService) UpdateUserPreference(preference *models.UserPreference) error {
+	if err := ValidateQuietHours(preference.QuietStart, preference.QuietEnd); err != nil {
+		return err
+	}
+	return s.preferenceRepo.Save(preference)
+}
+
+// QuietUntil 使用者目前是否處於勿擾時段，是則回傳勿擾結束時間；取得設定失敗時視為未設定
+func (s *userPreferenceService) QuietUntil(userID uint, now time.Time) (time.Time, bool) {
+	preference, err := s.GetUserPreference(userID)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return QuietUntil(preference, now)
+}
+
+// ValidateQuietHours 驗證勿擾時段，開始及結束需同時設定或同時空白
+func ValidateQuietHours(start, end string) error {
+	if start == "" && end == "" {
+		return nil
+	}
+	if !clockPattern.MatchString(start) || !clockPattern.MatchString(end) {
+		return fmt.Errorf("時間格式錯誤，請使用 HH:MM，例如 22:00")
+	}
+	if clockMinutes(start) == clockMinutes(end) {
+		return fmt.Errorf("勿擾開始及結束時間不可相同")
+	}
+	return nil
+}
+
+// QuietUntil 判斷 now 是否位於勿擾時段，是則回傳勿擾結束時間
+func QuietUntil(preference *models.UserPreference, now time.Time) (time.Time, bool) {
+	if ValidateQuietHours(preference.QuietStart, preference.QuietEnd) != nil || preference.QuietStart == "" {
+		return time.Time{}, false
+	}
+
+	timezone := preference.Timezone
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.FixedZone(defaultTimezone, 8*60*60)
+	}
+
+	local := now.In(loc)
+	minutes := local.Hour()*60 + local.Minute()
+	start, end := clockMinutes(preference.QuietStart), clockMinutes(preference.QuietEnd)
+
+	var quiet bool
+	if start < end {
+		quiet = minutes >= start && minutes < end
+	} else {
+		// 跨午夜，例如 22:00-07:00
+		quiet = minutes >= start || minutes < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// clockMinutes 將 HH:MM 轉為當日分鐘數，呼叫前需先驗證格式
+func clockMinutes(clock string) int {
+	matches := clockPattern.FindStringSubmatch(clock)
+	if matches == nil {
+		return -1
+	}
+	hour, _ := strconv.Atoi(matches[1])
+	minute, _ := strconv.Atoi(matches[2])
+	return hour*60 + minute
+}
@@ -0,0 +1,57 @@
+package user_preference
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tian841224/stock-bot/internal/db/models"
+)
+
+func TestQuietUntil(t *testing.T) {
+	taipei := time.FixedZone("Asia/Taipei", 8*60*60)
+	preference := &models.UserPreference{QuietStart: "22:00", QuietEnd: "07:30", Timezone: "Asia/Taipei"}
+
+	tests := []struct {
+		name  string
+		now   time.Time
+		quiet bool
+		until time.Time
+	}{
+		{"before start", time.Date(2025, 1, 2, 21, 59, 0, 0, taipei), false, time.Time{}},
+		{"after start", time.Date(2025, 1, 2, 23, 0, 0, 0, taipei), true, time.Date(2025, 1, 3, 7, 30, 0, 0, taipei)},
+		{"after midnight", time.Date(2025, 1, 3, 6, 0, 0, 0, taipei), true, time.Date(2025, 1, 3, 7, 30, 0, 0, taipei)},
+		{"at end", time.Date(2025, 1, 3, 7, 30, 0, 0, taipei), false, time.Time{}},
+		{"other timezone", time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC), true, time.Date(2025, 1, 3, 7, 30, 0, 0, taipei)},
+	}
+	for _, tt := range tests {
+		until, quiet := QuietUntil(preference, tt.now)
+		if quiet != tt.quiet || !until.Equal(tt.until) {
+			t.Errorf("%s: QuietUntil() = %v, %v, want %v, %v", tt.name, until, quiet, tt.until, tt.quiet)
+		}
+	}
+
+	daytime := &models.UserPreference{QuietStart: "12:00", QuietEnd: "13:00"}
+	if until, quiet := QuietUntil(daytime, time.Date(2025, 1, 2, 12, 30, 0, 0, taipei)); !quiet || !until.Equal(time.Date(2025, 1, 2, 13, 0, 0, 0, taipei)) {
+		t.Errorf("daytime quiet hours: got %v, %v", until, quiet)
+	}
+	if _, quiet := QuietUntil(models.DefaultUserPreference(1), time.Now()); quiet {
+		t.Error("default preference should not have quiet hours")
+	}
+}
+
+func TestValidateQuietHours(t *testing.T) {
+	for _, tt := range []struct {
+		start, end string
+		valid      bool
+	}{
+		{"", "", true},
+		{"22:00", "07:00", true},
+		{"22:00", "", false},
+		{"25:00", "07:00", false},
+		{"08:00", "08:00", false},
+	} {
+		if err := ValidateQuietHours(tt.start, tt.end); (err == nil) != tt.valid {
+			t.Errorf("ValidateQuietHours(%q, %q) error = %v", tt.start, tt.end, err)
+		}
+	}
+}
@@ -11,6 +11,7 @@ type UserSubscriptionService interface {
 	AddUserSubscriptionItem(userID uint, item models.SubscriptionItem) error
 	UpdateUserSubscriptionItem(userID uint, item models.SubscriptionItem, status bool) error
 	GetUserSubscriptionList(userID uint) ([]*models.Subscription, error)
+	UpdateUserSubscriptionSchedule(userID uint, item models.SubscriptionItem, scheduleCron, timezone string) error
 	AddUserSubscriptionStock(userID uint, stockSymbol string) (bool, error)
 	DeleteUserSubscriptionStock(userID uint, stockSymbol string) (bool, error)
 	GetUserSubscriptionStockList(userID uint) ([]*repository.UserSubscriptionStock, error)
@@ -44,6 +45,11 @@ func (s *userSubscriptionService) GetUserSubscriptionList(userID uint) ([]*model
 	return s.userSubscriptionRepo.GetUserSubscriptionList(userID)
 }
 
+// UpdateUserSubscriptionSchedule 更新使用者訂閱項目的排程，scheduleCron 為空白時恢復預設排程
+func (s *userSubscriptionService) UpdateUserSubscriptionSchedule(userID uint, item models.SubscriptionItem, scheduleCron, timezone string) error {
+	return s.userSubscriptionRepo.UpdateUserSubscriptionSchedule(userID, item, scheduleCron, timezone)
+}
+
 // AddUserSubscriptionStock 新增使用者訂閱股票
 func (s *userSubscriptionService) AddUserSubscriptionStock(userID uint, stockSymbol string) (bool, error) {
 	return s.userSubscriptionRepo.AddUserSubscriptionStock(userID, stockSymbol)
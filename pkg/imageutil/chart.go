@@ -49,6 +49,9 @@ type ChartColors struct {
 	HighestPriceRed  color.RGBA // 最高價紅色
 	LowestPriceGreen color.RGBA // 最低價綠色
 	MonthlyAvgRed    color.RGBA // 月均價紅色
+
+	// 多序列折線色盤
+	SeriesPalette []color.RGBA
 }
 
 // DefaultChartColors 預設圖表顏色配置
@@ -86,9 +89,56 @@ func DefaultChartColors() ChartColors {
 		HighestPriceRed:  color.RGBA{100, 20, 20, 255},
 		LowestPriceGreen: color.RGBA{20, 80, 40, 255},
 		MonthlyAvgRed:    color.RGBA{80, 15, 15, 255},
+
+		// 多序列折線色盤
+		SeriesPalette: []color.RGBA{
+			{200, 60, 60, 255},  // 紅
+			{50, 100, 180, 255}, // 藍
+			{60, 150, 80, 255},  // 綠
+			{230, 140, 30, 255}, // 橘
+			{130, 80, 170, 255}, // 紫
+			{40, 160, 170, 255}, // 青
+		},
 	}
 }
 
+// DarkChartColors 深色主題圖表顏色配置，僅K線圖使用
+func DarkChartColors() ChartColors {
+	colors := DefaultChartColors()
+
+	colors.BackgroundWhite = color.RGBA{24, 26, 32, 255}
+	colors.BackgroundLightGray = color.RGBA{32, 34, 40, 255}
+
+	colors.TextDarkGray = color.RGBA{220, 220, 220, 255}
+	colors.TextBlack = color.RGBA{235, 235, 235, 255}
+	colors.TextRed = color.RGBA{240, 110, 110, 255}
+	colors.TextGreen = color.RGBA{110, 200, 140, 255}
+
+	colors.AxisDarkGray = color.RGBA{170, 170, 170, 255}
+	colors.AxisBlack = color.RGBA{200, 200, 200, 255}
+	colors.GridLightGray = color.RGBA{60, 64, 72, 255}
+	colors.GridDashedGray = color.RGBA{120, 120, 120, 255}
+
+	colors.KLineShadow = color.RGBA{180, 180, 180, 255}
+	colors.KLineUpRed = color.RGBA{230, 80, 80, 255}
+	colors.KLineDownGreen = color.RGBA{70, 190, 110, 255}
+	colors.VolumeUpRed = color.RGBA{230, 80, 80, 255}
+	colors.VolumeDownGreen = color.RGBA{70, 190, 110, 255}
+
+	colors.HighestPriceRed = color.RGBA{255, 120, 120, 255}
+	colors.LowestPriceGreen = color.RGBA{120, 220, 150, 255}
+	colors.MonthlyAvgRed = color.RGBA{240, 150, 150, 255}
+	return colors
+}
+
+// ChartColorsForTheme 依主題名稱取得圖表顏色配置，"dark" 以外皆使用預設淺色主題
+func ChartColorsForTheme(theme string) ChartColors {
+	if theme == "dark" {
+		return DarkChartColors()
+	}
+	return DefaultChartColors()
+}
+
 // ChartTitle 圖表標題配置
 type ChartTitle struct {
 	// 標題字型大小
@@ -743,15 +793,15 @@ func GenerateRevenueChartPNG(data []RevenueChartData, stockName string, stockCod
 	return buf.Bytes(), nil
 }
 
-// 生成K線圖 (PNG格式)
-func GenerateCandlestickChartPNG(data []CandlestickData, stockName string, symbol string) ([]byte, error) {
+// 生成K線圖 (PNG格式)，colors 為圖表主題顏色
+func GenerateCandlestickChartPNG(data []CandlestickData, stockName string, symbol string, colors ChartColors) ([]byte, error) {
 	if len(data) == 0 {
 		return nil, fmt.Errorf("無K線資料可生成圖表")
 	}
 
-	// 取得顏色和標題配置
-	colors := DefaultChartColors()
+	// 標題顏色跟隨主題文字顏色
 	titleConfig := DefaultChartTitle()
+	titleConfig.Color = colors.TextDarkGray
 
 	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
 		data[i], data[j] = data[j], data[i]
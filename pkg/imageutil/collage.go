@@ -0,0 +1,68 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+
+	// 註冊 JPEG 解碼
+	_ "image/jpeg"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// 拼貼圖背景色
+var collageBackground = color.RGBA{255, 255, 255, 255}
+
+// GenerateCollagePNG 將多張圖表縮放為相同大小後拼成一張圖片 (PNG格式)，依 columns 欄由左至右、由上至下排列
+// 無法解析的圖片略過，全部無法解析時回傳錯誤
+func GenerateCollagePNG(images [][]byte, columns, tileWidth, tileHeight int) ([]byte, error) {
+	if columns <= 0 || tileWidth <= 0 || tileHeight <= 0 {
+		return nil, fmt.Errorf("拼貼圖尺寸錯誤")
+	}
+
+	decoded := make([]image.Image, 0, len(images))
+	for _, data := range images {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		decoded = append(decoded, img)
+	}
+	if len(decoded) == 0 {
+		return nil, fmt.Errorf("無資料可生成拼貼圖")
+	}
+
+	if len(decoded) < columns {
+		columns = len(decoded)
+	}
+	rows := (len(decoded) + columns - 1) / columns
+
+	canvas := image.NewRGBA(image.Rect(0, 0, columns*tileWidth, rows*tileHeight))
+	xdraw.Draw(canvas, canvas.Bounds(), &image.Uniform{collageBackground}, image.Point{}, xdraw.Src)
+
+	for i, img := range decoded {
+		tile := image.Rect(0, 0, tileWidth, tileHeight).Add(image.Pt((i%columns)*tileWidth, (i/columns)*tileHeight))
+		xdraw.CatmullRom.Scale(canvas, fitRect(img.Bounds(), tile), img, img.Bounds(), xdraw.Over, nil)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, fmt.Errorf("編碼 PNG 失敗: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fitRect 等比例縮放 src 至 dst 範圍內並置中
+func fitRect(src, dst image.Rectangle) image.Rectangle {
+	scale := float64(dst.Dx()) / float64(src.Dx())
+	if heightScale := float64(dst.Dy()) / float64(src.Dy()); heightScale < scale {
+		scale = heightScale
+	}
+	width := int(float64(src.Dx()) * scale)
+	height := int(float64(src.Dy()) * scale)
+	offset := image.Pt((dst.Dx()-width)/2, (dst.Dy()-height)/2)
+	return image.Rect(0, 0, width, height).Add(dst.Min).Add(offset)
+}
@@ -0,0 +1,31 @@
+package imageutil
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestGenerateCollagePNG(t *testing.T) {
+	var tile bytes.Buffer
+	if err := png.Encode(&tile, image.NewRGBA(image.Rect(0, 0, 300, 100))); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := GenerateCollagePNG([][]byte{tile.Bytes(), []byte("invalid"), tile.Bytes(), tile.Bytes()}, 2, 200, 100)
+	if err != nil {
+		t.Fatalf("GenerateCollagePNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode collage: %v", err)
+	}
+	if got := img.Bounds().Size(); got != image.Pt(400, 200) {
+		t.Errorf("collage size = %v, want 400x200", got)
+	}
+
+	if _, err := GenerateCollagePNG([][]byte{[]byte("invalid")}, 2, 200, 100); err == nil {
+		t.Error("expected error when no image can be decoded")
+	}
+}
@@ -0,0 +1,215 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/freetype"
+)
+
+// PerformanceSeries 多序列績效圖的單一序列
+type PerformanceSeries struct {
+	Name string            // 圖例名稱
+	Data []PerformanceData // Period 需為 YYYY-MM-DD，依日期由舊到新
+}
+
+// comparisonPoint 解析後的序列資料點
+type comparisonPoint struct {
+	date  time.Time
+	value float64
+}
+
+// 生成多序列累積報酬比較圖 (PNG格式)
+func GenerateMultiPerformanceChartPNG(series []PerformanceSeries, config ChartConfig) ([]byte, error) {
+	if len(series) == 0 {
+		return nil, fmt.Errorf("無績效資料可生成圖表")
+	}
+
+	colors := DefaultChartColors()
+	titleConfig := DefaultChartTitle()
+	palette := colors.SeriesPalette
+	if len(series) > len(palette) {
+		return nil, fmt.Errorf("最多只能比較 %d 檔股票", len(palette))
+	}
+
+	// 解析各序列資料並計算日期與數值範圍
+	points := make([][]comparisonPoint, len(series))
+	var minDate, maxDate time.Time
+	minVal, maxVal := 0.0, 0.0
+	for i, s := range series {
+		if len(s.Data) == 0 {
+			return nil, fmt.Errorf("%s 無績效資料", s.Name)
+		}
+		for _, item := range s.Data {
+			date, err := time.Parse("2006-01-02", item.Period)
+			if err != nil {
+				return nil, fmt.Errorf("解析日期失敗: %v", err)
+			}
+			value, err := strconv.ParseFloat(strings.TrimSuffix(item.Performance, "%"), 64)
+			if err != nil {
+				return nil, fmt.Errorf("解析績效數據失敗: %v", err)
+			}
+			points[i] = append(points[i], comparisonPoint{date: date, value: value})
+
+			if minDate.IsZero() || date.Before(minDate) {
+				minDate = date
+			}
+			if date.After(maxDate) {
+				maxDate = date
+			}
+			if value < minVal {
+				minVal = value
+			}
+			if value > maxVal {
+				maxVal = value
+			}
+		}
+	}
+
+	margin := (maxVal - minVal) * 0.1
+	if margin == 0 {
+		margin = 1
+	}
+	minVal -= margin
+	maxVal += margin
+
+	// 建立圖片
+	img := image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{colors.BackgroundWhite}, image.Point{}, draw.Src)
+
+	// 載入字型
+	ttf, err := LoadChineseFont()
+	if err != nil {
+		return nil, fmt.Errorf("載入字型失敗: %v", err)
+	}
+
+	c := freetype.NewContext()
+	c.SetDPI(72)
+	c.SetFont(ttf)
+	c.SetFontSize(14)
+	c.SetClip(img.Bounds())
+	c.SetDst(img)
+	c.SetSrc(image.NewUniform(colors.TextDarkGray))
+
+	// 圖表區域，右側保留最終報酬標籤空間
+	chartLeft := 120
+	chartTop := 120
+	chartWidth := config.Width - 340
+	chartHeight := config.Height - 200
+
+	// 繪製標題
+	titleConfig.DrawTitle(c, config.Width, config.Height, config.Title)
+	c.SetFontSize(14)
+
+	// 繪製座標軸
+	drawLine(img, chartLeft, chartTop, chartLeft, chartTop+chartHeight, colors.AxisBlack)
+	drawLine(img, chartLeft, chartTop+chartHeight, chartLeft+chartWidth, chartTop+chartHeight, colors.AxisBlack)
+
+	// 座標換算，X 軸依日期比例
+	totalDays := maxDate.Sub(minDate).Hours()
+	xOf := func(date time.Time) int {
+		if totalDays == 0 {
+			return chartLeft + chartWidth/2
+		}
+		return chartLeft + int(float64(chartWidth)*date.Sub(minDate).Hours()/totalDays)
+	}
+	yOf := func(v float64) int {
+		return chartTop + chartHeight - int((v-minVal)/(maxVal-minVal)*float64(chartHeight))
+	}
+
+	// Y 軸標籤與水平格線
+	if config.ShowGrid {
+		yGridLines := 5
+		for i := 0; i <= yGridLines; i++ {
+			y := chartTop + (chartHeight * i / yGridLines)
+			value := maxVal - ((maxVal - minVal) * float64(i) / float64(yGridLines))
+			if i > 0 && i < yGridLines {
+				drawLine(img, chartLeft, y, chartLeft+chartWidth, y, colors.GridLightGray)
+			}
+			c.DrawString(fmt.Sprintf("%.1f%%", value), freetype.Pt(chartLeft-100, y+5))
+		}
+	}
+
+	// X 軸日期標籤，平均顯示 8 個
+	c.SetSrc(image.NewUniform(colors.TextBlack))
+	xLabels := 8
+	for i := 0; i <= xLabels; i++ {
+		date := minDate.Add(time.Duration(float64(maxDate.Sub(minDate)) * float64(i) / float64(xLabels)))
+		x := xOf(date)
+		if config.ShowGrid && i > 0 && i < xLabels {
+			drawDashedVerticalLine(img, x, chartTop, chartTop+chartHeight, colors.GridLightGray)
+		}
+		c.DrawString(date.Format("2006/01/02"), freetype.Pt(x-40, chartTop+chartHeight+25))
+	}
+
+	// 零線
+	if minVal < 0 && maxVal > 0 {
+		zeroY := yOf(0)
+		drawDashedLine(img, chartLeft, zeroY, chartLeft+chartWidth, zeroY, colors.GridDashedGray)
+	}
+
+	// 繪製各序列折線
+	for i, pts := range points {
+		col := palette[i]
+		for j := 1; j < len(pts); j++ {
+			drawThickLine(img, xOf(pts[j-1].date), yOf(pts[j-1].value), xOf(pts[j].date), yOf(pts[j].value), 2, col)
+		}
+		last := pts[len(pts)-1]
+		drawCircle(img, xOf(last.date), yOf(last.value), 4, col)
+	}
+
+	// 圖例，橫向排列於標題下方
+	if config.ShowLegend {
+		legendX := chartLeft
+		legendY := chartTop - 22
+		c.SetFontSize(14)
+		for i, s := range series {
+			drawRect(img, legendX, legendY-8, 20, 4, palette[i])
+			c.SetSrc(image.NewUniform(colors.TextBlack))
+			c.DrawString(s.Name, freetype.Pt(legendX+26, legendY))
+			legendX += 26 + int(textWidth(s.Name, 14)) + 30
+		}
+	}
+
+	// 最終報酬標籤，依位置排序後上下錯開避免重疊
+	type finalLabel struct {
+		index int
+		y     int
+	}
+	labels := make([]finalLabel, len(points))
+	for i, pts := range points {
+		labels[i] = finalLabel{index: i, y: yOf(pts[len(pts)-1].value)}
+	}
+	sort.Slice(labels, func(a, b int) bool { return labels[a].y < labels[b].y })
+	minGap := 20
+	for i := 1; i < len(labels); i++ {
+		if labels[i].y-labels[i-1].y < minGap {
+			labels[i].y = labels[i-1].y + minGap
+		}
+	}
+
+	c.SetFontSize(14)
+	for _, label := range labels {
+		pts := points[label.index]
+		text := fmt.Sprintf("%s %+.2f%%", series[label.index].Name, pts[len(pts)-1].value)
+		c.SetSrc(image.NewUniform(palette[label.index]))
+		c.DrawString(text, freetype.Pt(chartLeft+chartWidth+15, label.y+5))
+	}
+
+	// 軸標籤
+	c.SetSrc(image.NewUniform(colors.TextBlack))
+	c.DrawString("Return (%)", freetype.Pt(chartLeft-100, chartTop-22))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("編碼 PNG 失敗: %v", err)
+	}
+	return buf.Bytes(), nil
+}
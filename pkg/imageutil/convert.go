@@ -0,0 +1,22 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image/jpeg"
+	"image/png"
+)
+
+// PNGToJPEG 將 PNG 圖片轉為 JPEG（部分平台如 Telegram 行內結果僅接受 JPEG）
+func PNGToJPEG(data []byte, quality int) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析 PNG 失敗: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("編碼 JPEG 失敗: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,214 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/golang/freetype"
+)
+
+// ExchangeRateChartData 匯率圖表資料結構
+type ExchangeRateChartData struct {
+	Date     string  // 日期 (YYYY-MM-DD)
+	CashBuy  float64 // 現金買入
+	CashSell float64 // 現金賣出
+	SpotBuy  float64 // 即期買入
+	SpotSell float64 // 即期賣出
+}
+
+// exchangeRateSeries 匯率折線設定
+type exchangeRateSeries struct {
+	name  string
+	color color.RGBA
+	value func(d ExchangeRateChartData) float64
+}
+
+// 生成匯率走勢圖 (PNG格式)
+func GenerateExchangeRateChartPNG(data []ExchangeRateChartData, currencyName string, currency string) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("無匯率資料可生成圖表")
+	}
+
+	// 取得顏色和標題配置
+	colors := DefaultChartColors()
+	titleConfig := DefaultChartTitle()
+
+	config := DefaultChartConfig()
+	config.Title = fmt.Sprintf("%s (%s) 近三個月匯率走勢", currencyName, currency)
+
+	series := []exchangeRateSeries{
+		{"現金買入", color.RGBA{70, 110, 170, 255}, func(d ExchangeRateChartData) float64 { return d.CashBuy }},
+		{"現金賣出", colors.KLineUpRed, func(d ExchangeRateChartData) float64 { return d.CashSell }},
+		{"即期買入", color.RGBA{110, 170, 200, 255}, func(d ExchangeRateChartData) float64 { return d.SpotBuy }},
+		{"即期賣出", colors.KLineDownGreen, func(d ExchangeRateChartData) float64 { return d.SpotSell }},
+	}
+
+	// 計算所有有效匯率的範圍（FinMind 以 -1 或 0 表示無報價）
+	minVal, maxVal := 0.0, 0.0
+	hasValue := false
+	for _, d := range data {
+		for _, s := range series {
+			v := s.value(d)
+			if v <= 0 {
+				continue
+			}
+			if !hasValue {
+				minVal, maxVal = v, v
+				hasValue = true
+				continue
+			}
+			if v < minVal {
+				minVal = v
+			}
+			if v > maxVal {
+				maxVal = v
+			}
+		}
+	}
+	if !hasValue {
+		return nil, fmt.Errorf("無有效匯率資料可生成圖表")
+	}
+
+	margin := (maxVal - minVal) * 0.1
+	if margin == 0 {
+		margin = maxVal * 0.01
+	}
+	minVal -= margin
+	maxVal += margin
+
+	// 建立圖片
+	img := image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{colors.BackgroundWhite}, image.Point{}, draw.Src)
+
+	// 載入字型
+	ttf, err := LoadChineseFont()
+	if err != nil {
+		return nil, fmt.Errorf("載入字型失敗: %v", err)
+	}
+
+	c := freetype.NewContext()
+	c.SetDPI(72)
+	c.SetFont(ttf)
+	c.SetFontSize(14)
+	c.SetClip(img.Bounds())
+	c.SetDst(img)
+	c.SetSrc(image.NewUniform(colors.TextDarkGray))
+
+	// 圖表區域
+	chartLeft := 120
+	chartTop := 100
+	chartWidth := config.Width - 300
+	chartHeight := config.Height - 200
+
+	// 繪製標題
+	titleConfig.DrawTitle(c, config.Width, config.Height, config.Title)
+	c.SetFontSize(14)
+
+	// 繪製座標軸
+	drawLine(img, chartLeft, chartTop, chartLeft, chartTop+chartHeight, colors.AxisBlack)
+	drawLine(img, chartLeft, chartTop+chartHeight, chartLeft+chartWidth, chartTop+chartHeight, colors.AxisBlack)
+
+	// Y 軸標籤與水平格線
+	yGridLines := 5
+	for i := 0; i <= yGridLines; i++ {
+		y := chartTop + (chartHeight * i / yGridLines)
+		value := maxVal - ((maxVal - minVal) * float64(i) / float64(yGridLines))
+		if i > 0 && i < yGridLines {
+			drawLine(img, chartLeft, y, chartLeft+chartWidth, y, colors.GridLightGray)
+		}
+		c.DrawString(formatRateLabel(value), freetype.Pt(chartLeft-100, y+5))
+	}
+
+	// X 軸位置計算
+	xOf := func(i int) int {
+		if len(data) == 1 {
+			return chartLeft + chartWidth/2
+		}
+		return chartLeft + (chartWidth * i / (len(data) - 1))
+	}
+	yOf := func(v float64) int {
+		return chartTop + chartHeight - int((v-minVal)/(maxVal-minVal)*float64(chartHeight))
+	}
+
+	// X 軸日期標籤，約顯示 8 個
+	labelStep := len(data) / 8
+	if labelStep < 1 {
+		labelStep = 1
+	}
+	c.SetSrc(image.NewUniform(colors.TextBlack))
+	for i, d := range data {
+		if i%labelStep != 0 && i != len(data)-1 {
+			continue
+		}
+		// 避免與最後一個標籤重疊
+		if i != len(data)-1 && len(data)-1-i < labelStep/2 {
+			continue
+		}
+		x := xOf(i)
+		if i > 0 && i < len(data)-1 {
+			drawDashedVerticalLine(img, x, chartTop, chartTop+chartHeight, colors.GridLightGray)
+		}
+		label := d.Date
+		if len(label) >= 10 {
+			label = label[5:10]
+		}
+		c.DrawString(label, freetype.Pt(x-20, chartTop+chartHeight+25))
+	}
+
+	// 繪製折線，跳過無報價的點
+	for _, s := range series {
+		prevX, prevY := -1, -1
+		for i, d := range data {
+			v := s.value(d)
+			if v <= 0 {
+				prevX, prevY = -1, -1
+				continue
+			}
+			x, y := xOf(i), yOf(v)
+			if prevX >= 0 {
+				drawThickLine(img, prevX, prevY, x, y, 2, s.color)
+			}
+			prevX, prevY = x, y
+		}
+	}
+
+	// 圖例與最新報價
+	latest := data[len(data)-1]
+	legendX := chartLeft + chartWidth + 20
+	legendY := chartTop + 20
+	for _, s := range series {
+		drawRect(img, legendX, legendY-10, 16, 4, s.color)
+		c.SetSrc(image.NewUniform(s.color))
+		c.DrawString(s.name, freetype.Pt(legendX+24, legendY))
+		c.SetSrc(image.NewUniform(colors.TextBlack))
+		c.DrawString(formatRateLabel(s.value(latest)), freetype.Pt(legendX+24, legendY+20))
+		legendY += 55
+	}
+
+	// 軸標籤
+	c.SetSrc(image.NewUniform(colors.TextBlack))
+	c.DrawString("Date", freetype.Pt(chartLeft+chartWidth+20, chartTop+chartHeight+25))
+	c.DrawString("TWD", freetype.Pt(chartLeft-50, chartTop-10))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("編碼 PNG 失敗: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatRateLabel 依匯率大小決定小數位數
+func formatRateLabel(v float64) string {
+	switch {
+	case v <= 0:
+		return "-"
+	case v < 1:
+		return fmt.Sprintf("%.4f", v)
+	default:
+		return fmt.Sprintf("%.3f", v)
+	}
+}
@@ -0,0 +1,198 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"github.com/golang/freetype"
+)
+
+// HeatmapItem 熱力圖方塊資料
+type HeatmapItem struct {
+	Label  string  // 顯示名稱
+	Value  float64 // 方塊大小依據（成交金額或市值）
+	Change float64 // 漲跌幅 (%)
+}
+
+// HeatmapGroup 熱力圖分組（產業）
+type HeatmapGroup struct {
+	Name  string
+	Items []HeatmapItem
+}
+
+// 漲跌幅達到此值時顏色飽和
+const heatmapMaxChange = 7.0
+
+// 生成產業熱力圖 (PNG格式)
+func GenerateHeatmapPNG(groups []HeatmapGroup, title string) ([]byte, error) {
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("無資料可生成熱力圖")
+	}
+
+	colors := DefaultChartColors()
+	titleConfig := DefaultChartTitle()
+
+	config := DefaultChartConfig()
+	config.Height = 1000
+	config.Title = title
+
+	img := image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{colors.BackgroundWhite}, image.Point{}, draw.Src)
+
+	// 載入字型
+	ttf, err := LoadChineseFont()
+	if err != nil {
+		return nil, fmt.Errorf("載入字型失敗: %v", err)
+	}
+
+	c := freetype.NewContext()
+	c.SetDPI(72)
+	c.SetFont(ttf)
+	c.SetClip(img.Bounds())
+	c.SetDst(img)
+
+	titleConfig.Y = 5
+	titleConfig.DrawTitle(c, config.Width, config.Height, config.Title)
+
+	// 產業層級排版
+	groupValues := make([]float64, len(groups))
+	for i, group := range groups {
+		for _, item := range group.Items {
+			groupValues[i] += item.Value
+		}
+	}
+	area := TreemapRect{X: 10, Y: 70, W: float64(config.Width - 20), H: float64(config.Height - 80)}
+	groupRects := squarify(groupValues, area)
+
+	headerHeight := 22.0
+	white := color.RGBA{255, 255, 255, 255}
+	groupBorder := color.RGBA{40, 40, 48, 255}
+	for i, group := range groups {
+		groupRect := groupRects[i]
+		if groupRect.W < 2 || groupRect.H < 2 {
+			continue
+		}
+		fillRect(img, groupRect, groupBorder)
+
+		// 空間足夠才顯示產業標題列
+		inner := TreemapRect{X: groupRect.X + 1, Y: groupRect.Y + 1, W: groupRect.W - 2, H: groupRect.H - 2}
+		if groupRect.H > headerHeight*3 && groupRect.W > 80 {
+			c.SetFontSize(14)
+			c.SetSrc(image.NewUniform(white))
+			label := fitText(fmt.Sprintf("%s %+.2f%%", group.Name, weightedChange(group.Items)), 14, groupRect.W-8)
+			c.DrawString(label, freetype.Pt(int(groupRect.X)+4, int(groupRect.Y+headerHeight)-6))
+			inner.Y += headerHeight
+			inner.H -= headerHeight
+		}
+
+		itemValues := make([]float64, len(group.Items))
+		for j, item := range group.Items {
+			itemValues[j] = item.Value
+		}
+		itemRects := squarify(itemValues, inner)
+		for j, item := range group.Items {
+			drawHeatmapTile(img, c, itemRects[j], item)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("編碼 PNG 失敗: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawHeatmapTile 繪製單一方塊與標籤
+func drawHeatmapTile(img *image.RGBA, c *freetype.Context, rect TreemapRect, item HeatmapItem) {
+	if rect.W < 2 || rect.H < 2 {
+		return
+	}
+	tile := TreemapRect{X: rect.X + 1, Y: rect.Y + 1, W: rect.W - 2, H: rect.H - 2}
+	fillRect(img, tile, heatmapColor(item.Change))
+
+	// 依方塊大小決定字型，太小則不顯示文字
+	fontSize := 16.0
+	if tile.W < 110 || tile.H < 60 {
+		fontSize = 11
+	}
+	if tile.W < 44 || tile.H < 2*fontSize+6 {
+		return
+	}
+
+	c.SetFontSize(fontSize)
+	c.SetSrc(image.NewUniform(color.RGBA{255, 255, 255, 255}))
+
+	label := fitText(item.Label, fontSize, tile.W-6)
+	changeText := fmt.Sprintf("%+.2f%%", item.Change)
+	centerX := tile.X + tile.W/2
+	centerY := tile.Y + tile.H/2
+
+	c.DrawString(label, freetype.Pt(int(centerX-textWidth(label, fontSize)/2), int(centerY-2)))
+	c.DrawString(changeText, freetype.Pt(int(centerX-textWidth(changeText, fontSize)/2), int(centerY+fontSize+2)))
+}
+
+// heatmapColor 依漲跌幅計算方塊顏色（紅漲綠跌）
+func heatmapColor(change float64) color.RGBA {
+	neutral := color.RGBA{70, 70, 80, 255}
+	up := color.RGBA{210, 40, 50, 255}
+	down := color.RGBA{30, 150, 70, 255}
+
+	ratio := math.Min(math.Abs(change)/heatmapMaxChange, 1)
+	target := up
+	if change < 0 {
+		target = down
+	}
+	blend := func(from, to uint8) uint8 {
+		return uint8(float64(from) + (float64(to)-float64(from))*ratio)
+	}
+	return color.RGBA{blend(neutral.R, target.R), blend(neutral.G, target.G), blend(neutral.B, target.B), 255}
+}
+
+// weightedChange 以方塊大小加權計算分組漲跌幅
+func weightedChange(items []HeatmapItem) float64 {
+	total, weighted := 0.0, 0.0
+	for _, item := range items {
+		total += item.Value
+		weighted += item.Value * item.Change
+	}
+	if total == 0 {
+		return 0
+	}
+	return weighted / total
+}
+
+// fillRect 填滿矩形區域
+func fillRect(img *image.RGBA, rect TreemapRect, col color.RGBA) {
+	r := image.Rect(int(math.Round(rect.X)), int(math.Round(rect.Y)), int(math.Round(rect.X+rect.W)), int(math.Round(rect.Y+rect.H)))
+	draw.Draw(img, r, &image.Uniform{col}, image.Point{}, draw.Src)
+}
+
+// textWidth 估算文字寬度（全形字約為字型大小，半形字約為一半）
+func textWidth(text string, fontSize float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		if r < 128 {
+			width += fontSize * 0.55
+		} else {
+			width += fontSize
+		}
+	}
+	return width
+}
+
+// fitText 截斷文字使其不超過指定寬度
+func fitText(text string, fontSize, maxWidth float64) string {
+	if textWidth(text, fontSize) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && textWidth(string(runes), fontSize) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
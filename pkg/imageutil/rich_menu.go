@@ -0,0 +1,85 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/golang/freetype"
+)
+
+// RichMenuTile 圖文選單格子
+type RichMenuTile struct {
+	Label    string // 主要文字
+	SubLabel string // 次要文字（指令說明）
+}
+
+// 圖文選單配色
+var (
+	richMenuBackground = color.RGBA{33, 37, 41, 255}
+	richMenuTile       = color.RGBA{52, 58, 64, 255}
+	richMenuLabel      = color.RGBA{255, 255, 255, 255}
+	richMenuSubLabel   = color.RGBA{173, 181, 189, 255}
+)
+
+// GenerateRichMenuPNG 依格子數量生成圖文選單圖片 (PNG格式)，格子由左至右、由上至下排列
+func GenerateRichMenuPNG(width, height, columns int, tiles []RichMenuTile) ([]byte, error) {
+	if len(tiles) == 0 || columns <= 0 {
+		return nil, fmt.Errorf("無資料可生成圖文選單")
+	}
+	rows := (len(tiles) + columns - 1) / columns
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{richMenuBackground}, image.Point{}, draw.Src)
+
+	// 載入字型
+	ttf, err := LoadChineseFont()
+	if err != nil {
+		return nil, fmt.Errorf("載入字型失敗: %v", err)
+	}
+
+	c := freetype.NewContext()
+	c.SetDPI(72)
+	c.SetFont(ttf)
+	c.SetClip(img.Bounds())
+	c.SetDst(img)
+
+	tileWidth := float64(width) / float64(columns)
+	tileHeight := float64(height) / float64(rows)
+	labelSize := tileHeight / 5
+	subLabelSize := labelSize / 2
+
+	for i, tile := range tiles {
+		rect := TreemapRect{
+			X: float64(i%columns)*tileWidth + 4,
+			Y: float64(i/columns)*tileHeight + 4,
+			W: tileWidth - 8,
+			H: tileHeight - 8,
+		}
+		fillRect(img, rect, richMenuTile)
+
+		centerX := rect.X + rect.W/2
+		centerY := rect.Y + rect.H/2
+
+		c.SetFontSize(labelSize)
+		c.SetSrc(image.NewUniform(richMenuLabel))
+		label := fitText(tile.Label, labelSize, rect.W-20)
+		c.DrawString(label, freetype.Pt(int(centerX-textWidth(label, labelSize)/2), int(centerY)))
+
+		if tile.SubLabel != "" {
+			c.SetFontSize(subLabelSize)
+			c.SetSrc(image.NewUniform(richMenuSubLabel))
+			subLabel := fitText(tile.SubLabel, subLabelSize, rect.W-20)
+			c.DrawString(subLabel, freetype.Pt(int(centerX-textWidth(subLabel, subLabelSize)/2), int(centerY+subLabelSize*2)))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("編碼 PNG 失敗: %v", err)
+	}
+	return buf.Bytes(), nil
+}
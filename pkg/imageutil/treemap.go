@@ -0,0 +1,106 @@
+package imageutil
+
+import (
+	"math"
+	"sort"
+)
+
+// TreemapRect 樹狀圖矩形區域
+type TreemapRect struct {
+	X, Y, W, H float64
+}
+
+// squarify 以 squarified treemap 演算法依數值比例切分矩形，
+// 回傳的矩形順序與輸入數值相同，數值小於等於 0 的項目回傳空矩形
+func squarify(values []float64, bounds TreemapRect) []TreemapRect {
+	result := make([]TreemapRect, len(values))
+	if len(values) == 0 || bounds.W <= 0 || bounds.H <= 0 {
+		return result
+	}
+
+	total := 0.0
+	indexes := make([]int, 0, len(values))
+	for i, v := range values {
+		if v > 0 {
+			total += v
+			indexes = append(indexes, i)
+		}
+	}
+	if total == 0 {
+		return result
+	}
+
+	// 依數值由大到小排列，並換算為實際面積
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return values[indexes[a]] > values[indexes[b]]
+	})
+	scale := bounds.W * bounds.H / total
+	areas := make([]float64, len(indexes))
+	for i, idx := range indexes {
+		areas[i] = values[idx] * scale
+	}
+
+	remaining := bounds
+	rowStart := 0
+	for i := range areas {
+		shortSide := math.Min(remaining.W, remaining.H)
+		current := areas[rowStart:i]
+		// 加入下一個項目會讓長寬比變差時，先將目前這一列排版
+		if len(current) > 0 && worstRatio(areas[rowStart:i+1], shortSide) > worstRatio(current, shortSide) {
+			remaining = layoutRow(current, indexes[rowStart:i], remaining, result)
+			rowStart = i
+		}
+	}
+	layoutRow(areas[rowStart:], indexes[rowStart:], remaining, result)
+
+	return result
+}
+
+// worstRatio 計算一列矩形中最差的長寬比
+func worstRatio(row []float64, side float64) float64 {
+	if len(row) == 0 || side <= 0 {
+		return math.Inf(1)
+	}
+	sum, minArea, maxArea := 0.0, math.Inf(1), 0.0
+	for _, area := range row {
+		sum += area
+		minArea = math.Min(minArea, area)
+		maxArea = math.Max(maxArea, area)
+	}
+	sideSquare := side * side
+	sumSquare := sum * sum
+	return math.Max(sideSquare*maxArea/sumSquare, sumSquare/(sideSquare*minArea))
+}
+
+// layoutRow 沿較短邊排列一列矩形，回傳剩餘區域
+func layoutRow(row []float64, indexes []int, bounds TreemapRect, result []TreemapRect) TreemapRect {
+	sum := 0.0
+	for _, area := range row {
+		sum += area
+	}
+	if sum == 0 {
+		return bounds
+	}
+
+	if bounds.W >= bounds.H {
+		// 寬度較長：在左側排成一欄
+		width := sum / bounds.H
+		y := bounds.Y
+		for i, area := range row {
+			height := area / width
+			result[indexes[i]] = TreemapRect{X: bounds.X, Y: y, W: width, H: height}
+			y += height
+		}
+		return TreemapRect{X: bounds.X + width, Y: bounds.Y, W: bounds.W - width, H: bounds.H}
+	}
+
+	// 高度較長：在上方排成一列
+	height := sum / bounds.W
+	x := bounds.X
+	for i, area := range row {
+		width := area / height
+		result[indexes[i]] = TreemapRect{X: x, Y: bounds.Y, W: width, H: height}
+		x += width
+	}
+	return TreemapRect{X: bounds.X, Y: bounds.Y + height, W: bounds.W, H: bounds.H - height}
+}
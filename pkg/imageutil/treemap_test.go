@@ -0,0 +1,55 @@
+package imageutil
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSquarifyPreservesArea(t *testing.T) {
+	values := []float64{6, 6, 4, 3, 2, 2, 1}
+	bounds := TreemapRect{X: 0, Y: 0, W: 600, H: 400}
+
+	rects := squarify(values, bounds)
+	if len(rects) != len(values) {
+		t.Fatalf("squarify returned %d rects; expected %d", len(rects), len(values))
+	}
+
+	total := 24.0
+	for i, rect := range rects {
+		expected := values[i] / total * bounds.W * bounds.H
+		if math.Abs(rect.W*rect.H-expected) > 1e-6 {
+			t.Errorf("rect %d area = %f; expected %f", i, rect.W*rect.H, expected)
+		}
+		if rect.X < bounds.X-1e-6 || rect.Y < bounds.Y-1e-6 ||
+			rect.X+rect.W > bounds.X+bounds.W+1e-6 || rect.Y+rect.H > bounds.Y+bounds.H+1e-6 {
+			t.Errorf("rect %d %+v is outside bounds", i, rect)
+		}
+	}
+}
+
+func TestSquarifyNoOverlap(t *testing.T) {
+	values := []float64{10, 8, 5, 5, 3, 1, 1, 0.5}
+	rects := squarify(values, TreemapRect{W: 1000, H: 500})
+
+	for i := 0; i < len(rects); i++ {
+		for j := i + 1; j < len(rects); j++ {
+			a, b := rects[i], rects[j]
+			overlapW := math.Min(a.X+a.W, b.X+b.W) - math.Max(a.X, b.X)
+			overlapH := math.Min(a.Y+a.H, b.Y+b.H) - math.Max(a.Y, b.Y)
+			if overlapW > 1e-6 && overlapH > 1e-6 {
+				t.Errorf("rect %d %+v overlaps rect %d %+v", i, a, j, b)
+			}
+		}
+	}
+}
+
+func TestSquarifySkipsNonPositive(t *testing.T) {
+	rects := squarify([]float64{0, 5, -1, 5}, TreemapRect{W: 100, H: 100})
+
+	if rects[0] != (TreemapRect{}) || rects[2] != (TreemapRect{}) {
+		t.Errorf("non-positive values should get empty rects, got %+v and %+v", rects[0], rects[2])
+	}
+	if math.Abs(rects[1].W*rects[1].H-5000) > 1e-6 || math.Abs(rects[3].W*rects[3].H-5000) > 1e-6 {
+		t.Errorf("positive values should split the area evenly, got %+v and %+v", rects[1], rects[3])
+	}
+}
@@ -0,0 +1,217 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/golang/freetype"
+)
+
+// YieldCurvePoint 殖利率曲線資料點
+type YieldCurvePoint struct {
+	Label string  // 期別 (1M, 2Y, 10Y...)
+	Yield float64 // 殖利率 (%)
+}
+
+// YieldSpreadPoint 利差資料點
+type YieldSpreadPoint struct {
+	Date   string  // 日期 (YYYY-MM-DD)
+	Spread float64 // 10Y-2Y 利差 (百分點)
+}
+
+// 生成美國公債殖利率曲線與 10Y-2Y 利差走勢圖 (PNG格式)
+func GenerateYieldCurveChartPNG(curve []YieldCurvePoint, spread []YieldSpreadPoint, date string) ([]byte, error) {
+	if len(curve) == 0 {
+		return nil, fmt.Errorf("無殖利率資料可生成圖表")
+	}
+
+	colors := DefaultChartColors()
+	titleConfig := DefaultChartTitle()
+
+	config := DefaultChartConfig()
+	config.Height = 1100
+	config.Title = fmt.Sprintf("美國公債殖利率曲線 (%s)", date)
+
+	img := image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{colors.BackgroundWhite}, image.Point{}, draw.Src)
+
+	// 載入字型
+	ttf, err := LoadChineseFont()
+	if err != nil {
+		return nil, fmt.Errorf("載入字型失敗: %v", err)
+	}
+
+	c := freetype.NewContext()
+	c.SetDPI(72)
+	c.SetFont(ttf)
+	c.SetClip(img.Bounds())
+	c.SetDst(img)
+
+	// 繪製標題
+	titleConfig.Y = 6
+	titleConfig.DrawTitle(c, config.Width, config.Height, config.Title)
+	c.SetFontSize(14)
+
+	chartLeft := 120
+	chartWidth := config.Width - 240
+
+	// ===== 上半部：殖利率曲線 =====
+	curveTop := 110
+	curveHeight := 380
+
+	minYield, maxYield := curve[0].Yield, curve[0].Yield
+	for _, point := range curve {
+		if point.Yield < minYield {
+			minYield = point.Yield
+		}
+		if point.Yield > maxYield {
+			maxYield = point.Yield
+		}
+	}
+	minYield, maxYield = padRange(minYield, maxYield)
+
+	drawLine(img, chartLeft, curveTop, chartLeft, curveTop+curveHeight, colors.AxisBlack)
+	drawLine(img, chartLeft, curveTop+curveHeight, chartLeft+chartWidth, curveTop+curveHeight, colors.AxisBlack)
+	drawHorizontalGrid(img, c, colors, chartLeft, curveTop, chartWidth, curveHeight, minYield, maxYield, "%.2f%%")
+
+	curveX := func(i int) int {
+		if len(curve) == 1 {
+			return chartLeft + chartWidth/2
+		}
+		return chartLeft + 40 + ((chartWidth - 80) * i / (len(curve) - 1))
+	}
+	curveY := func(v float64) int {
+		return curveTop + curveHeight - int((v-minYield)/(maxYield-minYield)*float64(curveHeight))
+	}
+
+	curveColor := color.RGBA{70, 110, 170, 255}
+	for i, point := range curve {
+		x, y := curveX(i), curveY(point.Yield)
+		if i > 0 {
+			drawThickLine(img, curveX(i-1), curveY(curve[i-1].Yield), x, y, 3, curveColor)
+		}
+	}
+	for i, point := range curve {
+		x, y := curveX(i), curveY(point.Yield)
+		drawCircle(img, x, y, 5, curveColor)
+
+		c.SetSrc(image.NewUniform(colors.TextBlack))
+		c.DrawString(fmt.Sprintf("%.2f", point.Yield), freetype.Pt(x-18, y-12))
+		c.DrawString(point.Label, freetype.Pt(x-12, curveTop+curveHeight+25))
+	}
+	c.DrawString("殖利率 (%)", freetype.Pt(chartLeft-60, curveTop-15))
+
+	// ===== 下半部：10Y-2Y 利差走勢 =====
+	spreadTop := curveTop + curveHeight + 120
+	spreadHeight := 380
+
+	c.SetFontSize(16)
+	c.SetSrc(image.NewUniform(colors.TextDarkGray))
+	c.DrawString("10Y-2Y 利差走勢 (百分點)", freetype.Pt(chartLeft, spreadTop-30))
+	c.SetFontSize(14)
+
+	drawLine(img, chartLeft, spreadTop, chartLeft, spreadTop+spreadHeight, colors.AxisBlack)
+	drawLine(img, chartLeft, spreadTop+spreadHeight, chartLeft+chartWidth, spreadTop+spreadHeight, colors.AxisBlack)
+
+	if len(spread) == 0 {
+		c.SetSrc(image.NewUniform(colors.TextDarkGray))
+		c.DrawString("暫無利差資料", freetype.Pt(chartLeft+chartWidth/2-50, spreadTop+spreadHeight/2))
+	} else {
+		minSpread, maxSpread := 0.0, 0.0
+		for _, point := range spread {
+			if point.Spread < minSpread {
+				minSpread = point.Spread
+			}
+			if point.Spread > maxSpread {
+				maxSpread = point.Spread
+			}
+		}
+		minSpread, maxSpread = padRange(minSpread, maxSpread)
+		drawHorizontalGrid(img, c, colors, chartLeft, spreadTop, chartWidth, spreadHeight, minSpread, maxSpread, "%.2f")
+
+		spreadX := func(i int) int {
+			if len(spread) == 1 {
+				return chartLeft + chartWidth/2
+			}
+			return chartLeft + (chartWidth * i / (len(spread) - 1))
+		}
+		spreadY := func(v float64) int {
+			return spreadTop + spreadHeight - int((v-minSpread)/(maxSpread-minSpread)*float64(spreadHeight))
+		}
+
+		// 零軸（低於零代表殖利率倒掛）
+		zeroY := spreadY(0)
+		drawDashedLine(img, chartLeft, zeroY, chartLeft+chartWidth, zeroY, colors.GridDashedGray)
+
+		for i := 1; i < len(spread); i++ {
+			lineColor := colors.KLineDownGreen
+			if spread[i].Spread < 0 {
+				lineColor = colors.KLineUpRed
+			}
+			drawThickLine(img, spreadX(i-1), spreadY(spread[i-1].Spread), spreadX(i), spreadY(spread[i].Spread), 2, lineColor)
+		}
+
+		// X 軸日期標籤，約顯示 8 個
+		labelStep := len(spread) / 8
+		if labelStep < 1 {
+			labelStep = 1
+		}
+		c.SetSrc(image.NewUniform(colors.TextBlack))
+		for i, point := range spread {
+			if i%labelStep != 0 && i != len(spread)-1 {
+				continue
+			}
+			// 避免與最後一個標籤重疊
+			if i != len(spread)-1 && len(spread)-1-i < labelStep/2 {
+				continue
+			}
+			label := point.Date
+			if len(label) >= 10 {
+				label = label[2:7]
+			}
+			c.DrawString(label, freetype.Pt(spreadX(i)-20, spreadTop+spreadHeight+25))
+		}
+
+		// 最新利差
+		latest := spread[len(spread)-1]
+		latestColor := colors.TextGreen
+		if latest.Spread < 0 {
+			latestColor = colors.TextRed
+		}
+		c.SetSrc(image.NewUniform(latestColor))
+		c.DrawString(fmt.Sprintf("%+.2f", latest.Spread), freetype.Pt(chartLeft+chartWidth+10, spreadY(latest.Spread)+5))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("編碼 PNG 失敗: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// padRange 在數值範圍上下保留 10% 空間
+func padRange(minVal, maxVal float64) (float64, float64) {
+	margin := (maxVal - minVal) * 0.1
+	if margin == 0 {
+		margin = 0.1
+	}
+	return minVal - margin, maxVal + margin
+}
+
+// drawHorizontalGrid 繪製水平格線與 Y 軸標籤
+func drawHorizontalGrid(img *image.RGBA, c *freetype.Context, colors ChartColors, left, top, width, height int, minVal, maxVal float64, format string) {
+	c.SetSrc(image.NewUniform(colors.TextDarkGray))
+	gridLines := 5
+	for i := 0; i <= gridLines; i++ {
+		y := top + (height * i / gridLines)
+		value := maxVal - ((maxVal - minVal) * float64(i) / float64(gridLines))
+		if i > 0 && i < gridLines {
+			drawLine(img, left, y, left+width, y, colors.GridLightGray)
+		}
+		c.DrawString(fmt.Sprintf(format, value), freetype.Pt(left-90, y+5))
+	}
+}